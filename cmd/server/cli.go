@@ -0,0 +1,301 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"imposter/internal/app"
+	"imposter/internal/buildinfo"
+	"imposter/internal/config"
+)
+
+// configFlagVars are the settings with a flag of their own; any other can be
+// set with -set KEY=VALUE
+var configFlagVars = []struct {
+	name, key, usage string
+}{
+	{"config", "CONFIG_FILE", "file of KEY=VALUE settings, re-read on SIGHUP"},
+	{"port", "PORT", "HTTP port"},
+	{"host", "HOST", "address to listen on"},
+	{"listen", "LISTEN", "unix:/path/to.sock or systemd instead of host and port"},
+	{"env", "ENV", "development or production"},
+	{"log-level", "LOG_LEVEL", "debug, info, warn or error"},
+	{"log-format", "LOG_FORMAT", "json or text"},
+	{"admin-token", "ADMIN_TOKEN", "bearer token for the admin API"},
+	{"database-url", "DATABASE_URL", "Postgres URL or SQLite file"},
+	{"words-file", "WORDS_FILE", "word list offered as \"file\""},
+	{"words-dir", "WORDS_DIR", "directory of JSON and YAML word lists"},
+	{"max-rooms", "MAX_ROOMS", "rooms open at once; 0 is unlimited"},
+	{"rate-limit", "RATE_LIMIT_ENABLED", "true or false"},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the subcommand named by the first argument, serve if there is none,
+// and returns the exit code
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		return runServe(args)
+	case "validate-config":
+		return runValidateConfig(args)
+	case "wordlist":
+		return runWordlist(args)
+	case "simulate":
+		return runSimulate(args)
+	case "typescript":
+		return runTypescript(args)
+	case "version":
+		return runVersion()
+	case "help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		printUsage(os.Stderr)
+		return 2
+	}
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: imposter <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  serve             run the game server (the default)")
+	fmt.Fprintln(w, "  validate-config   check the configuration and exit")
+	fmt.Fprintln(w, "  wordlist check    check word list files for problems")
+	fmt.Fprintln(w, "  simulate          load-test a running server")
+	fmt.Fprintln(w, "  typescript        write TypeScript declarations of the protocol")
+	fmt.Fprintln(w, "  version           print the version")
+	fmt.Fprintln(w, "\nRun imposter <command> -h for a command's flags.")
+}
+
+// configFlags adds flags for the configuration to flags. The returned function,
+// called once flags are parsed, loads and validates the configuration with the
+// flags given taking precedence over CONFIG_FILE and the environment.
+func configFlags(flags *flag.FlagSet) func() (*config.Config, error) {
+	values := make(map[string]*string, len(configFlagVars))
+	for _, v := range configFlagVars {
+		values[v.name] = flags.String(v.name, "", fmt.Sprintf("%s (%s)", v.usage, v.key))
+	}
+
+	var settings []string
+	flags.Func("set", "set any setting, as KEY=VALUE; may be repeated", func(s string) error {
+		if key, _, ok := strings.Cut(s, "="); !ok || key == "" {
+			return fmt.Errorf("expected KEY=VALUE")
+		}
+		settings = append(settings, s)
+		return nil
+	})
+
+	return func() (*config.Config, error) {
+		for _, s := range settings {
+			key, value, _ := strings.Cut(s, "=")
+			config.Override(key, value)
+		}
+		flags.Visit(func(f *flag.Flag) {
+			for _, v := range configFlagVars {
+				if v.name == f.Name {
+					config.Override(v.key, *values[v.name])
+				}
+			}
+		})
+
+		if err := config.ReadFile(); err != nil {
+			return nil, fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+		}
+		cfg := config.Load()
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration:\n%w", err)
+		}
+		return cfg, nil
+	}
+}
+
+// runValidateConfig runs the validate-config subcommand, which checks the
+// configuration the server would start with, and returns the exit code
+func runValidateConfig(args []string) int {
+	flags := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: imposter validate-config [flags]")
+		fmt.Fprintln(flags.Output(), "\nChecks the configuration from the flags, CONFIG_FILE and the environment,")
+		fmt.Fprintln(flags.Output(), "as serve would, and reports every problem found.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	loadConfig := configFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if _, err := loadConfig(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("configuration is valid")
+	return 0
+}
+
+// runWordlist runs the wordlist subcommand and returns the exit code
+func runWordlist(args []string) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, "Usage: imposter wordlist check [flags] [file or directory...]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("wordlist check", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: imposter wordlist check [flags] [file or directory...]")
+		fmt.Fprintln(flags.Output(), "\nLoads word lists as the server would, WORDS_FILE and WORDS_DIR unless paths")
+		fmt.Fprintln(flags.Output(), "are given, and reports lists that fail to load or have no words left after")
+		fmt.Fprintln(flags.Output(), "WORDS_BLOCKLIST, and warns of duplicates and words with unusual characters.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	strict := flags.Bool("strict", false, "fail on warnings too")
+	loadConfig := configFlags(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		for _, path := range []string{cfg.Words.File, cfg.Words.Dir} {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "nothing to check: give word list files or directories, or set WORDS_FILE or WORDS_DIR")
+		return 2
+	}
+
+	failures, warnings := 0, 0
+	for _, path := range paths {
+		lists, err := loadWordLists(path)
+		if err != nil {
+			fmt.Printf("%s: error: %v\n", path, err)
+			failures++
+			continue
+		}
+
+		names := make([]string, 0, len(lists))
+		for name := range lists {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			e, w := checkWordList(os.Stdout, name, lists[name], cfg.Words.Blocklist)
+			failures += e
+			warnings += w
+		}
+	}
+
+	if failures > 0 || (*strict && warnings > 0) {
+		return 1
+	}
+	return 0
+}
+
+// loadWordLists loads a word list file, named by its path, or the lists in a
+// directory, named as the server offers them
+func loadWordLists(path string) (map[string]*app.ListWordProvider, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		list, err := app.LoadWordList(path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]*app.ListWordProvider{path: list}, nil
+	}
+
+	lists, err := app.LoadWordDir(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("no .json, .yaml or .yml word lists")
+	}
+	return lists, nil
+}
+
+// checkWordList reports on a word list, returning how many errors and
+// warnings it found
+func checkWordList(w io.Writer, name string, list *app.ListWordProvider, blocklist []string) (failures, warnings int) {
+	words := list.Words()
+	usable := list.Without(blocklist).Words()
+	fmt.Fprintf(w, "%s: %d words", name, len(usable))
+	if blocked := len(words) - len(usable); blocked > 0 {
+		fmt.Fprintf(w, " (%d blocklisted)", blocked)
+	}
+	fmt.Fprintln(w)
+
+	if len(usable) == 0 {
+		fmt.Fprintf(w, "%s: error: no words left after the blocklist\n", name)
+		failures++
+	}
+
+	seen := make(map[string]bool, len(words))
+	var duplicates, unusual []string
+	for _, word := range words {
+		if seen[word] {
+			duplicates = append(duplicates, word)
+		}
+		seen[word] = true
+
+		for _, r := range word {
+			if !unicode.IsLetter(r) && r != ' ' && r != '-' {
+				unusual = append(unusual, word)
+				break
+			}
+		}
+	}
+	if len(duplicates) > 0 {
+		fmt.Fprintf(w, "%s: warning: duplicated: %s\n", name, strings.Join(duplicates, ", "))
+		warnings++
+	}
+	if len(unusual) > 0 {
+		fmt.Fprintf(w, "%s: warning: characters other than letters, spaces and hyphens: %s\n", name, strings.Join(unusual, ", "))
+		warnings++
+	}
+	return failures, warnings
+}
+
+// runVersion prints the version and what it was built from
+func runVersion() int {
+	info := buildinfo.Get()
+	fmt.Println("imposter", info.Version)
+	if info.Commit != "" {
+		fmt.Println("commit:", info.Commit)
+	}
+	if info.Date != "" {
+		fmt.Println("date:", info.Date)
+	}
+	if info.Modified {
+		fmt.Println("modified: true")
+	}
+	fmt.Printf("go: %s %s/%s\n", info.GoVersion, info.OS, info.Arch)
+	return 0
+}
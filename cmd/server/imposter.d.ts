@@ -0,0 +1,946 @@
+// Code generated by "imposter typescript". DO NOT EDIT.
+
+export interface ClientMessage {
+    type: MessageType;
+    payload?: unknown;
+}
+
+export interface ServerMessage {
+    type: MessageType;
+    payload?: unknown;
+    timestamp: string;
+}
+
+export interface HelloPayload {
+    protocolVersion: ProtocolVersion;
+    locale?: string;
+    acks?: boolean;
+}
+
+export interface JoinLobbyPayload {
+    nickname: string;
+    password?: string;
+    invite?: string;
+}
+
+export interface SubmitWordPayload {
+    word: string;
+}
+
+export interface CastVotePayload {
+    targetPlayerId: string;
+}
+
+export interface AudienceVotePayload {
+    targetPlayerId: string;
+}
+
+export interface CreateInvitePayload {
+    ttlSeconds?: number;
+    maxUses?: number;
+}
+
+export interface AckPayload {
+    seq: number;
+}
+
+export interface ProtocolPayload {
+    protocolVersion: ProtocolVersion;
+    supportedVersions: ProtocolVersion[];
+    encoding: Encoding;
+    supportedEncodings: Encoding[];
+}
+
+export interface ConnectedPayload {
+    playerId: string;
+    gameId: string;
+    reconnectToken: string;
+    gameState: Record<string, unknown>;
+    lastSeq: number;
+    protocolVersion: ProtocolVersion;
+    supportedVersions: ProtocolVersion[];
+    encoding: Encoding;
+    supportedEncodings: Encoding[];
+    serverVersion: string;
+}
+
+export interface ResumedPayload {
+    playerId: string;
+    gameId: string;
+    reconnectToken: string;
+}
+
+export interface SpectatingPayload {
+    gameId: string;
+    gameState: Record<string, unknown>;
+    protocolVersion: ProtocolVersion;
+    supportedVersions: ProtocolVersion[];
+    encoding: Encoding;
+    supportedEncodings: Encoding[];
+}
+
+export interface ReplayingPayload {
+    replayId: string;
+    roomCode: string;
+    rounds: number;
+    events: number;
+    durationMs: number;
+    speed: number;
+}
+
+export interface InviteCreatedPayload {
+    token: string;
+    expiresAt: string;
+    maxUses: number;
+}
+
+export interface SystemNoticePayload {
+    message: string;
+    severity: NoticeSeverity;
+}
+
+export interface ErrorPayload {
+    code: string;
+    message: string;
+    field?: string;
+    retryable: boolean;
+    details?: Record<string, unknown>;
+    requestId?: string;
+}
+
+export interface GameEvent {
+    type: EventType;
+    gameId: string;
+    playerId?: string;
+    payload?: unknown;
+    timestamp: string;
+    seq?: number;
+}
+
+export type EventType = string;
+
+export interface LobbyUpdatePayload {
+    players: PlayerInfo[];
+    hostId: string;
+    canStart: boolean;
+}
+
+export interface LobbyDeltaPayload {
+    changed?: PlayerInfo[];
+    removed?: string[];
+    hostId: string;
+    canStart: boolean;
+}
+
+export interface GameStartedPayload {
+    roundNumber: number;
+    playerCount: number;
+}
+
+export interface PhaseChangedPayload {
+    from: Phase;
+    to: Phase;
+    round?: number;
+}
+
+export interface RoleAssignedPayload {
+    role: Role;
+    secretWord?: string;
+}
+
+export interface SubmissionPhasePayload {
+    currentPlayerId: string;
+    playerOrder: PlayerInfo[];
+    submissions: Submission[];
+}
+
+export interface SubmissionUpdatePayload {
+    submissions: Submission[];
+    currentPlayerId: string;
+    isComplete: boolean;
+}
+
+export interface SubmissionDeltaPayload {
+    added: Submission | null;
+    currentPlayerId: string;
+    isComplete: boolean;
+}
+
+export interface VotingPhasePayload {
+    remainingSeconds: number;
+    deadline: string;
+    players: PlayerInfo[];
+    candidates?: string[];
+}
+
+export interface VotingCountdownPayload {
+    remainingSeconds: number;
+    deadline: string;
+}
+
+export interface VoteUpdatePayload {
+    votedCount: number;
+    totalPlayers: number;
+}
+
+export interface RoundResultsPayload {
+    votes: VoteResult[];
+    imposterId: string;
+    imposterIds: string[];
+    winner: Role;
+    secretWord: string;
+    accusedId?: string;
+    tied?: string[];
+    audience?: AudienceSuspicion;
+}
+
+export interface RoomExpiringPayload {
+    expiresAt: string;
+}
+
+export interface CheatSuspectedPayload {
+    kind: SuspicionKind;
+    round: number;
+    playerIds: string[];
+    nicknames: string[];
+    detail: string;
+}
+
+export interface TournamentUpdatePayload {
+    tournamentId: string;
+    name: string;
+    change: TournamentChange;
+    stage: number;
+    roomCode?: string;
+    winnerId?: string;
+    standings: TournamentStanding[];
+}
+
+export interface DomainErrorPayload {
+    code: string;
+    message: string;
+}
+
+export interface PlayerInfo {
+    id: string;
+    nickname: string;
+    hasVoted: boolean;
+    hasSubmitted: boolean;
+    status: ConnectionStatus;
+    isBot?: boolean;
+}
+
+export interface Response {
+    success: boolean;
+    data?: unknown;
+    error?: ErrorInfo;
+}
+
+export interface ErrorInfo {
+    code: string;
+    message: string;
+    field?: string;
+    retryable: boolean;
+    details?: Record<string, unknown>;
+    requestId?: string;
+}
+
+export interface CreateRoomRequest {
+    password?: string;
+    visibility?: Visibility;
+    code?: string;
+    codeLength?: number;
+    wordSource?: string;
+    language?: string;
+    tags?: string[];
+    maxPlayers?: number;
+    voteQuorum?: VoteQuorum;
+    tieRule?: TieRule;
+    seed?: number;
+    imposters?: string;
+    imposterThresholds?: number[];
+    familyFriendly?: boolean;
+    practice?: boolean;
+    practiceRole?: Role;
+}
+
+export interface CreateRoomResponse {
+    roomCode: string;
+    inviteLink: string;
+}
+
+export interface QuickJoinRequest {
+    language?: string;
+    maxPlayers?: number;
+    wordSource?: string;
+}
+
+export interface QuickJoinResponse {
+    roomCode: string;
+    inviteLink: string;
+    created: boolean;
+}
+
+export interface GetRoomResponse {
+    roomCode: string;
+    playerCount: number;
+    connectedPlayers: number;
+    spectators: number;
+    phase: string;
+    canJoin: boolean;
+    locked: boolean;
+}
+
+export interface ListRoomsResponse {
+    rooms: PublicRoom[];
+    page?: number;
+    pageSize: number;
+    total: number;
+    nextCursor?: string;
+}
+
+export interface RoomExistsResponse {
+    exists: boolean;
+}
+
+export interface PollEventsResponse {
+    events: SequencedEvent[];
+    lastSeq: number;
+    missed: boolean;
+}
+
+export interface JoinRequest {
+    nickname: string;
+    password?: string;
+    invite?: string;
+}
+
+export interface JoinResponse {
+    playerId: string;
+    token: string;
+}
+
+export interface SubmitWordRequest {
+    word: string;
+}
+
+export interface CastVoteRequest {
+    targetPlayerId: string;
+}
+
+export interface AudienceVotesRequest {
+    votes: AudienceVote[];
+}
+
+export interface AudienceVotesResponse {
+    recorded: number;
+}
+
+export interface CreateInviteRequest {
+    ttlSeconds?: number;
+    maxUses?: number;
+}
+
+export interface CreateInviteResponse {
+    token: string;
+    createdBy: string;
+    createdAt: string;
+    expiresAt: string;
+    maxUses: number;
+    uses: number;
+    nickname?: string;
+    inviteLink: string;
+}
+
+export interface ActionResponse {
+    phase: string;
+}
+
+export interface HealthResponse {
+    status: string;
+    dependencies?: Record<string, CheckResult>;
+}
+
+export interface ProbeResponse {
+    status: string;
+    checks?: Record<string, CheckResult>;
+}
+
+export interface StatsResponse {
+    activeGames: number;
+    hibernatedGames: number;
+    gamesByPhase: Record<string, number>;
+    totalPlayers: number;
+    connectedPlayers: number;
+    spectators: number;
+    rateLimits?: RateLimitMetrics;
+}
+
+export interface MeResponse {
+    authenticated: boolean;
+    playerId?: string;
+    name?: string;
+    provider?: string;
+    providers: string[];
+}
+
+export interface TournamentsResponse {
+    tournaments: Tournament[];
+}
+
+export interface RegisterTournamentRequest {
+    nickname: string;
+}
+
+export interface Tournament {
+    id: string;
+    name: string;
+    format: TournamentFormat;
+    status: TournamentStatus;
+    groupSize: number;
+    roundsPerMatch: number;
+    stages?: number;
+    stage: number;
+    winnerId?: string;
+    standings: TournamentStanding[];
+    matches: TournamentMatch[];
+    createdAt: string;
+    startedAt?: string;
+    finishedAt?: string;
+}
+
+export interface TournamentRegistration {
+    tournamentId: string;
+    playerId: string;
+    nickname: string;
+    token: string;
+}
+
+export interface TournamentAssignment {
+    tournamentId: string;
+    playerId: string;
+    nickname: string;
+    status: TournamentStatus;
+    stage: number;
+    roomCode?: string;
+    invite?: string;
+    eliminated: boolean;
+}
+
+export interface WordListResponse {
+    words: WordEntry[];
+}
+
+export interface AddWordRequest {
+    word: string;
+    category: string;
+}
+
+export interface WordEntry {
+    word: string;
+    category?: string;
+    disabled: boolean;
+    custom: boolean;
+    updatedAt: string;
+}
+
+export interface WordUpdate {
+    category: string | null;
+    disabled: boolean | null;
+}
+
+export interface GraphQLRequest {
+    query: string;
+    operationName: string;
+    variables: Record<string, unknown>;
+}
+
+export interface Replay {
+    id: string;
+    roomCode: string;
+    createdAt: string;
+    rounds: number;
+    events: GameEvent[];
+}
+
+export interface Transcript {
+    roomCode: string;
+    language?: string;
+    rounds: TranscriptRound[];
+    exportedAt: string;
+}
+
+export interface Report {
+    games: number;
+    rounds: number;
+    avgRoundSeconds: number;
+    gamesPerDay: DayReport[];
+    imposterWinRate: PlayerCountReport[];
+    topWords: WordCount[];
+}
+
+export interface Info {
+    version: string;
+    commit?: string;
+    date?: string;
+    modified?: boolean;
+    goVersion: string;
+    os: string;
+    arch: string;
+}
+
+export interface AdminOverviewResponse {
+    rooms: number;
+    hibernated: number;
+    players: number;
+    connections: number;
+    history: HubSample[];
+}
+
+export interface AdminRoomsResponse {
+    rooms: AdminRoom[];
+}
+
+export interface AdminWordStatsResponse {
+    words: WordStats[];
+}
+
+export interface ReloadWordsResponse {
+    wordSources: string[];
+}
+
+export interface ReloadResult {
+    applied: string[];
+    restartRequired: string[];
+    wordSources: string[];
+}
+
+export interface LogLevelRequest {
+    level: string;
+}
+
+export interface LogLevelResponse {
+    level: string;
+}
+
+export interface AnnouncementRequest {
+    message: string;
+    severity?: NoticeSeverity;
+    translations?: Record<string, string>;
+}
+
+export interface AnnouncementResponse {
+    severity: NoticeSeverity;
+    recipients: number;
+}
+
+export interface DeleteRoomResponse {
+    roomCode: string;
+    deleted: boolean;
+}
+
+export interface ForcePhaseRequest {
+    phase: Phase;
+}
+
+export interface AdminInvitesResponse {
+    roomCode: string;
+    invites: Invite[];
+}
+
+export interface RevokeInviteResponse {
+    token: string;
+    revoked: boolean;
+}
+
+export interface ImportRoomResponse {
+    roomCode: string;
+    playerCount: number;
+    phase: string;
+}
+
+export interface RuntimeStatsResponse {
+    goroutines: number;
+    heapAllocBytes: number;
+    heapInuseBytes: number;
+    heapObjects: number;
+    sysBytes: number;
+    numGC: number;
+    lastGCPauseMs: number;
+    activeGames: number;
+    totalPlayers: number;
+    goVersion: string;
+    eventQueue: EventQueueStats;
+}
+
+export interface SessionDetails {
+    roomCode: string;
+    phase: Phase;
+    hostId: string;
+    players: PlayerInfo[];
+    rounds: RoundSummary[];
+    canJoin: boolean;
+    locked: boolean;
+    visibility: Visibility;
+    language?: string;
+    wordSource?: string;
+    tags?: string[];
+    familyFriendly?: boolean;
+    settings: GameSettings;
+    spectators: number;
+    createdAt: string;
+    currentRound?: Round;
+    connectedPlayers: string[];
+    eventQueue: EventQueueStats;
+    metrics: RoomMetrics;
+}
+
+export interface SessionDump {
+    roomCode: string;
+    dumpedAt: string;
+    redacted: boolean;
+    game: unknown;
+    recordId?: string;
+    closed: boolean;
+    timers: TimerDump;
+    clients: ClientDump[];
+    queues: QueueDump;
+    quotas: RoomQuotas;
+    metrics: RoomMetrics;
+    buffers: BufferDump[];
+    hooks: number;
+    tokens: number;
+    bots: string[];
+}
+
+export interface RoomExport {
+    version: number;
+    roomCode: string;
+    exportedAt: string;
+    state: unknown;
+}
+
+export type MessageType = string;
+
+export type ProtocolVersion = number;
+
+export type Encoding = string;
+
+export type NoticeSeverity = string;
+
+export type Phase = string;
+
+export type Role = string;
+
+export interface Submission {
+    playerId: string;
+    nickname: string;
+    word: string;
+    order: number;
+    timestamp: string;
+}
+
+export interface VoteResult {
+    playerId: string;
+    nickname: string;
+    voteCount: number;
+    votedBy: string[];
+    isImposter: boolean;
+}
+
+export interface AudienceSuspicion {
+    voters: number;
+    players: AudienceSuspect[];
+}
+
+export type SuspicionKind = string;
+
+export type TournamentChange = string;
+
+export interface TournamentStanding {
+    rank: number;
+    playerId: string;
+    nickname: string;
+    points: number;
+    eliminated: boolean;
+}
+
+export type ConnectionStatus = string;
+
+export type Visibility = string;
+
+export type VoteQuorum = string;
+
+export type TieRule = string;
+
+export interface PublicRoom {
+    roomCode: string;
+    phase: Phase;
+    playerCount: number;
+    connectedPlayers: number;
+    spectators: number;
+    minPlayers: number;
+    maxPlayers: number;
+    votingDurationSecs: number;
+    locked: boolean;
+    language?: string;
+    wordSource?: string;
+    tags?: string[];
+    familyFriendly: boolean;
+    createdAt: string;
+}
+
+export interface SequencedEvent {
+    seq: number;
+    event: GameEvent | null;
+}
+
+export interface AudienceVote {
+    voterId: string;
+    targetPlayerId?: string;
+    targetNickname?: string;
+}
+
+export interface CheckResult {
+    status: string;
+    error?: string;
+    latencyMs: number;
+}
+
+export interface RateLimitMetrics {
+    rooms: RateLimiterStats;
+    connects: RateLimiterStats;
+}
+
+export type TournamentFormat = string;
+
+export type TournamentStatus = string;
+
+export interface TournamentMatch {
+    stage: number;
+    roomCode: string;
+    playerIds: string[];
+    points: Record<string, number>;
+    rounds: number;
+    finished: boolean;
+    advancing?: string[];
+}
+
+export interface TranscriptRound {
+    number: number;
+    secretWord: string;
+    imposter: TranscriptPlayer;
+    imposters: TranscriptPlayer[];
+    winner: Role;
+    clues: TranscriptClue[];
+    votes: TranscriptVote[];
+    startedAt: string;
+    endedAt: string;
+}
+
+export interface DayReport {
+    day: string;
+    games: number;
+    rounds: number;
+    avgRoundSeconds: number;
+}
+
+export interface PlayerCountReport {
+    players: number;
+    rounds: number;
+    imposterWins: number;
+    winRate: number;
+}
+
+export interface WordCount {
+    word: string;
+    count: number;
+}
+
+export interface HubSample {
+    at: string;
+    rooms: number;
+    players: number;
+    connections: number;
+    eventsPerSecond: number;
+    messagesPerSecond: number;
+}
+
+export interface AdminRoom {
+    roomCode: string;
+    phase: Phase;
+    hostId: string;
+    players: PlayerInfo[];
+    rounds: RoundSummary[];
+    canJoin: boolean;
+    locked: boolean;
+    visibility: Visibility;
+    language?: string;
+    wordSource?: string;
+    tags?: string[];
+    familyFriendly?: boolean;
+    settings: GameSettings;
+    spectators: number;
+    createdAt: string;
+    connections: number;
+    metrics: RoomMetrics;
+    lastActivity: string;
+}
+
+export interface WordStats {
+    word: string;
+    rounds: number;
+    imposterWins: number;
+    imposterWinRate: number;
+    avgVoteSpread: number;
+    difficulty: Difficulty;
+}
+
+export interface Invite {
+    token: string;
+    createdBy: string;
+    createdAt: string;
+    expiresAt: string;
+    maxUses: number;
+    uses: number;
+    nickname?: string;
+}
+
+export interface EventQueueStats {
+    coalesced: number;
+    shed: number;
+}
+
+export interface RoundSummary {
+    number: number;
+    secretWord: string;
+    imposterId: string;
+    imposterIds: string[];
+    winner: Role;
+    submissions: Submission[];
+    startedAt: string;
+    endedAt: string;
+}
+
+export interface GameSettings {
+    minPlayers: number;
+    maxPlayers: number;
+    votingDuration: number;
+    roleRevealTime: number;
+    voteQuorum?: VoteQuorum;
+    tieRule?: TieRule;
+    emoji?: EmojiPolicy;
+    imposters: ImposterCount;
+}
+
+export interface Round {
+    number: number;
+    secretWord: string;
+    imposterId: string;
+    imposterIds?: string[];
+    submissions: Submission[];
+    votes: Vote[];
+    currentPlayerIdx: number;
+    playerOrder: string[];
+    winner?: Role;
+    voters?: string[];
+    candidates?: string[];
+    tied?: string[];
+    accusedId?: string;
+    startedAt: string;
+    endedAt?: string;
+}
+
+export interface RoomMetrics {
+    eventsBroadcast: number;
+    eventsDropped: number;
+    eventsResent: number;
+    messagesReceived: number;
+    reconnects: number;
+    votesCast: number;
+    voteLatencySumSeconds: number;
+    avgVoteLatencyMs: number;
+}
+
+export interface TimerDump {
+    phaseTimerSet: boolean;
+    timerGeneration: number;
+    phaseRemainingNs: number;
+    deadlines: Record<string, string>;
+    votingStartedAt?: string;
+    lastActivity: string;
+    idleFor: string;
+    expiryWarned: boolean;
+    disconnectedAt?: string;
+}
+
+export interface ClientDump {
+    id: string;
+    type: string;
+    spectator?: boolean;
+    bot?: boolean;
+    ip?: string;
+}
+
+export interface QueueDump {
+    pending: number;
+    capacity: number;
+    stats: EventQueueStats;
+    replayEvents: number;
+}
+
+export interface RoomQuotas {
+    MaxConnections: number;
+    EventQueueSize: number;
+    MaxBots: number;
+}
+
+export interface BufferDump {
+    playerId: string;
+    events: number;
+    lastSeq: number;
+}
+
+export interface AudienceSuspect {
+    playerId: string;
+    votes: number;
+    share: number;
+}
+
+export interface RateLimiterStats {
+    allowed: number;
+    rejected: number;
+    trackedClients: number;
+}
+
+export interface TranscriptPlayer {
+    id: string;
+    nickname: string;
+}
+
+export interface TranscriptClue {
+    order: number;
+    player: TranscriptPlayer;
+    word: string;
+}
+
+export interface TranscriptVote {
+    voter: TranscriptPlayer;
+    target: TranscriptPlayer;
+}
+
+export type Difficulty = string;
+
+export type EmojiPolicy = string;
+
+export interface ImposterCount {
+    auto?: boolean;
+    fixed?: number;
+    thresholds?: number[];
+}
+
+export interface Vote {
+    voterId: string;
+    targetId: string;
+    timestamp: string;
+}
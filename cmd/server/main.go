@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"embed"
 	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -11,43 +15,502 @@ import (
 	"syscall"
 	"time"
 
+	"imposter/internal/analytics"
 	"imposter/internal/app"
-	"imposter/internal/config"
+	"imposter/internal/broadcast"
+	"imposter/internal/buildinfo"
+	"imposter/internal/broker"
+	"imposter/internal/domain"
+	"imposter/internal/logfile"
+	"imposter/internal/sentry"
+	"imposter/internal/storage"
+	grpcTransport "imposter/internal/transport/grpc"
 	httpTransport "imposter/internal/transport/http"
+	"imposter/internal/webhook"
+	"imposter/internal/wordgen"
 )
 
 //go:embed web/*
 var webFS embed.FS
 
-func main() {
+// runServe runs the serve subcommand, the game server, until it is
+// interrupted, and returns the exit code
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: imposter [serve] [flags]")
+		fmt.Fprintln(flags.Output(), "\nRuns the game server. Flags override CONFIG_FILE and the environment.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	loadConfig := configFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
 
-	// Set up logger
-	var logger *slog.Logger
+	// Set up logger; the level can change on reload
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(parseLogLevel(cfg.Logging.Level))
 	logOpts := &slog.HandlerOptions{
-		Level: parseLogLevel(cfg.Logging.Level),
+		Level: logLevel,
+	}
+	newLogger := func(w io.Writer) *slog.Logger {
+		if cfg.Logging.Format == "json" {
+			return slog.New(slog.NewJSONHandler(w, logOpts))
+		}
+		return slog.New(slog.NewTextHandler(w, logOpts))
 	}
 
-	if cfg.Logging.Format == "json" {
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, logOpts))
-	} else {
-		logger = slog.New(slog.NewTextHandler(os.Stdout, logOpts))
+	rotation := logfile.Options{
+		MaxSize:    int64(cfg.Logging.MaxSizeMB) << 20,
+		MaxAge:     cfg.Logging.MaxAge,
+		MaxBackups: cfg.Logging.MaxBackups,
+	}
+	var logOutput io.Writer = os.Stdout
+	if cfg.Logging.File != "" {
+		file, err := logfile.Open(cfg.Logging.File, rotation)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to open LOG_FILE:", err)
+			return 1
+		}
+		defer file.Close()
+		logOutput = logfile.Tee(os.Stdout, file)
+	}
+	logger := newLogger(logOutput)
+
+	// Requests are logged with the rest unless they have a file of their own
+	accessLogger := logger
+	if cfg.Logging.AccessFile != "" {
+		file, err := logfile.Open(cfg.Logging.AccessFile, rotation)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to open LOG_ACCESS_FILE:", err)
+			return 1
+		}
+		defer file.Close()
+		accessLogger = newLogger(file)
 	}
 
 	slog.SetDefault(logger)
 
 	logger.Info("starting imposter game server",
+		"version", buildinfo.Version,
 		"env", cfg.Server.Env,
 		"port", cfg.Server.Port,
 	)
 
+	// Deliver room events through Redis when running more than one instance
+	var broadcaster app.Broadcaster = app.NewLocalBroadcaster()
+	var redisBroadcaster *broadcast.RedisBroadcaster
+	switch cfg.Broadcast.Type {
+	case "memory":
+	case "redis":
+		var err error
+		redisBroadcaster, err = broadcast.NewRedisBroadcaster(cfg.Broadcast, logger)
+		if err != nil {
+			logger.Error("failed to connect to redis", "error", err)
+			os.Exit(1)
+		}
+		defer redisBroadcaster.Close()
+		broadcaster = redisBroadcaster
+	default:
+		logger.Error("unknown broadcast type", "type", cfg.Broadcast.Type)
+		os.Exit(1)
+	}
+
+	// Share hub-wide events and room presence between instances
+	var eventBus app.EventBus = app.NewLocalEventBus()
+	var redisEventBus *broadcast.RedisEventBus
+	switch cfg.Broadcast.EventBusType {
+	case "memory":
+	case "redis":
+		var err error
+		redisEventBus, err = broadcast.NewRedisEventBus(cfg.Broadcast, logger)
+		if err != nil {
+			logger.Error("failed to connect to redis", "error", err)
+			os.Exit(1)
+		}
+		defer redisEventBus.Close()
+		eventBus = redisEventBus
+	default:
+		logger.Error("unknown event bus type", "type", cfg.Broadcast.EventBusType)
+		os.Exit(1)
+	}
+
+	var presence app.Presence = app.NewLocalPresence()
+	var redisPresence *broadcast.RedisPresence
+	switch cfg.Broadcast.PresenceType {
+	case "memory":
+	case "redis":
+		var err error
+		redisPresence, err = broadcast.NewRedisPresence(cfg.Broadcast, logger)
+		if err != nil {
+			logger.Error("failed to connect to redis", "error", err)
+			os.Exit(1)
+		}
+		defer redisPresence.Close()
+		presence = redisPresence
+	default:
+		logger.Error("unknown presence type", "type", cfg.Broadcast.PresenceType)
+		os.Exit(1)
+	}
+
+	// Open the database when games are recorded or sessions kept there
+	var db *sql.DB
+	persistAnalytics := cfg.Analytics.Enabled && cfg.Analytics.Persist
+	if cfg.Database.PersistGames || persistAnalytics || cfg.Snapshot.Type == "database" || cfg.Hibernation.Type == "database" || cfg.Replays.Type == "database" || cfg.Words.StoreType == "database" {
+		if cfg.Database.URL == "" {
+			logger.Error("PERSIST_GAMES, PERSIST_ANALYTICS and SNAPSHOT_TYPE, HIBERNATION_TYPE, REPLAYS_TYPE or WORDS_STORE_TYPE=database require DATABASE_URL")
+			os.Exit(1)
+		}
+		var err error
+		db, err = storage.Open(cfg.Database, logger)
+		if err != nil {
+			logger.Error("failed to open database", "driver", cfg.Database.Driver, "error", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+	}
+
+	// Record finished games. Created before the hub so that it is closed after
+	// it, writing the games the hub closes on shutdown.
+	var history *storage.HistoryStore
+	if cfg.Database.PersistGames {
+		history = storage.NewHistoryStore(db, logger)
+		defer history.Close()
+	}
+
+	// Aggregate finished rounds for /api/analytics. Created before the hub
+	// for the same reason.
+	var collector *analytics.Collector
+	if cfg.Analytics.Enabled {
+		collector = analytics.NewCollector(logger)
+		if persistAnalytics {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := collector.EnablePersistence(ctx, storage.NewDBAnalyticsStore(db), cfg.Analytics.FlushInterval)
+			cancel()
+			if err != nil {
+				logger.Error("failed to load analytics", "error", err)
+				os.Exit(1)
+			}
+		}
+		defer collector.Close()
+	}
+
+	// Keep in-progress games across restarts
+	var snapshots app.SnapshotStore
+	switch cfg.Snapshot.Type {
+	case "":
+	case "file":
+		fileStore, err := storage.NewFileSnapshotStore(cfg.Snapshot.Path)
+		if err != nil {
+			logger.Error("failed to create snapshot store", "error", err)
+			os.Exit(1)
+		}
+		snapshots = fileStore
+	case "database":
+		snapshots = storage.NewDBSnapshotStore(db, cfg.Snapshot.Key)
+	default:
+		logger.Error("unknown snapshot type", "type", cfg.Snapshot.Type)
+		os.Exit(1)
+	}
+	if snapshots != nil && cfg.Security.TokenSecret == "" {
+		logger.Warn("TOKEN_SECRET is not set; players cannot reconnect to restored games")
+	}
+
+	// Evict rooms nobody is connected to from memory
+	var hibernation app.HibernationStore
+	switch cfg.Hibernation.Type {
+	case "":
+	case "file":
+		fileStore, err := storage.NewFileHibernationStore(cfg.Hibernation.Path)
+		if err != nil {
+			logger.Error("failed to create hibernation store", "error", err)
+			os.Exit(1)
+		}
+		hibernation = fileStore
+	case "database":
+		hibernation = storage.NewDBHibernationStore(db, cfg.Snapshot.Key)
+	default:
+		logger.Error("unknown hibernation type", "type", cfg.Hibernation.Type)
+		os.Exit(1)
+	}
+	if hibernation != nil && cfg.Security.TokenSecret == "" {
+		logger.Warn("TOKEN_SECRET is not set; players cannot reconnect to hibernated rooms after a restart")
+	}
+
+	// Save games for playback
+	var replays app.ReplayStore
+	switch cfg.Replays.Type {
+	case "":
+	case "file":
+		fileStore, err := storage.NewFileReplayStore(cfg.Replays.Path)
+		if err != nil {
+			logger.Error("failed to create replay store", "error", err)
+			os.Exit(1)
+		}
+		replays = fileStore
+	case "database":
+		replays = storage.NewDBReplayStore(db)
+	default:
+		logger.Error("unknown replay type", "type", cfg.Replays.Type)
+		os.Exit(1)
+	}
+
+	// Route room traffic to the node holding the room
+	var directory *broadcast.RedisDirectory
+	switch cfg.Cluster.Routing {
+	case "":
+	case "proxy", "redirect":
+		self := app.Node{ID: cfg.Broadcast.InstanceID, URL: cfg.Cluster.NodeURL}
+		var err error
+		directory, err = broadcast.NewRedisDirectory(cfg.Broadcast.RedisURL, cfg.Cluster, self, logger)
+		if err != nil {
+			logger.Error("failed to connect to redis", "error", err)
+			os.Exit(1)
+		}
+		defer directory.Close()
+		if cfg.Snapshot.Type != "database" || cfg.Snapshot.Key != cfg.Broadcast.InstanceID {
+			logger.Warn("rooms of a dead node cannot be taken over without SNAPSHOT_TYPE=database and SNAPSHOT_KEY equal to INSTANCE_ID")
+		}
+	default:
+		logger.Error("unknown cluster routing", "routing", cfg.Cluster.Routing)
+		os.Exit(1)
+	}
+
+	// Keep room codes unique across instances sharing Redis; the directory
+	// does so itself
+	var roomCodes *broadcast.RedisRoomCodes
+	if directory == nil && cfg.SharedStoreEnabled() {
+		var err error
+		roomCodes, err = broadcast.NewRedisRoomCodes(cfg.Broadcast, cfg.Cluster.KeyPrefix, logger)
+		if err != nil {
+			logger.Error("failed to connect to redis", "error", err)
+			os.Exit(1)
+		}
+		defer roomCodes.Close()
+	}
+
+	// Let admins change the embedded word list, keeping their changes in the
+	// configured store
+	catalog := app.NewWordCatalog(app.SecretWords, cfg.Words.Blocklist)
+	var wordStore app.WordStore
+	switch cfg.Words.StoreType {
+	case "":
+	case "file":
+		fileStore, err := storage.NewFileWordStore(cfg.Words.StorePath)
+		if err != nil {
+			logger.Error("failed to create word store", "error", err)
+			os.Exit(1)
+		}
+		wordStore = fileStore
+	case "database":
+		wordStore = storage.NewDBWordStore(db)
+	default:
+		logger.Error("unknown word store type", "type", cfg.Words.StoreType)
+		os.Exit(1)
+	}
+	if wordStore != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := catalog.Load(ctx, wordStore)
+		cancel()
+		if err != nil {
+			logger.Error("failed to load word catalog", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Offer the configured sources of secret words
+	words := map[string]app.WordProvider{
+		app.DefaultWordSource: catalog,
+		app.FamilyWordSource:  app.FamilyProvider.Without(cfg.Words.Blocklist),
+	}
+	if cfg.Words.File != "" {
+		list, err := app.LoadWordList(cfg.Words.File)
+		if err != nil {
+			logger.Error("failed to load word list", "path", cfg.Words.File, "error", err)
+			os.Exit(1)
+		}
+		words["file"] = list.Without(cfg.Words.Blocklist)
+	}
+	if cfg.Words.LLM.URL != "" {
+		generator := wordgen.NewGenerator(cfg.Words.LLM, cfg.Words.Blocklist, words[app.DefaultWordSource], logger)
+		defer generator.Close()
+		words["llm"] = generator
+	}
+	calibrate := func(provider app.WordProvider) app.WordProvider { return provider }
+	if cfg.Words.Calibrate {
+		if collector == nil {
+			logger.Error("WORDS_CALIBRATE requires ANALYTICS_ENABLED")
+			os.Exit(1)
+		}
+		calibrate = collector.Calibrate
+		for name, provider := range words {
+			words[name] = calibrate(provider)
+		}
+	}
+	var loadWords app.WordLoader
+	if cfg.Words.Dir != "" {
+		loadWords = func() (map[string]app.WordProvider, error) {
+			lists, err := app.LoadWordDir(cfg.Words.Dir)
+			if err != nil {
+				return nil, err
+			}
+			providers := make(map[string]app.WordProvider, len(lists))
+			for name, list := range lists {
+				providers[name] = calibrate(list.Without(cfg.Words.Blocklist))
+			}
+			return providers, nil
+		}
+	}
+	wordSources, err := app.NewWordSources(words, loadWords, cfg.Words.Source)
+	if err != nil {
+		logger.Error("invalid word source", "error", err)
+		os.Exit(1)
+	}
+
+	// Report panics and unexpected errors; closed after the hub so errors
+	// while shutting rooms down are still sent
+	var reporter *sentry.Reporter
+	if cfg.Sentry.DSN != "" {
+		if cfg.Sentry.Environment == "" {
+			cfg.Sentry.Environment = cfg.Server.Env
+		}
+		reporter, err = sentry.NewReporter(cfg.Sentry, buildinfo.Version, logger)
+		if err != nil {
+			logger.Error("failed to set up error reporting", "error", err)
+			os.Exit(1)
+		}
+		defer reporter.Close(5 * time.Second)
+		logger.Info("error reporting enabled", "environment", cfg.Sentry.Environment, "samplePercent", cfg.Sentry.SamplePercent)
+	}
+
 	// Create game hub
-	hub := app.NewGameHub(logger)
+	signer := app.NewTokenSigner([]byte(cfg.Security.TokenSecret), cfg.Security.ReconnectTokenTTL)
+	hub := app.NewGameHub(signer, broadcaster, logger)
+	hub.SetEventBus(eventBus)
+	if reporter != nil {
+		hub.SetErrorReporter(reporter)
+	}
+	hub.SetPresence(presence)
+	if directory != nil {
+		nodeSnapshots, _ := snapshots.(app.NodeSnapshots)
+		hub.SetDirectory(directory, nodeSnapshots)
+	} else if roomCodes != nil {
+		hub.SetRoomCodes(roomCodes)
+	}
+	hub.ConfigureRoomCodes(cfg.Game.RoomCodeLength, cfg.Game.ReservedRoomCodes)
+	hub.SetMaxSessions(cfg.Game.MaxRooms)
+	hub.SetVoteQuorum(domain.VoteQuorum(cfg.Game.VoteQuorum))
+	hub.SetTieRule(domain.TieRule(cfg.Game.TieRule))
+	imposters, _ := domain.ParseImposterCount(cfg.Game.Imposters, nil) // Validated with the configuration
+	hub.SetImposters(imposters, cfg.Game.ImposterThresholds)
+	hub.SetEmojiPolicy(domain.EmojiPolicy(cfg.Game.EmojiPolicy))
+	hub.SetProfanityFilter(app.NewProfanityFilter(app.ProfanityMode(cfg.Game.ProfanityFilter), cfg.Game.ProfanityWords))
+	hub.SetWordSources(wordSources)
+	hub.SetRoomQuotas(app.RoomQuotas{
+		MaxConnections: cfg.Game.RoomMaxConnections,
+		EventQueueSize: cfg.Game.RoomEventQueueSize,
+		MaxBots:        cfg.Game.RoomMaxBots,
+	})
+	hub.ConfigureCleanup(app.CleanupPolicy{
+		Interval:         cfg.Game.CleanupInterval,
+		StaleGameTimeout: cfg.Game.EmptyRoomTTL,
+		IdleTimeout:      cfg.Game.IdleRoomTimeout,
+		IdleWarning:      cfg.Game.IdleRoomWarning,
+	})
+	if history != nil {
+		hub.SetRecorder(history)
+	}
+	if collector != nil {
+		hub.AddHooks(collector)
+	}
+	if replays != nil {
+		hub.SetReplayStore(replays, cfg.Replays.TTL)
+	}
 	defer hub.Close()
 
+	if snapshots != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := hub.RestoreSnapshot(ctx, snapshots); err != nil {
+			logger.Error("failed to restore sessions", "error", err)
+		}
+		cancel()
+		go hub.RunSnapshots(snapshots, cfg.Snapshot.Interval)
+	}
+
+	if hibernation != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		count, err := hub.EnableHibernation(ctx, hibernation, app.HibernationPolicy{
+			After: cfg.Hibernation.After,
+			TTL:   cfg.Hibernation.TTL,
+		})
+		cancel()
+		if err != nil {
+			logger.Error("failed to enable hibernation", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("room hibernation enabled", "type", cfg.Hibernation.Type, "after", cfg.Hibernation.After, "hibernated", count)
+	}
+
+	// Deliver lifecycle events to webhooks
+	if len(cfg.Webhooks.URLs) > 0 {
+		notifier := webhook.NewNotifier(cfg.Webhooks, logger)
+		defer notifier.Close()
+		hub.Bus().Subscribe(notifier.Handle)
+	}
+
+	// Mirror all game events to a message broker
+	var sink *broker.Sink
+	if cfg.Broker.Type != "" {
+		publisher, err := broker.NewPublisher(cfg.Broker)
+		if err != nil {
+			logger.Error("failed to connect to broker", "type", cfg.Broker.Type, "error", err)
+			os.Exit(1)
+		}
+		sink = broker.NewSink(publisher, logger)
+		defer sink.Close()
+		hub.Bus().Subscribe(sink.Handle)
+	}
+
 	// Create HTTP server
 	server := httpTransport.NewServer(cfg, hub, logger, webFS)
+	server.SetWordCatalog(catalog)
+	server.SetTournaments(app.NewTournamentManager(hub, logger))
+	if collector != nil {
+		server.SetAnalytics(collector)
+	}
+	if sink != nil {
+		server.AddReadinessCheck("broker", sink.Check)
+	}
+	if redisBroadcaster != nil {
+		server.AddReadinessCheck("broadcast", redisBroadcaster.Check)
+	}
+	if redisEventBus != nil {
+		server.AddReadinessCheck("eventbus", redisEventBus.Check)
+	}
+	if redisPresence != nil {
+		server.AddReadinessCheck("presence", redisPresence.Check)
+	}
+	if directory != nil {
+		server.AddReadinessCheck("cluster", directory.Check)
+	}
+	if roomCodes != nil {
+		server.AddReadinessCheck("roomcodes", roomCodes.Check)
+	}
+	if db != nil {
+		server.AddReadinessCheck("database", db.PingContext)
+	}
+	reloader := newConfigReloader(cfg, logLevel, hub, server, logger)
+	server.SetConfigReloader(reloader.Reload)
+	server.SetLogLevel(logLevel)
+	server.SetAccessLogger(accessLogger)
 
 	// Start server in goroutine
 	go func() {
@@ -57,6 +520,51 @@ func main() {
 		}
 	}()
 
+	// Start gRPC server if configured
+	var grpcServer *grpcTransport.Server
+	if cfg.GRPCEnabled() {
+		grpcServer = grpcTransport.NewServer(cfg, hub, logger)
+		grpcServer.SetConnectionLimiter(server.WebSocket())
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				logger.Error("grpc server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// Reload CONFIG_FILE and the word list directory on SIGHUP
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			result, err := reloader.Reload()
+			if err != nil {
+				logger.Error("failed to reload configuration", "error", err)
+				continue
+			}
+			logger.Info("configuration reloaded",
+				"applied", result.Applied,
+				"restartRequired", result.RestartRequired,
+				"wordSources", result.WordSources)
+		}
+	}()
+
+	// Toggle debug logging on SIGUSR1, back to the configured level (or info,
+	// if that is debug) on the next
+	debugToggle := make(chan os.Signal, 1)
+	signal.Notify(debugToggle, syscall.SIGUSR1)
+	go func() {
+		for range debugToggle {
+			level := slog.LevelDebug
+			if logLevel.Level() == slog.LevelDebug {
+				level = max(reloader.LogLevel(), slog.LevelInfo)
+			}
+			logLevel.Set(level)
+			logger.Warn("log level changed by SIGUSR1", "level", level.String())
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -64,6 +572,12 @@ func main() {
 
 	logger.Info("shutting down server...")
 
+	// Stop receiving new traffic before closing connections
+	if cfg.Server.DrainDelay > 0 {
+		server.Drain()
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -72,7 +586,24 @@ func main() {
 		logger.Error("server forced to shutdown", "error", err)
 	}
 
+	if grpcServer != nil {
+		if err := grpcServer.Shutdown(ctx); err != nil {
+			logger.Error("grpc server forced to shutdown", "error", err)
+		}
+	}
+
+	// Save sessions once no more player actions can arrive, before the hub
+	// closes them
+	if snapshots != nil {
+		if err := hub.SaveSnapshot(ctx, snapshots); err != nil {
+			logger.Error("failed to save sessions", "error", err)
+		} else {
+			hub.DetachRecorder()
+		}
+	}
+
 	logger.Info("server stopped")
+	return 0
 }
 
 func parseLogLevel(level string) slog.Level {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+	httpTransport "imposter/internal/transport/http"
+)
+
+// reloadableSettings are the settings a reload puts into effect while games
+// play on. Changes to any other setting are reported as needing a restart.
+var reloadableSettings = map[string]bool{
+	"Logging.Level":               true,
+	"RateLimit.Enabled":           true,
+	"RateLimit.RoomsPerMinute":    true,
+	"RateLimit.RoomsBurst":        true,
+	"RateLimit.ConnectsPerMinute": true,
+	"RateLimit.ConnectsBurst":     true,
+	"Game.MaxRooms":               true,
+	"Game.VoteQuorum":             true, // Rooms created from then on
+	"Game.TieRule":                true, // Rooms created from then on
+	"Game.Imposters":              true, // Rooms created from then on
+	"Game.ImposterThresholds":     true, // Rooms created from then on
+	"Game.EmojiPolicy":            true, // Rooms created from then on
+	"Game.RoomMaxConnections":     true, // Rooms created from then on
+	"Game.RoomEventQueueSize":     true, // Rooms created from then on
+	"Game.RoomMaxBots":            true, // Rooms created from then on
+	"Game.CleanupInterval":        true,
+	"Game.EmptyRoomTTL":           true,
+	"Game.IdleRoomTimeout":        true,
+	"Game.IdleRoomWarning":        true,
+}
+
+// configReloader re-reads CONFIG_FILE and the word lists on SIGHUP or when an
+// admin asks, and applies what it can without a restart
+type configReloader struct {
+	mu       sync.Mutex
+	current  config.Config // As now in effect
+	logLevel *slog.LevelVar
+	hub      *app.GameHub
+	server   *httpTransport.Server
+	logger   *slog.Logger
+}
+
+func newConfigReloader(cfg *config.Config, logLevel *slog.LevelVar, hub *app.GameHub, server *httpTransport.Server, logger *slog.Logger) *configReloader {
+	return &configReloader{
+		current:  *cfg,
+		logLevel: logLevel,
+		hub:      hub,
+		server:   server,
+		logger:   logger,
+	}
+}
+
+// LogLevel returns the log level the configuration sets, which the level may
+// have been changed from at runtime
+func (r *configReloader) LogLevel() slog.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return parseLogLevel(r.current.Logging.Level)
+}
+
+// Reload applies the reloadable settings that changed. Nothing is applied if
+// the file or a word list fails to load, or the configuration is invalid.
+func (r *configReloader) Reload() (*config.ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := config.ReadFile(); err != nil {
+		return nil, err
+	}
+	next := config.Load()
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	wordSources, err := r.hub.ReloadWords()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &config.ReloadResult{
+		Applied:         make([]string, 0),
+		RestartRequired: make([]string, 0),
+		WordSources:     wordSources,
+	}
+	for _, name := range config.Changed(&r.current, next) {
+		if reloadableSettings[name] {
+			result.Applied = append(result.Applied, name)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, name)
+		}
+	}
+
+	r.logLevel.Set(parseLogLevel(next.Logging.Level))
+	r.server.ConfigureRateLimits(next.RateLimit)
+	r.hub.SetMaxSessions(next.Game.MaxRooms)
+	r.hub.SetVoteQuorum(domain.VoteQuorum(next.Game.VoteQuorum))
+	r.hub.SetTieRule(domain.TieRule(next.Game.TieRule))
+	imposters, _ := domain.ParseImposterCount(next.Game.Imposters, nil)
+	r.hub.SetImposters(imposters, next.Game.ImposterThresholds)
+	r.hub.SetEmojiPolicy(domain.EmojiPolicy(next.Game.EmojiPolicy))
+	r.hub.SetRoomQuotas(app.RoomQuotas{
+		MaxConnections: next.Game.RoomMaxConnections,
+		EventQueueSize: next.Game.RoomEventQueueSize,
+		MaxBots:        next.Game.RoomMaxBots,
+	})
+	r.hub.ConfigureCleanup(app.CleanupPolicy{
+		Interval:         next.Game.CleanupInterval,
+		StaleGameTimeout: next.Game.EmptyRoomTTL,
+		IdleTimeout:      next.Game.IdleRoomTimeout,
+		IdleWarning:      next.Game.IdleRoomWarning,
+	})
+
+	r.current.Logging.Level = next.Logging.Level
+	r.current.RateLimit = next.RateLimit
+	r.current.Game.MaxRooms = next.Game.MaxRooms
+	r.current.Game.VoteQuorum = next.Game.VoteQuorum
+	r.current.Game.TieRule = next.Game.TieRule
+	r.current.Game.Imposters = next.Game.Imposters
+	r.current.Game.ImposterThresholds = next.Game.ImposterThresholds
+	r.current.Game.EmojiPolicy = next.Game.EmojiPolicy
+	r.current.Game.RoomMaxConnections = next.Game.RoomMaxConnections
+	r.current.Game.RoomEventQueueSize = next.Game.RoomEventQueueSize
+	r.current.Game.RoomMaxBots = next.Game.RoomMaxBots
+	r.current.Game.CleanupInterval = next.Game.CleanupInterval
+	r.current.Game.EmptyRoomTTL = next.Game.EmptyRoomTTL
+	r.current.Game.IdleRoomTimeout = next.Game.IdleRoomTimeout
+	r.current.Game.IdleRoomWarning = next.Game.IdleRoomWarning
+
+	return result, nil
+}
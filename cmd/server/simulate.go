@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"imposter/internal/simulate"
+)
+
+// runSimulate runs the simulate subcommand, which load-tests a running server
+// with synthetic players, and returns the exit code
+func runSimulate(args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: imposter simulate [flags]")
+		fmt.Fprintln(flags.Output(), "\nPlays full games against a running server with synthetic WebSocket clients")
+		fmt.Fprintln(flags.Output(), "and reports latency and error statistics. The server's rate limits and room")
+		fmt.Fprintln(flags.Output(), "quotas apply to these clients too, so relax them on the target when testing.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+
+	var opts simulate.Options
+	flags.StringVar(&opts.Target, "target", "http://localhost:8080", "base URL of the server to test")
+	flags.IntVar(&opts.Rooms, "rooms", 10, "number of rooms to play at once")
+	flags.IntVar(&opts.Players, "players", 4, "players per room")
+	flags.IntVar(&opts.Rounds, "rounds", 1, "rounds to play in each room")
+	flags.DurationVar(&opts.RampUp, "ramp-up", 5*time.Second, "time over which room starts are spread")
+	flags.DurationVar(&opts.Think, "think", 500*time.Millisecond, "average time players wait before acting")
+	flags.DurationVar(&opts.Timeout, "timeout", 5*time.Minute, "time after which an unfinished room fails")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := simulate.Run(ctx, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulate:", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printReport(os.Stdout, report)
+	}
+
+	if report.RoomsCompleted < report.Rooms {
+		return 1
+	}
+	return 0
+}
+
+// printReport writes a report as a table
+func printReport(w io.Writer, report *simulate.Report) {
+	fmt.Fprintf(w, "rooms completed: %d/%d\n", report.RoomsCompleted, report.Rooms)
+	fmt.Fprintf(w, "rounds played:   %d\n", report.RoundsPlayed)
+	fmt.Fprintf(w, "duration:        %s\n", report.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "messages:        %d sent, %d received\n", report.MessagesSent, report.EventsReceived)
+
+	fmt.Fprintf(w, "\n%-12s %7s %10s %10s %10s %10s %10s\n", "operation", "count", "mean", "p50", "p95", "p99", "max")
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	for _, op := range simulate.Operations() {
+		s, ok := report.Latencies[op]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%-12s %7d %10s %10s %10s %10s %10s\n", op, s.Count, ms(s.Mean), ms(s.P50), ms(s.P95), ms(s.P99), ms(s.Max))
+	}
+
+	if len(report.Errors) == 0 {
+		return
+	}
+	kinds := make([]string, 0, len(report.Errors))
+	for kind := range report.Errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	fmt.Fprintln(w, "\nerrors:")
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "  %-24s %d\n", kind, report.Errors[kind])
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+//go:generate go run . typescript -o imposter.d.ts
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"imposter/internal/analytics"
+	"imposter/internal/app"
+	"imposter/internal/buildinfo"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+	httpTransport "imposter/internal/transport/http"
+	"imposter/internal/transport/ws"
+	"imposter/internal/tsgen"
+)
+
+// typescriptTypes are the messages, payloads, request bodies and responses
+// clients exchange with the server. The types they refer to are declared too.
+var typescriptTypes = []interface{}{
+	// WebSocket envelopes and payloads
+	ws.ClientMessage{}, ws.ServerMessage{},
+	ws.HelloPayload{}, ws.JoinLobbyPayload{}, ws.SubmitWordPayload{}, ws.CastVotePayload{}, ws.AudienceVotePayload{}, ws.CreateInvitePayload{}, ws.AckPayload{},
+	ws.ProtocolPayload{}, ws.ConnectedPayload{}, ws.ResumedPayload{}, ws.SpectatingPayload{}, ws.ReplayingPayload{},
+	ws.InviteCreatedPayload{}, ws.SystemNoticePayload{}, ws.ErrorPayload{},
+
+	// Game events
+	domain.GameEvent{}, domain.EventType(""),
+	domain.LobbyUpdatePayload{}, domain.LobbyDeltaPayload{}, domain.GameStartedPayload{}, domain.PhaseChangedPayload{}, domain.RoleAssignedPayload{},
+	domain.SubmissionPhasePayload{}, domain.SubmissionUpdatePayload{}, domain.SubmissionDeltaPayload{},
+	domain.VotingPhasePayload{}, domain.VotingCountdownPayload{}, domain.VoteUpdatePayload{}, domain.RoundResultsPayload{},
+	domain.RoomExpiringPayload{}, domain.CheatSuspectedPayload{}, domain.TournamentUpdatePayload{}, domain.ErrorPayload{},
+	domain.PlayerInfo{},
+
+	// REST envelope, requests and responses
+	httpTransport.Response{}, httpTransport.ErrorInfo{},
+	httpTransport.CreateRoomRequest{}, httpTransport.CreateRoomResponse{}, httpTransport.QuickJoinRequest{}, httpTransport.QuickJoinResponse{},
+	httpTransport.GetRoomResponse{}, httpTransport.ListRoomsResponse{}, httpTransport.RoomExistsResponse{}, httpTransport.PollEventsResponse{},
+	httpTransport.JoinRequest{}, httpTransport.JoinResponse{}, httpTransport.SubmitWordRequest{}, httpTransport.CastVoteRequest{}, httpTransport.AudienceVotesRequest{}, httpTransport.AudienceVotesResponse{},
+	httpTransport.CreateInviteRequest{}, httpTransport.CreateInviteResponse{}, httpTransport.ActionResponse{},
+	httpTransport.HealthResponse{}, httpTransport.ProbeResponse{}, httpTransport.StatsResponse{}, httpTransport.MeResponse{},
+	httpTransport.TournamentsResponse{}, httpTransport.RegisterTournamentRequest{}, app.Tournament{}, app.TournamentRegistration{}, app.TournamentAssignment{},
+	httpTransport.WordListResponse{}, httpTransport.AddWordRequest{}, app.WordEntry{}, app.WordUpdate{},
+	httpTransport.GraphQLRequest{}, app.Replay{}, app.Transcript{}, analytics.Report{}, buildinfo.Info{},
+
+	// Admin API
+	httpTransport.AdminOverviewResponse{}, httpTransport.AdminRoomsResponse{}, httpTransport.AdminWordStatsResponse{},
+	httpTransport.ReloadWordsResponse{}, config.ReloadResult{}, httpTransport.LogLevelRequest{}, httpTransport.LogLevelResponse{},
+	httpTransport.AnnouncementRequest{}, httpTransport.AnnouncementResponse{}, httpTransport.DeleteRoomResponse{},
+	httpTransport.ForcePhaseRequest{}, httpTransport.AdminInvitesResponse{}, httpTransport.RevokeInviteResponse{},
+	httpTransport.ImportRoomResponse{}, httpTransport.RuntimeStatsResponse{},
+	app.SessionDetails{}, app.SessionDump{}, app.RoomExport{},
+}
+
+// runTypescript runs the typescript subcommand, which writes TypeScript
+// declarations of the protocol's types, and returns the exit code
+func runTypescript(args []string) int {
+	flags := flag.NewFlagSet("typescript", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: imposter typescript [flags]")
+		fmt.Fprintln(flags.Output(), "\nWrites TypeScript declarations of the WebSocket messages, REST requests and")
+		fmt.Fprintln(flags.Output(), "responses and the types they use, generated from the server's Go types.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	output := flags.String("o", "", "file to write instead of standard output")
+	check := flags.Bool("check", false, "fail if the -o file is not up to date instead of writing it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *check && *output == "" {
+		fmt.Fprintln(os.Stderr, "-check needs -o")
+		return 2
+	}
+
+	gen := tsgen.New("imposter")
+	gen.Add(typescriptTypes...)
+	var b bytes.Buffer
+	gen.WriteTo(&b)
+
+	switch {
+	case *output == "":
+		os.Stdout.Write(b.Bytes())
+	case *check:
+		current, err := os.ReadFile(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !bytes.Equal(current, b.Bytes()) {
+			fmt.Fprintf(os.Stderr, "%s is out of date: run go generate ./cmd/server\n", *output)
+			return 1
+		}
+	default:
+		if err := os.WriteFile(*output, b.Bytes(), 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,266 @@
+// ============================================
+// IMPOSTER GAME - Admin Dashboard
+// ============================================
+
+(function() {
+    'use strict';
+
+    // How often the dashboard refreshes
+    const POLL_INTERVAL_MS = 5000;
+
+    // The token lives for the browser tab only
+    const TOKEN_KEY = 'imposter-admin-token';
+
+    const state = {
+        token: sessionStorage.getItem(TOKEN_KEY),
+        selectedRoom: null,
+        pollTimer: null
+    };
+
+    const $ = (id) => document.getElementById(id);
+
+    // ============================================
+    // API
+    // ============================================
+    class UnauthorizedError extends Error {}
+
+    async function api(method, path) {
+        const response = await fetch(path, {
+            method,
+            headers: { 'Authorization': `Bearer ${state.token}` }
+        });
+        if (response.status === 401) {
+            throw new UnauthorizedError('Invalid admin token');
+        }
+
+        const body = await response.json();
+        if (!body.success) {
+            throw new Error(body.error ? body.error.message : `Request failed (${response.status})`);
+        }
+        return body.data;
+    }
+
+    // ============================================
+    // Sign in
+    // ============================================
+    function showLogin(message) {
+        stopPolling();
+        state.token = null;
+        sessionStorage.removeItem(TOKEN_KEY);
+
+        $('dashboard').classList.add('hidden');
+        $('login').classList.remove('hidden');
+        $('login-error').textContent = message || '';
+        $('input-token').focus();
+    }
+
+    function showDashboard() {
+        $('login').classList.add('hidden');
+        $('dashboard').classList.remove('hidden');
+        startPolling();
+    }
+
+    $('login-form').addEventListener('submit', (e) => {
+        e.preventDefault();
+        state.token = $('input-token').value.trim();
+        sessionStorage.setItem(TOKEN_KEY, state.token);
+        $('input-token').value = '';
+        showDashboard();
+    });
+
+    $('btn-logout').addEventListener('click', () => showLogin());
+
+    // ============================================
+    // Polling
+    // ============================================
+    function startPolling() {
+        stopPolling();
+        refresh();
+        state.pollTimer = setInterval(refresh, POLL_INTERVAL_MS);
+    }
+
+    function stopPolling() {
+        if (state.pollTimer) {
+            clearInterval(state.pollTimer);
+            state.pollTimer = null;
+        }
+    }
+
+    async function refresh() {
+        try {
+            const [overview, rooms] = await Promise.all([
+                api('GET', '/api/admin/overview'),
+                api('GET', '/api/admin/rooms')
+            ]);
+            renderOverview(overview);
+            renderRooms(rooms.rooms);
+            if (state.selectedRoom) {
+                await loadRoom(state.selectedRoom);
+            }
+            $('updated').textContent = `Updated ${new Date().toLocaleTimeString()}`;
+        } catch (err) {
+            if (err instanceof UnauthorizedError) {
+                showLogin(err.message);
+                return;
+            }
+            $('updated').textContent = `Update failed: ${err.message}`;
+        }
+    }
+
+    // ============================================
+    // Overview
+    // ============================================
+    function renderOverview(overview) {
+        $('tile-rooms').textContent = overview.rooms;
+        $('tile-hibernated').textContent = overview.hibernated;
+        $('tile-players').textContent = overview.players;
+        $('tile-connections').textContent = overview.connections;
+
+        const history = overview.history || [];
+        drawGraph($('graph-events'), history.map((s) => s.eventsPerSecond));
+        drawGraph($('graph-messages'), history.map((s) => s.messagesPerSecond));
+    }
+
+    // drawGraph plots values, oldest first, as a line scaled to the largest
+    function drawGraph(canvas, values) {
+        const ctx = canvas.getContext('2d');
+        const { width, height } = canvas;
+        const pad = 4;
+        ctx.clearRect(0, 0, width, height);
+
+        const max = Math.max(1, ...values);
+        ctx.fillStyle = '#64748b';
+        ctx.font = '11px system-ui, sans-serif';
+        ctx.fillText(max.toFixed(1), pad, 12);
+
+        if (values.length < 2) {
+            ctx.fillText('Collecting samples…', width / 2 - 50, height / 2);
+            return;
+        }
+
+        const x = (i) => pad + (i / (values.length - 1)) * (width - 2 * pad);
+        const y = (v) => height - pad - (v / max) * (height - 2 * pad - 12);
+
+        ctx.beginPath();
+        values.forEach((v, i) => (i === 0 ? ctx.moveTo(x(i), y(v)) : ctx.lineTo(x(i), y(v))));
+        ctx.strokeStyle = '#22d3ee';
+        ctx.lineWidth = 2;
+        ctx.stroke();
+
+        ctx.lineTo(x(values.length - 1), height - pad);
+        ctx.lineTo(x(0), height - pad);
+        ctx.closePath();
+        ctx.fillStyle = 'rgba(34, 211, 238, 0.12)';
+        ctx.fill();
+    }
+
+    // ============================================
+    // Room list
+    // ============================================
+    function renderRooms(rooms) {
+        const tbody = $('rooms');
+        tbody.replaceChildren();
+        $('rooms-empty').classList.toggle('hidden', rooms.length > 0);
+
+        for (const room of rooms) {
+            const row = document.createElement('tr');
+            row.classList.toggle('selected', room.roomCode === state.selectedRoom);
+            const cells = [
+                room.roomCode,
+                room.phase,
+                room.players.length,
+                room.connections,
+                room.rounds.length,
+                room.metrics.eventsBroadcast,
+                timeAgo(room.lastActivity)
+            ];
+            for (const value of cells) {
+                const td = document.createElement('td');
+                td.textContent = value;
+                row.appendChild(td);
+            }
+            row.addEventListener('click', () => selectRoom(room.roomCode));
+            tbody.appendChild(row);
+        }
+
+        // The selected room was deleted or expired
+        if (state.selectedRoom && !rooms.some((r) => r.roomCode === state.selectedRoom)) {
+            closeRoom();
+        }
+    }
+
+    function timeAgo(timestamp) {
+        const seconds = Math.max(0, Math.round((Date.now() - new Date(timestamp)) / 1000));
+        if (seconds < 60) return `${seconds}s ago`;
+        if (seconds < 3600) return `${Math.floor(seconds / 60)}m ago`;
+        return `${Math.floor(seconds / 3600)}h ago`;
+    }
+
+    // ============================================
+    // Room drill-down
+    // ============================================
+    async function selectRoom(roomCode) {
+        state.selectedRoom = roomCode;
+        $('room-code').textContent = roomCode;
+        $('room-error').textContent = '';
+        $('room').classList.remove('hidden');
+        for (const row of $('rooms').children) {
+            row.classList.toggle('selected', row.firstChild.textContent === roomCode);
+        }
+        await loadRoom(roomCode);
+    }
+
+    async function loadRoom(roomCode) {
+        try {
+            const details = await api('GET', `/api/admin/rooms/${roomCode}`);
+            $('room-details').textContent = JSON.stringify(details, null, 2);
+        } catch (err) {
+            if (err instanceof UnauthorizedError) throw err;
+            $('room-error').textContent = err.message;
+        }
+    }
+
+    function closeRoom() {
+        state.selectedRoom = null;
+        $('room').classList.add('hidden');
+        $('room-details').textContent = '';
+        for (const row of $('rooms').children) {
+            row.classList.remove('selected');
+        }
+    }
+
+    async function roomAction(method, path, confirmMessage) {
+        const roomCode = state.selectedRoom;
+        if (!roomCode || (confirmMessage && !confirm(confirmMessage))) {
+            return;
+        }
+
+        $('room-error').textContent = '';
+        try {
+            await api(method, path(roomCode));
+        } catch (err) {
+            if (err instanceof UnauthorizedError) {
+                showLogin(err.message);
+                return;
+            }
+            $('room-error').textContent = err.message;
+        }
+        refresh();
+    }
+
+    $('btn-advance').addEventListener('click', () =>
+        roomAction('POST', (code) => `/api/admin/rooms/${code}/advance`));
+    $('btn-kill').addEventListener('click', () =>
+        roomAction('DELETE', (code) => `/api/admin/rooms/${code}`,
+            `Kill room ${state.selectedRoom}? Everyone in it will be disconnected.`));
+    $('btn-close').addEventListener('click', closeRoom);
+
+    // ============================================
+    // Start
+    // ============================================
+    if (state.token) {
+        showDashboard();
+    } else {
+        showLogin();
+    }
+})();
@@ -10,9 +10,13 @@
     // ============================================
     const state = {
         playerId: null,
+        reconnectToken: null,
+        lastSeq: 0,
         roomCode: null,
         nickname: null,
+        invite: null,
         isHost: false,
+        canStart: false,
         phase: 'LOBBY',
         players: [],
         role: null,
@@ -21,9 +25,18 @@
         currentPlayerId: null,
         hasVoted: false,
         votingSeconds: 20,
+        votingDeadline: null,
+        candidates: [],
+        countdownTimer: null,
         ws: null
     };
 
+    // WebSocket protocol version this client speaks (2: lobby and submission deltas)
+    const PROTOCOL_VERSION = 2;
+
+    // Events the server resends until acknowledged
+    const CRITICAL_EVENTS = ['ROLES_ASSIGNED', 'ROUND_ENDED'];
+
     // ============================================
     // DOM Elements
     // ============================================
@@ -39,9 +52,14 @@
     const elements = {
         // Home
         btnCreate: document.getElementById('btn-create'),
+        btnQuickJoin: document.getElementById('btn-quickjoin'),
+        checkFamily: document.getElementById('check-family'),
+        btnPractice: document.getElementById('btn-practice'),
+        selectPracticeRole: document.getElementById('select-practice-role'),
         inputRoomCode: document.getElementById('input-room-code'),
         btnJoin: document.getElementById('btn-join'),
         stats: document.getElementById('stats'),
+        auth: document.getElementById('auth'),
 
         // Lobby
         roomCode: document.getElementById('room-code'),
@@ -55,6 +73,7 @@
         hostControls: document.getElementById('host-controls'),
         btnStart: document.getElementById('btn-start'),
         startHint: document.getElementById('start-hint'),
+        btnAddBot: document.getElementById('btn-add-bot'),
         waitingMessage: document.getElementById('waiting-message'),
 
         // Role
@@ -122,9 +141,13 @@
     // ============================================
     // API Functions
     // ============================================
-    async function createRoom() {
+    async function createRoom(options) {
         try {
-            const response = await fetch('/api/rooms', { method: 'POST' });
+            const response = await fetch('/api/rooms', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(options || {})
+            });
             const data = await response.json();
 
             if (data.success) {
@@ -139,6 +162,28 @@
         }
     }
 
+    // Joins the fullest open public lobby, or a new one if none is open
+    async function quickJoin() {
+        try {
+            const language = (navigator.language || '').split('-')[0].toLowerCase();
+            const response = await fetch('/api/quickjoin', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(language ? { language } : {})
+            });
+            const data = await response.json();
+
+            if (data.success) {
+                joinRoom(data.data.roomCode);
+            } else {
+                showToast(data.error.message, 'error');
+            }
+        } catch (error) {
+            showToast('Failed to find a room', 'error');
+            console.error('Quick join error:', error);
+        }
+    }
+
     async function checkRoom(roomCode) {
         try {
             const response = await fetch(`/api/rooms/${roomCode}`);
@@ -163,12 +208,19 @@
     function connectWebSocket() {
         const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
         const wsUrl = `${protocol}//${window.location.host}/ws?roomCode=${state.roomCode}` +
-            (state.playerId ? `&playerId=${state.playerId}` : '');
+            `&protocolVersion=${PROTOCOL_VERSION}` +
+            (state.reconnectToken ? `&token=${encodeURIComponent(state.reconnectToken)}` : '') +
+            (state.reconnectToken && state.lastSeq ? `&lastSeq=${state.lastSeq}` : '');
 
+        let opened = false;
         state.ws = new WebSocket(wsUrl);
 
         state.ws.onopen = () => {
+            opened = true;
             console.log('WebSocket connected');
+
+            // Ask for the role and results to be resent until acknowledged
+            sendMessage('hello', { protocolVersion: PROTOCOL_VERSION, acks: true });
         };
 
         state.ws.onmessage = (event) => {
@@ -188,9 +240,17 @@
 
         state.ws.onclose = () => {
             console.log('WebSocket disconnected');
+
+            // A handshake rejected outright means the reconnect token is no longer valid
+            if (!opened && state.reconnectToken) {
+                state.reconnectToken = null;
+                localStorage.removeItem(`imposter_token_${state.roomCode}`);
+                return;
+            }
+
             // Try to reconnect after a delay
             setTimeout(() => {
-                if (state.roomCode && state.playerId) {
+                if (state.roomCode && state.reconnectToken) {
                     connectWebSocket();
                 }
             }, 3000);
@@ -210,9 +270,25 @@
     function handleMessage(message) {
         console.log('Received:', message.type, message.payload);
 
+        // Game events are sequenced; skip any already seen before a resume
+        if (message.seq) {
+            if (CRITICAL_EVENTS.includes(message.type)) {
+                // Acknowledge resends too, in case the first ack was lost
+                sendMessage('ack', { seq: message.seq });
+            }
+            if (message.seq <= state.lastSeq) {
+                return;
+            }
+            state.lastSeq = message.seq;
+        }
+
         switch (message.type) {
             case 'connected':
-                handleConnected(message.payload);
+                handleConnected(message.payload, message.timestamp);
+                break;
+            case 'resumed':
+                // Missed events were replayed; keep the current screen
+                saveReconnectToken(message.payload.reconnectToken);
                 break;
             case 'error':
                 handleError(message.payload);
@@ -223,14 +299,33 @@
             case 'PLAYER_RECONNECTED':
                 handleLobbyUpdate(message.payload);
                 break;
+            case 'GAME_ENDED':
+                // Returned to the lobby by an operator
+                state.phase = 'LOBBY';
+                handleLobbyUpdate(message.payload);
+                showScreen('lobby');
+                break;
+            case 'GAME_STARTED':
+                // Role assignment follows immediately
+                break;
+            case 'PHASE_CHANGED':
+                // The event starting the new phase follows and updates the screen
+                state.phase = message.payload.to;
+                break;
             case 'ROLES_ASSIGNED':
                 handleRoleAssigned(message.payload);
                 break;
+            case 'SUBMISSION_PHASE_STARTED':
+                handleSubmissionPhase(message.payload);
+                break;
             case 'SUBMISSION_MADE':
                 handleSubmissionUpdate(message.payload);
                 break;
             case 'VOTING_STARTED':
-                handleVotingStarted(message.payload);
+                handleVotingStarted(message.payload, message.timestamp);
+                break;
+            case 'VOTING_COUNTDOWN':
+                handleVotingCountdown(message.payload, message.timestamp);
                 break;
             case 'VOTE_CAST':
                 handleVoteUpdate(message.payload);
@@ -238,6 +333,24 @@
             case 'ROUND_ENDED':
                 handleRoundResults(message.payload);
                 break;
+            case 'ROOM_EXPIRING':
+                handleRoomExpiring(message.payload, message.timestamp);
+                break;
+            case 'CHEAT_SUSPECTED':
+                handleCheatSuspected(message.payload);
+                break;
+            case 'TOURNAMENT_UPDATE':
+                handleTournamentUpdate(message.payload);
+                break;
+            case 'system_notice':
+                handleSystemNotice(message.payload);
+                break;
+            case 'invite_created':
+                handleInviteCreated(message.payload);
+                break;
+            case 'protocol':
+                console.log('Protocol version:', message.payload.protocolVersion);
+                break;
             case 'pong':
                 // Heartbeat response
                 break;
@@ -246,15 +359,20 @@
         }
     }
 
-    function handleConnected(payload) {
+    function handleConnected(payload, serverTime) {
         state.playerId = payload.playerId;
         state.roomCode = payload.gameId;
+        state.lastSeq = payload.lastSeq || 0;
+        if (payload.serverVersion) {
+            console.log('Server version:', payload.serverVersion);
+        }
 
         // Restore state from gameState
         if (payload.gameState) {
             const gs = payload.gameState;
             state.phase = gs.phase;
             state.isHost = gs.hostId === state.playerId;
+            state.canStart = !!gs.canStart;
             
             if (gs.players) {
                 state.players = gs.players;
@@ -282,31 +400,83 @@
                     showSubmissionScreen();
                     break;
                 case 'VOTING':
+                    state.candidates = gs.candidates || [];
+                    state.votingDeadline = gs.votingDeadline
+                        ? localDeadline(gs.votingDeadline, serverTime)
+                        : null;
                     showVotingScreen();
                     break;
                 case 'RESULTS':
                     if (gs.results) {
-                        showResultsScreen(gs.results, gs.winner, gs.imposterId, gs.secretWord);
+                        showResultsScreen(gs.results, gs.winner, gs.imposterIds || [gs.imposterId], gs.secretWord);
                     }
                     break;
             }
         }
 
-        // Save player ID to localStorage for reconnection
-        localStorage.setItem(`imposter_player_${state.roomCode}`, state.playerId);
+        saveReconnectToken(payload.reconnectToken);
+    }
+
+    // Save reconnect token to localStorage for reconnection
+    function saveReconnectToken(token) {
+        if (token) {
+            state.reconnectToken = token;
+            localStorage.setItem(`imposter_token_${state.roomCode}`, state.reconnectToken);
+        }
     }
 
     function handleError(payload) {
+        const inviteFailed = payload.code === 'INVALID_INVITE' || payload.code === 'INVITE_EXPIRED';
+        if (payload.code === 'WRONG_PASSWORD' || inviteFailed) {
+            if (inviteFailed) {
+                // The invite no longer works, but the room password still does
+                showToast(payload.message, 'error');
+                state.invite = null;
+            }
+            // Locked room: ask for the password and retry the join
+            const password = window.prompt('This room is password protected. Enter the password:');
+            if (password !== null) {
+                sendMessage('join_lobby', { nickname: state.nickname, password });
+                return;
+            }
+            elements.nicknameForm.style.display = 'block';
+            elements.playersSection.style.display = 'none';
+            if (inviteFailed) {
+                return;
+            }
+        }
         showToast(payload.message, 'error');
     }
 
+    // Hosts share expiring invite links, which let players skip the room password
+    function handleInviteCreated(payload) {
+        const link = `${window.location.origin}/join/${state.roomCode}?invite=${encodeURIComponent(payload.token)}`;
+        copyToClipboard(link);
+    }
+
     function handleLobbyUpdate(payload) {
         if (payload.players) {
             state.players = payload.players;
         }
+        if (payload.changed) {
+            payload.changed.forEach(player => {
+                const i = state.players.findIndex(p => p.id === player.id);
+                if (i >= 0) {
+                    state.players[i] = player;
+                } else {
+                    state.players.push(player);
+                }
+            });
+        }
+        if (payload.removed) {
+            state.players = state.players.filter(p => !payload.removed.includes(p.id));
+        }
         if (payload.hostId) {
             state.isHost = payload.hostId === state.playerId;
         }
+        if (payload.canStart !== undefined) {
+            state.canStart = payload.canStart;
+        }
         updateLobbyUI();
     }
 
@@ -317,11 +487,21 @@
         showRoleScreen(payload.role, payload.secretWord);
     }
 
+    function handleSubmissionPhase(payload) {
+        state.phase = 'SUBMISSION';
+        state.submissions = payload.submissions || [];
+        state.currentPlayerId = payload.currentPlayerId;
+        showSubmissionScreen();
+    }
+
     function handleSubmissionUpdate(payload) {
         state.phase = 'SUBMISSION';
         if (payload.submissions) {
             state.submissions = payload.submissions;
         }
+        if (payload.added && !state.submissions.some(s => s.order === payload.added.order)) {
+            state.submissions.push(payload.added);
+        }
         if (payload.currentPlayerId !== undefined) {
             state.currentPlayerId = payload.currentPlayerId;
         }
@@ -339,20 +519,34 @@
         }
     }
 
-    function handleVotingStarted(payload) {
+    function handleVotingStarted(payload, serverTime) {
         state.phase = 'VOTING';
         state.hasVoted = false;
         state.votingSeconds = payload.remainingSeconds || 20;
+        state.votingDeadline = payload.deadline
+            ? localDeadline(payload.deadline, serverTime)
+            : Date.now() + state.votingSeconds * 1000;
         if (payload.players) {
             state.players = payload.players;
         }
+        // A revote after a tie is between the tied players only
+        state.candidates = payload.candidates || [];
+        if (state.candidates.length > 0) {
+            showToast('It\'s a tie! Vote again between the tied players', 'info');
+        }
         showVotingScreen();
     }
 
-    function handleVoteUpdate(payload) {
-        if (payload.remainingSeconds !== undefined) {
-            updateCountdown(payload.remainingSeconds);
+    // The deadline moved; votes already cast stand
+    function handleVotingCountdown(payload, serverTime) {
+        state.votingSeconds = payload.remainingSeconds;
+        state.votingDeadline = localDeadline(payload.deadline, serverTime);
+        if (state.phase === 'VOTING') {
+            startCountdown();
         }
+    }
+
+    function handleVoteUpdate(payload) {
         if (payload.votedCount !== undefined) {
             elements.votesCast.textContent = payload.votedCount;
             elements.votesTotal.textContent = payload.totalPlayers;
@@ -361,7 +555,36 @@
 
     function handleRoundResults(payload) {
         state.phase = 'RESULTS';
-        showResultsScreen(payload.votes, payload.winner, payload.imposterId, payload.secretWord);
+        stopCountdown();
+        showResultsScreen(payload.votes, payload.winner, payload.imposterIds || [payload.imposterId], payload.secretWord, payload.audience);
+    }
+
+    function handleRoomExpiring(payload, serverTime) {
+        // Measured against the server's clock, like the voting countdown
+        const minutes = Math.max(1, Math.round((new Date(payload.expiresAt) - new Date(serverTime)) / 60000));
+        showToast(`This room has been idle and will close in ${minutes} min unless someone plays`, 'error');
+    }
+
+    // Only the host is told; it is a hint, not proof
+    function handleCheatSuspected(payload) {
+        const names = payload.nicknames.filter(Boolean).join(', ') || 'A player';
+        showToast(`Suspicious play in round ${payload.round}: ${names} ${payload.detail}`, 'error');
+    }
+
+    // Sent by the operators to everyone connected, e.g. before a restart
+    function handleSystemNotice(payload) {
+        showToast(payload.message, payload.severity === 'info' ? 'info' : 'error');
+    }
+
+    function handleTournamentUpdate(payload) {
+        const leader = payload.standings[0];
+        if (payload.change === 'FINISHED') {
+            showToast(`${payload.name}: ${leader.nickname} wins with ${leader.points} points!`, 'success');
+        } else if (payload.change === 'STAGE_STARTED') {
+            showToast(`${payload.name}: stage ${payload.stage} has started`, 'info');
+        } else if (leader) {
+            showToast(`${payload.name}: a match ended; ${leader.nickname} leads with ${leader.points} points`, 'info');
+        }
     }
 
     // ============================================
@@ -376,6 +599,10 @@
             if (player.id === state.playerId) {
                 card.classList.add('is-you');
             }
+            const votable = state.candidates.length === 0 || state.candidates.includes(player.id);
+            if (!votable) {
+                card.classList.add('disabled');
+            }
             if (state.isHost && player.id === state.playerId) {
                 // Find if this player is host (we need to check from state)
             }
@@ -388,6 +615,9 @@
             if (player.status === 'DISCONNECTED') {
                 card.classList.add('disconnected');
             }
+            if (player.isBot) {
+                card.classList.add('is-bot');
+            }
 
             card.innerHTML = `<div class="player-nickname">${escapeHtml(player.nickname)}</div>`;
             elements.playersGrid.appendChild(card);
@@ -401,11 +631,12 @@
             elements.hostControls.style.display = 'block';
             elements.waitingMessage.style.display = 'none';
 
-            const canStart = state.players.length >= 4;
+            // Practice rooms start with fewer players, so the server decides
+            const canStart = state.canStart;
             elements.btnStart.disabled = !canStart;
             elements.startHint.textContent = canStart 
                 ? 'Ready to start!' 
-                : `Need ${4 - state.players.length} more player(s)`;
+                : `Need ${Math.max(4 - state.players.length, 1)} more player(s)`;
         } else {
             elements.hostControls.style.display = 'none';
             elements.waitingMessage.style.display = 'block';
@@ -477,7 +708,7 @@
 
         // Reset vote UI
         elements.votedMessage.style.display = 'none';
-        updateCountdown(state.votingSeconds);
+        startCountdown();
 
         // Build submissions list for reference
         elements.votingSubmissionsList.innerHTML = '';
@@ -515,7 +746,7 @@
             card.innerHTML = `<div class="vote-card-name">${escapeHtml(player.nickname)}</div>`;
             
             card.addEventListener('click', () => {
-                if (!state.hasVoted && player.id !== state.playerId) {
+                if (!state.hasVoted && votable && player.id !== state.playerId) {
                     castVote(player.id);
                     
                     // Mark as selected
@@ -550,6 +781,37 @@
         }
     }
 
+    // Convert a server deadline to local time using the server timestamp of the
+    // message that carried it, so clock skew between client and server cancels out
+    function localDeadline(deadline, serverTime) {
+        const offset = serverTime ? Date.now() - Date.parse(serverTime) : 0;
+        return Date.parse(deadline) + offset;
+    }
+
+    // Tick the voting countdown locally until the deadline; the server ends the phase
+    function startCountdown() {
+        stopCountdown();
+        if (!state.votingDeadline) {
+            state.votingDeadline = Date.now() + state.votingSeconds * 1000;
+        }
+        const tick = () => {
+            const remaining = Math.max(0, Math.ceil((state.votingDeadline - Date.now()) / 1000));
+            updateCountdown(remaining);
+            if (remaining === 0 || state.phase !== 'VOTING') {
+                stopCountdown();
+            }
+        };
+        tick();
+        state.countdownTimer = setInterval(tick, 250);
+    }
+
+    function stopCountdown() {
+        if (state.countdownTimer) {
+            clearInterval(state.countdownTimer);
+            state.countdownTimer = null;
+        }
+    }
+
     function updateCountdown(seconds) {
         elements.countdownNumber.textContent = seconds;
         elements.countdownNumber.classList.toggle('urgent', seconds <= 5);
@@ -566,17 +828,22 @@
         });
     }
 
-    function showResultsScreen(votes, winner, imposterId, secretWord) {
+    function showResultsScreen(votes, winner, imposterIds, secretWord, audience) {
         showScreen('results');
 
         // Winner banner
         const isVileksWin = winner === 'VILEK';
         elements.winnerBanner.className = 'winner-banner ' + (isVileksWin ? 'vileks-win' : 'imposter-wins');
-        elements.winnerText.textContent = isVileksWin ? 'VILEKS WIN!' : 'IMPOSTER WINS!';
+        elements.winnerText.textContent = isVileksWin
+            ? 'VILEKS WIN!'
+            : (imposterIds.length > 1 ? 'IMPOSTERS WIN!' : 'IMPOSTER WINS!');
 
         // Imposter reveal
-        const imposter = state.players.find(p => p.id === imposterId);
-        elements.imposterName.textContent = imposter ? imposter.nickname : 'Unknown';
+        const imposters = imposterIds.map(id => {
+            const imposter = state.players.find(p => p.id === id);
+            return imposter ? imposter.nickname : 'Unknown';
+        });
+        elements.imposterName.textContent = imposters.join(', ');
 
         // Secret word
         elements.revealedWord.textContent = secretWord;
@@ -610,6 +877,29 @@
             elements.votesBreakdown.appendChild(result);
         });
 
+        // Audience suspicion meter; spectators' votes do not count
+        if (audience && audience.players.length > 0) {
+            const heading = document.createElement('h4');
+            heading.className = 'audience-heading';
+            heading.textContent = `AUDIENCE SUSPICION (${audience.voters})`;
+            elements.votesBreakdown.appendChild(heading);
+
+            audience.players.forEach(suspect => {
+                const player = state.players.find(p => p.id === suspect.playerId);
+                const percent = Math.round(suspect.share * 100);
+                const meter = document.createElement('div');
+                meter.className = 'audience-meter';
+                meter.innerHTML = `
+                    <div class="audience-meter-label">
+                        <span>${escapeHtml(player ? player.nickname : 'Unknown')}</span>
+                        <span>${percent}%</span>
+                    </div>
+                    <div class="audience-meter-bar"><div style="width: ${percent}%"></div></div>
+                `;
+                elements.votesBreakdown.appendChild(meter);
+            });
+        }
+
         // Play again controls
         if (state.isHost) {
             elements.playAgainControls.style.display = 'block';
@@ -655,7 +945,15 @@
     // ============================================
     function setupEventListeners() {
         // Home screen
-        elements.btnCreate.addEventListener('click', createRoom);
+        elements.btnCreate.addEventListener('click', () => {
+            createRoom(elements.checkFamily.checked ? { familyFriendly: true } : {});
+        });
+        elements.btnQuickJoin.addEventListener('click', () => quickJoin());
+
+        elements.btnPractice.addEventListener('click', () => {
+            const practiceRole = elements.selectPracticeRole.value;
+            createRoom(practiceRole ? { practice: true, practiceRole } : { practice: true });
+        });
 
         elements.btnJoin.addEventListener('click', async () => {
             const code = elements.inputRoomCode.value.trim().toUpperCase();
@@ -684,6 +982,10 @@
 
         // Lobby screen
         elements.btnCopyLink.addEventListener('click', () => {
+            if (state.isHost) {
+                sendMessage('create_invite', {});
+                return;
+            }
             const link = `${window.location.origin}/join/${state.roomCode}`;
             copyToClipboard(link);
         });
@@ -701,10 +1003,10 @@
 
             state.nickname = nickname;
             
-            // Try to restore player ID from localStorage
-            const savedPlayerId = localStorage.getItem(`imposter_player_${state.roomCode}`);
-            if (savedPlayerId) {
-                state.playerId = savedPlayerId;
+            // Try to restore reconnect token from localStorage
+            const savedToken = localStorage.getItem(`imposter_token_${state.roomCode}`);
+            if (savedToken) {
+                state.reconnectToken = savedToken;
             }
 
             // Connect WebSocket
@@ -713,7 +1015,7 @@
             // Wait for connection then send join
             const checkAndJoin = () => {
                 if (state.ws && state.ws.readyState === WebSocket.OPEN) {
-                    sendMessage('join_lobby', { nickname });
+                    sendMessage('join_lobby', { nickname, invite: state.invite || undefined });
                     elements.nicknameForm.style.display = 'none';
                     elements.playersSection.style.display = 'block';
                 } else {
@@ -733,6 +1035,10 @@
             sendMessage('start_game');
         });
 
+        elements.btnAddBot.addEventListener('click', () => {
+            sendMessage('add_bot');
+        });
+
         // Submission screen
         elements.btnSubmitWord.addEventListener('click', () => {
             const word = elements.inputWord.value.trim();
@@ -777,6 +1083,7 @@
 
         if (joinMatch) {
             const roomCode = joinMatch[1].toUpperCase();
+            state.invite = new URLSearchParams(window.location.search).get('invite');
             checkRoom(roomCode).then(room => {
                 if (room) {
                     joinRoom(roomCode);
@@ -806,6 +1113,44 @@
         }
     }
 
+    async function loadIdentity() {
+        try {
+            const response = await fetch('/api/me');
+            const data = await response.json();
+            if (!data.success) {
+                return;
+            }
+
+            const me = data.data;
+            elements.auth.replaceChildren();
+            if (me.authenticated) {
+                // Signed-in players keep the same identity across rooms and devices
+                elements.auth.append(`Signed in as ${me.name || me.provider}`);
+                const logout = document.createElement('a');
+                logout.href = '#';
+                logout.textContent = 'Sign out';
+                logout.addEventListener('click', async (e) => {
+                    e.preventDefault();
+                    await fetch('/auth/logout', { method: 'POST' });
+                    loadIdentity();
+                });
+                elements.auth.append(logout);
+                if (me.name && !elements.inputNickname.value) {
+                    elements.inputNickname.value = me.name.slice(0, 15);
+                }
+            } else {
+                me.providers.forEach(provider => {
+                    const link = document.createElement('a');
+                    link.href = `/auth/${provider}/login`;
+                    link.textContent = `Sign in with ${provider.charAt(0).toUpperCase()}${provider.slice(1)}`;
+                    elements.auth.append(link);
+                });
+            }
+        } catch (e) {
+            // Anonymous play still works
+        }
+    }
+
     // ============================================
     // Initialize
     // ============================================
@@ -813,6 +1158,7 @@
         setupEventListeners();
         handleRouting();
         loadStats();
+        loadIdentity();
 
         // Periodically update stats
         setInterval(loadStats, 30000);
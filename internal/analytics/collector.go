@@ -0,0 +1,379 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+const (
+	// MaxWords bounds how many distinct submitted and secret words are
+	// counted; words first seen once it is reached are not
+	MaxWords = 10000
+
+	// topWords is how many of the most submitted words a report lists
+	topWords = 20
+
+	// flushTimeout bounds a single flush to the store
+	flushTimeout = 10 * time.Second
+
+	// dayLayout formats the UTC date aggregates are kept by
+	dayLayout = "2006-01-02"
+)
+
+// Store persists aggregates, so they survive restarts and add up across
+// instances
+type Store interface {
+	// LoadAggregates returns everything stored so far, with at most MaxWords
+	// of the most submitted and the most played secret words
+	LoadAggregates(ctx context.Context) (*Aggregates, error)
+
+	// AddAggregates adds delta to what is stored
+	AddAggregates(ctx context.Context, delta *Aggregates) error
+}
+
+// Aggregates are running totals over finished rounds
+type Aggregates struct {
+	Days         map[string]*DayTotals      // By UTC date, YYYY-MM-DD
+	PlayerCounts map[int]*PlayerCountTotals // By players in the round
+	Words        map[string]int             // Submitted words, lowercased
+	SecretWords  map[string]*WordTotals     // By secret word, lowercased
+}
+
+// DayTotals are the totals for the rounds that ended on one day. A game is
+// counted on the day its first round ended.
+type DayTotals struct {
+	Games        int
+	Rounds       int
+	RoundSeconds float64
+}
+
+// PlayerCountTotals are the totals for rounds played by the same number of
+// players
+type PlayerCountTotals struct {
+	Rounds       int
+	ImposterWins int
+}
+
+// WordTotals are the outcomes of the rounds played with one secret word
+type WordTotals struct {
+	Rounds        int
+	ImposterWins  int
+	VoteSpreadSum float64 // See voteSpread
+}
+
+// NewAggregates returns empty aggregates
+func NewAggregates() *Aggregates {
+	return &Aggregates{
+		Days:         make(map[string]*DayTotals),
+		PlayerCounts: make(map[int]*PlayerCountTotals),
+		Words:        make(map[string]int),
+		SecretWords:  make(map[string]*WordTotals),
+	}
+}
+
+// empty reports whether nothing has been counted
+func (a *Aggregates) empty() bool {
+	return len(a.Days) == 0 && len(a.PlayerCounts) == 0 && len(a.Words) == 0 && len(a.SecretWords) == 0
+}
+
+// add adds other's totals to a
+func (a *Aggregates) add(other *Aggregates) {
+	for day, totals := range other.Days {
+		a.day(day).add(totals)
+	}
+	for players, totals := range other.PlayerCounts {
+		t := a.playerCount(players)
+		t.Rounds += totals.Rounds
+		t.ImposterWins += totals.ImposterWins
+	}
+	for word, count := range other.Words {
+		a.Words[word] += count
+	}
+	for word, totals := range other.SecretWords {
+		t := a.secretWord(word)
+		t.Rounds += totals.Rounds
+		t.ImposterWins += totals.ImposterWins
+		t.VoteSpreadSum += totals.VoteSpreadSum
+	}
+}
+
+// day returns the totals for day, adding them if needed
+func (a *Aggregates) day(day string) *DayTotals {
+	totals, ok := a.Days[day]
+	if !ok {
+		totals = &DayTotals{}
+		a.Days[day] = totals
+	}
+	return totals
+}
+
+// playerCount returns the totals for players, adding them if needed
+func (a *Aggregates) playerCount(players int) *PlayerCountTotals {
+	totals, ok := a.PlayerCounts[players]
+	if !ok {
+		totals = &PlayerCountTotals{}
+		a.PlayerCounts[players] = totals
+	}
+	return totals
+}
+
+// secretWord returns the totals for word, adding them if needed
+func (a *Aggregates) secretWord(word string) *WordTotals {
+	totals, ok := a.SecretWords[word]
+	if !ok {
+		totals = &WordTotals{}
+		a.SecretWords[word] = totals
+	}
+	return totals
+}
+
+// add adds other's totals to t
+func (t *DayTotals) add(other *DayTotals) {
+	t.Games += other.Games
+	t.Rounds += other.Rounds
+	t.RoundSeconds += other.RoundSeconds
+}
+
+// Collector maintains aggregates over the rounds played on this instance,
+// added to those already stored if it persists them. It implements
+// app.GameHooks; register it with the hub before any session is created.
+type Collector struct {
+	app.NopGameHooks
+
+	mu      sync.Mutex
+	totals  *Aggregates
+	pending *Aggregates // Counted since the last flush
+
+	store  Store
+	logger *slog.Logger
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCollector returns a collector keeping its aggregates in memory
+func NewCollector(logger *slog.Logger) *Collector {
+	return &Collector{
+		totals:  NewAggregates(),
+		pending: NewAggregates(),
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+}
+
+// EnablePersistence loads the stored aggregates and flushes new ones to the
+// store every interval, and on Close. Call it once, before rounds are
+// collected.
+func (c *Collector) EnablePersistence(ctx context.Context, store Store, interval time.Duration) error {
+	stored, err := store.LoadAggregates(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.totals.add(stored)
+	c.store = store
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go c.flushLoop(interval)
+	return nil
+}
+
+// Close stops flushing, after flushing what is left
+func (c *Collector) Close() {
+	close(c.done)
+	c.wg.Wait()
+}
+
+// OnRoundEnded counts a finished round. It is called on the session's actor,
+// so it only updates the totals.
+func (c *Collector) OnRoundEnded(record *app.RoundRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, a := range []*Aggregates{c.totals, c.pending} {
+		day := a.day(record.EndedAt.UTC().Format(dayLayout))
+		if record.Number == 1 {
+			day.Games++
+		}
+		day.Rounds++
+		day.RoundSeconds += record.EndedAt.Sub(record.StartedAt).Seconds()
+
+		players := a.playerCount(len(record.Players))
+		players.Rounds++
+		if record.Winner == domain.RoleImposter {
+			players.ImposterWins++
+		}
+	}
+
+	secret := strings.ToLower(record.SecretWord)
+	if _, ok := c.totals.SecretWords[secret]; ok || len(c.totals.SecretWords) < MaxWords {
+		spread := voteSpread(record.Votes)
+		for _, a := range []*Aggregates{c.totals, c.pending} {
+			word := a.secretWord(secret)
+			word.Rounds++
+			if record.Winner == domain.RoleImposter {
+				word.ImposterWins++
+			}
+			word.VoteSpreadSum += spread
+		}
+	}
+
+	for _, sub := range record.Submissions {
+		word := strings.ToLower(strings.TrimSpace(sub.Word))
+		if word == "" {
+			continue
+		}
+		if _, ok := c.totals.Words[word]; !ok && len(c.totals.Words) >= MaxWords {
+			continue
+		}
+		c.totals.Words[word]++
+		c.pending.Words[word]++
+	}
+}
+
+// flushLoop flushes every interval until the collector is closed
+func (c *Collector) flushLoop(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			c.flush()
+			return
+		case <-ticker.C:
+			c.flush()
+		}
+	}
+}
+
+// flush adds what was counted since the last flush to the store, keeping it
+// for the next flush if that fails
+func (c *Collector) flush() {
+	c.mu.Lock()
+	delta := c.pending
+	c.pending = NewAggregates()
+	c.mu.Unlock()
+
+	if delta.empty() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	if err := c.store.AddAggregates(ctx, delta); err != nil {
+		c.logger.Warn("failed to flush analytics", "error", err)
+		c.mu.Lock()
+		c.pending.add(delta)
+		c.mu.Unlock()
+	}
+}
+
+// Report summarizes the aggregates
+type Report struct {
+	Games           int                 `json:"games"`
+	Rounds          int                 `json:"rounds"`
+	AvgRoundSeconds float64             `json:"avgRoundSeconds"`
+	GamesPerDay     []DayReport         `json:"gamesPerDay"`     // Oldest first; days without rounds are left out
+	ImposterWinRate []PlayerCountReport `json:"imposterWinRate"` // By player count
+	TopWords        []WordCount         `json:"topWords"`        // Most submitted first
+}
+
+// DayReport is a day's games and rounds
+type DayReport struct {
+	Day             string  `json:"day"` // UTC, YYYY-MM-DD
+	Games           int     `json:"games"`
+	Rounds          int     `json:"rounds"`
+	AvgRoundSeconds float64 `json:"avgRoundSeconds"`
+}
+
+// PlayerCountReport is how often the imposter won rounds played by Players
+type PlayerCountReport struct {
+	Players      int     `json:"players"`
+	Rounds       int     `json:"rounds"`
+	ImposterWins int     `json:"imposterWins"`
+	WinRate      float64 `json:"winRate"` // 0 to 1
+}
+
+// WordCount is how often a word was submitted
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Report summarizes all rounds counted, listing the last days days
+func (c *Collector) Report(days int) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &Report{
+		GamesPerDay:     make([]DayReport, 0),
+		ImposterWinRate: make([]PlayerCountReport, 0, len(c.totals.PlayerCounts)),
+		TopWords:        make([]WordCount, 0, topWords),
+	}
+
+	first := time.Now().UTC().AddDate(0, 0, 1-days).Format(dayLayout)
+	var roundSeconds float64
+	for day, totals := range c.totals.Days {
+		report.Games += totals.Games
+		report.Rounds += totals.Rounds
+		roundSeconds += totals.RoundSeconds
+		if day >= first {
+			report.GamesPerDay = append(report.GamesPerDay, DayReport{
+				Day:             day,
+				Games:           totals.Games,
+				Rounds:          totals.Rounds,
+				AvgRoundSeconds: average(totals.RoundSeconds, totals.Rounds),
+			})
+		}
+	}
+	report.AvgRoundSeconds = average(roundSeconds, report.Rounds)
+	sort.Slice(report.GamesPerDay, func(i, j int) bool {
+		return report.GamesPerDay[i].Day < report.GamesPerDay[j].Day
+	})
+
+	for players, totals := range c.totals.PlayerCounts {
+		report.ImposterWinRate = append(report.ImposterWinRate, PlayerCountReport{
+			Players:      players,
+			Rounds:       totals.Rounds,
+			ImposterWins: totals.ImposterWins,
+			WinRate:      average(float64(totals.ImposterWins), totals.Rounds),
+		})
+	}
+	sort.Slice(report.ImposterWinRate, func(i, j int) bool {
+		return report.ImposterWinRate[i].Players < report.ImposterWinRate[j].Players
+	})
+
+	words := make([]WordCount, 0, len(c.totals.Words))
+	for word, count := range c.totals.Words {
+		words = append(words, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+	report.TopWords = append(report.TopWords, words[:min(len(words), topWords)]...)
+
+	return report
+}
+
+// average returns sum / n, or 0 when there is nothing to average
+func average(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"sort"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// A secret word is rated once it has been played minRatedRounds times, by how
+// often the imposter won with it
+const (
+	minRatedRounds = 5
+	easyBelow      = 0.2 // The vileks' clues give the imposter away
+	hardAbove      = 0.6 // The vileks cannot hint at it without helping the imposter
+)
+
+// Difficulty rates a secret word by its outcomes
+type Difficulty string
+
+const (
+	DifficultyUnrated Difficulty = "unrated" // Not played enough to tell
+	DifficultyEasy    Difficulty = "easy"
+	DifficultyMedium  Difficulty = "medium"
+	DifficultyHard    Difficulty = "hard"
+)
+
+// difficulty rates the word the totals are for
+func (t *WordTotals) difficulty() Difficulty {
+	if t.Rounds < minRatedRounds {
+		return DifficultyUnrated
+	}
+	switch rate := average(float64(t.ImposterWins), t.Rounds); {
+	case rate < easyBelow:
+		return DifficultyEasy
+	case rate > hardAbove:
+		return DifficultyHard
+	}
+	return DifficultyMedium
+}
+
+// voteSpread measures how far a round's votes were from unanimous: 0 when
+// everyone voted for the same player, approaching 1 as the votes scatter
+func voteSpread(votes []domain.Vote) float64 {
+	if len(votes) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(votes))
+	top := 0
+	for _, vote := range votes {
+		counts[vote.TargetID]++
+		top = max(top, counts[vote.TargetID])
+	}
+	return 1 - float64(top)/float64(len(votes))
+}
+
+// WordStats are the outcomes of the rounds played with a secret word
+type WordStats struct {
+	Word            string     `json:"word"`
+	Rounds          int        `json:"rounds"`
+	ImposterWins    int        `json:"imposterWins"`
+	ImposterWinRate float64    `json:"imposterWinRate"` // 0 to 1
+	AvgVoteSpread   float64    `json:"avgVoteSpread"`   // 0 when votes are unanimous, towards 1 as they scatter
+	Difficulty      Difficulty `json:"difficulty"`
+}
+
+// WordStats returns the outcomes of every secret word played, most played
+// first
+func (c *Collector) WordStats() []WordStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make([]WordStats, 0, len(c.totals.SecretWords))
+	for word, totals := range c.totals.SecretWords {
+		stats = append(stats, WordStats{
+			Word:            word,
+			Rounds:          totals.Rounds,
+			ImposterWins:    totals.ImposterWins,
+			ImposterWinRate: average(float64(totals.ImposterWins), totals.Rounds),
+			AvgVoteSpread:   average(totals.VoteSpreadSum, totals.Rounds),
+			Difficulty:      totals.difficulty(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Rounds != stats[j].Rounds {
+			return stats[i].Rounds > stats[j].Rounds
+		}
+		return stats[i].Word < stats[j].Word
+	})
+	return stats
+}
+
+// miscalibrated returns the secret words rated easy or hard
+func (c *Collector) miscalibrated() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var words []string
+	for word, totals := range c.totals.SecretWords {
+		if d := totals.difficulty(); d == DifficultyEasy || d == DifficultyHard {
+			words = append(words, word)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Calibrate wraps provider so it avoids secret words rated easy or hard,
+// falling back on them as it would on used words. Seeded games then deal the
+// same words only while the ratings stay the same.
+func (c *Collector) Calibrate(provider app.WordProvider) app.WordProvider {
+	return &calibratedWords{provider: provider, collector: c}
+}
+
+// calibratedWords is a provider avoiding miscalibrated words
+type calibratedWords struct {
+	provider  app.WordProvider
+	collector *Collector
+}
+
+// NextWord returns a word from the wrapped provider, treating miscalibrated
+// words as used
+func (w *calibratedWords) NextWord(rng domain.Rand, used []string) (string, error) {
+	avoid := append(used[:len(used):len(used)], w.collector.miscalibrated()...)
+	return w.provider.NextWord(rng, avoid)
+}
@@ -0,0 +1,97 @@
+// Package apierror classifies errors for clients: the code, HTTP status and
+// message every transport reports an error with, so a domain error reads the
+// same over REST, WebSocket and gRPC. An error missing from the table is
+// reported as INTERNAL_ERROR and its text is never sent, as it may reveal
+// internals.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"imposter/internal/domain"
+)
+
+// Error describes how an error is reported to clients
+type Error struct {
+	Code    string
+	Status  int    // HTTP status
+	Message string // In English; transports translate it
+}
+
+// Retryable returns true if repeating the request later may succeed
+func (e Error) Retryable() bool {
+	return IsRetryable(e.Code)
+}
+
+// Internal describes every error Classify does not know
+var Internal = Error{Code: "INTERNAL_ERROR", Status: http.StatusInternalServerError, Message: "Internal server error"}
+
+// known maps domain errors to how they are reported, checked in order with errors.Is
+var known = []struct {
+	err   error
+	error Error
+}{
+	{domain.ErrGameNotFound, Error{"ROOM_NOT_FOUND", http.StatusNotFound, "Room not found"}},
+	{domain.ErrGameFull, Error{"GAME_FULL", http.StatusConflict, "Game is full"}},
+	{domain.ErrGameAlreadyStarted, Error{"INVALID_ACTION", http.StatusConflict, "Game has already started"}},
+	{domain.ErrNotEnoughPlayers, Error{"INVALID_ACTION", http.StatusConflict, "Not enough players to start"}},
+	{domain.ErrNotYourTurn, Error{"NOT_YOUR_TURN", http.StatusConflict, "It's not your turn"}},
+	{domain.ErrAlreadySubmitted, Error{"INVALID_ACTION", http.StatusConflict, "You have already submitted"}},
+	{domain.ErrAlreadyVoted, Error{"ALREADY_VOTED", http.StatusConflict, "You have already voted"}},
+	{domain.ErrInvalidPhase, Error{"INVALID_ACTION", http.StatusConflict, "Action not allowed in the current phase"}},
+	{domain.ErrPlayerNotFound, Error{"PLAYER_NOT_FOUND", http.StatusNotFound, "Player not found"}},
+	{domain.ErrNotHost, Error{"NOT_HOST", http.StatusForbidden, "Only the host can perform this action"}},
+	{domain.ErrCannotVoteSelf, Error{"CANNOT_VOTE_SELF", http.StatusBadRequest, "Cannot vote for yourself"}},
+	{domain.ErrInvalidTransition, Error{"INVALID_TRANSITION", http.StatusConflict, "Transition not allowed from the current phase"}},
+	{domain.ErrEmptyWord, Error{"INVALID_MESSAGE", http.StatusBadRequest, "Word is required"}},
+	{domain.ErrWordTooLong, Error{"INVALID_MESSAGE", http.StatusBadRequest, "Words can be at most 30 characters"}},
+	{domain.ErrEmptyNickname, Error{"INVALID_MESSAGE", http.StatusBadRequest, "Nickname is required"}},
+	{domain.ErrNicknameTooLong, Error{"INVALID_MESSAGE", http.StatusBadRequest, "Nicknames can be at most 20 characters"}},
+	{domain.ErrEmojiNotAllowed, Error{"INVALID_MESSAGE", http.StatusBadRequest, "Emoji are not allowed on this server"}},
+	{domain.ErrInvalidTargetID, Error{"INVALID_TARGET", http.StatusBadRequest, "Invalid vote target"}},
+	{domain.ErrNotACandidate, Error{"INVALID_TARGET", http.StatusBadRequest, "Vote for one of the tied players"}},
+	{domain.ErrWrongPassword, Error{"WRONG_PASSWORD", http.StatusForbidden, "Wrong room password"}},
+	{domain.ErrInvalidRoomCode, Error{"INVALID_ROOM_CODE", http.StatusBadRequest, "Invalid room code"}},
+	{domain.ErrReservedRoomCode, Error{"ROOM_CODE_RESERVED", http.StatusBadRequest, "This room code is reserved"}},
+	{domain.ErrRoomCodeTaken, Error{"ROOM_CODE_TAKEN", http.StatusConflict, "This room code is already in use"}},
+	{domain.ErrInvalidInvite, Error{"INVALID_INVITE", http.StatusForbidden, "Invalid invite link"}},
+	{domain.ErrInviteExpired, Error{"INVITE_EXPIRED", http.StatusForbidden, "This invite link has expired or been used up"}},
+	{domain.ErrInvalidExport, Error{"INVALID_EXPORT", http.StatusBadRequest, "Invalid or unsupported room export"}},
+	{domain.ErrServerAtCapacity, Error{"SERVER_AT_CAPACITY", http.StatusServiceUnavailable, "Too many rooms are open right now, please try again shortly"}},
+	{domain.ErrRoomQuotaExceeded, Error{"QUOTA_EXCEEDED", http.StatusTooManyRequests, "This room is too busy right now, please try again"}},
+	{domain.ErrTooManyBots, Error{"TOO_MANY_BOTS", http.StatusConflict, "This room cannot have any more bots"}},
+	{domain.ErrInvalidImposters, Error{"INVALID_IMPOSTERS", http.StatusBadRequest, "imposters must be auto or 1 to 4, with up to 3 increasing thresholds of at least 2 players for auto"}},
+	{domain.ErrUnknownWordSource, Error{"INVALID_WORD_SOURCE", http.StatusBadRequest, "This server does not offer that word source"}},
+	{domain.ErrFamilyWordSource, Error{"INVALID_WORD_SOURCE", http.StatusBadRequest, "Family-friendly rooms deal words from the family list"}},
+	{domain.ErrInappropriateText, Error{"INAPPROPRIATE_TEXT", http.StatusBadRequest, "That is not allowed in this room, please choose other words"}},
+	{domain.ErrPracticeRoom, Error{"PRACTICE_ROOM", http.StatusForbidden, "This is someone's practice room"}},
+}
+
+// Classify returns how err is reported to clients. ok is false for an
+// unexpected error, described as Internal, which the caller should report.
+func Classify(err error) (e Error, ok bool) {
+	for _, k := range known {
+		if errors.Is(err, k.err) {
+			return k.error, true
+		}
+	}
+	return Internal, false
+}
+
+// retryable lists the codes of errors caused by load or timing rather than by
+// the request itself
+var retryable = map[string]bool{
+	"QUOTA_EXCEEDED":     true,
+	"RATE_LIMITED":       true,
+	"SERVER_AT_CAPACITY": true,
+	"ROOM_AT_CAPACITY":   true,
+	"NODE_UNAVAILABLE":   true,
+	"INTERNAL_ERROR":     true,
+}
+
+// IsRetryable returns true if a request that failed with code may succeed
+// when repeated later, unchanged
+func IsRetryable(code string) bool {
+	return retryable[code]
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// Critical events are resent to clients that acknowledge events until they do
+const (
+	ackTimeout = 5 * time.Second // How long a client has to acknowledge a critical event
+	maxResends = 3               // Resends before a critical event is given up on
+)
+
+// AckingClient is a connection whose client may acknowledge the critical
+// events it receives with GameSession.Ack. Connections that do not implement
+// it, or whose AcksEvents returns false, are sent each event once.
+type AckingClient interface {
+	ClientConnection
+	AcksEvents() bool
+}
+
+// unackedEvent is a critical event sent to a player whose client has not
+// acknowledged it yet
+type unackedEvent struct {
+	event   *domain.GameEvent // Carries the player's sequence number
+	sentAt  time.Time
+	resends int
+}
+
+// acksEvents returns true if client acknowledges critical events
+func acksEvents(client ClientConnection) bool {
+	acking, ok := client.(AckingClient)
+	return ok && acking.AcksEvents()
+}
+
+// Ack records that the player's client has received every event up to and
+// including seq, so critical ones among them are not resent
+func (s *GameSession) Ack(playerID string, seq uint64) {
+	s.call(func() {
+		pending := s.unacked[playerID]
+		kept := pending[:0]
+		for _, u := range pending {
+			if u.event.Seq > seq {
+				kept = append(kept, u)
+			}
+		}
+		clear(pending[len(kept):])
+
+		if len(kept) == 0 {
+			delete(s.unacked, playerID)
+			return
+		}
+		s.unacked[playerID] = kept
+	})
+}
+
+// trackCritical waits for the player's client to acknowledge a critical event
+// sent to it, resending the event if it does not in time
+func (s *GameSession) trackCritical(playerID string, client ClientConnection, event *domain.GameEvent) {
+	if !acksEvents(client) {
+		return
+	}
+
+	s.unacked[playerID] = append(s.unacked[playerID], &unackedEvent{event: event, sentAt: time.Now()})
+	s.scheduleAckCheck()
+}
+
+// scheduleAckCheck resends unacknowledged events after ackTimeout, unless a
+// check is already scheduled
+func (s *GameSession) scheduleAckCheck() {
+	if !s.timers.isRunning(timerAcks) {
+		s.timers.start(timerAcks, ackTimeout, s.resendUnacked)
+	}
+}
+
+// resendUnacked resends the critical events that have waited ackTimeout for
+// an acknowledgment. An event resent maxResends times is given up on; the
+// player still gets its state from the snapshot when they reconnect.
+func (s *GameSession) resendUnacked() {
+	now := time.Now()
+	for playerID, pending := range s.unacked {
+		client, ok := s.clients[playerID]
+		if !ok {
+			// Resuming replays the events, and a new connection gets a snapshot
+			continue
+		}
+		if !acksEvents(client) {
+			delete(s.unacked, playerID)
+			continue
+		}
+
+		kept := pending[:0]
+		for _, u := range pending {
+			if now.Sub(u.sentAt) < ackTimeout {
+				kept = append(kept, u)
+				continue
+			}
+			if u.resends >= maxResends {
+				s.counters.sendFailures.Add(1)
+				s.logger.Warn("critical event never acknowledged", "roomCode", s.game.ID, "playerID", playerID, "type", u.event.Type, "seq", u.event.Seq)
+				continue
+			}
+
+			if err := client.Send(u.event); err != nil {
+				s.logger.Debug("failed to resend event", "playerID", playerID, "seq", u.event.Seq, "error", err)
+			}
+			u.resends++
+			u.sentAt = now
+			s.counters.eventsResent.Add(1)
+			kept = append(kept, u)
+		}
+		clear(pending[len(kept):])
+
+		if len(kept) == 0 {
+			delete(s.unacked, playerID)
+			continue
+		}
+		s.unacked[playerID] = kept
+	}
+
+	if len(s.unacked) > 0 {
+		s.scheduleAckCheck()
+	}
+}
+
+// restartAcks restarts the wait for the player's unacknowledged events, which
+// were just replayed to a resuming client
+func (s *GameSession) restartAcks(playerID string) {
+	now := time.Now()
+	for _, u := range s.unacked[playerID] {
+		u.sentAt = now
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+// WordAssociations lists, for each secret word, clue words a player who
+// knows it might plausibly give. Bots draw their clues from it, and use it to
+// judge the clues of others.
+var WordAssociations = map[string][]string{
+	// Cyberpunk / Tech
+	"hacker":    {"code", "breach", "hoodie", "terminal", "password", "exploit"},
+	"cyborg":    {"implant", "metal", "augmented", "machine", "hybrid", "chrome"},
+	"android":   {"robot", "synthetic", "humanoid", "phone", "artificial", "circuit"},
+	"hologram":  {"projection", "light", "ghost", "virtual", "shimmer", "3d"},
+	"matrix":    {"simulation", "grid", "code", "reality", "green", "pill"},
+	"neon":      {"glow", "sign", "pink", "bright", "tube", "night"},
+	"chrome":    {"shiny", "metal", "silver", "reflective", "polish", "browser"},
+	"synth":     {"keyboard", "music", "electronic", "wave", "eighties", "analog"},
+	"glitch":    {"bug", "error", "static", "flicker", "broken", "lag"},
+	"virus":     {"infection", "malware", "spread", "sick", "contagious", "antidote"},
+	"laser":     {"beam", "red", "light", "pointer", "cut", "precise"},
+	"plasma":    {"hot", "energy", "gas", "blood", "screen", "fusion"},
+	"quantum":   {"physics", "particle", "leap", "uncertain", "tiny", "computer"},
+	"binary":    {"zero", "one", "bits", "digital", "code", "switch"},
+	"pixel":     {"dot", "screen", "square", "resolution", "retro", "color"},
+	"drone":     {"flying", "propeller", "camera", "buzz", "remote", "bee"},
+	"robot":     {"machine", "metal", "beep", "factory", "arm", "automatic"},
+	"avatar":    {"profile", "character", "blue", "online", "identity", "game"},
+	"firewall":  {"security", "block", "network", "protect", "barrier", "traffic"},
+	"bitcoin":   {"crypto", "wallet", "mining", "blockchain", "money", "volatile"},
+	"server":    {"rack", "cloud", "host", "data", "waiter", "uptime"},
+	"arcade":    {"coins", "tokens", "retro", "cabinet", "highscore", "flashing"},
+	"console":   {"controller", "gaming", "comfort", "cartridge", "living", "panel"},
+	"joystick":  {"stick", "controller", "pilot", "lever", "buttons", "gaming"},
+	"keyboard":  {"keys", "typing", "piano", "clicky", "letters", "shortcut"},
+	"monitor":   {"screen", "display", "watch", "lizard", "heart", "desk"},
+	"circuit":   {"board", "wire", "current", "electric", "loop", "solder"},
+	"antenna":   {"signal", "radio", "insect", "roof", "reception", "tall"},
+	"satellite": {"orbit", "space", "dish", "moon", "signal", "gps"},
+	"radar":     {"blip", "detect", "sweep", "plane", "screen", "sonar"},
+
+	// Animals
+	"dragon":   {"fire", "wings", "scales", "hoard", "legend", "lizard"},
+	"phoenix":  {"fire", "rebirth", "ashes", "bird", "rise", "flame"},
+	"unicorn":  {"horn", "magic", "rainbow", "horse", "rare", "startup"},
+	"kraken":   {"tentacles", "sea", "monster", "ship", "deep", "release"},
+	"serpent":  {"snake", "scales", "slither", "venom", "garden", "coil"},
+	"tiger":    {"stripes", "orange", "jungle", "roar", "cat", "fierce"},
+	"falcon":   {"bird", "fast", "dive", "hunt", "talons", "millennium"},
+	"wolf":     {"howl", "pack", "moon", "forest", "grey", "hungry"},
+	"panther":  {"black", "cat", "stealth", "pink", "prowl", "jungle"},
+	"cobra":    {"snake", "hood", "venom", "charmer", "strike", "basket"},
+	"dolphin":  {"ocean", "smart", "flipper", "click", "swim", "friendly"},
+	"octopus":  {"tentacles", "ink", "eight", "suckers", "clever", "ocean"},
+	"scorpion": {"sting", "desert", "tail", "claws", "venom", "poison"},
+	"spider":   {"web", "eight", "legs", "silk", "crawl", "creepy"},
+	"beetle":   {"bug", "shell", "car", "crawl", "dung", "insect"},
+
+	// Places
+	"casino":    {"gamble", "chips", "jackpot", "vegas", "dice", "slots"},
+	"subway":    {"train", "underground", "tunnel", "sandwich", "commute", "station"},
+	"rooftop":   {"view", "party", "high", "skyline", "ledge", "garden"},
+	"alley":     {"narrow", "dark", "back", "shortcut", "cats", "bowling"},
+	"warehouse": {"storage", "boxes", "forklift", "shelves", "rave", "loading"},
+	"temple":    {"worship", "ancient", "stone", "monk", "sacred", "ruins"},
+	"fortress":  {"walls", "castle", "defense", "siege", "stone", "solitude"},
+	"pyramid":   {"egypt", "triangle", "pharaoh", "tomb", "desert", "scheme"},
+	"bunker":    {"underground", "shelter", "concrete", "war", "hidden", "golf"},
+	"tower":     {"tall", "clock", "castle", "climb", "radio", "view"},
+	"bridge":    {"river", "cross", "span", "cable", "cards", "troll"},
+	"tunnel":    {"dark", "underground", "light", "dig", "train", "mine"},
+	"harbor":    {"boats", "dock", "port", "sea", "anchor", "lighthouse"},
+	"factory":   {"production", "workers", "assembly", "smoke", "machines", "chocolate"},
+	"stadium":   {"crowd", "sports", "seats", "concert", "cheering", "field"},
+
+	// Objects
+	"diamond":   {"gem", "ring", "sparkle", "carat", "hard", "expensive"},
+	"crystal":   {"clear", "glass", "ball", "gem", "shard", "healing"},
+	"mirror":    {"reflection", "glass", "vanity", "selfie", "image", "cracked"},
+	"shadow":    {"dark", "silhouette", "follow", "sun", "puppet", "shade"},
+	"blade":     {"sharp", "knife", "sword", "edge", "cut", "runner"},
+	"helmet":    {"head", "protection", "knight", "bike", "visor", "hard"},
+	"shield":    {"protect", "block", "knight", "armor", "captain", "defense"},
+	"gauntlet":  {"glove", "armor", "challenge", "throw", "run", "iron"},
+	"compass":   {"north", "direction", "needle", "navigate", "map", "lost"},
+	"lantern":   {"light", "candle", "glow", "camping", "paper", "green"},
+	"whistle":   {"referee", "blow", "sound", "dog", "train", "tune"},
+	"umbrella":  {"rain", "wet", "shade", "open", "cocktail", "storm"},
+	"hammer":    {"nail", "tool", "build", "thor", "pound", "carpenter"},
+	"anchor":    {"ship", "heavy", "sea", "chain", "drop", "news"},
+	"hourglass": {"sand", "time", "timer", "glass", "countdown", "waist"},
+
+	// Food & Drinks
+	"coffee":    {"caffeine", "morning", "espresso", "mug", "beans", "latte"},
+	"whiskey":   {"scotch", "barrel", "bourbon", "rocks", "aged", "irish"},
+	"sushi":     {"fish", "rice", "japan", "raw", "chopsticks", "roll"},
+	"burger":    {"bun", "patty", "cheese", "grill", "fries", "fast"},
+	"pizza":     {"cheese", "slice", "italy", "dough", "pepperoni", "delivery"},
+	"chocolate": {"sweet", "cocoa", "bar", "dark", "melt", "dessert"},
+	"vanilla":   {"flavor", "bean", "ice", "cream", "plain", "pod"},
+	"cinnamon":  {"spice", "roll", "bark", "brown", "sweet", "christmas"},
+	"wasabi":    {"green", "spicy", "sushi", "horseradish", "hot", "paste"},
+	"honey":     {"bees", "sweet", "sticky", "golden", "jar", "comb"},
+
+	// Nature
+	"thunder":   {"storm", "loud", "rumble", "clap", "rain", "god"},
+	"lightning": {"bolt", "storm", "flash", "electric", "strike", "fast"},
+	"tornado":   {"twister", "wind", "spin", "funnel", "storm", "kansas"},
+	"volcano":   {"lava", "eruption", "magma", "ash", "mountain", "hot"},
+	"glacier":   {"ice", "cold", "melt", "slow", "mountain", "blue"},
+	"meteor":    {"space", "rock", "shooting", "crater", "impact", "dinosaurs"},
+	"eclipse":   {"sun", "moon", "dark", "shadow", "rare", "glasses"},
+	"aurora":    {"lights", "north", "sky", "green", "polar", "dance"},
+	"tsunami":   {"wave", "ocean", "flood", "earthquake", "giant", "coast"},
+	"avalanche": {"snow", "mountain", "slide", "rush", "danger", "ski"},
+
+	// Abstract / Concepts
+	"phantom":  {"ghost", "opera", "mask", "invisible", "haunt", "menace"},
+	"specter":  {"ghost", "spirit", "apparition", "haunt", "pale", "dread"},
+	"enigma":   {"mystery", "puzzle", "riddle", "code", "secret", "unknown"},
+	"paradox":  {"contradiction", "logic", "loop", "impossible", "time", "twist"},
+	"illusion": {"magic", "trick", "optical", "fake", "mirage", "deceive"},
+	"chaos":    {"disorder", "mess", "random", "theory", "wild", "storm"},
+	"harmony":  {"peace", "music", "balance", "chord", "agreement", "unity"},
+	"velocity": {"speed", "fast", "physics", "direction", "motion", "rate"},
+	"gravity":  {"fall", "weight", "newton", "apple", "pull", "earth"},
+	"infinity": {"endless", "forever", "eight", "loop", "beyond", "limitless"},
+
+	// Music / Art
+	"rhythm":       {"beat", "drum", "tempo", "dance", "groove", "pulse"},
+	"melody":       {"tune", "song", "notes", "hum", "sing", "catchy"},
+	"symphony":     {"orchestra", "classical", "conductor", "beethoven", "movements", "grand"},
+	"canvas":       {"paint", "art", "blank", "easel", "fabric", "tent"},
+	"sculpture":    {"statue", "marble", "clay", "chisel", "museum", "carve"},
+	"graffiti":     {"spray", "wall", "street", "tag", "paint", "urban"},
+	"tattoo":       {"ink", "needle", "skin", "permanent", "design", "sleeve"},
+	"mosaic":       {"tiles", "pieces", "pattern", "colorful", "glass", "floor"},
+	"origami":      {"paper", "fold", "crane", "japan", "crease", "swan"},
+	"kaleidoscope": {"colors", "pattern", "mirrors", "spin", "tube", "symmetry"},
+}
+
+// vagueClues fit almost any secret word, for an imposter with nothing to go on
+var vagueClues = []string{
+	"interesting", "classic", "common", "strange", "popular", "unique",
+	"modern", "famous", "special", "useful", "cool", "big",
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"imposter/internal/domain"
+)
+
+// maxAudienceVoters bounds how many audience members a vote keeps track of;
+// later ones are turned away, so a flood of viewers cannot exhaust memory
+const maxAudienceVoters = 10000
+
+// Prefixes keeping the voter IDs of spectators and of viewers voting through
+// a chat bridge apart
+const (
+	spectatorVoterPrefix = "spectator:"
+	bridgeVoterPrefix    = "bridge:"
+)
+
+// AudienceVote is a vote from a chat bridge: one viewer, known by the
+// bridge's own ID for them, suspecting a player named by ID or nickname
+type AudienceVote struct {
+	VoterID        string `json:"voterId"`
+	TargetPlayerID string `json:"targetPlayerId,omitempty"`
+	TargetNickname string `json:"targetNickname,omitempty"` // Ignoring case, e.g. from "!vote alice"
+}
+
+// audienceVotes collects the audience's votes during a voting phase. They do
+// not count toward the result; the results show them as how suspected each
+// player was. Only the actor may use it.
+type audienceVotes struct {
+	votes map[string]string // voterID -> target playerID; a later vote replaces an earlier one
+}
+
+func newAudienceVotes() *audienceVotes {
+	return &audienceVotes{votes: make(map[string]string)}
+}
+
+// reset forgets every vote, as a vote starts
+func (a *audienceVotes) reset() {
+	clear(a.votes)
+}
+
+// cast records voter's vote for target, returning false if the audience is
+// full and voter has not voted yet
+func (a *audienceVotes) cast(voterID, targetID string) bool {
+	if _, voted := a.votes[voterID]; !voted && len(a.votes) >= maxAudienceVoters {
+		return false
+	}
+	a.votes[voterID] = targetID
+	return true
+}
+
+// tally returns the votes for each player, most suspected first, or nil if
+// nobody in the audience voted
+func (a *audienceVotes) tally() *domain.AudienceSuspicion {
+	if len(a.votes) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, target := range a.votes {
+		counts[target]++
+	}
+
+	suspicion := &domain.AudienceSuspicion{Voters: len(a.votes)}
+	for playerID, votes := range counts {
+		suspicion.Players = append(suspicion.Players, domain.AudienceSuspect{
+			PlayerID: playerID,
+			Votes:    votes,
+			Share:    float64(votes) / float64(len(a.votes)),
+		})
+	}
+	sort.Slice(suspicion.Players, func(i, j int) bool {
+		if suspicion.Players[i].Votes != suspicion.Players[j].Votes {
+			return suspicion.Players[i].Votes > suspicion.Players[j].Votes
+		}
+		return suspicion.Players[i].PlayerID < suspicion.Players[j].PlayerID
+	})
+	return suspicion
+}
+
+// CastSpectatorVote records a spectator's non-binding vote for the player
+// they suspect
+func (s *GameSession) CastSpectatorVote(connID, targetID string) error {
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if _, ok := s.spectators[connID]; !ok {
+			err = domain.ErrPlayerNotFound
+			return
+		}
+		err = s.castAudienceVote(spectatorVoterPrefix+connID, targetID)
+	})
+	return err
+}
+
+// CastBridgeVotes records the non-binding votes a chat bridge relays for its
+// viewers, on behalf of the host, returning how many were recorded. Votes
+// naming no suspect the room's players could vote for are skipped.
+func (s *GameSession) CastBridgeVotes(hostID string, votes []AudienceVote) (int, error) {
+	var (
+		recorded int
+		err      = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		if !s.game.IsHost(hostID) {
+			err = domain.ErrNotHost
+			return
+		}
+		if s.game.Phase != domain.PhaseVoting {
+			err = domain.ErrInvalidPhase
+			return
+		}
+
+		err = nil
+		for _, vote := range votes {
+			if vote.VoterID == "" {
+				continue
+			}
+			targetID := vote.TargetPlayerID
+			if targetID == "" {
+				targetID = s.playerIDByNickname(vote.TargetNickname)
+			}
+			if s.castAudienceVote(bridgeVoterPrefix+vote.VoterID, targetID) == nil {
+				recorded++
+			}
+		}
+	})
+	return recorded, err
+}
+
+// castAudienceVote records an audience vote for a player dealt into the
+// round, and one of the tied players in a revote
+func (s *GameSession) castAudienceVote(voterID, targetID string) error {
+	round := s.game.CurrentRound
+	if s.game.Phase != domain.PhaseVoting || round == nil {
+		return domain.ErrInvalidPhase
+	}
+	if !slices.Contains(round.PlayerOrder, targetID) {
+		return domain.ErrInvalidTargetID
+	}
+	if !round.IsCandidate(targetID) {
+		return domain.ErrNotACandidate
+	}
+	if !s.audience.cast(voterID, targetID) {
+		return domain.ErrRoomQuotaExceeded
+	}
+	return nil
+}
+
+// playerIDByNickname returns the ID of the player with nickname, ignoring
+// case, or "" if there is none
+func (s *GameSession) playerIDByNickname(nickname string) string {
+	for id, player := range s.game.Players {
+		if nickname != "" && strings.EqualFold(player.Nickname, strings.TrimSpace(nickname)) {
+			return id
+		}
+	}
+	return ""
+}
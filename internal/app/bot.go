@@ -0,0 +1,432 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"imposter/internal/domain"
+)
+
+// How long a bot takes to act, picked at random each time so bots do not
+// answer in lockstep
+const (
+	botThinkMin = 1500 * time.Millisecond
+	botThinkMax = 4 * time.Second
+)
+
+// PracticeBots is how many bots a practice room is filled with when its
+// player joins
+const PracticeBots = 3
+
+// botNames are the nicknames given to bots, in order of preference
+var botNames = []string{
+	"Ada", "Turing", "Hopper", "Lovelace", "Babbage",
+	"Knuth", "Ritchie", "Liskov", "Hamilton", "Dijkstra",
+}
+
+// Bot plays for a computer player. It is the player's ClientConnection: the
+// session delivers events to it on the actor, and it looks at the game and
+// acts from its own goroutine after a moment's thought, as a person would.
+type Bot struct {
+	playerID string
+	session  *GameSession
+
+	wake      chan struct{} // Signalled when the game may need the bot to act
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newBot starts a bot playing for playerID, which looks at the game once
+// straight away in case it is already the bot's turn
+func newBot(session *GameSession, playerID string) *Bot {
+	b := &Bot{
+		playerID: playerID,
+		session:  session,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	b.poke()
+
+	go b.run()
+
+	return b
+}
+
+// Send implements ClientConnection. The event itself is not needed, since the
+// bot reads the game's state when it acts.
+func (b *Bot) Send(message interface{}) error {
+	b.poke()
+	return nil
+}
+
+// GetPlayerID implements ClientConnection
+func (b *Bot) GetPlayerID() string {
+	return b.playerID
+}
+
+// Close implements ClientConnection and stops the bot
+func (b *Bot) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+	return nil
+}
+
+// poke makes the bot look at the game, without blocking the caller
+func (b *Bot) poke() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run acts on the game whenever it changes, until the bot is closed
+func (b *Bot) run() {
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-b.wake:
+		}
+
+		think := time.NewTimer(botThinkMin + time.Duration(rand.Int63n(int64(botThinkMax-botThinkMin))))
+		select {
+		case <-b.done:
+			think.Stop()
+			return
+		case <-think.C:
+		}
+
+		b.act()
+	}
+}
+
+// act submits a clue on the bot's turn and votes once voting starts
+func (b *Bot) act() {
+	view, ok := b.session.botView(b.playerID)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch {
+	case view.phase == domain.PhaseSubmission && view.currentPlayerID == b.playerID && !view.hasSubmitted:
+		err = b.session.SubmitWord(b.playerID, chooseClue(view))
+	case view.phase == domain.PhaseVoting && !view.hasVoted && len(view.others) > 0:
+		err = b.session.CastVote(b.playerID, chooseSuspect(view))
+	}
+
+	// Nothing else may wake the bot on its turn, so try again
+	if err != nil && err != domain.ErrGameNotFound {
+		b.session.logger.Debug("bot action failed, retrying", "roomCode", b.session.GetRoomCode(), "playerID", b.playerID, "error", err)
+		b.poke()
+	}
+}
+
+// AddBot adds a computer player to the room on behalf of the host, so a few
+// people can still reach the minimum player count
+func (s *GameSession) AddBot(hostID string) (*domain.Player, error) {
+	var (
+		player *domain.Player
+		err    error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
+		if !s.game.IsHost(hostID) {
+			err = domain.ErrNotHost
+			return
+		}
+
+		// Practice rooms have a single player, so any number of bots
+		if !s.game.Practice && len(s.bots) >= s.quotas.MaxBots {
+			err = domain.ErrTooManyBots
+			return
+		}
+
+		player, err = s.addBot()
+	})
+	return player, err
+}
+
+// addBot adds a computer player to the game
+func (s *GameSession) addBot() (*domain.Player, error) {
+	player, err := s.game.AddPlayer(uuid.New().String(), s.botNickname())
+	if err != nil {
+		return nil, err
+	}
+	player.IsBot = true
+
+	s.attachBot(player.ID)
+	s.playerJoined(player)
+
+	s.logger.Info("bot added", "roomCode", s.game.ID, "playerID", player.ID, "nickname", player.Nickname)
+	return player, nil
+}
+
+// fillPracticeBots tops a practice room up to PracticeBots bots
+func (s *GameSession) fillPracticeBots() {
+	for len(s.bots) < PracticeBots {
+		if _, err := s.addBot(); err != nil {
+			s.logger.Warn("failed to add practice bot", "roomCode", s.game.ID, "error", err)
+			return
+		}
+	}
+}
+
+// FillBots adds bots on behalf of the host until the lobby has the minimum
+// number of players, whatever MaxBots allows, and returns those added. It is
+// a development aid, so one person can play a whole game; transports offer it
+// only outside production.
+func (s *GameSession) FillBots(hostID string) ([]*domain.Player, error) {
+	var (
+		added []*domain.Player
+		err   error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
+		if !s.game.IsHost(hostID) {
+			err = domain.ErrNotHost
+			return
+		}
+		added, err = s.fillBots()
+	})
+	return added, err
+}
+
+// fillBots adds bots until the lobby has the minimum number of players
+func (s *GameSession) fillBots() ([]*domain.Player, error) {
+	if s.game.Phase != domain.PhaseLobby {
+		return nil, domain.ErrGameAlreadyStarted
+	}
+
+	var added []*domain.Player
+	for len(s.game.Players) < s.game.Settings.MinPlayers {
+		player, err := s.addBot()
+		if err != nil {
+			return added, err
+		}
+		added = append(added, player)
+	}
+	return added, nil
+}
+
+// botNickname returns a nickname no player in the room has yet
+func (s *GameSession) botNickname() string {
+	taken := make(map[string]bool, len(s.game.Players))
+	for _, player := range s.game.Players {
+		taken[player.Nickname] = true
+	}
+
+	for i := 1; ; i++ {
+		for _, name := range botNames {
+			nickname := "Bot " + name
+			if i > 1 {
+				nickname = fmt.Sprintf("%s %d", nickname, i)
+			}
+			if !taken[nickname] {
+				return nickname
+			}
+		}
+	}
+}
+
+// attachBot starts a bot playing for playerID. Bots have no event buffer or
+// presence, since nobody reconnects as them.
+func (s *GameSession) attachBot(playerID string) {
+	bot := newBot(s, playerID)
+	s.bots[playerID] = bot
+	s.clients[playerID] = bot
+}
+
+// detachBot stops the bot playing for playerID, if there is one
+func (s *GameSession) detachBot(playerID string) {
+	if bot, ok := s.bots[playerID]; ok {
+		bot.Close()
+		delete(s.bots, playerID)
+		delete(s.clients, playerID)
+	}
+}
+
+// botView is what a bot's player can see of the game
+type botView struct {
+	phase           domain.Phase
+	role            domain.Role
+	secretWord      string // Empty for the imposter
+	currentPlayerID string
+	submissions     []domain.Submission
+	others          []string // IDs of the other players who may be voted for
+	hasSubmitted    bool
+	hasVoted        bool
+}
+
+// botView returns what playerID can see of the game, or false if the session
+// is closed or the player has left
+func (s *GameSession) botView(playerID string) (botView, bool) {
+	var (
+		view botView
+		ok   bool
+	)
+	s.call(func() {
+		player, err := s.game.GetPlayer(playerID)
+		if err != nil {
+			return
+		}
+		ok = true
+
+		view.phase = s.game.Phase
+		view.role = player.Role
+		view.hasSubmitted = player.HasSubmitted
+		view.hasVoted = player.HasVoted
+		round := s.game.CurrentRound
+		for id := range s.game.Players {
+			if id != playerID && (round == nil || round.IsCandidate(id)) {
+				view.others = append(view.others, id)
+			}
+		}
+		if round == nil {
+			return
+		}
+		if player.Role == domain.RoleVilek {
+			view.secretWord = round.SecretWord
+		}
+		view.currentPlayerID = round.GetCurrentPlayerID()
+		for _, sub := range round.Submissions {
+			view.submissions = append(view.submissions, *sub)
+		}
+	})
+	return view, ok
+}
+
+// chooseClue picks a clue for the secret word, or for the imposter the word
+// the clues so far seem to point at, avoiding clues already given
+func chooseClue(view botView) string {
+	given := make(map[string]bool, len(view.submissions))
+	for _, sub := range view.submissions {
+		given[normalizeClue(sub.Word)] = true
+	}
+
+	secret := view.secretWord
+	if view.role == domain.RoleImposter {
+		secret = guessSecretWord(view.submissions)
+	}
+
+	var clues []string
+	for _, clue := range WordAssociations[secret] {
+		if !given[clue] {
+			clues = append(clues, clue)
+		}
+	}
+	if len(clues) == 0 {
+		for _, clue := range vagueClues {
+			if !given[clue] {
+				clues = append(clues, clue)
+			}
+		}
+	}
+	if len(clues) == 0 {
+		return vagueClues[rand.Intn(len(vagueClues))]
+	}
+	return clues[rand.Intn(len(clues))]
+}
+
+// chooseSuspect picks the player whose clue fits the secret word worst. The
+// imposter judges against its guess, pointing suspicion away from itself.
+func chooseSuspect(view botView) string {
+	secret := view.secretWord
+	if view.role == domain.RoleImposter {
+		secret = guessSecretWord(view.submissions)
+	}
+
+	// Players without a clue are judged like a vague one; the random part
+	// breaks ties
+	suspicion := make(map[string]float64, len(view.others))
+	for _, playerID := range view.others {
+		suspicion[playerID] = 2 + rand.Float64()
+	}
+	for _, sub := range view.submissions {
+		if _, ok := suspicion[sub.PlayerID]; ok {
+			suspicion[sub.PlayerID] = clueSuspicion(sub.Word, secret) + rand.Float64()
+		}
+	}
+
+	suspect := view.others[0]
+	for playerID, score := range suspicion {
+		if score > suspicion[suspect] {
+			suspect = playerID
+		}
+	}
+	return suspect
+}
+
+// clueSuspicion rates how badly a clue fits secret: 0 if it is one of its
+// associations, 2 if it is vague enough to fit anything, 1 otherwise. Every
+// clue rates 1 when secret is unknown.
+func clueSuspicion(clue, secret string) float64 {
+	if secret == "" {
+		return 1
+	}
+
+	clue = normalizeClue(clue)
+	for _, vague := range vagueClues {
+		if clue == vague {
+			return 2
+		}
+	}
+	if clue == secret {
+		return 0
+	}
+	for _, association := range WordAssociations[secret] {
+		if clue == association {
+			return 0
+		}
+	}
+	return 1
+}
+
+// guessSecretWord returns the secret word whose associations match the most
+// clues, or "" if none match
+func guessSecretWord(submissions []domain.Submission) string {
+	var (
+		best      []string
+		bestScore int
+	)
+	for secret, associations := range WordAssociations {
+		score := 0
+		for _, sub := range submissions {
+			clue := normalizeClue(sub.Word)
+			for _, association := range associations {
+				if clue == association {
+					score++
+					break
+				}
+			}
+		}
+
+		switch {
+		case score == 0 || score < bestScore:
+		case score > bestScore:
+			best, bestScore = []string{secret}, score
+		default:
+			best = append(best, secret)
+		}
+	}
+
+	if len(best) == 0 {
+		return ""
+	}
+	return best[rand.Intn(len(best))]
+}
+
+// normalizeClue prepares a clue for comparison with the word lists
+func normalizeClue(word string) string {
+	return strings.ToLower(strings.TrimSpace(word))
+}
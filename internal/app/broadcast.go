@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// broadcastTimeout bounds publishing a single event
+const broadcastTimeout = 2 * time.Second
+
+// Broadcaster carries each room's events to every server instance that has
+// clients in that room. Sessions publish their events to it and receive them
+// back through a subscription before delivering them to local clients.
+type Broadcaster interface {
+	// Publish sends event to all subscribers of event.GameID
+	Publish(ctx context.Context, event *domain.GameEvent) error
+
+	// Subscribe calls deliver, in publish order, for every event published for
+	// roomCode until the returned function is called
+	Subscribe(roomCode string, deliver func(event *domain.GameEvent)) (unsubscribe func())
+}
+
+// LocalBroadcaster delivers events to subscribers in this process. It is the
+// default for single-instance deployments.
+type LocalBroadcaster struct {
+	mu     sync.RWMutex
+	rooms  map[string]map[int]func(event *domain.GameEvent)
+	nextID int
+}
+
+// NewLocalBroadcaster creates an in-memory broadcaster
+func NewLocalBroadcaster() *LocalBroadcaster {
+	return &LocalBroadcaster{
+		rooms: make(map[string]map[int]func(event *domain.GameEvent)),
+	}
+}
+
+// Publish delivers event synchronously to the room's subscribers
+func (b *LocalBroadcaster) Publish(ctx context.Context, event *domain.GameEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, deliver := range b.rooms[event.GameID] {
+		deliver(event)
+	}
+	return nil
+}
+
+// Subscribe registers deliver for the room's events
+func (b *LocalBroadcaster) Subscribe(roomCode string, deliver func(event *domain.GameEvent)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	if b.rooms[roomCode] == nil {
+		b.rooms[roomCode] = make(map[int]func(event *domain.GameEvent))
+	}
+	b.rooms[roomCode][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.rooms[roomCode], id)
+		if len(b.rooms[roomCode]) == 0 {
+			delete(b.rooms, roomCode)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	"imposter/internal/domain"
+)
+
+// RoomFilter selects the public rooms the room browser lists. Zero fields
+// match any room.
+type RoomFilter struct {
+	Language       string       // Rooms created for this language, e.g. "en"
+	WordSource     string       // Rooms dealing from this word source, the server default included
+	MinPlayerCount int          // Rooms with at least this many players
+	MaxPlayerCount int          // Rooms with at most this many players; 0 is unbounded
+	Tags           []string     // Rooms with every one of these tags
+	FamilyFriendly bool         // Only family-friendly rooms
+	Phase          domain.Phase // Rooms in this phase; empty lists only rooms that can be joined
+}
+
+// RoomOrder is the order the room browser lists rooms in
+type RoomOrder string
+
+const (
+	OrderNewest RoomOrder = "newest" // Newest first
+	OrderFill   RoomOrder = "fill"   // Closest to full first, then oldest, so new players fill rooms up
+)
+
+// IsValid returns true if o is a known order
+func (o RoomOrder) IsValid() bool {
+	return o == OrderNewest || o == OrderFill
+}
+
+// RoomCursor marks where a page of the room browser ended: the last room
+// listed, by the values it was ordered on. The next page starts after it, so
+// rooms opening or closing meanwhile do not shift later pages.
+type RoomCursor struct {
+	Order     RoomOrder `json:"o"`
+	Fill      float64   `json:"f,omitempty"`
+	CreatedAt int64     `json:"t"` // Unix nanoseconds
+	RoomCode  string    `json:"c"`
+}
+
+// NewRoomCursor returns the cursor for a page ending at room
+func NewRoomCursor(room *RoomSnapshot, order RoomOrder) RoomCursor {
+	return RoomCursor{
+		Order:     order,
+		Fill:      fill(room),
+		CreatedAt: room.CreatedAt.UnixNano(),
+		RoomCode:  room.RoomCode,
+	}
+}
+
+// Encode returns the cursor as an opaque string for clients
+func (c RoomCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseRoomCursor decodes a cursor returned by Encode
+func ParseRoomCursor(s string) (RoomCursor, bool) {
+	var cursor RoomCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || !cursor.Order.IsValid() || cursor.RoomCode == "" {
+		return RoomCursor{}, false
+	}
+	return cursor, true
+}
+
+// fill returns how full room is, from 0 (empty) to 1
+func fill(room *RoomSnapshot) float64 {
+	if room.Settings.MaxPlayers <= 0 {
+		return 0
+	}
+	return float64(len(room.Players)) / float64(room.Settings.MaxPlayers)
+}
+
+// before returns true if a is listed before b in order. Room codes break
+// ties, so every room has its own place.
+func (a RoomCursor) before(b RoomCursor) bool {
+	if a.Order == OrderFill {
+		if a.Fill != b.Fill {
+			return a.Fill > b.Fill
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.RoomCode < b.RoomCode
+	}
+
+	if a.CreatedAt != b.CreatedAt {
+		return a.CreatedAt > b.CreatedAt
+	}
+	return a.RoomCode < b.RoomCode
+}
+
+// BrowseRooms returns the public rooms that match filter, in order
+func (h *GameHub) BrowseRooms(filter RoomFilter, order RoomOrder) ([]*RoomSnapshot, error) {
+	h.mu.RLock()
+	sources := h.wordSources
+	h.mu.RUnlock()
+	if filter.WordSource != "" && !sources.Has(filter.WordSource) {
+		return nil, domain.ErrUnknownWordSource
+	}
+
+	rooms := make([]*RoomSnapshot, 0)
+	for _, session := range h.allSessions() {
+		room := session.Snapshot()
+		if room.Visibility != domain.VisibilityPublic {
+			continue
+		}
+		if filter.Phase == "" && !room.CanJoin {
+			continue
+		}
+		if filter.Phase != "" && room.Phase != filter.Phase {
+			continue
+		}
+		if filter.Language != "" && !strings.EqualFold(room.Language, filter.Language) {
+			continue
+		}
+		if filter.WordSource != "" && sources.Resolve(room.WordSource) != filter.WordSource {
+			continue
+		}
+		if len(room.Players) < filter.MinPlayerCount || (filter.MaxPlayerCount > 0 && len(room.Players) > filter.MaxPlayerCount) {
+			continue
+		}
+		if filter.FamilyFriendly && !room.FamilyFriendly {
+			continue
+		}
+		if !hasTags(room.Tags, filter.Tags) {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+
+	sort.Slice(rooms, func(i, j int) bool {
+		return NewRoomCursor(rooms[i], order).before(NewRoomCursor(rooms[j], order))
+	})
+	return rooms, nil
+}
+
+// RoomsAfter returns the rooms, listed by BrowseRooms in the cursor's order,
+// that come after the cursor
+func RoomsAfter(rooms []*RoomSnapshot, cursor RoomCursor) []*RoomSnapshot {
+	i := sort.Search(len(rooms), func(i int) bool {
+		return cursor.before(NewRoomCursor(rooms[i], cursor.Order))
+	})
+	return rooms[i:]
+}
+
+// hasTags returns true if tags includes every one of wanted
+func hasTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
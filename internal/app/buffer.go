@@ -0,0 +1,91 @@
+package app
+
+import (
+	"sync"
+
+	"imposter/internal/domain"
+)
+
+// DefaultEventBufferSize is how many recent events are kept per player
+const DefaultEventBufferSize = 256
+
+// SequencedEvent is a game event tagged with its per-player sequence number
+type SequencedEvent struct {
+	Seq   uint64            `json:"seq"`
+	Event *domain.GameEvent `json:"event"`
+}
+
+// EventBuffer is a bounded, sequence-numbered log of the events delivered to one player.
+// Sequence numbers start at 1 and never repeat; once the buffer is full the oldest
+// events are discarded.
+type EventBuffer struct {
+	mu       sync.Mutex
+	events   []SequencedEvent
+	capacity int
+	lastSeq  uint64
+	notify   chan struct{} // Closed and replaced on every append
+}
+
+// NewEventBuffer creates an event buffer holding at most capacity events
+func NewEventBuffer(capacity int) *EventBuffer {
+	return &EventBuffer{
+		events:   make([]SequencedEvent, 0, capacity),
+		capacity: capacity,
+		notify:   make(chan struct{}),
+	}
+}
+
+// Append adds an event to the buffer and wakes any waiters
+func (b *EventBuffer) Append(event *domain.GameEvent) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastSeq++
+	if len(b.events) >= b.capacity {
+		copy(b.events, b.events[1:])
+		b.events = b.events[:len(b.events)-1]
+	}
+	b.events = append(b.events, SequencedEvent{Seq: b.lastSeq, Event: event})
+
+	close(b.notify)
+	b.notify = make(chan struct{})
+
+	return b.lastSeq
+}
+
+// Len returns the number of buffered events
+func (b *EventBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.events)
+}
+
+// LastSeq returns the sequence number of the most recent event
+func (b *EventBuffer) LastSeq() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastSeq
+}
+
+// Since returns all buffered events with a sequence number greater than seq.
+// missed is true if events after seq have already been discarded, in which case
+// the caller should resynchronize from the full game state. If there are no new
+// events, wait is closed as soon as one is appended.
+func (b *EventBuffer) Since(seq uint64) (events []SequencedEvent, missed bool, wait <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if seq > b.lastSeq || (len(b.events) > 0 && b.events[0].Seq > seq+1) {
+		missed = true
+	}
+
+	for i, e := range b.events {
+		if e.Seq > seq {
+			events = make([]SequencedEvent, len(b.events)-i)
+			copy(events, b.events[i:])
+			break
+		}
+	}
+
+	return events, missed, b.notify
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"sync"
+
+	"imposter/internal/domain"
+)
+
+// EventHandler receives events published on an EventBus
+type EventHandler func(event *domain.GameEvent)
+
+// EventBus fans out every session's game events, and room lifecycle events,
+// to subscribers such as webhooks. Handlers are called synchronously and must
+// not block.
+type EventBus interface {
+	// Subscribe registers a handler for all subsequently published events
+	Subscribe(handler EventHandler)
+
+	// Publish delivers an event to every subscriber
+	Publish(event *domain.GameEvent)
+}
+
+// LocalEventBus delivers events to subscribers in this process. It is the
+// default for single-instance deployments.
+type LocalEventBus struct {
+	mu       sync.RWMutex
+	handlers []EventHandler
+}
+
+// NewLocalEventBus creates an empty in-memory event bus
+func NewLocalEventBus() *LocalEventBus {
+	return &LocalEventBus{
+		handlers: make([]EventHandler, 0),
+	}
+}
+
+// Subscribe registers a handler for all subsequently published events
+func (b *LocalEventBus) Subscribe(handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish delivers an event to every subscriber
+func (b *LocalEventBus) Publish(event *domain.GameEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"imposter/internal/domain"
+)
+
+// maxCatalogWordLength bounds the words and categories admins add, in runes
+const maxCatalogWordLength = 32
+
+// Errors returned by WordCatalog
+var (
+	ErrWordExists   = errors.New("word already exists")
+	ErrWordNotFound = errors.New("word not found")
+	ErrWordBlocked  = errors.New("word is blocklisted")
+	ErrInvalidWord  = errors.New("words and categories must be 1 to 32 letters, spaces or hyphens")
+	ErrLastWord     = errors.New("the last enabled word cannot be disabled")
+)
+
+// WordEntry is a secret word in a catalog
+type WordEntry struct {
+	Word      string    `json:"word"`
+	Category  string    `json:"category,omitempty"`
+	Disabled  bool      `json:"disabled"`
+	Custom    bool      `json:"custom"`    // Added at runtime rather than built in
+	UpdatedAt time.Time `json:"updatedAt"` // Zero for built-in words never changed
+}
+
+// WordUpdate changes a catalog word; nil fields are left as they are
+type WordUpdate struct {
+	Category *string `json:"category"` // Empty removes the category
+	Disabled *bool   `json:"disabled"`
+}
+
+// WordStore persists the changes made to a word catalog: the words added and
+// the built-in words changed
+type WordStore interface {
+	// LoadWords returns every entry saved
+	LoadWords(ctx context.Context) ([]WordEntry, error)
+
+	// SaveWord replaces the entry for the word
+	SaveWord(ctx context.Context, entry WordEntry) error
+}
+
+// WordCatalog is a word provider admins can change while the server runs:
+// words are added, disabled and categorized without a rebuild, and new rounds
+// choose from the enabled words at once. Changes are saved to the store if it
+// has one; instances sharing a store see each other's changes on restart.
+type WordCatalog struct {
+	mu      sync.RWMutex
+	entries map[string]*WordEntry
+	order   []string          // Built-in words in their order, then added ones
+	enabled *ListWordProvider // Rebuilt on every change
+	blocked map[string]bool
+	store   WordStore
+}
+
+// NewWordCatalog returns a catalog of the built-in words, without the blocked
+// ones, which cannot be added either
+func NewWordCatalog(words []string, blocked []string) *WordCatalog {
+	c := &WordCatalog{
+		entries: make(map[string]*WordEntry, len(words)),
+		blocked: make(map[string]bool, len(blocked)),
+	}
+	for _, word := range blocked {
+		c.blocked[strings.ToLower(word)] = true
+	}
+	for _, word := range words {
+		if _, ok := c.entries[word]; !ok && !c.blocked[word] {
+			c.entries[word] = &WordEntry{Word: word}
+			c.order = append(c.order, word)
+		}
+	}
+	c.rebuild()
+	return c
+}
+
+// Load applies the changes saved to store and saves later ones there. Call it
+// once, before rounds are played.
+func (c *WordCatalog) Load(ctx context.Context, store WordStore) error {
+	saved, err := store.LoadWords(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Added words follow the built-in ones alphabetically, so seeded games
+	// pick the same words whatever order the store returns them in
+	sort.Slice(saved, func(i, j int) bool { return saved[i].Word < saved[j].Word })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range saved {
+		if c.blocked[entry.Word] {
+			continue
+		}
+		if existing, ok := c.entries[entry.Word]; ok {
+			existing.Category = entry.Category
+			existing.Disabled = entry.Disabled
+			existing.UpdatedAt = entry.UpdatedAt
+			continue
+		}
+		entry.Custom = true
+		c.entries[entry.Word] = &entry
+		c.order = append(c.order, entry.Word)
+	}
+	c.store = store
+	c.rebuild()
+	return nil
+}
+
+// List returns the catalog's words in order, only those in category unless
+// it is empty
+func (c *WordCatalog) List(category string) []WordEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entries := make([]WordEntry, 0, len(c.order))
+	for _, word := range c.order {
+		entry := c.entries[word]
+		if category == "" || entry.Category == category {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries
+}
+
+// Add adds an enabled word to the catalog
+func (c *WordCatalog) Add(ctx context.Context, word, category string) (WordEntry, error) {
+	word, ok := normalizeCatalogWord(word)
+	if !ok {
+		return WordEntry{}, ErrInvalidWord
+	}
+	category, ok = normalizeCatalogWord(category)
+	if !ok && category != "" {
+		return WordEntry{}, ErrInvalidWord
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.blocked[word] {
+		return WordEntry{}, ErrWordBlocked
+	}
+	if _, ok := c.entries[word]; ok {
+		return WordEntry{}, ErrWordExists
+	}
+
+	entry := &WordEntry{Word: word, Category: category, Custom: true, UpdatedAt: time.Now()}
+	if err := c.save(ctx, entry); err != nil {
+		return WordEntry{}, err
+	}
+	c.entries[word] = entry
+	c.order = append(c.order, word)
+	c.rebuild()
+	return *entry, nil
+}
+
+// Update changes a word in the catalog
+func (c *WordCatalog) Update(ctx context.Context, word string, update WordUpdate) (WordEntry, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	category := ""
+	if update.Category != nil {
+		var ok bool
+		category, ok = normalizeCatalogWord(*update.Category)
+		if !ok && category != "" {
+			return WordEntry{}, ErrInvalidWord
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	existing, ok := c.entries[word]
+	if !ok {
+		return WordEntry{}, ErrWordNotFound
+	}
+
+	entry := *existing
+	if update.Category != nil {
+		entry.Category = category
+	}
+	if update.Disabled != nil {
+		if *update.Disabled && !existing.Disabled && len(c.enabled.words) == 1 {
+			return WordEntry{}, ErrLastWord
+		}
+		entry.Disabled = *update.Disabled
+	}
+	entry.UpdatedAt = time.Now()
+	if err := c.save(ctx, &entry); err != nil {
+		return WordEntry{}, err
+	}
+	*existing = entry
+	c.rebuild()
+	return entry, nil
+}
+
+// NextWord returns a random enabled word not in used, or any enabled word
+// once all are used
+func (c *WordCatalog) NextWord(rng domain.Rand, used []string) (string, error) {
+	c.mu.RLock()
+	enabled := c.enabled
+	c.mu.RUnlock()
+	return enabled.NextWord(rng, used)
+}
+
+// save writes the entry to the store, if there is one. Called with c.mu held,
+// so changes are saved in the order they are made.
+func (c *WordCatalog) save(ctx context.Context, entry *WordEntry) error {
+	if c.store == nil {
+		return nil
+	}
+	return c.store.SaveWord(ctx, *entry)
+}
+
+// rebuild replaces the provider of enabled words. Called with c.mu held.
+func (c *WordCatalog) rebuild() {
+	words := make([]string, 0, len(c.order))
+	for _, word := range c.order {
+		if !c.entries[word].Disabled {
+			words = append(words, word)
+		}
+	}
+	c.enabled = NewListWordProvider(words)
+}
+
+// normalizeCatalogWord lowercases and trims s, reporting whether the result
+// is a valid word or category
+func normalizeCatalogWord(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || utf8.RuneCountInString(s) > maxCatalogWordLength {
+		return s, false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != ' ' && r != '-' {
+			return s, false
+		}
+	}
+	return s, true
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"imposter/internal/domain"
+)
+
+// minPerfectVotes is how many votes a player must have cast, all for the
+// imposter, before it looks like they know who it is rather than guess well
+const minPerfectVotes = 3
+
+// SetClientIP records the address a player connects from, so that players
+// sharing one can be spotted
+func (s *GameSession) SetClientIP(playerID, ip string) {
+	if ip == "" {
+		return
+	}
+	s.call(func() {
+		s.clientIPs[playerID] = ip
+	})
+}
+
+// detectCheats looks for suspicious play in a round that just ended, warning
+// the host privately and logging each finding. Bots are never suspected.
+func (s *GameSession) detectCheats(round *domain.Round) {
+	var suspicions []*domain.CheatSuspectedPayload
+	suspect := func(kind domain.SuspicionKind, playerIDs []string, detail string) {
+		suspicions = append(suspicions, &domain.CheatSuspectedPayload{
+			Kind:      kind,
+			Round:     round.Number,
+			PlayerIDs: playerIDs,
+			Nicknames: s.nicknames(playerIDs),
+			Detail:    detail,
+		})
+	}
+
+	// Vileks are meant to hint at the secret word, not give it away
+	secret := normalizeClue(round.SecretWord)
+	for _, sub := range round.Submissions {
+		if !round.IsImposter(sub.PlayerID) && !s.isBot(sub.PlayerID) && normalizeClue(sub.Word) == secret {
+			suspect(domain.SuspicionSecretWord, []string{sub.PlayerID}, fmt.Sprintf("submitted the secret word %q", sub.Word))
+		}
+	}
+
+	// One person playing several seats tends to repeat themselves
+	type clueKey struct{ ip, clue string }
+	sharedClues := make(map[clueKey][]string)
+	for _, sub := range round.Submissions {
+		if ip, ok := s.clientIPs[sub.PlayerID]; ok && !s.isBot(sub.PlayerID) {
+			key := clueKey{ip: ip, clue: normalizeClue(sub.Word)}
+			sharedClues[key] = append(sharedClues[key], sub.PlayerID)
+		}
+	}
+	for key, playerIDs := range sharedClues {
+		if len(playerIDs) > 1 {
+			sort.Strings(playerIDs)
+			suspect(domain.SuspicionSharedClue, playerIDs, fmt.Sprintf("submitted the same clue %q from one address", key.clue))
+		}
+	}
+
+	// Raised once, in the round a player's run of correct votes gets long
+	// enough to stand out
+	for _, vote := range round.Votes {
+		playerID := vote.VoterID
+		if round.IsImposter(playerID) || s.isBot(playerID) {
+			continue
+		}
+		if correct, ok := s.perfectVotes(playerID); ok && correct == minPerfectVotes {
+			suspect(domain.SuspicionPerfectVotes, []string{playerID}, fmt.Sprintf("voted for an imposter in all %d rounds they voted in", correct))
+		}
+	}
+
+	for _, suspicion := range suspicions {
+		s.logger.Warn("suspicious play",
+			"roomCode", s.game.ID,
+			"gameID", s.recordID,
+			"round", suspicion.Round,
+			"kind", suspicion.Kind,
+			"playerIDs", suspicion.PlayerIDs,
+			"detail", suspicion.Detail,
+		)
+		s.queueEvent(domain.NewPlayerEvent(domain.EventCheatSuspected, s.game.ID, s.game.HostID, suspicion))
+	}
+}
+
+// perfectVotes returns how many votes the player has cast as a vilek this
+// game, and whether every one of them was for an imposter
+func (s *GameSession) perfectVotes(playerID string) (int, bool) {
+	correct := 0
+	for _, round := range s.game.RoundHistory {
+		if round.IsImposter(playerID) {
+			continue
+		}
+		for _, vote := range round.Votes {
+			if vote.VoterID != playerID {
+				continue
+			}
+			if !round.IsImposter(vote.TargetID) {
+				return correct, false
+			}
+			correct++
+		}
+	}
+	return correct, true
+}
+
+// isBot returns true if the player is one of the room's computer players
+func (s *GameSession) isBot(playerID string) bool {
+	_, ok := s.bots[playerID]
+	return ok
+}
+
+// nicknames returns the players' nicknames, empty for those who have left
+func (s *GameSession) nicknames(playerIDs []string) []string {
+	nicknames := make([]string, len(playerIDs))
+	for i, playerID := range playerIDs {
+		if player, err := s.game.GetPlayer(playerID); err == nil {
+			nicknames[i] = player.Nickname
+		}
+	}
+	return nicknames
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// roomCodeTimeout bounds a single reservation in the shared store
+const roomCodeTimeout = 2 * time.Second
+
+// RoomCodes reserves room codes in a store shared by every instance, so two
+// instances never create rooms with the same code. Without one the hub only
+// checks its own rooms.
+type RoomCodes interface {
+	// Reserve reserves roomCode for this instance. It returns false if another
+	// instance holds it; reserving a code this instance holds succeeds.
+	Reserve(ctx context.Context, roomCode string) (bool, error)
+
+	// Release frees roomCode if this instance holds it
+	Release(ctx context.Context, roomCode string) error
+}
+
+// SetRoomCodes makes the hub reserve the codes of rooms it creates, restores
+// or imports in codes, and release them when the rooms are deleted. Call it
+// before creating or restoring sessions.
+func (h *GameHub) SetRoomCodes(codes RoomCodes) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.codes = codes
+}
+
+// reserveRoomCode reserves the room's code, returning false if another
+// instance holds it (caller must hold mu)
+func (h *GameHub) reserveRoomCode(roomCode string) (bool, error) {
+	if h.codes == nil {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), roomCodeTimeout)
+	defer cancel()
+	return h.codes.Reserve(ctx, roomCode)
+}
+
+// releaseRoomCode frees the room's code once its session is gone
+func (h *GameHub) releaseRoomCode(roomCode string) {
+	h.mu.RLock()
+	codes := h.codes
+	h.mu.RUnlock()
+	if codes == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), roomCodeTimeout)
+	defer cancel()
+	if err := codes.Release(ctx, roomCode); err != nil {
+		h.logger.Warn("failed to release room code", "roomCode", roomCode, "error", err)
+	}
+}
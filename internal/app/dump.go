@@ -0,0 +1,198 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// redactedWord replaces secret words in a redacted dump
+const redactedWord = "[redacted]"
+
+// SessionDump is the raw state of a session, for debugging a stuck room.
+// Unlike SessionDetails it includes the actor's own bookkeeping. Game is the
+// full game struct, secret words included unless redacted.
+type SessionDump struct {
+	RoomCode string          `json:"roomCode"`
+	DumpedAt time.Time       `json:"dumpedAt"`
+	Redacted bool            `json:"redacted"`
+	Game     json.RawMessage `json:"game"`
+	RecordID string          `json:"recordId,omitempty"`
+	Closed   bool            `json:"closed"`
+
+	Timers  TimerDump    `json:"timers"`
+	Clients []ClientDump `json:"clients"`
+	Queues  QueueDump    `json:"queues"`
+	Quotas  RoomQuotas   `json:"quotas"`
+	Metrics RoomMetrics  `json:"metrics"`
+	Buffers []BufferDump `json:"buffers"`
+	Hooks   int          `json:"hooks"`
+	Tokens  int          `json:"tokens"` // REST bearer tokens issued; the tokens themselves are never dumped
+	Bots    []string     `json:"bots"`
+}
+
+// TimerDump describes the session's timers
+type TimerDump struct {
+	PhaseTimerSet   bool                 `json:"phaseTimerSet"`
+	TimerGeneration uint64               `json:"timerGeneration"`
+	PhaseRemaining  time.Duration        `json:"phaseRemainingNs"`
+	Deadlines       map[string]time.Time `json:"deadlines"` // When each running timer fires, by kind
+	VotingStartedAt time.Time            `json:"votingStartedAt,omitempty"`
+
+	LastActivity   time.Time `json:"lastActivity"`
+	IdleFor        string    `json:"idleFor"`
+	ExpiryWarned   bool      `json:"expiryWarned"`
+	DisconnectedAt time.Time `json:"disconnectedAt,omitempty"`
+}
+
+// ClientDump is one entry in the session's client registry
+type ClientDump struct {
+	ID        string `json:"id"` // Player ID, or connection ID for spectators
+	Type      string `json:"type"`
+	Spectator bool   `json:"spectator,omitempty"`
+	Bot       bool   `json:"bot,omitempty"`
+	IP        string `json:"ip,omitempty"`
+}
+
+// QueueDump describes the session's event queue
+type QueueDump struct {
+	Pending  int             `json:"pending"`
+	Capacity int             `json:"capacity"`
+	Stats    EventQueueStats `json:"stats"`
+
+	// Public events kept for the replay
+	ReplayEvents int `json:"replayEvents"`
+}
+
+// BufferDump describes a long-polling player's event buffer
+type BufferDump struct {
+	PlayerID string `json:"playerId"`
+	Events   int    `json:"events"`
+	LastSeq  uint64 `json:"lastSeq"`
+}
+
+// Dump returns the session's raw state. With redact set, secret words are
+// replaced in the game and its rounds.
+func (s *GameSession) Dump(redact bool) (*SessionDump, error) {
+	var (
+		dump *SessionDump
+		err  error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		var game []byte
+		if game, err = json.Marshal(s.game); err != nil {
+			return
+		}
+		if redact {
+			if game, err = redactSecretWords(game); err != nil {
+				return
+			}
+		}
+
+		dump = &SessionDump{
+			RoomCode: s.game.ID,
+			DumpedAt: time.Now(),
+			Redacted: redact,
+			Game:     game,
+			RecordID: s.recordID,
+			Closed:   s.closed,
+			Timers: TimerDump{
+				PhaseTimerSet:   s.timers.isRunning(timerPhase),
+				TimerGeneration: s.timers.generation,
+				PhaseRemaining:  s.timerRemaining(),
+				Deadlines:       s.timers.deadlines(),
+				VotingStartedAt: s.votingStartedAt,
+				ExpiryWarned:    s.expiryWarned.Load(),
+			},
+			Clients: []ClientDump{},
+			Queues: QueueDump{
+				Pending:      s.events.len(),
+				Capacity:     s.events.capacity,
+				Stats:        s.events.stats(),
+				ReplayEvents: len(s.replayEvents),
+			},
+			Quotas:  s.quotas,
+			Metrics: s.Metrics(),
+			Buffers: []BufferDump{},
+			Hooks:   len(s.hooks),
+			Tokens:  len(s.tokens),
+			Bots:    []string{},
+		}
+		if last := s.lastActivity.Load(); last > 0 {
+			dump.Timers.LastActivity = time.Unix(0, last)
+			dump.Timers.IdleFor = time.Since(dump.Timers.LastActivity).Round(time.Second).String()
+		}
+		if at := s.disconnectedAt.Load(); at > 0 {
+			dump.Timers.DisconnectedAt = time.Unix(0, at)
+		}
+
+		for playerID, client := range s.clients {
+			_, bot := s.bots[playerID]
+			dump.Clients = append(dump.Clients, ClientDump{
+				ID:   playerID,
+				Type: fmt.Sprintf("%T", client),
+				Bot:  bot,
+				IP:   s.clientIPs[playerID],
+			})
+		}
+		for connID, client := range s.spectators {
+			dump.Clients = append(dump.Clients, ClientDump{
+				ID:        connID,
+				Type:      fmt.Sprintf("%T", client),
+				Spectator: true,
+			})
+		}
+		for playerID, buffer := range s.buffers {
+			dump.Buffers = append(dump.Buffers, BufferDump{
+				PlayerID: playerID,
+				Events:   buffer.Len(),
+				LastSeq:  buffer.LastSeq(),
+			})
+		}
+		for playerID := range s.bots {
+			dump.Bots = append(dump.Bots, playerID)
+		}
+	})
+	if dump == nil {
+		return nil, err
+	}
+
+	sort.Slice(dump.Clients, func(i, j int) bool { return dump.Clients[i].ID < dump.Clients[j].ID })
+	sort.Slice(dump.Buffers, func(i, j int) bool { return dump.Buffers[i].PlayerID < dump.Buffers[j].PlayerID })
+	sort.Strings(dump.Bots)
+	return dump, nil
+}
+
+// redactSecretWords replaces every secretWord field in an encoded game
+func redactSecretWords(data []byte) ([]byte, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	var redact func(v interface{})
+	redact = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, field := range v {
+				if key == "secretWord" {
+					if word, ok := field.(string); ok && word != "" {
+						v[key] = redactedWord
+					}
+					continue
+				}
+				redact(field)
+			}
+		case []interface{}:
+			for _, item := range v {
+				redact(item)
+			}
+		}
+	}
+	redact(value)
+
+	return json.Marshal(value)
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"sync"
+
+	"imposter/internal/domain"
+)
+
+// Frame is one recipient's copy of an event fanned out to a room. The copies
+// share a cache so the event is encoded once per wire format, and each
+// connection adds its recipient's sequence number to the shared bytes.
+type Frame struct {
+	// Event is shared by every copy and carries no sequence number
+	Event *domain.GameEvent
+
+	// Seq is the recipient's sequence number, 0 for spectators
+	Seq uint64
+
+	cache *frameCache
+}
+
+// frameCache holds a frame's encodings, keyed by the connection's codec
+type frameCache struct {
+	mu      sync.Mutex
+	encoded map[interface{}][]byte
+}
+
+// NewFrame creates a frame for fanning out event
+func NewFrame(event *domain.GameEvent) *Frame {
+	return &Frame{
+		Event: event,
+		cache: &frameCache{encoded: make(map[interface{}][]byte)},
+	}
+}
+
+// WithSeq returns a copy of the frame for a recipient with the given sequence number
+func (f *Frame) WithSeq(seq uint64) *Frame {
+	sequenced := *f
+	sequenced.Seq = seq
+	return &sequenced
+}
+
+// Encoded returns the event encoded by encode, which only runs the first time
+// a key is seen across all copies of the frame. The returned bytes are shared
+// and must not be modified.
+func (f *Frame) Encoded(key interface{}, encode func(message interface{}) ([]byte, error)) ([]byte, error) {
+	f.cache.mu.Lock()
+	defer f.cache.mu.Unlock()
+
+	if data, ok := f.cache.encoded[key]; ok {
+		return data, nil
+	}
+
+	data, err := encode(f.Event)
+	if err != nil {
+		return nil, err
+	}
+	f.cache.encoded[key] = data
+	return data, nil
+}
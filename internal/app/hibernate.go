@@ -0,0 +1,268 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// hibernationTimeout bounds saving or loading a single room
+const hibernationTimeout = 5 * time.Second
+
+// HibernationStore keeps the state of rooms evicted from memory while nobody
+// is connected, until someone comes back
+type HibernationStore interface {
+	SaveRoom(ctx context.Context, roomCode string, data []byte) error
+
+	// LoadRoom returns nil if the room is not stored
+	LoadRoom(ctx context.Context, roomCode string) ([]byte, error)
+
+	DeleteRoom(ctx context.Context, roomCode string) error
+
+	// ListRooms returns when each stored room was saved
+	ListRooms(ctx context.Context) (map[string]time.Time, error)
+}
+
+// HibernationPolicy controls when rooms are evicted to a HibernationStore
+type HibernationPolicy struct {
+	// After is how long a room with players may go without a connection
+	// before it is hibernated
+	After time.Duration
+
+	// TTL is how long a room stays hibernated before it is deleted. It
+	// replaces the idle timeout, which only applies to rooms in memory.
+	TTL time.Duration
+}
+
+// EnableHibernation evicts rooms to store as policy says, and wakes them when
+// they are next looked up. It returns how many rooms store already holds,
+// which become available again. Call it after restoring a snapshot and before
+// the hub starts serving players.
+func (h *GameHub) EnableHibernation(ctx context.Context, store HibernationStore, policy HibernationPolicy) (int, error) {
+	rooms, err := store.ListRooms(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	h.hibernateMu.Lock()
+	defer h.hibernateMu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hibernation = store
+	h.hibernationPolicy = policy
+
+	count := 0
+	for roomCode, savedAt := range rooms {
+		// Restored from a snapshot saved before the room was hibernated
+		if h.lookupSession(roomCode) != nil {
+			if err := store.DeleteRoom(ctx, roomCode); err != nil {
+				h.logger.Warn("failed to delete restored room from the store", "roomCode", roomCode, "error", err)
+			}
+			continue
+		}
+
+		// Another instance may have taken the code over while this one was down
+		if reserved, err := h.reserveRoomCode(roomCode); err != nil {
+			h.logger.Warn("failed to reserve hibernated room code", "roomCode", roomCode, "error", err)
+		} else if !reserved {
+			h.logger.Info("hibernated room code is held by another instance, dropping the room", "roomCode", roomCode)
+			if err := store.DeleteRoom(ctx, roomCode); err != nil {
+				h.logger.Warn("failed to delete hibernated room", "roomCode", roomCode, "error", err)
+			}
+			continue
+		}
+
+		h.hibernated.Store(roomCode, savedAt)
+		count++
+	}
+	return count, nil
+}
+
+// HibernatedCount returns how many rooms are hibernated
+func (h *GameHub) HibernatedCount() int {
+	count := 0
+	h.hibernated.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// isHibernated returns true if roomCode belongs to a hibernated room
+func (h *GameHub) isHibernated(roomCode string) bool {
+	_, ok := h.hibernated.Load(roomCode)
+	return ok
+}
+
+// hibernate saves an unused session to store and evicts it. The session stays
+// if a client connected in the meantime or it cannot be saved.
+func (h *GameHub) hibernate(session *GameSession, store HibernationStore) {
+	h.hibernateMu.Lock()
+	defer h.hibernateMu.Unlock()
+
+	roomCode := session.GetRoomCode()
+
+	// Marked hibernated before it is removed, so lookups from now on wait to
+	// wake it from the store and its code is not handed out meanwhile
+	h.hibernated.Store(roomCode, time.Now())
+	if !h.removeSession(session) {
+		h.hibernated.Delete(roomCode)
+		return
+	}
+
+	data, err := session.marshalSnapshot()
+	if err == nil {
+		if _, idle := session.DisconnectedSince(); !idle {
+			h.restoreHibernating(session)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), hibernationTimeout)
+		err = store.SaveRoom(ctx, roomCode, data)
+		cancel()
+	}
+	if err != nil {
+		h.logger.Error("failed to hibernate room", "roomCode", roomCode, "error", err)
+		h.restoreHibernating(session)
+		return
+	}
+
+	h.hibernated.Store(roomCode, time.Now())
+
+	// The game goes on once woken, so it is not recorded as ended
+	session.setRecorder(nil)
+	session.setHooks(nil)
+	session.Close()
+	h.logger.Info("room hibernated", "roomCode", roomCode)
+}
+
+// restoreHibernating puts back a session hibernate had removed, and clears
+// its hibernated mark
+func (h *GameHub) restoreHibernating(session *GameSession) {
+	if h.addSession(session) {
+		h.sessionCount.Add(1)
+	}
+	h.hibernated.Delete(session.GetRoomCode())
+}
+
+// wake restores a hibernated room into memory, returning ErrGameNotFound if
+// roomCode is not hibernated
+func (h *GameHub) wake(roomCode string) (*GameSession, error) {
+	if !h.isHibernated(roomCode) {
+		return nil, domain.ErrGameNotFound
+	}
+
+	h.hibernateMu.Lock()
+	defer h.hibernateMu.Unlock()
+
+	// Woken by another lookup while this one waited
+	if session := h.lookupSession(roomCode); session != nil {
+		return session, nil
+	}
+	if !h.isHibernated(roomCode) {
+		return nil, domain.ErrGameNotFound
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), hibernationTimeout)
+	defer cancel()
+
+	data, err := h.hibernation.LoadRoom(ctx, roomCode)
+	if err != nil {
+		h.logger.Error("failed to load hibernated room", "roomCode", roomCode, "error", err)
+		return nil, domain.ErrGameNotFound
+	}
+	if data == nil {
+		h.hibernated.Delete(roomCode)
+		return nil, domain.ErrGameNotFound
+	}
+
+	session, err := restoreSession(data, h.bus, h.broadcaster, h.signer, h.quotas, h.logger)
+	if err != nil {
+		h.logger.Error("failed to restore hibernated room", "roomCode", roomCode, "error", err)
+		return nil, domain.ErrGameNotFound
+	}
+
+	session.setRecorder(h.recorder)
+	session.setErrorReporter(h.reporter)
+	session.setHooks(h.hooks)
+	session.setPresence(h.presence)
+	session.setWords(h.wordSources)
+	session.setProfanityFilter(h.profanity)
+	session.setReplays(h.replays)
+	if !h.addSession(session) {
+		session.Close()
+		return nil, domain.ErrGameNotFound
+	}
+	h.sessionCount.Add(1)
+	h.hibernated.Delete(roomCode)
+
+	if err := h.hibernation.DeleteRoom(ctx, roomCode); err != nil {
+		h.logger.Warn("failed to delete woken room from the store", "roomCode", roomCode, "error", err)
+	}
+
+	h.logger.Info("room woken", "roomCode", roomCode)
+	return session, nil
+}
+
+// deleteHibernated deletes a hibernated room from the store without waking
+// it. It returns false if the room is not hibernated, including when it was
+// woken meanwhile, or cannot be deleted.
+func (h *GameHub) deleteHibernated(roomCode string) bool {
+	if !h.isHibernated(roomCode) {
+		return false
+	}
+
+	h.hibernateMu.Lock()
+	defer h.hibernateMu.Unlock()
+
+	if !h.isHibernated(roomCode) {
+		return false
+	}
+
+	h.mu.RLock()
+	store := h.hibernation
+	h.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), hibernationTimeout)
+	defer cancel()
+	if err := store.DeleteRoom(ctx, roomCode); err != nil {
+		h.logger.Warn("failed to delete hibernated room", "roomCode", roomCode, "error", err)
+		return false
+	}
+
+	h.hibernated.Delete(roomCode)
+	return true
+}
+
+// expireHibernated deletes rooms hibernated for longer than the policy allows
+func (h *GameHub) expireHibernated(store HibernationStore, ttl time.Duration) {
+	h.hibernateMu.Lock()
+	defer h.hibernateMu.Unlock()
+
+	now := time.Now()
+	h.hibernated.Range(func(key, value interface{}) bool {
+		roomCode := key.(string)
+		if now.Sub(value.(time.Time)) <= ttl {
+			return true
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), hibernationTimeout)
+		err := store.DeleteRoom(ctx, roomCode)
+		cancel()
+		if err != nil {
+			h.logger.Warn("failed to delete expired hibernated room", "roomCode", roomCode, "error", err)
+			return true
+		}
+
+		h.hibernated.Delete(roomCode)
+		h.releaseRoomCode(roomCode)
+		h.logger.Info("hibernated room expired", "roomCode", roomCode)
+		h.Bus().Publish(domain.NewEvent(domain.EventGameDeleted, roomCode, nil))
+		return true
+	})
+}
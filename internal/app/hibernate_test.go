@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// memoryHibernation is a HibernationStore in memory that counts the rooms
+// loaded. If saving is set, SaveRoom signals it and waits for resume.
+type memoryHibernation struct {
+	mu    sync.Mutex
+	rooms map[string][]byte
+	loads int
+
+	saving, resume chan struct{}
+}
+
+func (m *memoryHibernation) SaveRoom(_ context.Context, roomCode string, data []byte) error {
+	if m.saving != nil {
+		m.saving <- struct{}{}
+		<-m.resume
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rooms[roomCode] = data
+	return nil
+}
+
+func (m *memoryHibernation) LoadRoom(_ context.Context, roomCode string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads++
+	return m.rooms[roomCode], nil
+}
+
+func (m *memoryHibernation) DeleteRoom(_ context.Context, roomCode string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rooms, roomCode)
+	return nil
+}
+
+func (m *memoryHibernation) ListRooms(context.Context) (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rooms := make(map[string]time.Time, len(m.rooms))
+	for roomCode := range m.rooms {
+		rooms[roomCode] = time.Now()
+	}
+	return rooms, nil
+}
+
+func TestDeleteSessionLeavesHibernatedRoomAsleep(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := NewGameHub(NewTokenSigner(nil, 0), NewLocalBroadcaster(), logger)
+	defer hub.Close()
+
+	store := &memoryHibernation{rooms: map[string][]byte{"SLEEPY": []byte("snapshot")}}
+	policy := HibernationPolicy{After: time.Hour, TTL: time.Hour}
+	if _, err := hub.EnableHibernation(context.Background(), store, policy); err != nil {
+		t.Fatalf("EnableHibernation: %v", err)
+	}
+
+	deleted := make(chan string, 1)
+	hub.Bus().Subscribe(func(event *domain.GameEvent) {
+		if event.Type == domain.EventGameDeleted {
+			deleted <- event.GameID
+		}
+	})
+
+	if !hub.DeleteSession("SLEEPY") {
+		t.Fatalf("DeleteSession of a hibernated room returned false")
+	}
+	if store.loads != 0 {
+		t.Errorf("the room was loaded %d times to delete it", store.loads)
+	}
+	if _, ok := store.rooms["SLEEPY"]; ok {
+		t.Errorf("the room is still in the store")
+	}
+	if hub.HibernatedCount() != 0 {
+		t.Errorf("the room is still hibernated")
+	}
+	if _, err := hub.GetSession("SLEEPY"); !errors.Is(err, domain.ErrGameNotFound) {
+		t.Errorf("GetSession after delete: err = %v, want ErrGameNotFound", err)
+	}
+	select {
+	case roomCode := <-deleted:
+		if roomCode != "SLEEPY" {
+			t.Errorf("GAME_DELETED for %s, want SLEEPY", roomCode)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no GAME_DELETED event")
+	}
+
+	if hub.DeleteSession("SLEEPY") {
+		t.Errorf("DeleteSession of a deleted room returned true")
+	}
+}
+
+func TestHibernatingRoomStaysTaken(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := NewGameHub(NewTokenSigner(nil, 0), NewLocalBroadcaster(), logger)
+	defer hub.Close()
+
+	store := &memoryHibernation{
+		rooms:  make(map[string][]byte),
+		saving: make(chan struct{}),
+		resume: make(chan struct{}),
+	}
+	policy := HibernationPolicy{After: time.Hour, TTL: time.Hour}
+	if _, err := hub.EnableHibernation(context.Background(), store, policy); err != nil {
+		t.Fatalf("EnableHibernation: %v", err)
+	}
+
+	session, err := hub.CreateGame(RoomOptions{Code: "SLEEPY"})
+	if err != nil {
+		t.Fatalf("CreateGame: %v", err)
+	}
+	session.markDisconnected()
+
+	hibernated := make(chan struct{})
+	go func() {
+		hub.hibernate(session, store)
+		close(hibernated)
+	}()
+	<-store.saving
+
+	// While the room is being saved its code is neither free nor missing
+	if _, err := hub.CreateGame(RoomOptions{Code: "SLEEPY"}); !errors.Is(err, domain.ErrRoomCodeTaken) {
+		t.Errorf("CreateGame while hibernating: err = %v, want ErrRoomCodeTaken", err)
+	}
+	woken := make(chan error, 1)
+	go func() {
+		_, err := hub.GetSession("SLEEPY")
+		woken <- err
+	}()
+
+	close(store.resume)
+	<-hibernated
+	select {
+	case err := <-woken:
+		if err != nil {
+			t.Errorf("GetSession while hibernating: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("GetSession did not return")
+	}
+}
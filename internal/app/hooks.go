@@ -0,0 +1,83 @@
+package app
+
+import (
+	"time"
+)
+
+// GameHooks lets deployments react to games as they are played, e.g. to
+// announce rounds in a chat or keep custom scores, without changing the
+// session. Sessions call hooks on their actor while handling the action that
+// triggered them, so implementations must return quickly, hand slow work to
+// another goroutine, and neither call back into the session nor modify what
+// they are passed. Embed NopGameHooks to implement only some of them.
+type GameHooks interface {
+	OnPlayerJoined(info *PlayerJoinedInfo)
+	OnRoundStarted(info *RoundStartedInfo)
+
+	// OnRoundEnded is called once the round's results are in
+	OnRoundEnded(record *RoundRecord)
+
+	// OnGameEnded is called when the session closes after at least one round
+	OnGameEnded(record *GameRecord)
+}
+
+// PlayerJoinedInfo describes a player who joined a room
+type PlayerJoinedInfo struct {
+	GameID      string // Unique per session; room codes are reused
+	RoomCode    string
+	Player      RecordedPlayer
+	PlayerCount int
+	JoinedAt    time.Time
+}
+
+// RoundStartedInfo describes a round that just started. The secret word and
+// imposters are only revealed to players when the round ends.
+type RoundStartedInfo struct {
+	GameID      string
+	RoomCode    string
+	Number      int
+	SecretWord  string
+	ImposterIDs []string
+	Players     []RecordedPlayer
+	StartedAt   time.Time
+}
+
+// NopGameHooks implements GameHooks by doing nothing
+type NopGameHooks struct{}
+
+func (NopGameHooks) OnPlayerJoined(*PlayerJoinedInfo) {}
+func (NopGameHooks) OnRoundStarted(*RoundStartedInfo) {}
+func (NopGameHooks) OnRoundEnded(*RoundRecord)        {}
+func (NopGameHooks) OnGameEnded(*GameRecord)          {}
+
+// AddHooks registers hooks for sessions created from now on, after any
+// registered before
+func (h *GameHub) AddHooks(hooks GameHooks) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks[:len(h.hooks):len(h.hooks)], hooks)
+}
+
+// setHooks sets the hooks the session reports its lifecycle to
+func (s *GameSession) setHooks(hooks []GameHooks) {
+	s.call(func() {
+		s.hooks = hooks
+	})
+}
+
+// runHooks calls fn with each of the session's hooks, so that one that panics
+// neither takes down the actor nor skips the others
+func (s *GameSession) runHooks(fn func(hooks GameHooks)) {
+	for _, hooks := range s.hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err := newPanicError(r)
+					s.logger.Error("game hook panicked", "roomCode", s.game.ID, "panic", r, "stack", string(err.Stack))
+					s.reportError(err, map[string]string{"source": "hooks"})
+				}
+			}()
+			fn(hooks)
+		}()
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"imposter/internal/domain"
@@ -14,128 +17,709 @@ const (
 	// DefaultRoomCodeLength is the default length for room codes
 	DefaultRoomCodeLength = 6
 
-	// StaleGameTimeout is how long before an inactive game is cleaned up
-	StaleGameTimeout = 2 * time.Hour
+	// Bounds for generated and custom room code lengths
+	MinRoomCodeLength = 4
+	MaxRoomCodeLength = 10
+
+	// hubShardCount is how many independently locked buckets hold the sessions
+	hubShardCount = 32
 )
 
+// CleanupPolicy controls when the hub reaps abandoned rooms
+type CleanupPolicy struct {
+	// Interval is how often rooms are checked
+	Interval time.Duration
+
+	// StaleGameTimeout is how long a room without players is kept after creation
+	StaleGameTimeout time.Duration
+
+	// IdleTimeout is how long a room with players is kept without any activity;
+	// zero keeps such rooms until they empty
+	IdleTimeout time.Duration
+
+	// IdleWarning is how long before reaping an idle room its players are warned
+	IdleWarning time.Duration
+}
+
+// DefaultCleanupPolicy returns the default cleanup policy
+func DefaultCleanupPolicy() CleanupPolicy {
+	return CleanupPolicy{
+		Interval:         time.Minute,
+		StaleGameTimeout: 2 * time.Hour,
+		IdleTimeout:      6 * time.Hour,
+		IdleWarning:      10 * time.Minute,
+	}
+}
+
 // RoomCodeChars are characters used for room codes (no ambiguous chars)
 const RoomCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
 
-// GameHub manages all active game sessions
+// defaultReservedRoomCodes may never be requested as custom codes
+var defaultReservedRoomCodes = []string{"ADMIN", "API", "AUTH", "DEBUG", "HEALTH", "JOIN", "ROOT", "STATIC", "SYSTEM", "TEST"}
+
+// RoomOptions are the creator's choices for a new room
+type RoomOptions struct {
+	Password   string            // Optional; players must supply it to join
+	Visibility domain.Visibility // Defaults to unlisted
+	Code       string            // Optional custom code, e.g. "PIZZA"; letters and digits only
+	CodeLength int               // Length of the generated code; 0 uses the hub default
+	WordSource string            // Named word provider; empty uses the hub default
+	Language   string            // Language the players speak, e.g. "en", matched by quick join
+	Tags       []string          // Lowercase labels the room browser filters on, e.g. "casual"
+	MaxPlayers int               // Room size; 0 uses the default
+	VoteQuorum domain.VoteQuorum // Whose votes a round waits for and counts; empty uses the hub default
+	TieRule    domain.TieRule    // How a tied vote is settled; empty uses the hub default
+	Seed       int64             // Seeds the room's rounds for tests, replays and tournaments; 0 is random
+
+	// How many imposters each round deals; nil uses the hub default
+	Imposters *domain.ImposterCount
+
+	// A practice room is unlisted, filled with bots when its one player joins
+	// and started without the MinPlayers check. PracticeRole, if set, is the
+	// player's role every round.
+	Practice     bool
+	PracticeRole domain.Role
+
+	// A family-friendly room deals from the family word list only and checks
+	// nicknames and clues strictly for profanity, for classrooms and children
+	FamilyFriendly bool
+
+	// FillBots fills the lobby with bots up to the minimum player count when
+	// its first player joins. A development aid; a restored room does not.
+	FillBots bool
+}
+
+// GameHub manages all active game sessions. Sessions are spread across
+// shards by room code, so lookups in different rooms do not contend.
 type GameHub struct {
-	sessions       map[string]*GameSession
+	shards       [hubShardCount]sessionShard
+	sessionCount atomic.Int64
+
+	// Guards the settings below
 	mu             sync.RWMutex
 	roomCodeLength int
+	reservedCodes  map[string]bool
+	maxSessions    int // 0 is unlimited
+	quotas         RoomQuotas
+	voteQuorum     domain.VoteQuorum    // For rooms that do not choose one
+	tieRule        domain.TieRule       // For rooms that do not choose one
+	imposters      domain.ImposterCount // For rooms that do not choose how many
+	thresholds     []int                // For auto rooms that do not give their own
+	emoji          domain.EmojiPolicy
+	bus            EventBus
+	broadcaster    Broadcaster
+	presence       Presence
+	codes          RoomCodes     // nil checks only local rooms
+	directory      RoomDirectory // nil unless clustered
+	nodeSnapshots  NodeSnapshots
+	recorder       GameRecorder
+	reporter       ErrorReporter
+	replays        ReplayStore
+	replayTTL      time.Duration
+	hooks          []GameHooks
+	wordSources    *WordSources
+	profanity      *ProfanityFilter
+	signer         *TokenSigner
 	logger         *slog.Logger
-	done           chan struct{}
+	cleanup        CleanupPolicy
+	cleanupTicker  *time.Ticker
+
+	// Serializes snapshot saves with Close, so a save never sees closed sessions
+	snapshotMu sync.Mutex
+
+	// Serializes quick-join searches, so concurrent ones fill the same room
+	quickJoinMu sync.Mutex
+
+	// Rooms evicted from memory while unused (store is nil unless enabled).
+	// hibernateMu serializes hibernating and waking rooms.
+	hibernation       HibernationStore
+	hibernationPolicy HibernationPolicy
+	hibernated        sync.Map // roomCode -> time.Time hibernated
+	hibernateMu       sync.Mutex
+
+	// Sampled activity for the admin dashboard
+	stats hubStats
+
+	done chan struct{}
 }
 
-// NewGameHub creates a new game hub
-func NewGameHub(logger *slog.Logger) *GameHub {
+// sessionShard is one bucket of the hub's sessions
+type sessionShard struct {
+	mu       sync.RWMutex
+	sessions map[string]*GameSession
+}
+
+// NewGameHub creates a new game hub. Sessions deliver their events to clients
+// through broadcaster.
+func NewGameHub(signer *TokenSigner, broadcaster Broadcaster, logger *slog.Logger) *GameHub {
 	hub := &GameHub{
-		sessions:       make(map[string]*GameSession),
 		roomCodeLength: DefaultRoomCodeLength,
+		reservedCodes:  make(map[string]bool),
+		bus:            NewLocalEventBus(),
+		broadcaster:    broadcaster,
+		presence:       NewLocalPresence(),
+		signer:         signer,
 		logger:         logger,
+		quotas:         DefaultRoomQuotas(),
+		wordSources:    DefaultWordSources(),
+		profanity:      DefaultProfanityFilter(),
+		cleanup:        DefaultCleanupPolicy(),
 		done:           make(chan struct{}),
 	}
+	hub.cleanupTicker = time.NewTicker(hub.cleanup.Interval)
+
+	for i := range hub.shards {
+		hub.shards[i].sessions = make(map[string]*GameSession)
+	}
+
+	for _, code := range defaultReservedRoomCodes {
+		hub.reservedCodes[code] = true
+	}
 
 	// Start cleanup goroutine
 	go hub.cleanupLoop()
+	go hub.statsLoop()
 
 	return hub
 }
 
-// CreateGame creates a new game and returns its session
-func (h *GameHub) CreateGame() (*GameSession, error) {
+// ConfigureRoomCodes sets the default generated code length and adds to the
+// codes that may not be requested. Lengths outside the allowed bounds are ignored.
+func (h *GameHub) ConfigureRoomCodes(length int, reserved []string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	// Generate unique room code
-	var roomCode string
-	for attempts := 0; attempts < 10; attempts++ {
-		roomCode = h.generateRoomCode()
-		if _, exists := h.sessions[roomCode]; !exists {
-			break
-		}
+	if length >= MinRoomCodeLength && length <= MaxRoomCodeLength {
+		h.roomCodeLength = length
+	} else {
+		h.logger.Warn("room code length out of range, using default", "length", length, "default", h.roomCodeLength)
 	}
 
-	// Check if we found a unique code
-	if _, exists := h.sessions[roomCode]; exists {
-		return nil, fmt.Errorf("failed to generate unique room code")
+	for _, code := range reserved {
+		h.reservedCodes[strings.ToUpper(code)] = true
 	}
+}
 
-	game := domain.NewGame(roomCode)
-	session := NewGameSession(game, h.logger)
-	h.sessions[roomCode] = session
+// SetMaxSessions limits how many rooms may be open at once; 0 removes the
+// limit. Restored and imported rooms are admitted regardless.
+func (h *GameHub) SetMaxSessions(max int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxSessions = max
+}
 
-	h.logger.Info("game created", "roomCode", roomCode)
+// SetRoomQuotas sets the quotas of rooms created from now on
+func (h *GameHub) SetRoomQuotas(quotas RoomQuotas) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.quotas = quotas
+}
 
-	return session, nil
+// SetVoteQuorum sets the quorum rule of rooms created from now on that do not
+// choose one
+func (h *GameHub) SetVoteQuorum(quorum domain.VoteQuorum) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.voteQuorum = quorum
 }
 
-// GetSession returns a game session by room code
-func (h *GameHub) GetSession(roomCode string) (*GameSession, error) {
+// SetTieRule sets the tie rule of rooms created from now on that do not
+// choose one
+func (h *GameHub) SetTieRule(rule domain.TieRule) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.tieRule = rule
+}
+
+// SetImposters sets how many imposters rounds deal in rooms created from now
+// on that do not choose, and the thresholds auto mode scales by in rooms that
+// do not give their own
+func (h *GameHub) SetImposters(imposters domain.ImposterCount, thresholds []int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.imposters = imposters
+	h.thresholds = thresholds
+}
+
+// SetEmojiPolicy sets what happens to emoji in the nicknames and clues of
+// rooms created from now on
+func (h *GameHub) SetEmojiPolicy(policy domain.EmojiPolicy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.emoji = policy
+}
+
+// EmojiPolicy returns what happens to emoji in new rooms
+func (h *GameHub) EmojiPolicy() domain.EmojiPolicy {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	if h.emoji == "" {
+		return domain.EmojiAllow
+	}
+	return h.emoji
+}
+
+// ConfigureCleanup replaces the cleanup policy. A non-positive interval keeps
+// the current one.
+func (h *GameHub) ConfigureCleanup(policy CleanupPolicy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	session, ok := h.sessions[roomCode]
-	if !ok {
-		return nil, domain.ErrGameNotFound
+	if policy.Interval <= 0 {
+		policy.Interval = h.cleanup.Interval
 	}
+	h.cleanup = policy
+	h.cleanupTicker.Reset(policy.Interval)
+}
 
-	return session, nil
+// SetRecorder persists the finished rounds and games of sessions created from now on
+func (h *GameHub) SetRecorder(recorder GameRecorder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recorder = recorder
+}
+
+// SetEventBus replaces the bus carrying every game event. Call it before
+// creating sessions or subscribing to Bus.
+func (h *GameHub) SetEventBus(bus EventBus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bus = bus
 }
 
-// DeleteSession removes a game session
-func (h *GameHub) DeleteSession(roomCode string) {
+// SetPresence sets where sessions created from now on record their connected players
+func (h *GameHub) SetPresence(presence Presence) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.presence = presence
+}
+
+// SetWordSources sets the word providers sessions created from now on may use
+func (h *GameHub) SetWordSources(sources *WordSources) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.wordSources = sources
+}
+
+// SetProfanityFilter sets the filter sessions created from now on check
+// nicknames and clues with
+func (h *GameHub) SetProfanityFilter(filter *ProfanityFilter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.profanity = filter
+}
+
+// ReloadWords reloads the word lists that can change while the server runs,
+// returning the names of the sources now offered
+func (h *GameHub) ReloadWords() ([]string, error) {
+	h.mu.RLock()
+	sources := h.wordSources
+	h.mu.RUnlock()
+	return sources.Reload()
+}
+
+// CreateGame creates a new game and returns its session
+func (h *GameHub) CreateGame(opts RoomOptions) (*GameSession, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.reserveSession() {
+		h.logger.Warn("room limit reached, rejecting new room", "limit", h.maxSessions)
+		return nil, domain.ErrServerAtCapacity
+	}
+
+	session, err := h.newSession(opts)
+	if err != nil {
+		h.sessionCount.Add(-1)
+		return nil, err
+	}
+
+	roomCode := session.GetRoomCode()
+	h.logger.Info("game created", "roomCode", roomCode, "locked", session.IsLocked())
+	h.bus.Publish(domain.NewEvent(domain.EventGameCreated, roomCode, nil))
+
+	return session, nil
+}
+
+// newSession creates a session under a free room code and adds it to its
+// shard (caller must hold mu)
+func (h *GameHub) newSession(opts RoomOptions) (*GameSession, error) {
+	if opts.WordSource != "" && !h.wordSources.Has(opts.WordSource) {
+		return nil, domain.ErrUnknownWordSource
+	}
+	if opts.FamilyFriendly {
+		if opts.WordSource != "" && opts.WordSource != FamilyWordSource {
+			return nil, domain.ErrFamilyWordSource
+		}
+		opts.WordSource = FamilyWordSource
+	}
+	for _, tag := range opts.Tags {
+		if !h.profanity.Allows(tag, opts.FamilyFriendly) {
+			return nil, domain.ErrInappropriateText
+		}
+	}
+
+	for attempts := 0; attempts < 10; attempts++ {
+		roomCode, err := h.chooseRoomCode(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		game := domain.NewGame(roomCode)
+		game.SetPassword(opts.Password)
+		if opts.Visibility != "" && !opts.Practice {
+			game.Visibility = opts.Visibility
+		}
+		game.WordSource = opts.WordSource
+		game.Language = opts.Language
+		game.Tags = opts.Tags
+		game.FamilyFriendly = opts.FamilyFriendly
+		if opts.MaxPlayers != 0 {
+			game.Settings.MaxPlayers = opts.MaxPlayers
+		}
+		if opts.VoteQuorum != "" {
+			game.Settings.VoteQuorum = opts.VoteQuorum
+		} else if h.voteQuorum != "" {
+			game.Settings.VoteQuorum = h.voteQuorum
+		}
+		if opts.TieRule != "" {
+			game.Settings.TieRule = opts.TieRule
+		} else if h.tieRule != "" {
+			game.Settings.TieRule = h.tieRule
+		}
+		imposters := h.imposters
+		if opts.Imposters != nil {
+			imposters = *opts.Imposters
+		}
+		if imposters.Auto && len(imposters.Thresholds) == 0 {
+			imposters.Thresholds = h.thresholds
+		}
+		game.Settings.Imposters = imposters
+		if h.emoji != "" {
+			game.Settings.Emoji = h.emoji
+		}
+		game.SetSeed(opts.Seed)
+		game.Practice = opts.Practice
+		game.PracticeRole = opts.PracticeRole
+		session := NewGameSession(game, h.bus, h.broadcaster, h.signer, h.quotas, h.logger)
+		session.fillBotsOnJoin = opts.FillBots && !opts.Practice
+		session.setRecorder(h.recorder)
+		session.setErrorReporter(h.reporter)
+		session.setHooks(h.hooks)
+		session.setPresence(h.presence)
+		session.setWords(h.wordSources)
+		session.setProfanityFilter(h.profanity)
+		session.setReplays(h.replays)
+
+		// The code may be in use on another instance
+		reserved, err := h.reserveRoomCode(roomCode)
+		if err != nil {
+			h.logger.Error("failed to reserve room code", "roomCode", roomCode, "error", err)
+			session.Close()
+			return nil, err
+		}
+
+		if reserved && h.addSession(session) {
+			return session, nil
+		}
 
-	if session, ok := h.sessions[roomCode]; ok {
+		// Another room, here or on another instance, took the code after it
+		// was chosen. A local one holds the reservation, so it is kept.
 		session.Close()
-		delete(h.sessions, roomCode)
-		h.logger.Info("game deleted", "roomCode", roomCode)
+		if opts.Code != "" {
+			return nil, domain.ErrRoomCodeTaken
+		}
 	}
+
+	return nil, fmt.Errorf("failed to generate unique room code")
 }
 
-// GetSessionCount returns the number of active sessions
-func (h *GameHub) GetSessionCount() int {
+// reserveSession counts a new session against the room limit, returning
+// false if the hub is at capacity (caller must hold mu)
+func (h *GameHub) reserveSession() bool {
+	for {
+		count := h.sessionCount.Load()
+		if h.maxSessions > 0 && count >= int64(h.maxSessions) {
+			return false
+		}
+		if h.sessionCount.CompareAndSwap(count, count+1) {
+			return true
+		}
+	}
+}
+
+// shard returns the shard holding roomCode
+func (h *GameHub) shard(roomCode string) *sessionShard {
+	// FNV-1a
+	hash := uint32(2166136261)
+	for i := 0; i < len(roomCode); i++ {
+		hash ^= uint32(roomCode[i])
+		hash *= 16777619
+	}
+	return &h.shards[hash%hubShardCount]
+}
+
+// addSession adds a session unless its room code is taken, returning false
+// if it is. The caller counts the session in sessionCount.
+func (h *GameHub) addSession(session *GameSession) bool {
+	roomCode := session.GetRoomCode()
+	shard := h.shard(roomCode)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if _, exists := shard.sessions[roomCode]; exists {
+		return false
+	}
+	shard.sessions[roomCode] = session
+	return true
+}
+
+// removeSession removes a session if it is still the one registered under
+// its room code, returning false if not
+func (h *GameHub) removeSession(session *GameSession) bool {
+	roomCode := session.GetRoomCode()
+	shard := h.shard(roomCode)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if shard.sessions[roomCode] != session {
+		return false
+	}
+	delete(shard.sessions, roomCode)
+	h.sessionCount.Add(-1)
+	return true
+}
+
+// allSessions returns every active session. Each shard is locked only while
+// it is copied, so callers query the sessions without holding any hub lock.
+func (h *GameHub) allSessions() []*GameSession {
+	sessions := make([]*GameSession, 0, h.sessionCount.Load())
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.RLock()
+		for _, session := range shard.sessions {
+			sessions = append(sessions, session)
+		}
+		shard.mu.RUnlock()
+	}
+	return sessions
+}
+
+// GetSession returns a game session by room code, waking the room if it is
+// hibernated
+func (h *GameHub) GetSession(roomCode string) (*GameSession, error) {
+	if session := h.lookupSession(roomCode); session != nil {
+		return session, nil
+	}
+	return h.wake(roomCode)
+}
+
+// PeekSession returns a game session by room code if it is in memory,
+// leaving a hibernated room asleep, for lookups that only read
+func (h *GameHub) PeekSession(roomCode string) (*GameSession, error) {
+	if session := h.lookupSession(roomCode); session != nil {
+		return session, nil
+	}
+	return nil, domain.ErrGameNotFound
+}
+
+// lookupSession returns the room's session if it is in memory, or nil
+func (h *GameHub) lookupSession(roomCode string) *GameSession {
+	shard := h.shard(roomCode)
+
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+	return shard.sessions[roomCode]
+}
+
+// DeleteSession removes a game session, or deletes a hibernated room from the
+// store without waking it. It returns false if there is no such room.
+func (h *GameHub) DeleteSession(roomCode string) bool {
+	if !h.deleteHibernated(roomCode) {
+		session := h.lookupSession(roomCode)
+		if session == nil || !h.removeSession(session) {
+			return false
+		}
+		session.Close()
+	}
+
+	h.releaseRoomCode(roomCode)
+	h.logger.Info("game deleted", "roomCode", roomCode)
+	h.Bus().Publish(domain.NewEvent(domain.EventGameDeleted, roomCode, nil))
+	return true
+}
+
+// Bus returns the event bus carrying every game event from every session
+func (h *GameHub) Bus() EventBus {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	return len(h.sessions)
+	return h.bus
+}
+
+// ListSessions returns all active sessions ordered by room code
+func (h *GameHub) ListSessions() []*GameSession {
+	sessions := h.allSessions()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].GetRoomCode() < sessions[j].GetRoomCode()
+	})
+	return sessions
+}
+
+// ListPublicRooms returns snapshots of public rooms that can still be joined, newest first
+func (h *GameHub) ListPublicRooms() []*RoomSnapshot {
+	rooms := make([]*RoomSnapshot, 0)
+	for _, session := range h.allSessions() {
+		snapshot := session.Snapshot()
+		if snapshot.Visibility == domain.VisibilityPublic && snapshot.CanJoin {
+			rooms = append(rooms, snapshot)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].CreatedAt.After(rooms[j].CreatedAt)
+	})
+	return rooms
+}
+
+// GetSessionCount returns the number of active sessions
+func (h *GameHub) GetSessionCount() int {
+	return int(h.sessionCount.Load())
 }
 
 // GetTotalPlayerCount returns the total number of players across all sessions
 func (h *GameHub) GetTotalPlayerCount() int {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
 	total := 0
-	for _, session := range h.sessions {
+	for _, session := range h.allSessions() {
 		total += session.GetPlayerCount()
 	}
 	return total
 }
 
+// HubCounts counts the players and spectators in rooms in memory, and the
+// rooms in each phase
+type HubCounts struct {
+	Players          int
+	ConnectedPlayers int
+	Spectators       int
+	GamesByPhase     map[domain.Phase]int
+}
+
+// Counts returns the players, spectators and phases of the rooms in memory
+func (h *GameHub) Counts() HubCounts {
+	counts := HubCounts{GamesByPhase: make(map[domain.Phase]int)}
+	for _, session := range h.allSessions() {
+		room := session.Counts()
+		if room.Phase == "" {
+			continue // Closed since it was listed
+		}
+		counts.Players += room.Players
+		counts.ConnectedPlayers += room.ConnectedPlayers
+		counts.Spectators += room.Spectators
+		counts.GamesByPhase[room.Phase]++
+	}
+	return counts
+}
+
+// EventQueueStats returns the event queue drop counters summed over active sessions
+func (h *GameHub) EventQueueStats() EventQueueStats {
+	var total EventQueueStats
+	for _, session := range h.allSessions() {
+		stats := session.EventQueueStats()
+		total.Coalesced += stats.Coalesced
+		total.Shed += stats.Shed
+	}
+	return total
+}
+
+// IsRunning returns false once the hub has been closed
+func (h *GameHub) IsRunning() bool {
+	select {
+	case <-h.done:
+		return false
+	default:
+		return true
+	}
+}
+
 // Close shuts down the hub and all sessions
 func (h *GameHub) Close() {
 	close(h.done)
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	// Let a snapshot already being saved finish with live sessions
+	h.snapshotMu.Lock()
+	defer h.snapshotMu.Unlock()
 
-	for _, session := range h.sessions {
-		session.Close()
+	for i := range h.shards {
+		shard := &h.shards[i]
+		shard.mu.Lock()
+		sessions := shard.sessions
+		shard.sessions = make(map[string]*GameSession)
+		h.sessionCount.Add(-int64(len(sessions)))
+		shard.mu.Unlock()
+
+		for _, session := range sessions {
+			session.Close()
+		}
 	}
-	h.sessions = make(map[string]*GameSession)
 }
 
-// generateRoomCode generates a random room code
-func (h *GameHub) generateRoomCode() string {
-	b := make([]byte, h.roomCodeLength)
+// chooseRoomCode validates the requested custom code, or generates one not
+// in use (caller must hold mu)
+func (h *GameHub) chooseRoomCode(opts RoomOptions) (string, error) {
+	if opts.Code != "" {
+		code := strings.ToUpper(opts.Code)
+		if !IsValidRoomCode(code) {
+			return "", domain.ErrInvalidRoomCode
+		}
+		if h.reservedCodes[code] {
+			return "", domain.ErrReservedRoomCode
+		}
+		if h.lookupSession(code) != nil || h.isHibernated(code) {
+			return "", domain.ErrRoomCodeTaken
+		}
+		return code, nil
+	}
+
+	length := opts.CodeLength
+	if length == 0 {
+		length = h.roomCodeLength
+	}
+	if length < MinRoomCodeLength || length > MaxRoomCodeLength {
+		return "", domain.ErrInvalidRoomCode
+	}
+
+	// Generate unique room code
+	for attempts := 0; attempts < 10; attempts++ {
+		code := generateRoomCode(length)
+		if h.lookupSession(code) == nil && !h.isHibernated(code) && !h.reservedCodes[code] {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate unique room code")
+}
+
+// IsValidRoomCode returns true if code is an acceptable custom room code:
+// MinRoomCodeLength to MaxRoomCodeLength uppercase letters or digits
+func IsValidRoomCode(code string) bool {
+	if len(code) < MinRoomCodeLength || len(code) > MaxRoomCodeLength {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRoomCode generates a random room code of the given length
+func generateRoomCode(length int) string {
+	b := make([]byte, length)
 	rand.Read(b)
 
-	code := make([]byte, h.roomCodeLength)
+	code := make([]byte, length)
 	for i := range code {
 		code[i] = RoomCodeChars[int(b[i])%len(RoomCodeChars)]
 	}
@@ -145,40 +729,81 @@ func (h *GameHub) generateRoomCode() string {
 
 // cleanupLoop periodically cleans up stale games
 func (h *GameHub) cleanupLoop() {
-	ticker := time.NewTicker(10 * time.Minute)
-	defer ticker.Stop()
+	defer h.cleanupTicker.Stop()
 
 	for {
 		select {
 		case <-h.done:
 			return
-		case <-ticker.C:
+		case <-h.cleanupTicker.C:
 			h.cleanupStaleGames()
 		}
 	}
 }
 
-// cleanupStaleGames removes games that have been inactive for too long
+// cleanupStaleGames removes empty rooms past their TTL and rooms whose players
+// have been idle too long, warning the latter shortly beforehand. With
+// hibernation enabled, rooms nobody has been connected to for a while are
+// hibernated instead, and hibernated rooms past their TTL are deleted. So are
+// replays past theirs.
 func (h *GameHub) cleanupStaleGames() {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	h.mu.RLock()
+	policy := h.cleanup
+	store := h.hibernation
+	hibernation := h.hibernationPolicy
+	replays, replayTTL := h.replays, h.replayTTL
+	h.mu.RUnlock()
 
 	now := time.Now()
-	stale := make([]string, 0)
+	stale := make(map[*GameSession]string) // session -> reason
+	var unused []*GameSession
+
+	for _, session := range h.allSessions() {
+		if session.GetPlayerCount() == 0 {
+			if now.Sub(session.GetCreatedAt()) > policy.StaleGameTimeout {
+				stale[session] = "empty"
+			}
+			continue
+		}
+		if since, ok := session.DisconnectedSince(); ok && store != nil && now.Sub(since) > hibernation.After {
+			unused = append(unused, session)
+			continue
+		}
+		if policy.IdleTimeout <= 0 {
+			continue
+		}
 
-	for roomCode, session := range h.sessions {
-		// Check if game has no players and is old
-		if session.GetPlayerCount() == 0 && now.Sub(session.GetCreatedAt()) > StaleGameTimeout {
-			stale = append(stale, roomCode)
+		expiresAt := session.LastActivity().Add(policy.IdleTimeout)
+		switch {
+		case now.After(expiresAt):
+			stale[session] = "idle"
+		case now.After(expiresAt.Add(-policy.IdleWarning)):
+			session.warnExpiring(expiresAt)
 		}
 	}
 
-	for _, roomCode := range stale {
-		if session, ok := h.sessions[roomCode]; ok {
+	for session, reason := range stale {
+		if h.removeSession(session) {
+			roomCode := session.GetRoomCode()
 			session.Close()
-			delete(h.sessions, roomCode)
-			h.logger.Info("stale game cleaned up", "roomCode", roomCode)
+			h.releaseRoomCode(roomCode)
+			h.logger.Info("stale game cleaned up", "roomCode", roomCode, "reason", reason)
+			h.Bus().Publish(domain.NewEvent(domain.EventGameDeleted, roomCode, nil))
 		}
 	}
+
+	if replays != nil && replayTTL > 0 {
+		h.expireReplays(replays, replayTTL)
+	}
+
+	if store == nil {
+		return
+	}
+	for _, session := range unused {
+		h.hibernate(session, store)
+	}
+	if hibernation.TTL > 0 {
+		h.expireHibernated(store, hibernation.TTL)
+	}
 }
 
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// benchmarkRooms is how many rooms the hub holds while benchmarked
+const benchmarkRooms = 4096
+
+// populatedHub returns a hub holding n rooms, created in parallel, and their
+// codes
+func populatedHub(b *testing.B, n int) (*GameHub, []string) {
+	b.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := NewGameHub(NewTokenSigner(nil, 0), NewLocalBroadcaster(), logger)
+	b.Cleanup(hub.Close)
+
+	codes := make([]string, n)
+	var wg sync.WaitGroup
+	var failed atomic.Pointer[error]
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session, err := hub.CreateGame(RoomOptions{})
+			if err != nil {
+				failed.Store(&err)
+				return
+			}
+			codes[i] = session.GetRoomCode()
+		}()
+	}
+	wg.Wait()
+	if err := failed.Load(); err != nil {
+		b.Fatalf("CreateGame: %v", *err)
+	}
+	return hub, codes
+}
+
+// BenchmarkHub creates and looks up rooms from many goroutines at once in a
+// hub already holding several thousand, the contention the sharded session
+// map spreads out
+func BenchmarkHub(b *testing.B) {
+	b.Run("create", func(b *testing.B) {
+		hub, _ := populatedHub(b, benchmarkRooms)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				session, err := hub.CreateGame(RoomOptions{})
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				code := session.GetRoomCode()
+				if _, err := hub.GetSession(code); err != nil {
+					b.Error(err)
+					return
+				}
+				// Keep the hub at its size
+				hub.DeleteSession(code)
+			}
+		})
+	})
+
+	b.Run("lookup", func(b *testing.B) {
+		hub, codes := populatedHub(b, benchmarkRooms)
+		var next atomic.Uint64
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			// Each goroutine walks the rooms from its own offset
+			i := next.Add(benchmarkRooms / 16)
+			for pb.Next() {
+				if _, err := hub.GetSession(codes[i%benchmarkRooms]); err != nil {
+					b.Error(err)
+					return
+				}
+				i++
+			}
+		})
+	})
+}
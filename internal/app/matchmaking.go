@@ -0,0 +1,74 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"imposter/internal/domain"
+)
+
+// QuickJoinPreferences describe the game a player looking for one would like.
+// Empty fields match any room.
+type QuickJoinPreferences struct {
+	Language   string // e.g. "en"; matches only rooms created for that language
+	MaxPlayers int    // Room size
+	WordSource string // Named word provider
+}
+
+// QuickJoin finds the open public lobby that best fits prefs: of the rooms
+// that match, the one closest to full, then the oldest. If none fits, it
+// creates a public room with those preferences. The second result is true if
+// the room was created. Searches are serialized, so players quick-joining at
+// once end up in the same new room rather than one each.
+func (h *GameHub) QuickJoin(prefs QuickJoinPreferences) (*GameSession, bool, error) {
+	h.quickJoinMu.Lock()
+	defer h.quickJoinMu.Unlock()
+
+	h.mu.RLock()
+	sources := h.wordSources
+	h.mu.RUnlock()
+	if prefs.WordSource != "" && !sources.Has(prefs.WordSource) {
+		return nil, false, domain.ErrUnknownWordSource
+	}
+
+	var candidates []*RoomSnapshot
+	for _, room := range h.ListPublicRooms() {
+		if room.Locked || room.Phase != domain.PhaseLobby {
+			continue
+		}
+		if prefs.Language != "" && !strings.EqualFold(room.Language, prefs.Language) {
+			continue
+		}
+		if prefs.MaxPlayers != 0 && room.Settings.MaxPlayers != prefs.MaxPlayers {
+			continue
+		}
+		if prefs.WordSource != "" && sources.Resolve(room.WordSource) != prefs.WordSource {
+			continue
+		}
+		candidates = append(candidates, room)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if len(candidates[i].Players) != len(candidates[j].Players) {
+			return len(candidates[i].Players) > len(candidates[j].Players)
+		}
+		return candidates[i].CreatedAt.Before(candidates[j].CreatedAt)
+	})
+	for _, room := range candidates {
+		// The room may have started or closed since it was listed
+		if session := h.lookupSession(room.RoomCode); session != nil && session.CanJoin() {
+			return session, false, nil
+		}
+	}
+
+	session, err := h.CreateGame(RoomOptions{
+		Visibility: domain.VisibilityPublic,
+		Language:   strings.ToLower(prefs.Language),
+		MaxPlayers: prefs.MaxPlayers,
+		WordSource: prefs.WordSource,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return session, true, nil
+}
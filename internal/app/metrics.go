@@ -0,0 +1,61 @@
+package app
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RoomMetrics counts a session's activity since it was created or last
+// restored, so operators can spot a room that misbehaves
+type RoomMetrics struct {
+	EventsBroadcast  uint64  `json:"eventsBroadcast"`
+	EventsDropped    uint64  `json:"eventsDropped"`    // Shed from the event queue, or failed to reach a client
+	EventsResent     uint64  `json:"eventsResent"`     // Critical events a client did not acknowledge in time
+	MessagesReceived uint64  `json:"messagesReceived"` // From WebSocket and gRPC clients
+	Reconnects       uint64  `json:"reconnects"`
+	VotesCast        uint64  `json:"votesCast"`
+	VoteLatencySum   float64 `json:"voteLatencySumSeconds"` // From the start of voting to each vote
+	AvgVoteLatencyMs float64 `json:"avgVoteLatencyMs"`
+}
+
+// roomCounters holds a session's metrics, updated from any goroutine
+type roomCounters struct {
+	eventsBroadcast  atomic.Uint64
+	sendFailures     atomic.Uint64
+	eventsResent     atomic.Uint64
+	messagesReceived atomic.Uint64
+	reconnects       atomic.Uint64
+	votesCast        atomic.Uint64
+	voteLatency      atomic.Int64 // Nanoseconds, summed over votesCast
+}
+
+// Metrics returns the session's activity counters
+func (s *GameSession) Metrics() RoomMetrics {
+	votes := s.counters.votesCast.Load()
+	latency := time.Duration(s.counters.voteLatency.Load())
+
+	metrics := RoomMetrics{
+		EventsBroadcast:  s.counters.eventsBroadcast.Load(),
+		EventsDropped:    s.events.stats().Shed + s.counters.sendFailures.Load(),
+		EventsResent:     s.counters.eventsResent.Load(),
+		MessagesReceived: s.counters.messagesReceived.Load(),
+		Reconnects:       s.counters.reconnects.Load(),
+		VotesCast:        votes,
+		VoteLatencySum:   latency.Seconds(),
+	}
+	if votes > 0 {
+		metrics.AvgVoteLatencyMs = float64(latency) / float64(time.Millisecond) / float64(votes)
+	}
+	return metrics
+}
+
+// RecordMessage counts a message received from one of the room's clients
+func (s *GameSession) RecordMessage() {
+	s.counters.messagesReceived.Add(1)
+}
+
+// recordVote counts a vote cast latency after voting started
+func (s *GameSession) recordVote(latency time.Duration) {
+	s.counters.votesCast.Add(1)
+	s.counters.voteLatency.Add(int64(latency))
+}
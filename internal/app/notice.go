@@ -0,0 +1,59 @@
+package app
+
+// BroadcastNotice sends a message, such as a system notice from the
+// operators, to every player and spectator connected to a room on this
+// instance, and returns how many connections it was sent to. message builds
+// it for a connection's locale ("" if unknown). It is sent outside the room's
+// event stream, so clients that reconnect later do not see it.
+func (h *GameHub) BroadcastNotice(message func(locale string) interface{}) int {
+	sent := 0
+	for _, session := range h.allSessions() {
+		sent += session.SendNotice(message)
+	}
+	return sent
+}
+
+// SendNotice sends message, built for the room's locale or else each
+// connection's, to the room's connected players and spectators, skipping
+// bots, and returns how many connections it was sent to
+func (s *GameSession) SendNotice(message func(locale string) interface{}) int {
+	sent := 0
+	roomLocale := s.Locale()
+	connLocale := func(conn ClientConnection) string {
+		if roomLocale != "" {
+			return roomLocale
+		}
+		return localeOf(conn)
+	}
+	s.call(func() {
+		for playerID, client := range s.clients {
+			if _, ok := s.bots[playerID]; ok {
+				continue
+			}
+			if err := client.Send(message(connLocale(client))); err != nil {
+				s.counters.sendFailures.Add(1)
+				s.logger.Debug("failed to send notice to client", "playerID", playerID, "error", err)
+				continue
+			}
+			sent++
+		}
+		for _, spectator := range s.spectators {
+			if err := spectator.Send(message(connLocale(spectator))); err != nil {
+				s.counters.sendFailures.Add(1)
+				s.logger.Debug("failed to send notice to spectator", "error", err)
+				continue
+			}
+			sent++
+		}
+	})
+	return sent
+}
+
+// localeOf returns the language a connection's messages are sent in, or an
+// empty string if the transport does not track it
+func localeOf(conn ClientConnection) string {
+	if localized, ok := conn.(interface{ GetLocale() string }); ok {
+		return localized.GetLocale()
+	}
+	return ""
+}
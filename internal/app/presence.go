@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Presence records which players have a live connection to each room, so
+// every instance can see a room's members wherever they are connected.
+// Sessions call Join and Leave on their actor, so they must not block.
+type Presence interface {
+	// Join records that playerID is connected to roomCode on this instance
+	Join(roomCode, playerID string)
+
+	// Leave records that playerID is no longer connected to roomCode on this instance
+	Leave(roomCode, playerID string)
+
+	// Members returns the players connected to roomCode on any instance, sorted
+	Members(ctx context.Context, roomCode string) ([]string, error)
+}
+
+// LocalPresence tracks the players connected to this process. It is the
+// default for single-instance deployments.
+type LocalPresence struct {
+	mu    sync.RWMutex
+	rooms map[string]map[string]bool // roomCode -> playerIDs
+}
+
+// NewLocalPresence creates an empty in-memory presence tracker
+func NewLocalPresence() *LocalPresence {
+	return &LocalPresence{
+		rooms: make(map[string]map[string]bool),
+	}
+}
+
+// Join records a player's connection to a room
+func (p *LocalPresence) Join(roomCode, playerID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rooms[roomCode] == nil {
+		p.rooms[roomCode] = make(map[string]bool)
+	}
+	p.rooms[roomCode][playerID] = true
+}
+
+// Leave forgets a player's connection to a room
+func (p *LocalPresence) Leave(roomCode, playerID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.rooms[roomCode], playerID)
+	if len(p.rooms[roomCode]) == 0 {
+		delete(p.rooms, roomCode)
+	}
+}
+
+// Members returns the players connected to a room
+func (p *LocalPresence) Members(ctx context.Context, roomCode string) ([]string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	members := make([]string, 0, len(p.rooms[roomCode]))
+	for playerID := range p.rooms[roomCode] {
+		members = append(members, playerID)
+	}
+	sort.Strings(members)
+	return members, nil
+}
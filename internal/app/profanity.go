@@ -0,0 +1,179 @@
+package app
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProfanityMode is how closely players' text is checked for profanity
+type ProfanityMode string
+
+const (
+	ProfanityOff    ProfanityMode = "off"    // Nothing is checked
+	ProfanityNormal ProfanityMode = "normal" // Listed words written plainly
+	ProfanityStrict ProfanityMode = "strict" // Also disguised: l33t spellings, repeated or spaced-out letters, words inside others
+)
+
+// IsValid returns true if m is a known mode
+func (m ProfanityMode) IsValid() bool {
+	return m == ProfanityOff || m == ProfanityNormal || m == ProfanityStrict
+}
+
+// profanity is the built-in list of words players may not use where the
+// filter is on; PROFANITY_WORDS adds to it
+var profanity = []string{
+	"asshole", "bastard", "bitch", "bollocks", "bullshit", "cock", "cunt", "damn", "dick", "dildo", "fag", "faggot", "fuck", "hell",
+	"jerkoff", "motherfucker", "nigga", "nigger", "penis", "piss", "porn",
+	"prick", "pussy", "retard", "sex", "shit", "slut", "tits", "twat",
+	"vagina", "wank", "whore",
+}
+
+// minEmbeddedProfanity is the shortest listed word strict mode finds inside
+// other words; shorter ones are found alone, so "class" is not taken for "ass"
+const minEmbeddedProfanity = 4
+
+// innocentWords are ordinary words and names with a listed word inside, which
+// strict mode lets through
+var innocentWords = []string{
+	"cockatoo", "cockerel", "cockney", "cockpit", "cockroach", "cocktail", "hancock", "hitchcock", "peacock", "shuttlecock", "woodcock",
+	"dickens", "dickinson", "scunthorpe", "swank", "swanky", "penistone", "prickle", "prickly", "retardant", "shiitake",
+	"niger", "nigeria", "snigger",
+}
+
+// innocent holds innocentWords squashed
+var innocent = func() []string {
+	words := make([]string, len(innocentWords))
+	for i, word := range innocentWords {
+		words[i] = squash(word)
+	}
+	return words
+}()
+
+// ProfanityFilter checks nicknames and clues against a list of words. Rooms
+// are checked in the filter's mode, and family-friendly rooms always strictly.
+type ProfanityFilter struct {
+	mode  ProfanityMode
+	words map[string]bool // Lowercase
+	bare  []string        // The words squashed, for strict mode
+}
+
+// NewProfanityFilter creates a filter checking in mode against the built-in
+// words and extra
+func NewProfanityFilter(mode ProfanityMode, extra []string) *ProfanityFilter {
+	f := &ProfanityFilter{mode: mode, words: make(map[string]bool)}
+	for _, word := range append(append([]string(nil), profanity...), extra...) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || f.words[word] {
+			continue
+		}
+		f.words[word] = true
+		f.bare = append(f.bare, squash(word))
+	}
+	return f
+}
+
+// DefaultProfanityFilter checks only family-friendly rooms, against the built-in words
+func DefaultProfanityFilter() *ProfanityFilter {
+	return NewProfanityFilter(ProfanityOff, nil)
+}
+
+// Mode returns the mode rooms that are not family-friendly are checked in
+func (f *ProfanityFilter) Mode() ProfanityMode {
+	return f.mode
+}
+
+// Allows returns true if text may be used in a room, checked strictly when
+// the room is family-friendly
+func (f *ProfanityFilter) Allows(text string, familyFriendly bool) bool {
+	mode := f.mode
+	if familyFriendly {
+		mode = ProfanityStrict
+	}
+
+	switch mode {
+	case ProfanityNormal:
+		return !f.hasWord(strings.ToLower(text))
+	case ProfanityStrict:
+		return !f.hasWord(unleet(strings.ToLower(text))) && !f.hasDisguised(text)
+	}
+	return true
+}
+
+// hasWord returns true if a listed word stands alone in text
+func (f *ProfanityFilter) hasWord(text string) bool {
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, word := range words {
+		if f.words[word] || f.words[squash(word)] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDisguised returns true if a long enough listed word appears inside a
+// word of text, or is spelled out by a run of single letters, as in
+// "f.u.c.k". Words are never run together, so "wash it" is not taken for
+// "shit".
+func (f *ProfanityFilter) hasDisguised(text string) bool {
+	var letters strings.Builder
+	for _, word := range strings.FieldsFunc(unleet(strings.ToLower(text)), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if utf8.RuneCountInString(word) == 1 {
+			letters.WriteString(word)
+			continue
+		}
+		if f.hasEmbedded(squash(letters.String())) || f.hasEmbedded(squash(word)) {
+			return true
+		}
+		letters.Reset()
+	}
+	return f.hasEmbedded(squash(letters.String()))
+}
+
+// hasEmbedded returns true if a long enough listed word appears anywhere in a
+// squashed word, other than inside an innocent word
+func (f *ProfanityFilter) hasEmbedded(squashed string) bool {
+	if squashed == "" {
+		return false
+	}
+	for _, word := range innocent {
+		squashed = strings.ReplaceAll(squashed, word, " ")
+	}
+	for _, word := range f.bare {
+		if len(word) >= minEmbeddedProfanity && strings.Contains(squashed, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// leet maps digits and symbols to the letters they stand in for
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '!': 'i', '|': 'i', '3': 'e', '4': 'a', '@': 'a',
+	'5': 's', '$': 's', '7': 't', '+': 't', '8': 'b', '9': 'g',
+}
+
+// unleet replaces the stand-ins in lowercase text with their letters
+func unleet(text string) string {
+	return strings.Map(func(r rune) rune {
+		if letter, ok := leet[r]; ok {
+			return letter
+		}
+		return r
+	}, text)
+}
+
+// squash reduces text to its letters, with stand-ins replaced and repeated
+// letters collapsed, so "s h 1 i i t" reads as "shit"
+func squash(text string) string {
+	var b strings.Builder
+	var last rune
+	for _, r := range unleet(strings.ToLower(text)) {
+		if !unicode.IsLetter(r) || r == last {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
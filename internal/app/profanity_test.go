@@ -0,0 +1,40 @@
+package app
+
+import "testing"
+
+func TestProfanityFilterStrict(t *testing.T) {
+	filter := NewProfanityFilter(ProfanityOff, nil)
+
+	for _, text := range []string{
+		"shit", "SHIT", "sh1t", "$hit", "shiiiit", "bullshitter", "fuckface",
+		"s h 1 i i t", "f.u.c.k", "f u c k you", "nice c o c k", "peacockfuck",
+	} {
+		if filter.Allows(text, true) {
+			t.Errorf("Allows(%q) = true, want false", text)
+		}
+	}
+
+	for _, text := range []string{
+		"peacock", "Peacocks", "cockroach", "Dickens", "Scunthorpe", "swanky", "wash it",
+		"class", "hello", "shiitake", "Nigeria", "a b c", "I am a spy",
+	} {
+		if !filter.Allows(text, true) {
+			t.Errorf("Allows(%q) = false, want true", text)
+		}
+	}
+}
+
+func TestProfanityFilterNormal(t *testing.T) {
+	filter := NewProfanityFilter(ProfanityNormal, []string{"Frak"})
+
+	for _, text := range []string{"shit", "oh frak!"} {
+		if filter.Allows(text, false) {
+			t.Errorf("Allows(%q) = true, want false", text)
+		}
+	}
+	for _, text := range []string{"bullshitter", "s h i t", "peacock"} {
+		if !filter.Allows(text, false) {
+			t.Errorf("Allows(%q) = false, want true", text)
+		}
+	}
+}
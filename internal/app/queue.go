@@ -0,0 +1,118 @@
+package app
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"imposter/internal/domain"
+)
+
+// EventQueueStats counts events a session queued but did not broadcast
+type EventQueueStats struct {
+	Coalesced uint64 `json:"coalesced"` // Replaced by a newer event of the same kind
+	Shed      uint64 `json:"shed"`      // Dropped because the queue was full
+}
+
+// eventQueue holds a session's events until they are broadcast, in the order
+// they were queued. Critical events are never dropped, even past capacity.
+// Low-priority events, which are superseded by the next one of their kind,
+// replace a queued one instead of adding to the queue and are shed first
+// when it is full.
+type eventQueue struct {
+	mu       sync.Mutex
+	events   []*domain.GameEvent
+	capacity int
+	ready    chan struct{} // Signalled when events are waiting
+
+	coalesced atomic.Uint64
+	shed      atomic.Uint64
+}
+
+// newEventQueue creates a queue holding about capacity events
+func newEventQueue(capacity int) *eventQueue {
+	return &eventQueue{
+		capacity: capacity,
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// push queues an event. It returns false if the event was shed.
+func (q *eventQueue) push(event *domain.GameEvent) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if event.IsLowPriority() {
+		if i := q.supersededBy(event); i >= 0 {
+			q.events[i] = event
+			q.coalesced.Add(1)
+			return true
+		}
+		if len(q.events) >= q.capacity {
+			q.shed.Add(1)
+			return false
+		}
+	} else if len(q.events) >= q.capacity {
+		// Make room by shedding the oldest low-priority event, if any
+		if i := slices.IndexFunc(q.events, (*domain.GameEvent).IsLowPriority); i >= 0 {
+			q.events = slices.Delete(q.events, i, i+1)
+			q.shed.Add(1)
+		}
+	}
+
+	q.events = append(q.events, event)
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// supersededBy returns the index of a queued event that event replaces, or
+// -1. Only events after the last critical one qualify, so replacing them
+// does not reorder the event with a critical one (caller must hold mu).
+func (q *eventQueue) supersededBy(event *domain.GameEvent) int {
+	for i := len(q.events) - 1; i >= 0; i-- {
+		queued := q.events[i]
+		if !queued.IsLowPriority() {
+			return -1
+		}
+		if queued.Type == event.Type && queued.PlayerID == event.PlayerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// drain removes and returns all queued events in order
+func (q *eventQueue) drain() []*domain.GameEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := q.events
+	q.events = nil
+	return events
+}
+
+// free returns how many more events fit before the queue is full
+func (q *eventQueue) free() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.capacity - len(q.events)
+}
+
+// len returns how many events are waiting
+func (q *eventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+// stats returns the queue's drop counters
+func (q *eventQueue) stats() EventQueueStats {
+	return EventQueueStats{
+		Coalesced: q.coalesced.Load(),
+		Shed:      q.shed.Load(),
+	}
+}
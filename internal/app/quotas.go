@@ -0,0 +1,59 @@
+package app
+
+import "imposter/internal/domain"
+
+// eventQueueHeadroom is how many free queue slots an action needs, since one
+// action can queue several events (e.g. the last word also starts voting)
+const eventQueueHeadroom = 4
+
+// RoomQuotas caps the resources a single room may use, so one busy room
+// cannot starve the others on the instance
+type RoomQuotas struct {
+	// MaxConnections caps players plus spectators connected at once; players
+	// already in the game are always admitted, while new players and
+	// spectators are refused once it is reached. 0 is unlimited.
+	MaxConnections int
+
+	// EventQueueSize is how many events may wait to be broadcast. Actions are
+	// refused while the queue is nearly full; events the session queues on its
+	// own, such as phase changes, may exceed it.
+	EventQueueSize int
+
+	// MaxBots caps the computer players the host may add. 0 disables bots.
+	MaxBots int
+}
+
+// DefaultRoomQuotas returns the default room quotas
+func DefaultRoomQuotas() RoomQuotas {
+	return RoomQuotas{
+		MaxConnections: 0,
+		EventQueueSize: 100,
+		MaxBots:        3,
+	}
+}
+
+// checkEventQuota refuses an action while the room's events are not being
+// broadcast fast enough to take more
+func (s *GameSession) checkEventQuota() error {
+	if s.events.free() < eventQueueHeadroom {
+		s.logger.Warn("event queue nearly full, refusing action", "roomCode", s.game.ID, "queued", s.events.len())
+		return domain.ErrRoomQuotaExceeded
+	}
+	return nil
+}
+
+// HasConnectionCapacity returns true if the room may take another connection
+// from someone who is not already playing in it
+func (s *GameSession) HasConnectionCapacity() bool {
+	ok := false
+	s.call(func() {
+		ok = s.quotas.MaxConnections == 0 || s.connectionCount() < s.quotas.MaxConnections
+	})
+	return ok
+}
+
+// connectionCount returns how many players and spectators are connected. Bots
+// do not count, so a room left to them is reaped like an empty one.
+func (s *GameSession) connectionCount() int {
+	return len(s.clients) - len(s.bots) + len(s.spectators)
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// GameRecorder persists completed rounds and games, e.g. for leaderboards and
+// word statistics. Sessions call it while holding their lock, so
+// implementations must queue the record rather than write it synchronously.
+type GameRecorder interface {
+	RecordRound(record *RoundRecord)
+	RecordGame(record *GameRecord)
+}
+
+// RecordedPlayer identifies a player in a persisted record. Players signed in
+// with an external identity keep the same ID across games.
+type RecordedPlayer struct {
+	ID       string
+	Nickname string
+}
+
+// RoundRecord is a finished round
+type RoundRecord struct {
+	GameID        string // Unique per session; room codes are reused
+	RoomCode      string
+	GameCreatedAt time.Time
+	Number        int
+	SecretWord    string
+	ImposterID    string   // The first of ImposterIDs
+	ImposterIDs   []string // Every imposter
+	Winner        domain.Role
+	Players       []RecordedPlayer
+	Submissions   []domain.Submission
+	Votes         []domain.Vote
+	StartedAt     time.Time
+	EndedAt       time.Time
+}
+
+// GameRecord is a game whose session has closed after at least one round
+type GameRecord struct {
+	GameID    string
+	RoomCode  string
+	CreatedAt time.Time
+	EndedAt   time.Time
+	Rounds    int
+}
+
+// newRoundRecord copies a finished round so it can be written after the
+// session lock is released (caller must hold the session lock)
+func newRoundRecord(gameID string, game *domain.Game, round *domain.Round) *RoundRecord {
+	record := &RoundRecord{
+		GameID:        gameID,
+		RoomCode:      game.ID,
+		GameCreatedAt: game.CreatedAt,
+		Number:        round.Number,
+		SecretWord:    round.SecretWord,
+		ImposterID:    round.ImposterID,
+		ImposterIDs:   round.Imposters(),
+		Winner:        round.Winner,
+		Players:       recordedPlayers(game, round.PlayerOrder),
+		Submissions:   make([]domain.Submission, 0, len(round.Submissions)),
+		Votes:         make([]domain.Vote, 0, len(round.Votes)),
+		StartedAt:     round.StartedAt,
+		EndedAt:       round.EndedAt,
+	}
+
+	for _, sub := range round.Submissions {
+		record.Submissions = append(record.Submissions, *sub)
+	}
+	for _, vote := range round.Votes {
+		record.Votes = append(record.Votes, *vote)
+	}
+
+	return record
+}
+
+// recordedPlayers identifies the given players, keeping the nicknames of
+// those still in the game
+func recordedPlayers(game *domain.Game, playerIDs []string) []RecordedPlayer {
+	players := make([]RecordedPlayer, 0, len(playerIDs))
+	for _, playerID := range playerIDs {
+		player := RecordedPlayer{ID: playerID}
+		if p, err := game.GetPlayer(playerID); err == nil {
+			player.Nickname = p.Nickname
+		}
+		players = append(players, player)
+	}
+	return players
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// replayTimeout bounds saving or loading a single replay
+const replayTimeout = 5 * time.Second
+
+// maxReplayEvents caps how many events a game's replay holds; later events
+// are left out
+const maxReplayEvents = 5000
+
+// ErrReplayNotFound is returned for a replay that was never saved or has
+// been deleted
+var ErrReplayNotFound = errors.New("replay not found")
+
+// ReplayStore keeps recorded games so they can be watched again after the
+// room has closed
+type ReplayStore interface {
+	// SaveReplay replaces the replay with the given ID
+	SaveReplay(ctx context.Context, id string, data []byte) error
+
+	// LoadReplay returns nil if the replay is not stored
+	LoadReplay(ctx context.Context, id string) ([]byte, error)
+
+	// DeleteReplays removes the replays last saved before cutoff and returns
+	// how many there were
+	DeleteReplays(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// Replay is a game's public events in the order they were broadcast, which
+// spectators saw as they happened. Each event's timestamp lets playback keep
+// the original timing.
+type Replay struct {
+	ID        string              `json:"id"`
+	RoomCode  string              `json:"roomCode"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Rounds    int                 `json:"rounds"`
+	Events    []*domain.GameEvent `json:"events"`
+}
+
+// Duration returns how long the replay runs from its first event to its last
+func (r *Replay) Duration() time.Duration {
+	if len(r.Events) == 0 {
+		return 0
+	}
+	return r.Events[len(r.Events)-1].Timestamp.Sub(r.Events[0].Timestamp)
+}
+
+// SetReplayStore records the games of sessions created from now on to store,
+// saving each game's replay as its rounds end. Replays are deleted ttl after
+// they were last saved; 0 keeps them. nil stops recording.
+func (h *GameHub) SetReplayStore(store ReplayStore, ttl time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replays = store
+	h.replayTTL = ttl
+}
+
+// expireReplays deletes the replays older than ttl
+func (h *GameHub) expireReplays(store ReplayStore, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+	defer cancel()
+
+	count, err := store.DeleteReplays(ctx, time.Now().Add(-ttl))
+	if err != nil {
+		h.logger.Warn("failed to delete expired replays", "error", err)
+		return
+	}
+	if count > 0 {
+		h.logger.Info("expired replays deleted", "count", count)
+	}
+}
+
+// LoadReplay returns the replay with the given ID, which players of the game
+// find in its state as replayId
+func (h *GameHub) LoadReplay(ctx context.Context, id string) (*Replay, error) {
+	h.mu.RLock()
+	store := h.replays
+	h.mu.RUnlock()
+	if store == nil {
+		return nil, ErrReplayNotFound
+	}
+
+	data, err := store.LoadReplay(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrReplayNotFound
+	}
+
+	var replay Replay
+	if err := json.Unmarshal(data, &replay); err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+// setReplays sets where the session saves its replay; nil stops recording
+func (s *GameSession) setReplays(store ReplayStore) {
+	s.call(func() {
+		s.replays = store
+	})
+}
+
+// recordReplayEvent adds a public event to the game's replay, saving the
+// replay once a round ends. Expiry warnings are left out, since they are
+// about the room rather than the game.
+func (s *GameSession) recordReplayEvent(event *domain.GameEvent) {
+	if s.replays == nil || event.Type == domain.EventRoomExpiring || len(s.replayEvents) >= maxReplayEvents {
+		return
+	}
+
+	s.replayEvents = append(s.replayEvents, event)
+	if event.Type == domain.EventRoundEnded {
+		s.saveReplay()
+	}
+}
+
+// saveReplay writes the replay recorded so far in the background, so the
+// actor never waits on the store
+func (s *GameSession) saveReplay() {
+	if s.replays == nil || len(s.game.RoundHistory) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(&Replay{
+		ID:        s.recordID,
+		RoomCode:  s.game.ID,
+		CreatedAt: s.game.CreatedAt,
+		Rounds:    len(s.game.RoundHistory),
+		Events:    s.replayEvents,
+	})
+	if err != nil {
+		s.logger.Error("failed to encode replay", "roomCode", s.game.ID, "error", err)
+		return
+	}
+
+	store, id, roomCode := s.replays, s.recordID, s.game.ID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+		defer cancel()
+
+		if err := store.SaveReplay(ctx, id, data); err != nil {
+			s.logger.Warn("failed to save replay", "roomCode", roomCode, "replayID", id, "error", err)
+		}
+	}()
+}
+
+// replayID returns the ID the game's replay is saved under, or "" if it is
+// not being recorded
+func (s *GameSession) replayID() string {
+	if s.replays == nil {
+		return ""
+	}
+	return s.recordID
+}
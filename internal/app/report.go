@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrorReporter sends errors to an error tracking service. Sessions report
+// panics they recover from and transports report errors they did not expect,
+// tagged with the room and phase they happened in. Report is called from the
+// session's actor, so implementations must queue the error rather than send it
+// synchronously.
+type ErrorReporter interface {
+	Report(err error, tags map[string]string)
+}
+
+// PanicError is a recovered panic and the stack it was raised on
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// newPanicError captures the stack of a panic being recovered
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+// SetErrorReporter sets where sessions created from now on, and the hub
+// itself, report unexpected errors
+func (h *GameHub) SetErrorReporter(reporter ErrorReporter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reporter = reporter
+}
+
+// ReportError reports an unexpected error that happened outside any room
+func (h *GameHub) ReportError(err error, tags map[string]string) {
+	h.mu.RLock()
+	reporter := h.reporter
+	h.mu.RUnlock()
+
+	if reporter != nil {
+		reporter.Report(err, tags)
+	}
+}
+
+// setErrorReporter sets where the session reports unexpected errors
+func (s *GameSession) setErrorReporter(reporter ErrorReporter) {
+	s.call(func() {
+		s.reporter = reporter
+	})
+}
+
+// ReportError reports an unexpected error that happened while handling the
+// room, e.g. a transport failing to carry out an action, with the room code
+// and current phase added to tags
+func (s *GameSession) ReportError(err error, tags map[string]string) {
+	if !s.call(func() {
+		s.reportError(err, tags)
+	}) {
+		// The session has closed, so the actor no longer changes the
+		// reporter; report without the phase
+		if s.reporter != nil {
+			s.reporter.Report(err, withTags(tags, map[string]string{"roomCode": s.game.ID}))
+		}
+	}
+}
+
+// reportError reports err tagged with the room code and phase. Called on the actor.
+func (s *GameSession) reportError(err error, tags map[string]string) {
+	if s.reporter == nil {
+		return
+	}
+	s.reporter.Report(err, withTags(tags, map[string]string{
+		"roomCode": s.game.ID,
+		"phase":    string(s.game.Phase),
+	}))
+}
+
+// withTags returns a copy of tags with extra added
+func withTags(tags, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(tags)+len(extra))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
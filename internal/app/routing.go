@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"imposter/internal/domain"
+)
+
+// Node is a server in a cluster
+type Node struct {
+	ID  string `json:"id"`
+	URL string `json:"url"` // Base URL peers and clients reach the node at, e.g. http://10.0.0.5:8080
+}
+
+// RoomOwner is the node a room lives on
+type RoomOwner struct {
+	Node
+	Alive bool `json:"alive"` // False once the node stops renewing its registration
+}
+
+// RoomDirectory records which node owns each room, so a request reaching any
+// node can be routed to the one holding the room's session. A node owns the
+// rooms whose codes it reserved, even once it is no longer alive.
+type RoomDirectory interface {
+	RoomCodes
+
+	// Self returns this node
+	Self() Node
+
+	// TakeOver makes this node roomCode's owner if deadNodeID still owns it
+	TakeOver(ctx context.Context, roomCode, deadNodeID string) (bool, error)
+
+	// Owner returns roomCode's owner, or nil if no node owns it
+	Owner(ctx context.Context, roomCode string) (*RoomOwner, error)
+}
+
+// NodeSnapshots loads the snapshots other nodes saved, so rooms can be taken
+// over from a node that died
+type NodeSnapshots interface {
+	// LoadNodeSnapshot returns nil if the node has no snapshot
+	LoadNodeSnapshot(ctx context.Context, nodeID string) ([]byte, error)
+}
+
+// SetDirectory enables cluster routing. Room codes are reserved in directory,
+// which makes this node their owner, and rooms of a dead node are restored
+// from its snapshot in snapshots, which may be nil to disable takeover. Call
+// it before creating or restoring sessions.
+func (h *GameHub) SetDirectory(directory RoomDirectory, snapshots NodeSnapshots) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.directory = directory
+	h.codes = directory
+	h.nodeSnapshots = snapshots
+}
+
+// RouteRoom returns the node that should serve roomCode, or nil if this node
+// should: it holds the room, the room does not exist, or the room's owner
+// died and this node took it over.
+func (h *GameHub) RouteRoom(ctx context.Context, roomCode string) (*RoomOwner, error) {
+	if _, err := h.GetSession(roomCode); err == nil {
+		return nil, nil
+	}
+
+	h.mu.RLock()
+	directory := h.directory
+	h.mu.RUnlock()
+	if directory == nil {
+		return nil, nil
+	}
+
+	owner, err := directory.Owner(ctx, roomCode)
+	if err != nil || owner == nil || owner.ID == directory.Self().ID {
+		return nil, err
+	}
+	if owner.Alive {
+		return owner, nil
+	}
+
+	if _, err := h.takeOverRoom(ctx, roomCode, owner.ID); err != nil && err != domain.ErrGameNotFound {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// takeOverRoom restores roomCode from the snapshot of the dead node that owns
+// it and makes this node its owner
+func (h *GameHub) takeOverRoom(ctx context.Context, roomCode, deadNodeID string) (*GameSession, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.nodeSnapshots == nil {
+		return nil, domain.ErrGameNotFound
+	}
+
+	data, err := h.nodeSnapshots.LoadNodeSnapshot(ctx, deadNodeID)
+	if err != nil || data == nil {
+		return nil, orGameNotFound(err)
+	}
+
+	var snapshot hubSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	for _, state := range snapshot.Sessions {
+		var room struct {
+			Game struct {
+				Game struct {
+					ID string `json:"id"`
+				} `json:"game"`
+			} `json:"game"`
+		}
+		if json.Unmarshal(state, &room) != nil || room.Game.Game.ID != roomCode {
+			continue
+		}
+
+		session, err := restoreSession(state, h.bus, h.broadcaster, h.signer, h.quotas, h.logger)
+		if err != nil {
+			return nil, err
+		}
+
+		// Another node may have taken the room over first
+		won, err := h.directory.TakeOver(ctx, roomCode, deadNodeID)
+		if err != nil || !won {
+			session.Close()
+			return nil, orGameNotFound(err)
+		}
+
+		session.setRecorder(h.recorder)
+		session.setErrorReporter(h.reporter)
+		session.setHooks(h.hooks)
+		session.setPresence(h.presence)
+		session.setWords(h.wordSources)
+		session.setProfanityFilter(h.profanity)
+		session.setReplays(h.replays)
+		if !h.addSession(session) {
+			// Taken over concurrently by this node
+			session.Close()
+			if existing := h.lookupSession(roomCode); existing != nil {
+				return existing, nil
+			}
+			return nil, domain.ErrGameNotFound
+		}
+		h.sessionCount.Add(1)
+
+		h.logger.Info("room taken over", "roomCode", roomCode, "from", deadNodeID, "savedAt", snapshot.SavedAt)
+		return session, nil
+	}
+
+	return nil, domain.ErrGameNotFound
+}
+
+// orGameNotFound returns err, or ErrGameNotFound if it is nil
+func orGameNotFound(err error) error {
+	if err != nil {
+		return err
+	}
+	return domain.ErrGameNotFound
+}
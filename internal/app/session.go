@@ -1,13 +1,68 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+
 	"imposter/internal/domain"
+	"imposter/internal/i18n"
+)
+
+// Invite lifetimes
+const (
+	DefaultInviteTTL = time.Hour      // Used when the host does not choose one
+	MaxInviteTTL     = 24 * time.Hour // Longest lifetime a host may choose
 )
 
+// RoomSnapshot is a point-in-time, read-only copy of a session's public state
+type RoomSnapshot struct {
+	RoomCode       string              `json:"roomCode"`
+	Phase          domain.Phase        `json:"phase"`
+	HostID         string              `json:"hostId"`
+	Players        []domain.PlayerInfo `json:"players"`
+	Rounds         []RoundSummary      `json:"rounds"` // Completed rounds only
+	CanJoin        bool                `json:"canJoin"`
+	Locked         bool                `json:"locked"`
+	Visibility     domain.Visibility   `json:"visibility"`
+	Language       string              `json:"language,omitempty"`
+	WordSource     string              `json:"wordSource,omitempty"` // Empty uses the server default
+	Tags           []string            `json:"tags,omitempty"`
+	FamilyFriendly bool                `json:"familyFriendly,omitempty"`
+	Settings       domain.GameSettings `json:"settings"`
+	Spectators     int                 `json:"spectators"` // Watching through this instance
+	CreatedAt      time.Time           `json:"createdAt"`
+}
+
+// SessionDetails is an operator's view of a session, including state hidden from players
+type SessionDetails struct {
+	*RoomSnapshot
+	CurrentRound     *domain.Round   `json:"currentRound,omitempty"`
+	ConnectedPlayers []string        `json:"connectedPlayers"`
+	EventQueue       EventQueueStats `json:"eventQueue"`
+	Metrics          RoomMetrics     `json:"metrics"`
+}
+
+// RoundSummary describes a completed round. Everything here was already
+// revealed to players on the results screen.
+type RoundSummary struct {
+	Number      int                 `json:"number"`
+	SecretWord  string              `json:"secretWord"`
+	ImposterID  string              `json:"imposterId"` // The first imposter
+	ImposterIDs []string            `json:"imposterIds"`
+	Winner      domain.Role         `json:"winner"`
+	Submissions []domain.Submission `json:"submissions"`
+	StartedAt   time.Time           `json:"startedAt"`
+	EndedAt     time.Time           `json:"endedAt"`
+}
+
 // ClientConnection represents a connected client
 type ClientConnection interface {
 	Send(message interface{}) error
@@ -15,44 +70,191 @@ type ClientConnection interface {
 	Close() error
 }
 
-// GameSession wraps a game with concurrency control and client management
+// GameSession wraps a game with client management. A single goroutine, the
+// session's actor, owns the game, connections, event buffers and timers: public
+// methods send it a command and wait for the reply, and timers fire by sending
+// commands too, so no state is shared between goroutines. Unexported methods
+// other than call run on the actor.
 type GameSession struct {
-	game      *domain.Game
-	mu        sync.RWMutex
-	clients   map[string]ClientConnection // playerID -> client
-	clientsMu sync.RWMutex
-	logger    *slog.Logger
-
-	// Timers
-	votingTimer   *time.Timer
-	countdownDone chan struct{}
-
-	// Event channel for broadcasting
-	events chan *domain.GameEvent
+	game    *domain.Game
+	clients map[string]ClientConnection // playerID -> client
+
+	// Read-only connections receiving public events
+	spectators map[string]ClientConnection // connection ID -> client
+
+	// Spectators' and chat viewers' votes in the current vote
+	audience *audienceVotes
+
+	// Computer players, which are also in clients
+	bots map[string]*Bot // playerID -> bot
+
+	// Fills the lobby with bots once a player joins (see RoomOptions.FillBots)
+	fillBotsOnJoin bool
+
+	bus    EventBus
+	signer *TokenSigner
+
+	// Carries this room's events to clients on every instance
+	broadcaster Broadcaster
+	unsubscribe func()
+
+	// Records which players are connected, for every instance to see
+	presence Presence
+
+	logger *slog.Logger
+
+	// Persists finished rounds and the game when the session closes (nil disables).
+	// recordID distinguishes this game from later ones reusing the room code.
+	recorder GameRecorder
+	recordID string
+
+	// Where panics and unexpected errors are reported (nil disables)
+	reporter ErrorReporter
+
+	// Saves the game's public events for playback (nil disables)
+	replays      ReplayStore
+	replayEvents []*domain.GameEvent
+
+	// Deployment hooks notified of the game's lifecycle
+	hooks []GameHooks
+
+	// Caps on connections and queued events
+	quotas RoomQuotas
+
+	// Where players connect from, for cheat detection
+	clientIPs map[string]string // playerID -> IP
+
+	// The providers the game's word source is chosen from
+	words *WordSources
+
+	// Checks nicknames and clues
+	profanity *ProfanityFilter
+
+	// Bearer tokens for REST clients
+	tokens map[string]string // token -> playerID
+
+	// Per-player event logs for long-polling clients
+	buffers map[string]*EventBuffer // playerID -> buffer
+
+	// Critical events sent to clients that acknowledge events, until they do
+	unacked map[string][]*unackedEvent // playerID -> events
+
+	// When any event other than an expiry warning was last queued (Unix nanoseconds),
+	// and whether players were warned since then that the idle room will be reaped
+	lastActivity atomic.Int64
+	expiryWarned atomic.Bool
+
+	// When the last connection closed or a REST client last polled (Unix
+	// nanoseconds); 0 while any connection is open
+	disconnectedAt atomic.Int64
+
+	// Every timer the session runs: the one driving the current phase and
+	// the one resending unacknowledged events
+	timers          *sessionTimers
+	votingStartedAt time.Time
+
+	// Activity counters for operators
+	counters roomCounters
+
+	// Commands for the actor; closed is set once it has shut the session down
+	commands chan command
+	closed   bool
+
+	// Events waiting to be broadcast
+	events *eventQueue
 	done   chan struct{}
 }
 
+// command is a unit of work for the session's actor
+type command struct {
+	fn   func()
+	done chan bool // Receives whether fn ran
+}
+
 // NewGameSession creates a new game session
-func NewGameSession(game *domain.Game, logger *slog.Logger) *GameSession {
+func NewGameSession(game *domain.Game, bus EventBus, broadcaster Broadcaster, signer *TokenSigner, quotas RoomQuotas, logger *slog.Logger) *GameSession {
 	session := &GameSession{
-		game:    game,
-		clients: make(map[string]ClientConnection),
-		logger:  logger,
-		events:  make(chan *domain.GameEvent, 100),
-		done:    make(chan struct{}),
+		game:        game,
+		clients:     make(map[string]ClientConnection),
+		spectators:  make(map[string]ClientConnection),
+		audience:    newAudienceVotes(),
+		bots:        make(map[string]*Bot),
+		bus:         bus,
+		broadcaster: broadcaster,
+		presence:    NewLocalPresence(),
+		signer:      signer,
+		logger:      logger,
+		tokens:      make(map[string]string),
+		buffers:     make(map[string]*EventBuffer),
+		unacked:     make(map[string][]*unackedEvent),
+		clientIPs:   make(map[string]string),
+		events:      newEventQueue(max(quotas.EventQueueSize, eventQueueHeadroom)),
+		quotas:      quotas,
+		words:       DefaultWordSources(),
+		commands:    make(chan command),
+		done:        make(chan struct{}),
+		recordID:    uuid.New().String(),
 	}
 
+	session.timers = newSessionTimers(session)
+	game.SetEmitter(session.queueEvent)
+	game.SetTextFilter(session.allowsText)
+	session.lastActivity.Store(time.Now().UnixNano())
+	session.disconnectedAt.Store(time.Now().UnixNano())
+
+	go session.run()
+
+	// Deliver the room's events, wherever they were published, to local clients.
+	// Only the first Close may unsubscribe, since the code can be reused.
+	session.unsubscribe = sync.OnceFunc(broadcaster.Subscribe(game.ID, session.broadcastEvent))
+
 	// Start event broadcaster
 	go session.eventLoop()
 
 	return session
 }
 
-// GetGame returns the underlying game (read-only operations should use specific methods)
-func (s *GameSession) GetGame() *domain.Game {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.game
+// run executes commands one at a time until the session is closed
+func (s *GameSession) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case cmd := <-s.commands:
+			if s.closed {
+				cmd.done <- false
+				continue
+			}
+			s.runCommand(cmd.fn)
+			cmd.done <- true
+		}
+	}
+}
+
+// runCommand runs fn, recovering from a panic so that one failed action
+// neither takes down the actor nor leaves its caller waiting forever
+func (s *GameSession) runCommand(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			s.logger.Error("session command panicked", "roomCode", s.game.ID, "phase", s.game.Phase, "panic", r, "stack", string(err.Stack))
+			s.reportError(err, map[string]string{"source": "session"})
+		}
+	}()
+	fn()
+}
+
+// call runs fn on the actor and waits for it to finish. It returns false
+// without running fn if the session is closed. fn must not call back into
+// the session's public methods.
+func (s *GameSession) call(fn func()) bool {
+	cmd := command{fn: fn, done: make(chan bool, 1)}
+	select {
+	case s.commands <- cmd:
+		return <-cmd.done
+	case <-s.done:
+		return false
+	}
 }
 
 // GetRoomCode returns the room code
@@ -60,127 +262,644 @@ func (s *GameSession) GetRoomCode() string {
 	return s.game.ID
 }
 
+// Locale returns the locale the room's language selects for server messages,
+// whatever each player's own, or an empty string if it selects none
+func (s *GameSession) Locale() string {
+	return i18n.Match(s.game.Language)
+}
+
 // GetCreatedAt returns when the game was created
 func (s *GameSession) GetCreatedAt() time.Time {
 	return s.game.CreatedAt
 }
 
+// LastActivity returns when the room last changed, e.g. a player joined or acted
+func (s *GameSession) LastActivity() time.Time {
+	return time.Unix(0, s.lastActivity.Load())
+}
+
+// DisconnectedSince returns when the room was last used by a client: when its
+// last connection closed or a REST client last polled. It returns false while
+// any connection is open.
+func (s *GameSession) DisconnectedSince() (time.Time, bool) {
+	at := s.disconnectedAt.Load()
+	if at == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, at), true
+}
+
+// markDisconnected records that the last connection closed, if it did
+func (s *GameSession) markDisconnected() {
+	if s.connectionCount() == 0 {
+		s.disconnectedAt.Store(time.Now().UnixNano())
+	}
+}
+
+// warnExpiring tells players once per idle stretch that the room will be
+// reaped at expiresAt unless someone acts
+func (s *GameSession) warnExpiring(expiresAt time.Time) {
+	if s.expiryWarned.Swap(true) {
+		return
+	}
+
+	s.logger.Info("idle room expiring soon", "roomCode", s.game.ID, "expiresAt", expiresAt)
+	s.queueEvent(domain.NewEvent(domain.EventRoomExpiring, s.game.ID, &domain.RoomExpiringPayload{
+		ExpiresAt: expiresAt,
+	}))
+}
+
+// setWords sets the providers the game's word source is chosen from
+func (s *GameSession) setWords(sources *WordSources) {
+	s.call(func() {
+		s.words = sources
+	})
+}
+
+// setProfanityFilter sets the filter nicknames and clues are checked with
+func (s *GameSession) setProfanityFilter(filter *ProfanityFilter) {
+	s.call(func() {
+		s.profanity = filter
+	})
+}
+
+// allowsText returns true if a player may use text, a nickname or clue, in the room
+func (s *GameSession) allowsText(text string) bool {
+	return s.profanity == nil || s.profanity.Allows(text, s.game.FamilyFriendly)
+}
+
+// setRecorder sets where the session records its finished rounds and game
+func (s *GameSession) setRecorder(recorder GameRecorder) {
+	s.call(func() {
+		s.recorder = recorder
+	})
+}
+
+// setPresence sets where the session records its connected players. Call it
+// before any client registers.
+func (s *GameSession) setPresence(presence Presence) {
+	s.call(func() {
+		s.presence = presence
+	})
+}
+
 // GetPlayerCount returns the number of players
 func (s *GameSession) GetPlayerCount() int {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return len(s.game.Players)
+	var count int
+	s.call(func() {
+		count = len(s.game.Players)
+	})
+	return count
+}
+
+// RoomCounts counts a room's players and spectators
+type RoomCounts struct {
+	Phase            domain.Phase
+	Players          int
+	ConnectedPlayers int // Players currently connected, bots included
+	Spectators       int // Watching through this instance
+}
+
+// Counts returns the room's phase and how many players and spectators it has
+func (s *GameSession) Counts() RoomCounts {
+	var counts RoomCounts
+	s.call(func() {
+		counts = RoomCounts{
+			Phase:            s.game.Phase,
+			Players:          len(s.game.Players),
+			ConnectedPlayers: s.game.GetConnectedPlayerCount(),
+			Spectators:       len(s.spectators),
+		}
+	})
+	return counts
 }
 
 // GetPhase returns the current game phase
 func (s *GameSession) GetPhase() domain.Phase {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.game.Phase
+	var phase domain.Phase
+	s.call(func() {
+		phase = s.game.Phase
+	})
+	return phase
+}
+
+// Snapshot returns a copy of the session's public state
+func (s *GameSession) Snapshot() *RoomSnapshot {
+	var snapshot *RoomSnapshot
+	if !s.call(func() {
+		snapshot = s.snapshot()
+	}) {
+		return &RoomSnapshot{RoomCode: s.game.ID, Players: []domain.PlayerInfo{}, Rounds: []RoundSummary{}, CreatedAt: s.game.CreatedAt}
+	}
+	return snapshot
+}
+
+// snapshot copies the session's public state
+func (s *GameSession) snapshot() *RoomSnapshot {
+	rounds := make([]RoundSummary, 0, len(s.game.RoundHistory))
+	for _, round := range s.game.RoundHistory {
+		submissions := make([]domain.Submission, 0, len(round.Submissions))
+		for _, sub := range round.Submissions {
+			submissions = append(submissions, *sub)
+		}
+		rounds = append(rounds, RoundSummary{
+			Number:      round.Number,
+			SecretWord:  round.SecretWord,
+			ImposterID:  round.ImposterID,
+			ImposterIDs: round.Imposters(),
+			Winner:      round.Winner,
+			Submissions: submissions,
+			StartedAt:   round.StartedAt,
+			EndedAt:     round.EndedAt,
+		})
+	}
+
+	return &RoomSnapshot{
+		RoomCode:       s.game.ID,
+		Phase:          s.game.Phase,
+		HostID:         s.game.HostID,
+		Players:        s.game.GetPlayerInfoList(),
+		Rounds:         rounds,
+		CanJoin:        s.game.Phase == domain.PhaseLobby && len(s.game.Players) < s.game.Settings.MaxPlayers,
+		Locked:         s.game.IsLocked(),
+		Visibility:     s.game.Visibility,
+		Language:       s.game.Language,
+		WordSource:     s.game.WordSource,
+		Tags:           s.game.Tags,
+		FamilyFriendly: s.game.FamilyFriendly,
+		Settings:       s.game.Settings,
+		Spectators:     len(s.spectators),
+		CreatedAt:      s.game.CreatedAt,
+	}
+}
+
+// Inspect returns the session's full state for administration. Connected
+// players include those connected to the room on other instances.
+func (s *GameSession) Inspect() *SessionDetails {
+	details := &SessionDetails{
+		ConnectedPlayers: []string{},
+		EventQueue:       s.EventQueueStats(),
+		Metrics:          s.Metrics(),
+	}
+	var presence Presence
+	if !s.call(func() {
+		details.RoomSnapshot = s.snapshot()
+		details.CurrentRound = s.game.CurrentRound
+		for playerID := range s.clients {
+			details.ConnectedPlayers = append(details.ConnectedPlayers, playerID)
+		}
+		presence = s.presence
+	}) {
+		details.RoomSnapshot = s.Snapshot()
+	}
+	sort.Strings(details.ConnectedPlayers)
+
+	// Outside the actor, since a shared presence store may be remote
+	if presence != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), broadcastTimeout)
+		defer cancel()
+
+		members, err := presence.Members(ctx, s.game.ID)
+		if err != nil {
+			s.logger.Warn("failed to read room presence, listing local players", "roomCode", s.game.ID, "error", err)
+		} else {
+			details.ConnectedPlayers = members
+		}
+	}
+
+	return details
+}
+
+// EventQueueStats returns how many of the session's events were coalesced or shed
+func (s *GameSession) EventQueueStats() EventQueueStats {
+	return s.events.stats()
 }
 
 // CanJoin checks if a new player can join the game
 func (s *GameSession) CanJoin() bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.game.Phase == domain.PhaseLobby && len(s.game.Players) < s.game.Settings.MaxPlayers
+	var canJoin bool
+	s.call(func() {
+		canJoin = s.game.Phase == domain.PhaseLobby && len(s.game.Players) < s.game.Settings.MaxPlayers
+	})
+	return canJoin
+}
+
+// CheckPassword returns true if password unlocks the room (always true if it is not locked)
+func (s *GameSession) CheckPassword(password string) bool {
+	var ok bool
+	s.call(func() {
+		ok = s.game.CheckPassword(password)
+	})
+	return ok
+}
+
+// HasPlayer returns true if playerID has joined the game
+func (s *GameSession) HasPlayer(playerID string) bool {
+	var ok bool
+	s.call(func() {
+		_, err := s.game.GetPlayer(playerID)
+		ok = err == nil
+	})
+	return ok
+}
+
+// IsLocked returns true if joining requires the room password
+func (s *GameSession) IsLocked() bool {
+	var locked bool
+	s.call(func() {
+		locked = s.game.IsLocked()
+	})
+	return locked
 }
 
 // RegisterClient registers a client connection for a player
 func (s *GameSession) RegisterClient(playerID string, client ClientConnection) {
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	s.clients[playerID] = client
+	s.call(func() {
+		s.setClient(playerID, client)
+
+		// The new connection's snapshot holds the role and results instead
+		delete(s.unacked, playerID)
+	})
+}
+
+// ResumeClient registers client for a reconnecting player and replays the
+// events delivered after since, so a short disconnect needs no state snapshot.
+// It returns false without registering if some of those events were discarded.
+func (s *GameSession) ResumeClient(playerID string, client ClientConnection, since uint64) bool {
+	resumed := false
+	s.call(func() {
+		// Events are delivered on the actor, so live ones follow the replayed ones
+		buffer, ok := s.buffers[playerID]
+		if !ok {
+			return
+		}
+
+		events, missed, _ := buffer.Since(since)
+		if missed {
+			return
+		}
+
+		for _, e := range events {
+			if err := client.Send(e.Event.WithSeq(e.Seq)); err != nil {
+				s.logger.Debug("failed to replay event", "playerID", playerID, "seq", e.Seq, "error", err)
+				return
+			}
+		}
+
+		s.setClient(playerID, client)
+		s.restartAcks(playerID)
+		resumed = true
+	})
+	return resumed
+}
+
+// LastSeq returns the sequence number of the last event delivered to a player
+func (s *GameSession) LastSeq(playerID string) uint64 {
+	var seq uint64
+	s.call(func() {
+		if buffer, ok := s.buffers[playerID]; ok {
+			seq = buffer.LastSeq()
+		}
+	})
+	return seq
 }
 
 // UnregisterClient removes a client connection
 func (s *GameSession) UnregisterClient(playerID string) {
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	delete(s.clients, playerID)
+	s.call(func() {
+		if _, ok := s.clients[playerID]; ok {
+			delete(s.clients, playerID)
+			s.presence.Leave(s.game.ID, playerID)
+			s.markDisconnected()
+		}
+	})
+}
+
+// setClient makes client the player's connection, replacing any other
+func (s *GameSession) setClient(playerID string, client ClientConnection) {
+	if _, ok := s.clients[playerID]; !ok {
+		s.presence.Join(s.game.ID, playerID)
+	}
+	s.clients[playerID] = client
+	s.disconnectedAt.Store(0)
+}
+
+// AddSpectator registers a read-only connection for public events. It returns
+// false if the room already has limit spectators or is at its connection quota.
+func (s *GameSession) AddSpectator(connID string, client ClientConnection, limit int) bool {
+	added := false
+	s.call(func() {
+		if len(s.spectators) >= limit {
+			return
+		}
+		if s.quotas.MaxConnections > 0 && s.connectionCount() >= s.quotas.MaxConnections {
+			return
+		}
+		s.spectators[connID] = client
+		s.disconnectedAt.Store(0)
+		added = true
+	})
+	return added
+}
+
+// RemoveSpectator removes a read-only connection
+func (s *GameSession) RemoveSpectator(connID string) {
+	s.call(func() {
+		if _, ok := s.spectators[connID]; ok {
+			delete(s.spectators, connID)
+			s.markDisconnected()
+		}
+	})
 }
 
 // GetClient returns the client for a player
 func (s *GameSession) GetClient(playerID string) (ClientConnection, bool) {
-	s.clientsMu.RLock()
-	defer s.clientsMu.RUnlock()
-	client, ok := s.clients[playerID]
+	var (
+		client ClientConnection
+		ok     bool
+	)
+	s.call(func() {
+		client, ok = s.clients[playerID]
+	})
 	return client, ok
 }
 
-// AddPlayer adds a player to the game
-func (s *GameSession) AddPlayer(playerID, nickname string) (*domain.Player, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// AddPlayer adds a player to the game. Locked rooms require the password or
+// an active invite; the invite is only used up if the player joins.
+func (s *GameSession) AddPlayer(playerID, nickname, password, invite string) (*domain.Player, error) {
+	var (
+		player *domain.Player
+		err    error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		player, err = s.addPlayer(playerID, nickname, password, invite)
+	})
+	return player, err
+}
+
+// addPlayer adds a player to the game
+func (s *GameSession) addPlayer(playerID, nickname, password, invite string) (*domain.Player, error) {
+	if err := s.checkEventQuota(); err != nil {
+		return nil, err
+	}
+
+	if s.game.Practice && s.game.GetHumanCount() > 0 {
+		return nil, domain.ErrPracticeRoom
+	}
+
+	redeem := false
+	if !s.game.CheckPassword(password) {
+		if invite == "" {
+			return nil, domain.ErrWrongPassword
+		}
+		if err := s.game.CheckInvite(invite, nickname); err != nil {
+			return nil, err
+		}
+		redeem = true
+	}
 
 	player, err := s.game.AddPlayer(playerID, nickname)
 	if err != nil {
 		return nil, err
 	}
 
-	// Broadcast lobby update
-	s.queueEvent(domain.NewEvent(domain.EventPlayerJoined, s.game.ID, s.game.GetLobbyState()))
+	if redeem {
+		s.game.RedeemInvite(invite)
+	}
+
+	s.buffers[playerID] = NewEventBuffer(DefaultEventBufferSize)
+	s.playerJoined(player)
+
+	if s.game.Practice {
+		s.fillPracticeBots()
+	}
+	if s.fillBotsOnJoin {
+		s.fillBotsOnJoin = false
+		if _, err := s.fillBots(); err != nil {
+			s.logger.Warn("failed to fill lobby with bots", "roomCode", s.game.ID, "error", err)
+		}
+	}
 
 	return player, nil
 }
 
+// playerJoined announces a player who just joined the game
+func (s *GameSession) playerJoined(player *domain.Player) {
+	// Broadcast lobby update
+	event := domain.NewEvent(domain.EventPlayerJoined, s.game.ID, s.game.GetLobbyState())
+	event.Delta = s.game.GetLobbyDelta([]string{player.ID}, nil)
+	s.queueEvent(event)
+
+	if len(s.hooks) > 0 {
+		info := &PlayerJoinedInfo{
+			GameID:      s.recordID,
+			RoomCode:    s.game.ID,
+			Player:      RecordedPlayer{ID: player.ID, Nickname: player.Nickname},
+			PlayerCount: len(s.game.Players),
+			JoinedAt:    time.Now(),
+		}
+		s.runHooks(func(hooks GameHooks) { hooks.OnPlayerJoined(info) })
+	}
+}
+
+// CreateInvite issues an invite to the room on behalf of the host. A zero ttl
+// uses DefaultInviteTTL and longer ones are capped at MaxInviteTTL; maxUses 0
+// allows any number of joins until the invite expires.
+func (s *GameSession) CreateInvite(playerID string, ttl time.Duration, maxUses int) (*domain.Invite, error) {
+	var (
+		invite *domain.Invite
+		err    error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		if !s.game.IsHost(playerID) {
+			err = domain.ErrNotHost
+			return
+		}
+
+		if ttl <= 0 {
+			ttl = DefaultInviteTTL
+		}
+		created := *s.game.CreateInvite(playerID, min(ttl, MaxInviteTTL), maxUses)
+		invite, err = &created, nil
+
+		s.logger.Info("invite created", "roomCode", s.game.ID, "expiresAt", created.ExpiresAt, "maxUses", maxUses)
+	})
+	return invite, err
+}
+
+// CreateNamedInvite issues an invite to the room that only a player joining
+// with nickname can redeem, such as a tournament entrant's seat. It is for the
+// server's own use, so no player need be the host.
+func (s *GameSession) CreateNamedInvite(nickname string, ttl time.Duration) domain.Invite {
+	var invite domain.Invite
+	s.call(func() {
+		invite = *s.game.CreateNamedInvite("", nickname, ttl)
+	})
+	return invite
+}
+
+// ListInvites returns the room's invites that can still be redeemed
+func (s *GameSession) ListInvites() []domain.Invite {
+	var invites []domain.Invite
+	s.call(func() {
+		invites = s.game.ActiveInvites()
+	})
+	return invites
+}
+
+// RevokeInvite deletes an invite, returning false if it does not exist
+func (s *GameSession) RevokeInvite(token string) bool {
+	revoked := false
+	s.call(func() {
+		revoked = s.game.RevokeInvite(token)
+	})
+	return revoked
+}
+
 // RemovePlayer removes a player from the game
 func (s *GameSession) RemovePlayer(playerID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if err = s.game.RemovePlayer(playerID); err != nil {
+			return
+		}
+
+		delete(s.buffers, playerID)
+		delete(s.unacked, playerID)
+		s.detachBot(playerID)
+
+		// Broadcast lobby update
+		event := domain.NewEvent(domain.EventPlayerLeft, s.game.ID, s.game.GetLobbyState())
+		event.Delta = s.game.GetLobbyDelta(nil, []string{playerID})
+		s.queueEvent(event)
+		s.votersChanged()
+	})
+	return err
+}
 
-	err := s.game.RemovePlayer(playerID)
+// IssuePlayerToken creates a bearer token that authenticates REST requests as the player
+func (s *GameSession) IssuePlayerToken(playerID string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if _, err = s.game.GetPlayer(playerID); err != nil {
+			return
+		}
+		s.tokens[token] = playerID
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// Broadcast lobby update
-	s.queueEvent(domain.NewEvent(domain.EventPlayerLeft, s.game.ID, s.game.GetLobbyState()))
+	return token, nil
+}
 
-	return nil
+// AuthenticatePlayer returns the player ID a bearer token was issued for
+func (s *GameSession) AuthenticatePlayer(token string) (string, error) {
+	var playerID string
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		var ok bool
+		if playerID, ok = s.tokens[token]; !ok {
+			err = domain.ErrPlayerNotFound
+			return
+		}
+
+		// Tokens of removed players are no longer valid
+		_, err = s.game.GetPlayer(playerID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return playerID, nil
+}
+
+// IssueReconnectToken creates a signed token that lets the player reconnect to this room
+func (s *GameSession) IssueReconnectToken(playerID string) string {
+	return s.signer.Sign(s.game.ID, playerID)
+}
+
+// VerifyReconnectToken returns the player a reconnect token was issued to
+func (s *GameSession) VerifyReconnectToken(token string) (string, error) {
+	return s.signer.Verify(token, s.game.ID)
 }
 
 // DisconnectPlayer marks a player as disconnected
 func (s *GameSession) DisconnectPlayer(playerID string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if player, err := s.game.GetPlayer(playerID); err == nil {
-		player.Disconnect()
-		s.queueEvent(domain.NewEvent(domain.EventPlayerLeft, s.game.ID, s.game.GetLobbyState()))
-	}
+	s.call(func() {
+		if player, err := s.game.GetPlayer(playerID); err == nil {
+			wasConnected := player.IsConnected()
+			player.Disconnect()
+			event := domain.NewEvent(domain.EventPlayerLeft, s.game.ID, s.game.GetLobbyState())
+			event.Delta = s.game.GetLobbyDelta([]string{playerID}, nil)
+			s.queueEvent(event)
+			if wasConnected {
+				s.votersChanged()
+			}
+		}
+	})
 }
 
 // ReconnectPlayer marks a player as reconnected
 func (s *GameSession) ReconnectPlayer(playerID string) (*domain.Player, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	player, err := s.game.GetPlayer(playerID)
-	if err != nil {
-		return nil, err
-	}
-
-	player.Reconnect()
-	s.queueEvent(domain.NewEvent(domain.EventPlayerReconnected, s.game.ID, s.game.GetLobbyState()))
+	var (
+		player *domain.Player
+		err    error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		if player, err = s.game.GetPlayer(playerID); err != nil {
+			return
+		}
 
-	return player, nil
+		wasConnected := player.IsConnected()
+		player.Reconnect()
+		s.counters.reconnects.Add(1)
+		event := domain.NewEvent(domain.EventPlayerReconnected, s.game.ID, s.game.GetLobbyState())
+		event.Delta = s.game.GetLobbyDelta([]string{playerID}, nil)
+		s.queueEvent(event)
+		if !wasConnected {
+			s.votersChanged()
+		}
+	})
+	return player, err
 }
 
 // StartGame starts the game (host only)
 func (s *GameSession) StartGame(playerID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
 
-	if !s.game.IsHost(playerID) {
-		return domain.ErrNotHost
-	}
+		if !s.game.IsHost(playerID) {
+			err = domain.ErrNotHost
+			return
+		}
+
+		err = s.startGame()
+	})
+	return err
+}
 
-	secretWord := GetRandomWord()
-	err := s.game.StartRound(secretWord)
+// startGame starts the first round
+func (s *GameSession) startGame() error {
+	err := s.game.StartRound(s.nextSecretWord())
 	if err != nil {
 		return err
 	}
+	s.roundStarted()
+
+	s.queueEvent(domain.NewEvent(domain.EventGameStarted, s.game.ID, &domain.GameStartedPayload{
+		RoundNumber: s.game.CurrentRound.Number,
+		PlayerCount: len(s.game.Players),
+	}))
 
 	// Send role assignments to each player
 	for pid, player := range s.game.Players {
@@ -194,23 +913,63 @@ func (s *GameSession) StartGame(playerID string) error {
 	}
 
 	// Schedule transition to submission phase
-	go func() {
-		time.Sleep(s.game.Settings.RoleRevealTime)
-		s.transitionToSubmission()
-	}()
+	s.scheduleSubmission(s.game.Settings.RoleRevealTime)
 
 	return nil
 }
 
+// nextSecretWord chooses the next round's secret word from the room's word
+// source in its language, avoiding words used in earlier rounds. The embedded list stands in
+// if the source fails, or the family list in a family-friendly room.
+func (s *GameSession) nextSecretWord() string {
+	usedWords := make([]string, 0, len(s.game.RoundHistory))
+	for _, round := range s.game.RoundHistory {
+		usedWords = append(usedWords, round.SecretWord)
+	}
+
+	word, err := s.words.ProviderFor(s.game.WordSource, s.game.Language).NextWord(s.game.Rand(), usedWords)
+	if err != nil {
+		fallback := EmbeddedWords
+		if s.game.FamilyFriendly {
+			fallback = FamilyProvider
+		}
+		s.logger.Warn("word source failed, using a built-in list", "roomCode", s.game.ID, "wordSource", s.game.WordSource, "language", s.game.Language, "error", err)
+		word, _ = fallback.NextWord(s.game.Rand(), usedWords)
+	}
+	return word
+}
+
+// roundStarted notifies the hooks of the round that just started
+func (s *GameSession) roundStarted() {
+	if len(s.hooks) == 0 {
+		return
+	}
+
+	round := s.game.CurrentRound
+	info := &RoundStartedInfo{
+		GameID:      s.recordID,
+		RoomCode:    s.game.ID,
+		Number:      round.Number,
+		SecretWord:  round.SecretWord,
+		ImposterIDs: round.Imposters(),
+		Players:     recordedPlayers(s.game, round.PlayerOrder),
+		StartedAt:   round.StartedAt,
+	}
+	s.runHooks(func(hooks GameHooks) { hooks.OnRoundStarted(info) })
+}
+
+// scheduleSubmission moves to the submission phase once roles have been shown for delay
+func (s *GameSession) scheduleSubmission(delay time.Duration) {
+	s.timers.start(timerPhase, delay, s.transitionToSubmission)
+}
+
 // transitionToSubmission moves to submission phase
 func (s *GameSession) transitionToSubmission() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if s.game.Phase != domain.PhaseRoleAssignment {
 		return
 	}
 
+	s.timers.cancel(timerPhase)
 	s.game.TransitionToSubmission()
 
 	// Build player order info
@@ -227,157 +986,192 @@ func (s *GameSession) transitionToSubmission() {
 		Submissions:     s.game.CurrentRound.Submissions,
 	}
 
-	s.queueEvent(domain.NewEvent(domain.EventSubmissionMade, s.game.ID, payload))
+	s.queueEvent(domain.NewEvent(domain.EventSubmissionPhaseStarted, s.game.ID, payload))
 }
 
 // SubmitWord submits a word for a player
 func (s *GameSession) SubmitWord(playerID, word string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	err := s.game.SubmitWord(playerID, word)
-	if err != nil {
-		return err
-	}
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
 
-	// Broadcast submission update
-	s.queueEvent(domain.NewEvent(domain.EventSubmissionMade, s.game.ID, s.game.GetSubmissionState()))
+		if err = s.game.SubmitWord(playerID, word); err != nil {
+			return
+		}
 
-	// Check if all submitted
-	if s.game.AllSubmitted() {
-		s.game.TransitionToVoting()
-		s.startVotingPhase()
-	}
+		// Broadcast submission update
+		event := domain.NewEvent(domain.EventSubmissionMade, s.game.ID, s.game.GetSubmissionState())
+		event.Delta = s.game.GetSubmissionDelta()
+		s.queueEvent(event)
 
-	return nil
+		// Check if all submitted
+		if s.game.AllSubmitted() {
+			s.game.TransitionToVoting()
+			s.startVotingPhase()
+		}
+	})
+	return err
 }
 
 // startVotingPhase starts the voting phase with countdown
 func (s *GameSession) startVotingPhase() {
-	// Already holding lock from caller
-
 	votingDuration := s.game.Settings.VotingDuration
-	remainingSeconds := int(votingDuration.Seconds())
+	s.votingStartedAt = time.Now()
+	s.audience.reset()
+	s.startCountdown(votingDuration)
 
-	// Broadcast voting phase start
+	// Broadcast voting phase start; clients count down to the deadline locally
 	payload := &domain.VotingPhasePayload{
-		RemainingSeconds: remainingSeconds,
+		RemainingSeconds: int(votingDuration.Seconds()),
+		Deadline:         s.timers.deadline(timerPhase),
 		Players:          s.game.GetPlayerInfoList(),
+		Candidates:       s.game.CurrentRound.Candidates,
 	}
 	s.queueEvent(domain.NewEvent(domain.EventVotingStarted, s.game.ID, payload))
-
-	// Start countdown
-	s.countdownDone = make(chan struct{})
-	go s.votingCountdown(remainingSeconds)
 }
 
-// votingCountdown runs the voting countdown
-func (s *GameSession) votingCountdown(seconds int) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	remaining := seconds
+// startCountdown ends the voting phase after duration
+func (s *GameSession) startCountdown(duration time.Duration) {
+	s.timers.start(timerPhase, duration, s.endVotingPhase)
+}
 
-	for {
-		select {
-		case <-s.countdownDone:
-			return
-		case <-s.done:
-			return
-		case <-ticker.C:
-			remaining--
-			if remaining <= 0 {
-				s.endVotingPhase()
-				return
-			}
+// restartCountdown gives the voting phase its full duration again, keeping
+// the votes already cast
+func (s *GameSession) restartCountdown() {
+	votingDuration := s.game.Settings.VotingDuration
+	s.startCountdown(votingDuration)
 
-			// Broadcast countdown
-			s.queueEvent(domain.NewEvent(domain.EventVoteCast, s.game.ID, &domain.VotingCountdownPayload{
-				RemainingSeconds: remaining,
-			}))
-		}
+	payload := &domain.VotingCountdownPayload{
+		RemainingSeconds: int(votingDuration.Seconds()),
+		Deadline:         s.timers.deadline(timerPhase),
 	}
+	s.queueEvent(domain.NewEvent(domain.EventVotingCountdown, s.game.ID, payload))
 }
 
 // CastVote casts a vote for a player
 func (s *GameSession) CastVote(voterID, targetID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
 
-	err := s.game.CastVote(voterID, targetID)
-	if err != nil {
-		return err
-	}
+		if err = s.game.CastVote(voterID, targetID); err != nil {
+			return
+		}
+		if !s.votingStartedAt.IsZero() {
+			s.recordVote(time.Since(s.votingStartedAt))
+		}
 
-	// Broadcast vote progress (without revealing who voted for whom)
-	s.queueEvent(domain.NewEvent(domain.EventVoteCast, s.game.ID, s.game.GetVoteProgress()))
+		// Broadcast vote progress (without revealing who voted for whom)
+		s.queueEvent(domain.NewEvent(domain.EventVoteCast, s.game.ID, s.game.GetVoteProgress()))
 
-	// Check if all voted - end early
-	if s.game.AllVoted() {
-		// Stop the countdown
-		if s.countdownDone != nil {
-			close(s.countdownDone)
-			s.countdownDone = nil
+		// Check if all voted - end early
+		if s.game.AllVoted() {
+			s.endVotingPhase()
 		}
-		s.endVotingPhaseUnlocked()
-	}
+	})
+	return err
+}
 
-	return nil
+// votersChanged follows a player disconnecting, reconnecting or leaving
+// during a vote: the vote ends if everyone it now waits for has voted, and
+// players are told the new progress otherwise
+func (s *GameSession) votersChanged() {
+	if s.game.Phase != domain.PhaseVoting {
+		return
+	}
+	if s.game.AllVoted() {
+		s.endVotingPhase()
+		return
+	}
+	s.queueEvent(domain.NewEvent(domain.EventVoteCast, s.game.ID, s.game.GetVoteProgress()))
 }
 
-// endVotingPhase ends the voting phase and shows results
+// endVotingPhase ends the vote: with a revote among the tied players if the
+// room's tie rule calls for one, or else by ending the round
 func (s *GameSession) endVotingPhase() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.endVotingPhaseUnlocked()
+	if s.game.Phase != domain.PhaseVoting {
+		return
+	}
+
+	if s.game.StartRevote() {
+		s.logger.Info("vote tied, holding a revote", "roomCode", s.game.ID, "candidates", s.game.CurrentRound.Candidates)
+		s.startVotingPhase()
+		return
+	}
+	s.endRound()
 }
 
-// endVotingPhaseUnlocked ends voting phase (caller must hold lock)
-func (s *GameSession) endVotingPhaseUnlocked() {
+// endRound ends the voting phase and shows results
+func (s *GameSession) endRound() {
 	if s.game.Phase != domain.PhaseVoting {
 		return
 	}
 
+	s.timers.cancel(timerPhase)
+
 	results, winner, err := s.game.EndRound()
 	if err != nil {
 		s.logger.Error("failed to end round", "error", err)
 		return
 	}
 
+	if s.recorder != nil || len(s.hooks) > 0 {
+		record := newRoundRecord(s.recordID, s.game, s.game.CurrentRound)
+		if s.recorder != nil {
+			s.recorder.RecordRound(record)
+		}
+		s.runHooks(func(hooks GameHooks) { hooks.OnRoundEnded(record) })
+	}
+
 	payload := &domain.RoundResultsPayload{
-		Votes:      results,
-		ImposterID: s.game.CurrentRound.ImposterID,
-		Winner:     winner,
-		SecretWord: s.game.CurrentRound.SecretWord,
+		Votes:       results,
+		ImposterID:  s.game.CurrentRound.ImposterID,
+		ImposterIDs: s.game.CurrentRound.Imposters(),
+		Winner:      winner,
+		SecretWord:  s.game.CurrentRound.SecretWord,
+		AccusedID:   s.game.CurrentRound.AccusedID,
+		Tied:        s.game.CurrentRound.Tied,
+		Audience:    s.audience.tally(),
 	}
 
 	s.queueEvent(domain.NewEvent(domain.EventRoundEnded, s.game.ID, payload))
+	s.detectCheats(s.game.CurrentRound)
 }
 
 // StartNewRound starts a new round (host only)
 func (s *GameSession) StartNewRound(playerID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		if err = s.checkEventQuota(); err != nil {
+			return
+		}
 
-	if !s.game.IsHost(playerID) {
-		return domain.ErrNotHost
-	}
+		if !s.game.IsHost(playerID) {
+			err = domain.ErrNotHost
+			return
+		}
 
-	if s.game.Phase != domain.PhaseResults {
-		return domain.ErrInvalidPhase
-	}
+		if s.game.Phase != domain.PhaseResults {
+			err = domain.ErrInvalidPhase
+			return
+		}
 
-	// Get words used in previous rounds to avoid repeats
-	usedWords := make([]string, 0, len(s.game.RoundHistory))
-	for _, round := range s.game.RoundHistory {
-		usedWords = append(usedWords, round.SecretWord)
-	}
+		err = s.startNewRound()
+	})
+	return err
+}
 
-	secretWord := GetRandomWordExcluding(usedWords)
-	err := s.game.StartRound(secretWord)
+// startNewRound starts the next round after results
+func (s *GameSession) startNewRound() error {
+	err := s.game.StartRound(s.nextSecretWord())
 	if err != nil {
 		return err
 	}
+	s.roundStarted()
 
 	// Send role assignments
 	for pid, player := range s.game.Players {
@@ -391,119 +1185,334 @@ func (s *GameSession) StartNewRound(playerID string) error {
 	}
 
 	// Schedule transition to submission
-	go func() {
-		time.Sleep(s.game.Settings.RoleRevealTime)
-		s.transitionToSubmission()
-	}()
+	s.scheduleSubmission(s.game.Settings.RoleRevealTime)
 
 	return nil
 }
 
-// GetGameState returns the current game state for a reconnecting player
-func (s *GameSession) GetGameState(playerID string) map[string]interface{} {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// ForcePhase moves the session to target without waiting for players or timers.
+// Used by operators to unstick a room; only transitions allowed by the domain are accepted.
+func (s *GameSession) ForcePhase(target domain.Phase) error {
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		err = s.forcePhase(target)
+	})
+	return err
+}
+
+// AdvancePhase moves the session on to the phase it would reach next, without
+// waiting for players or timers, and returns that phase
+func (s *GameSession) AdvancePhase() (domain.Phase, error) {
+	var next domain.Phase
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		next = s.game.Phase.Next()
+		err = s.forcePhase(next)
+	})
+	return next, err
+}
 
-	state := map[string]interface{}{
-		"phase":    s.game.Phase,
-		"players":  s.game.GetPlayerInfoList(),
-		"hostId":   s.game.HostID,
-		"canStart": s.game.CanStart(),
+// forcePhase moves the session to target
+func (s *GameSession) forcePhase(target domain.Phase) error {
+	if !s.game.Phase.CanTransitionTo(target) {
+		return domain.ErrInvalidTransition
 	}
 
-	// Add phase-specific state
-	switch s.game.Phase {
-	case domain.PhaseSubmission:
-		if s.game.CurrentRound != nil {
-			state["submissions"] = s.game.CurrentRound.Submissions
-			state["currentPlayerId"] = s.game.CurrentRound.GetCurrentPlayerID()
+	s.logger.Info("forcing phase transition", "roomCode", s.game.ID, "from", s.game.Phase, "to", target)
+
+	switch target {
+	case domain.PhaseRoleAssignment:
+		if s.game.Phase == domain.PhaseLobby {
+			return s.startGame()
 		}
+		return s.startNewRound()
+	case domain.PhaseSubmission:
+		s.transitionToSubmission()
 	case domain.PhaseVoting:
-		state["voteProgress"] = s.game.GetVoteProgress()
+		if err := s.game.TransitionToVoting(); err != nil {
+			return err
+		}
+		s.startVotingPhase()
 	case domain.PhaseResults:
-		if s.game.CurrentRound != nil {
-			results, _ := s.game.CurrentRound.CalculateResults(s.game.Players)
-			state["results"] = results
-			state["winner"] = s.game.CurrentRound.Winner
-			state["imposterId"] = s.game.CurrentRound.ImposterID
-			state["secretWord"] = s.game.CurrentRound.SecretWord
+		s.endRound()
+	case domain.PhaseLobby:
+		if err := s.game.ReturnToLobby(); err != nil {
+			return err
 		}
+		s.timers.cancel(timerPhase)
+		s.queueEvent(domain.NewEvent(domain.EventGameEnded, s.game.ID, s.game.GetLobbyState()))
 	}
 
-	// Add player's role if in game
-	if player, err := s.game.GetPlayer(playerID); err == nil && player.Role != "" {
-		state["role"] = player.Role
-		if player.Role == domain.RoleVilek && s.game.CurrentRound != nil {
-			state["secretWord"] = s.game.CurrentRound.SecretWord
+	return nil
+}
+
+// RestartTimer restarts the timer driving the current phase, replacing any running one
+func (s *GameSession) RestartTimer() error {
+	err := domain.ErrGameNotFound
+	s.call(func() {
+		switch s.game.Phase {
+		case domain.PhaseRoleAssignment:
+			s.scheduleSubmission(s.game.Settings.RoleRevealTime)
+		case domain.PhaseVoting:
+			s.restartCountdown()
+		default:
+			err = domain.ErrInvalidPhase
+			return
 		}
-	}
+
+		err = nil
+		s.logger.Info("phase timer restarted", "roomCode", s.game.ID, "phase", s.game.Phase)
+	})
+	return err
+}
+
+// GetGameState returns the current game state for a reconnecting player
+func (s *GameSession) GetGameState(playerID string) map[string]interface{} {
+	state := map[string]interface{}{}
+	s.call(func() {
+		state["phase"] = s.game.Phase
+		state["players"] = s.game.GetPlayerInfoList()
+		state["hostId"] = s.game.HostID
+		state["canStart"] = s.game.CanStart()
+		if id := s.replayID(); id != "" {
+			state["replayId"] = id
+		}
+
+		// Add phase-specific state
+		switch s.game.Phase {
+		case domain.PhaseSubmission:
+			if s.game.CurrentRound != nil {
+				state["submissions"] = s.game.CurrentRound.Submissions
+				state["currentPlayerId"] = s.game.CurrentRound.GetCurrentPlayerID()
+			}
+		case domain.PhaseVoting:
+			state["voteProgress"] = s.game.GetVoteProgress()
+			state["votingDeadline"] = s.timers.deadline(timerPhase)
+			if s.game.CurrentRound != nil && len(s.game.CurrentRound.Candidates) > 0 {
+				state["candidates"] = s.game.CurrentRound.Candidates
+			}
+		case domain.PhaseResults:
+			if s.game.CurrentRound != nil {
+				state["results"] = s.game.CurrentRound.Results(s.game.Players)
+				state["winner"] = s.game.CurrentRound.Winner
+				state["accusedId"] = s.game.CurrentRound.AccusedID
+				if len(s.game.CurrentRound.Tied) > 0 {
+					state["tied"] = s.game.CurrentRound.Tied
+				}
+				state["imposterId"] = s.game.CurrentRound.ImposterID
+				state["imposterIds"] = s.game.CurrentRound.Imposters()
+				state["secretWord"] = s.game.CurrentRound.SecretWord
+			}
+		}
+
+		// Add player's role if in game
+		if player, err := s.game.GetPlayer(playerID); err == nil && player.Role != "" {
+			state["role"] = player.Role
+			if player.Role == domain.RoleVilek && s.game.CurrentRound != nil {
+				state["secretWord"] = s.game.CurrentRound.SecretWord
+			}
+		}
+	})
 
 	return state
 }
 
-// queueEvent adds an event to the broadcast queue
+// GetPublicState returns the game state visible to spectators: no roles or secret word
+// until the round results reveal it
+func (s *GameSession) GetPublicState() map[string]interface{} {
+	return s.GetGameState("")
+}
+
+// PollEvents returns the events for a player with a sequence number greater than since,
+// waiting until at least one is available or ctx is done. missed is true if some
+// events were discarded and the client should resynchronize from GetGameState.
+func (s *GameSession) PollEvents(ctx context.Context, playerID string, since uint64) ([]SequencedEvent, uint64, bool, error) {
+	// Buffers are safe to read off the actor, so the wait does not block the session
+	var buffer *EventBuffer
+	s.call(func() {
+		buffer = s.buffers[playerID]
+		if buffer != nil && s.connectionCount() == 0 {
+			s.disconnectedAt.Store(time.Now().UnixNano())
+		}
+	})
+	if buffer == nil {
+		return nil, 0, false, domain.ErrPlayerNotFound
+	}
+
+	for {
+		events, missed, wait := buffer.Since(since)
+		if len(events) > 0 || missed {
+			return events, buffer.LastSeq(), missed, nil
+		}
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, buffer.LastSeq(), false, nil
+		case <-s.done:
+			return nil, buffer.LastSeq(), false, nil
+		}
+	}
+}
+
+// queueEvent adds an event to the broadcast queue. It never blocks, so it is
+// safe to call from the actor.
 func (s *GameSession) queueEvent(event *domain.GameEvent) {
-	select {
-	case s.events <- event:
-	default:
-		s.logger.Warn("event queue full, dropping event", "type", event.Type)
+	if event.Type != domain.EventRoomExpiring {
+		s.lastActivity.Store(time.Now().UnixNano())
+		s.expiryWarned.Store(false)
+	}
+
+	if !s.events.push(event) {
+		s.logger.Debug("event queue full, shedding low-priority event", "roomCode", s.game.ID, "type", event.Type)
 	}
 }
 
-// eventLoop processes events and broadcasts to clients
+// eventLoop processes events and broadcasts to clients. A panic while
+// broadcasting is reported and the loop restarted, since without it the room
+// would silently stop delivering events.
 func (s *GameSession) eventLoop() {
+	defer func() {
+		if r := recover(); r != nil {
+			err := newPanicError(r)
+			s.logger.Error("event loop panicked", "roomCode", s.game.ID, "panic", r, "stack", string(err.Stack))
+			s.ReportError(err, map[string]string{"source": "eventLoop"})
+			go s.eventLoop()
+		}
+	}()
+
 	for {
 		select {
 		case <-s.done:
 			return
-		case event := <-s.events:
-			s.broadcastEvent(event)
+		case <-s.events.ready:
+			for _, event := range s.events.drain() {
+				s.publishEvent(event)
+				s.bus.Publish(event)
+				s.counters.eventsBroadcast.Add(1)
+			}
 		}
 	}
 }
 
+// publishEvent hands an event to the broadcaster, falling back to local
+// delivery so this instance's clients are unaffected if it is unavailable
+func (s *GameSession) publishEvent(event *domain.GameEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), broadcastTimeout)
+	defer cancel()
+
+	if err := s.broadcaster.Publish(ctx, event); err != nil {
+		s.logger.Warn("failed to broadcast event, delivering locally", "roomCode", s.game.ID, "type", event.Type, "error", err)
+		s.broadcastEvent(event)
+	}
+}
+
 // broadcastEvent sends an event to appropriate clients
 func (s *GameSession) broadcastEvent(event *domain.GameEvent) {
-	s.clientsMu.RLock()
-	defer s.clientsMu.RUnlock()
+	s.call(func() {
+		s.deliverEvent(event)
+	})
+}
+
+// deliverEvent buffers an event and sends it to the connected clients. Running
+// on the actor keeps ResumeClient from missing or reordering events.
+func (s *GameSession) deliverEvent(event *domain.GameEvent) {
+	seqs := s.bufferEvent(event)
 
 	// If player-specific, send only to that player
 	if event.PlayerID != "" {
 		if client, ok := s.clients[event.PlayerID]; ok {
-			if err := client.Send(event); err != nil {
+			sequenced := event.WithSeq(seqs[event.PlayerID])
+			if err := client.Send(sequenced); err != nil {
+				s.counters.sendFailures.Add(1)
 				s.logger.Debug("failed to send to client", "playerID", event.PlayerID, "error", err)
 			}
+			if event.IsCritical() {
+				s.trackCritical(event.PlayerID, client, sequenced)
+			}
 		}
 		return
 	}
 
-	// Broadcast to all clients
+	// Broadcast to all clients, encoding the event once per wire format
+	frame := NewFrame(event)
 	for playerID, client := range s.clients {
-		if err := client.Send(event); err != nil {
+		if err := client.Send(frame.WithSeq(seqs[playerID])); err != nil {
+			s.counters.sendFailures.Add(1)
 			s.logger.Debug("failed to send to client", "playerID", playerID, "error", err)
 		}
+		if event.IsCritical() {
+			s.trackCritical(playerID, client, event.WithSeq(seqs[playerID]))
+		}
+	}
+
+	s.recordReplayEvent(event)
+
+	// Spectators only ever see public events
+	for _, spectator := range s.spectators {
+		if err := spectator.Send(frame); err != nil {
+			s.counters.sendFailures.Add(1)
+			s.logger.Debug("failed to send to spectator", "error", err)
+		}
+	}
+}
+
+// bufferEvent records an event in the buffers of the players it is addressed to
+// and returns the sequence number it was given for each of them
+func (s *GameSession) bufferEvent(event *domain.GameEvent) map[string]uint64 {
+	seqs := make(map[string]uint64, len(s.buffers))
+	if event.PlayerID != "" {
+		if buffer, ok := s.buffers[event.PlayerID]; ok {
+			seqs[event.PlayerID] = buffer.Append(event)
+		}
+		return seqs
+	}
+
+	for playerID, buffer := range s.buffers {
+		seqs[playerID] = buffer.Append(event)
 	}
+	return seqs
 }
 
-// Close shuts down the session
+// Close shuts down the session. Later calls, and calls to other methods,
+// have no effect.
 func (s *GameSession) Close() {
-	select {
-	case <-s.done:
-		return // Already closed
-	default:
+	// Outside the actor, since the broadcaster may be waiting on it to deliver an event
+	s.unsubscribe()
+
+	if s.call(s.shutdown) {
 		close(s.done)
 	}
+}
 
-	if s.countdownDone != nil {
-		close(s.countdownDone)
+// shutdown records the game and closes every connection
+func (s *GameSession) shutdown() {
+	s.closed = true
+	s.timers.stop()
+
+	if len(s.game.RoundHistory) > 0 {
+		record := &GameRecord{
+			GameID:    s.recordID,
+			RoomCode:  s.game.ID,
+			CreatedAt: s.game.CreatedAt,
+			EndedAt:   time.Now(),
+			Rounds:    len(s.game.RoundHistory),
+		}
+		if s.recorder != nil {
+			s.recorder.RecordGame(record)
+		}
+		s.runHooks(func(hooks GameHooks) { hooks.OnGameEnded(record) })
 	}
 
 	// Close all client connections
-	s.clientsMu.Lock()
-	for _, client := range s.clients {
+	for playerID, client := range s.clients {
 		client.Close()
+		s.presence.Leave(s.game.ID, playerID)
+	}
+	for _, spectator := range s.spectators {
+		spectator.Close()
 	}
 	s.clients = make(map[string]ClientConnection)
-	s.clientsMu.Unlock()
+	s.spectators = make(map[string]ClientConnection)
+	s.bots = make(map[string]*Bot)
 }
-
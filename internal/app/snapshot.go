@@ -0,0 +1,319 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// snapshotVersion is bumped when the snapshot format changes incompatibly
+const snapshotVersion = 1
+
+// SnapshotStore keeps the most recent snapshot of every active session so
+// games survive a restart
+type SnapshotStore interface {
+	SaveSnapshot(ctx context.Context, data []byte) error
+
+	// LoadSnapshot returns nil if no snapshot has been saved
+	LoadSnapshot(ctx context.Context) ([]byte, error)
+}
+
+// hubSnapshot is the saved state of all sessions
+type hubSnapshot struct {
+	Version  int               `json:"version"`
+	SavedAt  time.Time         `json:"savedAt"`
+	Sessions []json.RawMessage `json:"sessions"`
+}
+
+// RoomExport is one room's complete state, for moving the room to another
+// instance or reproducing a bug report locally. State contains secrets such as
+// reconnect tokens and the password hash.
+type RoomExport struct {
+	Version    int             `json:"version"`
+	RoomCode   string          `json:"roomCode"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	State      json.RawMessage `json:"state"`
+}
+
+// sessionSnapshot is the saved state of one session. Event buffers are not
+// kept: reconnecting clients receive the full game state instead of a replay.
+type sessionSnapshot struct {
+	Game     *domain.GameSnapshot `json:"game"`
+	RecordID string               `json:"recordId"`
+	Tokens   map[string]string    `json:"tokens"`
+
+	// When the phase timer advances the phase, for the role reveal and voting
+	// phases. A restored session waits only for what is left of it, so the
+	// time the room spent down counts against the phase.
+	TimerDeadline time.Time `json:"timerDeadline,omitempty"`
+
+	// Time left on the phase timer when saved, for snapshots written before
+	// TimerDeadline and instances that still read them
+	TimerRemaining time.Duration `json:"timerRemaining,omitempty"`
+}
+
+// timerRemaining returns how long the restored phase timer still has to run;
+// 0 if its deadline passed while the room was down
+func (s *sessionSnapshot) timerRemaining() time.Duration {
+	if s.TimerDeadline.IsZero() {
+		return s.TimerRemaining
+	}
+	return max(time.Until(s.TimerDeadline), 0)
+}
+
+// marshalSnapshot encodes the session's state
+func (s *GameSession) marshalSnapshot() ([]byte, error) {
+	var (
+		data []byte
+		err  error = domain.ErrGameNotFound
+	)
+	// Encoded on the actor, since the snapshot shares the game's data
+	s.call(func() {
+		snapshot := &sessionSnapshot{
+			Game:     s.game.Snapshot(),
+			RecordID: s.recordID,
+			Tokens:   s.tokens,
+
+			TimerDeadline:  s.timers.deadline(timerPhase),
+			TimerRemaining: s.timerRemaining(),
+		}
+
+		data, err = json.Marshal(snapshot)
+	})
+	return data, err
+}
+
+// timerRemaining returns the time left before the phase timer advances the
+// phase, or 0 in phases without one
+func (s *GameSession) timerRemaining() time.Duration {
+	return s.timers.remaining(timerPhase)
+}
+
+// Export returns the session's complete state
+func (s *GameSession) Export() (*RoomExport, error) {
+	data, err := s.marshalSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoomExport{
+		Version:    snapshotVersion,
+		RoomCode:   s.GetRoomCode(),
+		ExportedAt: time.Now(),
+		State:      data,
+	}, nil
+}
+
+// restoreSession rebuilds a session from its snapshot and resumes its phase
+// timer where it left off
+func restoreSession(data []byte, bus EventBus, broadcaster Broadcaster, signer *TokenSigner, quotas RoomQuotas, logger *slog.Logger) (*GameSession, error) {
+	var snapshot sessionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Game == nil || snapshot.Game.Game == nil {
+		return nil, fmt.Errorf("snapshot has no game")
+	}
+
+	game := domain.RestoreGame(snapshot.Game)
+	session := NewGameSession(game, bus, broadcaster, signer, quotas, logger)
+
+	session.call(func() {
+		if snapshot.RecordID != "" {
+			session.recordID = snapshot.RecordID
+		}
+		if snapshot.Tokens != nil {
+			session.tokens = snapshot.Tokens
+		}
+		for playerID, player := range game.Players {
+			// Bots play on the server, so they are back with it, while
+			// everyone else stays disconnected until they reconnect
+			if player.IsBot {
+				player.Reconnect()
+				session.attachBot(playerID)
+				continue
+			}
+			session.buffers[playerID] = NewEventBuffer(DefaultEventBufferSize)
+		}
+
+		switch game.Phase {
+		case domain.PhaseRoleAssignment:
+			session.scheduleSubmission(snapshot.timerRemaining())
+		case domain.PhaseVoting:
+			session.startCountdown(snapshot.timerRemaining())
+		}
+	})
+
+	return session, nil
+}
+
+// SaveSnapshot writes the state of every active session to store. It does
+// nothing once the hub is closed, so a late periodic save cannot replace the
+// shutdown snapshot with an empty one.
+func (h *GameHub) SaveSnapshot(ctx context.Context, store SnapshotStore) error {
+	h.snapshotMu.Lock()
+	defer h.snapshotMu.Unlock()
+
+	select {
+	case <-h.done:
+		return nil
+	default:
+	}
+	sessions := h.allSessions()
+
+	snapshot := &hubSnapshot{
+		Version:  snapshotVersion,
+		SavedAt:  time.Now(),
+		Sessions: make([]json.RawMessage, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		data, err := session.marshalSnapshot()
+		if err != nil {
+			h.logger.Error("failed to snapshot session", "roomCode", session.GetRoomCode(), "error", err)
+			continue
+		}
+		snapshot.Sessions = append(snapshot.Sessions, data)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return store.SaveSnapshot(ctx, data)
+}
+
+// DetachRecorder stops the current sessions from recording their games, or
+// reporting them to hooks, as ended when closed. Call it after saving the
+// shutdown snapshot, since those games continue once restored.
+func (h *GameHub) DetachRecorder() {
+	for _, session := range h.allSessions() {
+		session.setRecorder(nil)
+		session.setHooks(nil)
+	}
+}
+
+// RestoreSnapshot recreates the sessions saved in store, returning how many
+// were restored. Call it before the hub starts serving players.
+func (h *GameHub) RestoreSnapshot(ctx context.Context, store SnapshotStore) (int, error) {
+	data, err := store.LoadSnapshot(ctx)
+	if err != nil || data == nil {
+		return 0, err
+	}
+
+	var snapshot hubSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, err
+	}
+	if snapshot.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	restored := 0
+	for _, data := range snapshot.Sessions {
+		session, err := restoreSession(data, h.bus, h.broadcaster, h.signer, h.quotas, h.logger)
+		if err != nil {
+			h.logger.Error("failed to restore session", "error", err)
+			continue
+		}
+
+		// Another instance may have taken the code, or the room, over while
+		// this one was down
+		roomCode := session.GetRoomCode()
+		if reserved, err := h.reserveRoomCode(roomCode); err != nil {
+			h.logger.Warn("failed to reserve restored room code", "roomCode", roomCode, "error", err)
+		} else if !reserved {
+			h.logger.Info("restored room code is held by another instance, dropping the room", "roomCode", roomCode)
+			session.Close()
+			continue
+		}
+
+		session.setRecorder(h.recorder)
+		session.setErrorReporter(h.reporter)
+		session.setHooks(h.hooks)
+		session.setPresence(h.presence)
+		session.setWords(h.wordSources)
+		session.setProfanityFilter(h.profanity)
+		session.setReplays(h.replays)
+		if !h.addSession(session) {
+			session.Close()
+			continue
+		}
+		h.sessionCount.Add(1)
+		restored++
+	}
+
+	h.logger.Info("sessions restored", "count", restored, "savedAt", snapshot.SavedAt)
+	return restored, nil
+}
+
+// ImportSession recreates an exported room under its original code. Players
+// rejoin by reconnecting, which requires the exporting instance's TOKEN_SECRET.
+func (h *GameHub) ImportSession(export *RoomExport) (*GameSession, error) {
+	if export.Version != snapshotVersion || len(export.State) == 0 {
+		return nil, domain.ErrInvalidExport
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	session, err := restoreSession(export.State, h.bus, h.broadcaster, h.signer, h.quotas, h.logger)
+	if err != nil {
+		h.logger.Warn("failed to import room", "roomCode", export.RoomCode, "error", err)
+		return nil, domain.ErrInvalidExport
+	}
+
+	reserved, err := h.reserveRoomCode(session.GetRoomCode())
+	if err != nil || !reserved {
+		session.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRoomCodeTaken
+	}
+
+	session.setRecorder(h.recorder)
+	session.setErrorReporter(h.reporter)
+	session.setHooks(h.hooks)
+	session.setPresence(h.presence)
+	session.setWords(h.wordSources)
+	session.setProfanityFilter(h.profanity)
+	session.setReplays(h.replays)
+	if !h.addSession(session) {
+		session.Close()
+		return nil, domain.ErrRoomCodeTaken
+	}
+	h.sessionCount.Add(1)
+
+	roomCode := session.GetRoomCode()
+
+	h.logger.Info("game imported", "roomCode", roomCode, "exportedAt", export.ExportedAt)
+	h.bus.Publish(domain.NewEvent(domain.EventGameCreated, roomCode, nil))
+
+	return session, nil
+}
+
+// RunSnapshots saves a snapshot to store every interval until the hub is closed
+func (h *GameHub) RunSnapshots(store SnapshotStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := h.SaveSnapshot(ctx, store); err != nil {
+				h.logger.Warn("failed to save session snapshot", "error", err)
+			}
+			cancel()
+		}
+	}
+}
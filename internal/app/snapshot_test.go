@@ -0,0 +1,107 @@
+package app
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// votingGame returns a game in its voting phase with one human, hostID, and
+// three bots, the first of which has voted for the human
+func votingGame(t *testing.T) (game *domain.Game, hostID, botID string) {
+	t.Helper()
+
+	game = domain.NewGame("BOTSAV")
+	game.Settings.VoteQuorum = domain.QuorumConnected
+	if _, err := game.AddPlayer("human", "Human"); err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+	for _, id := range []string{"bot-1", "bot-2", "bot-3"} {
+		player, err := game.AddPlayer(id, "Bot "+id)
+		if err != nil {
+			t.Fatalf("AddPlayer: %v", err)
+		}
+		player.IsBot = true
+	}
+
+	if err := game.StartRound("apple"); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	if err := game.TransitionToSubmission(); err != nil {
+		t.Fatalf("TransitionToSubmission: %v", err)
+	}
+	if err := game.TransitionToVoting(); err != nil {
+		t.Fatalf("TransitionToVoting: %v", err)
+	}
+	if err := game.CastVote("bot-1", "human"); err != nil {
+		t.Fatalf("CastVote: %v", err)
+	}
+	return game, "human", "bot-1"
+}
+
+func TestRestoreSessionReconnectsBots(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	signer := NewTokenSigner(nil, 0)
+
+	game, hostID, botID := votingGame(t)
+	original := NewGameSession(game, NewLocalEventBus(), NewLocalBroadcaster(), signer, RoomQuotas{}, logger)
+	defer original.Close()
+
+	// The vote is still open when the room is restored
+	original.call(func() {
+		original.startCountdown(time.Minute)
+	})
+
+	data, err := original.marshalSnapshot()
+	if err != nil {
+		t.Fatalf("marshalSnapshot: %v", err)
+	}
+
+	restored, err := restoreSession(data, NewLocalEventBus(), NewLocalBroadcaster(), signer, RoomQuotas{}, logger)
+	if err != nil {
+		t.Fatalf("restoreSession: %v", err)
+	}
+	defer restored.Close()
+
+	// Read before the bots have had time to think, so only the vote cast
+	// before the snapshot is in
+	var (
+		disconnectedBots []string
+		hostConnected    bool
+		progress         *domain.VoteUpdatePayload
+		results          []domain.VoteResult
+		endErr           error
+	)
+	restored.call(func() {
+		game := restored.game
+		for id, player := range game.Players {
+			if player.IsBot && !player.IsConnected() {
+				disconnectedBots = append(disconnectedBots, id)
+			}
+		}
+		hostConnected = game.Players[hostID].IsConnected()
+		progress = game.GetVoteProgress()
+		results, _, endErr = game.EndRound()
+	})
+
+	if len(disconnectedBots) > 0 {
+		t.Errorf("bots %v are disconnected after the restore", disconnectedBots)
+	}
+	if hostConnected {
+		t.Errorf("human player is connected before reconnecting")
+	}
+	if progress.VotedCount != 1 || progress.TotalPlayers != 3 {
+		t.Errorf("vote progress = %d of %d, want 1 of 3", progress.VotedCount, progress.TotalPlayers)
+	}
+	if endErr != nil {
+		t.Fatalf("EndRound: %v", endErr)
+	}
+	for _, result := range results {
+		if result.PlayerID == hostID && result.VoteCount != 1 {
+			t.Errorf("human got %d votes, want %s's vote counted", result.VoteCount, botID)
+		}
+	}
+}
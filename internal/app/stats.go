@@ -0,0 +1,114 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// The hub samples its activity every statsInterval and keeps statsHistory
+// samples, an hour's worth, for the admin dashboard's graphs
+const (
+	statsInterval = 10 * time.Second
+	statsHistory  = 360
+)
+
+// HubSample is the hub's activity over one sampling interval
+type HubSample struct {
+	At                time.Time `json:"at"`
+	Rooms             int       `json:"rooms"`
+	Players           int       `json:"players"`
+	Connections       int       `json:"connections"` // Players and spectators, not bots
+	EventsPerSecond   float64   `json:"eventsPerSecond"`
+	MessagesPerSecond float64   `json:"messagesPerSecond"`
+}
+
+// hubStats holds the sampled history
+type hubStats struct {
+	mu      sync.Mutex
+	samples []HubSample // Oldest first
+
+	// Each session's counters at the last sample, to take the difference
+	// from; sessions that have closed are forgotten
+	last   map[*GameSession]RoomMetrics
+	lastAt time.Time
+}
+
+// StatsHistory returns the hub's sampled activity, oldest first
+func (h *GameHub) StatsHistory() []HubSample {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+	samples := make([]HubSample, len(h.stats.samples))
+	copy(samples, h.stats.samples)
+	return samples
+}
+
+// statsLoop samples the hub's activity until it is closed
+func (h *GameHub) statsLoop() {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	h.stats.mu.Lock()
+	h.stats.lastAt = time.Now()
+	h.stats.mu.Unlock()
+
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+			h.sampleStats()
+		}
+	}
+}
+
+// sampleStats records the hub's activity since the last sample
+func (h *GameHub) sampleStats() {
+	now := time.Now()
+	sample := HubSample{At: now}
+	current := make(map[*GameSession]RoomMetrics)
+
+	var events, messages uint64
+	for _, session := range h.allSessions() {
+		metrics := session.Metrics()
+		current[session] = metrics
+
+		sample.Rooms++
+		sample.Players += session.GetPlayerCount()
+		sample.Connections += session.ConnectionCount()
+
+		previous := h.stats.lastMetrics(session)
+		events += metrics.EventsBroadcast - previous.EventsBroadcast
+		messages += metrics.MessagesReceived - previous.MessagesReceived
+	}
+
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+
+	elapsed := now.Sub(h.stats.lastAt).Seconds()
+	sample.EventsPerSecond = float64(events) / elapsed
+	sample.MessagesPerSecond = float64(messages) / elapsed
+	h.stats.last = current
+	h.stats.lastAt = now
+
+	h.stats.samples = append(h.stats.samples, sample)
+	if len(h.stats.samples) > statsHistory {
+		h.stats.samples = h.stats.samples[len(h.stats.samples)-statsHistory:]
+	}
+}
+
+// lastMetrics returns the session's counters at the last sample, which are
+// zero for a session created since
+func (s *hubStats) lastMetrics(session *GameSession) RoomMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last[session]
+}
+
+// ConnectionCount returns how many players and spectators are connected
+func (s *GameSession) ConnectionCount() int {
+	var count int
+	s.call(func() {
+		count = s.connectionCount()
+	})
+	return count
+}
@@ -0,0 +1,111 @@
+package app
+
+import "time"
+
+// timerKind names one of a session's timers. A session runs at most one
+// timer of each kind.
+type timerKind string
+
+const (
+	timerPhase timerKind = "phase" // Advances the phase: ends the role reveal or the vote
+	timerAcks  timerKind = "acks"  // Resends critical events clients have not acknowledged
+)
+
+// sessionTimer is a running timer
+type sessionTimer struct {
+	timer    *time.Timer
+	deadline time.Time
+}
+
+// sessionTimers runs every timer of a session. Timers fire on the session's
+// actor, and one cancelled or replaced after it fired but before its command
+// ran does nothing. Only the actor may use it.
+type sessionTimers struct {
+	session *GameSession
+	running map[timerKind]*sessionTimer
+
+	// Bumped by every start and cancel, so a dump shows timers being replaced
+	generation uint64
+
+	// Set once the session has closed; no timer starts after that
+	stopped bool
+}
+
+// newSessionTimers creates the timers of session, none running
+func newSessionTimers(session *GameSession) *sessionTimers {
+	return &sessionTimers{
+		session: session,
+		running: make(map[timerKind]*sessionTimer),
+	}
+}
+
+// start runs fire on the actor after d, replacing the running timer of the
+// same kind. Starting a running timer's kind again resets it.
+func (t *sessionTimers) start(kind timerKind, d time.Duration, fire func()) {
+	t.cancel(kind)
+	if t.stopped {
+		return
+	}
+
+	timer := &sessionTimer{deadline: time.Now().Add(d)}
+	timer.timer = time.AfterFunc(d, func() {
+		t.session.call(func() {
+			if t.running[kind] != timer {
+				return
+			}
+			delete(t.running, kind)
+			fire()
+		})
+	})
+	t.running[kind] = timer
+}
+
+// cancel stops the timer of kind, including one that has fired but whose
+// command has not run yet
+func (t *sessionTimers) cancel(kind timerKind) {
+	t.generation++
+	if timer, ok := t.running[kind]; ok {
+		timer.timer.Stop()
+		delete(t.running, kind)
+	}
+}
+
+// isRunning returns true if a timer of kind is waiting to fire
+func (t *sessionTimers) isRunning(kind timerKind) bool {
+	_, ok := t.running[kind]
+	return ok
+}
+
+// deadline returns when the timer of kind fires, or the zero time if none is running
+func (t *sessionTimers) deadline(kind timerKind) time.Time {
+	if timer, ok := t.running[kind]; ok {
+		return timer.deadline
+	}
+	return time.Time{}
+}
+
+// remaining returns the time left before the timer of kind fires, or 0 if
+// none is running
+func (t *sessionTimers) remaining(kind timerKind) time.Duration {
+	if timer, ok := t.running[kind]; ok {
+		return max(time.Until(timer.deadline), 0)
+	}
+	return 0
+}
+
+// deadlines returns when each running timer fires, by kind
+func (t *sessionTimers) deadlines() map[string]time.Time {
+	deadlines := make(map[string]time.Time, len(t.running))
+	for kind, timer := range t.running {
+		deadlines[string(kind)] = timer.deadline
+	}
+	return deadlines
+}
+
+// stop cancels every timer for good, as the session closes
+func (t *sessionTimers) stop() {
+	for kind := range t.running {
+		t.cancel(kind)
+	}
+	t.stopped = true
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenSignerVerify(t *testing.T) {
+	signer := NewTokenSigner([]byte("secret"), time.Hour)
+
+	for _, playerID := range []string{"3f2b9c1e-uuid", "github:12345", "oidc:tenant:user"} {
+		token := signer.Sign("ABC123", playerID)
+		got, err := signer.Verify(token, "ABC123")
+		if err != nil {
+			t.Errorf("Verify(%q): %v", playerID, err)
+		} else if got != playerID {
+			t.Errorf("Verify = %q, want %q", got, playerID)
+		}
+		if _, err := signer.Verify(token, "XYZ789"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) for another room: err = %v, want ErrInvalidToken", playerID, err)
+		}
+	}
+
+	expired := NewTokenSigner([]byte("secret"), -time.Minute).Sign("ABC123", "github:12345")
+	if _, err := signer.Verify(expired, "ABC123"); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Verify of an expired token: err = %v, want ErrTokenExpired", err)
+	}
+}
@@ -0,0 +1,840 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"imposter/internal/domain"
+)
+
+// TournamentFormat decides how players move between a tournament's stages
+type TournamentFormat string
+
+const (
+	// FormatBracket advances the top half of every match until a single
+	// match is left, whose top scorer wins
+	FormatBracket TournamentFormat = "bracket"
+
+	// FormatRoundRobin plays a fixed number of stages with everyone,
+	// reshuffling the groups each stage so players meet different opponents;
+	// the most points overall wins
+	FormatRoundRobin TournamentFormat = "round-robin"
+)
+
+// TournamentStatus is where a tournament is in its lifecycle
+type TournamentStatus string
+
+const (
+	TournamentOpen    TournamentStatus = "REGISTRATION" // Players may register
+	TournamentRunning TournamentStatus = "RUNNING"
+	TournamentDone    TournamentStatus = "FINISHED"
+)
+
+// Points a player scores for each round their side wins in a match
+const (
+	imposterWinPoints = 2
+	vilekWinPoints    = 1
+)
+
+// Tournament limits and defaults
+const (
+	DefaultTournamentRounds = 3 // Rounds per match
+	DefaultTournamentStages = 3 // Round-robin stages
+	MaxTournamentRounds     = 10
+	MaxTournamentStages     = 10
+	MaxTournamentPlayers    = 256
+	maxTournamentNameLength = 64
+
+	// maxTournaments bounds how many tournaments are kept; the oldest
+	// finished one makes way for a new one
+	maxTournaments = 100
+)
+
+// Errors returned by TournamentManager
+var (
+	ErrTournamentNotFound = errors.New("tournament not found")
+	ErrTournamentStarted  = errors.New("tournament has already started")
+	ErrTournamentFull     = errors.New("tournament is full")
+	ErrTooManyTournaments = errors.New("too many tournaments are running")
+	ErrNicknameTaken      = errors.New("nickname is already registered")
+	ErrInvalidNickname    = errors.New("nickname must be 1 to 20 characters")
+	ErrNotEnoughEntrants  = errors.New("not enough players registered")
+	ErrInvalidTournament  = errors.New("invalid tournament options")
+)
+
+// TournamentOptions configure a new tournament
+type TournamentOptions struct {
+	Name           string           `json:"name"`
+	Format         TournamentFormat `json:"format"`         // Defaults to bracket
+	GroupSize      int              `json:"groupSize"`      // Players per room; 0 uses the game's maximum
+	RoundsPerMatch int              `json:"roundsPerMatch"` // 0 uses DefaultTournamentRounds
+	Stages         int              `json:"stages"`         // Round-robin only; 0 uses DefaultTournamentStages
+	Seed           int64            `json:"seed"`           // Seeds the groups and every match's rooms; 0 is random
+}
+
+// Tournament is a snapshot of a tournament, safe to serve publicly: seat
+// invites and registration tokens are left out
+type Tournament struct {
+	ID             string                      `json:"id"`
+	Name           string                      `json:"name"`
+	Format         TournamentFormat            `json:"format"`
+	Status         TournamentStatus            `json:"status"`
+	GroupSize      int                         `json:"groupSize"`
+	RoundsPerMatch int                         `json:"roundsPerMatch"`
+	Stages         int                         `json:"stages,omitempty"` // Round-robin only
+	Stage          int                         `json:"stage"`            // 0 until it starts
+	WinnerID       string                      `json:"winnerId,omitempty"`
+	Standings      []domain.TournamentStanding `json:"standings"`
+	Matches        []TournamentMatch           `json:"matches"`
+	CreatedAt      time.Time                   `json:"createdAt"`
+	StartedAt      *time.Time                  `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time                  `json:"finishedAt,omitempty"`
+}
+
+// TournamentMatch is one room of a tournament stage
+type TournamentMatch struct {
+	Stage     int            `json:"stage"`
+	RoomCode  string         `json:"roomCode"`
+	PlayerIDs []string       `json:"playerIds"`
+	Points    map[string]int `json:"points"` // By player ID, this match only
+	Rounds    int            `json:"rounds"` // Played so far
+	Finished  bool           `json:"finished"`
+	Advancing []string       `json:"advancing,omitempty"` // Bracket only
+}
+
+// TournamentRegistration is returned to a player who registers. The token
+// fetches their assignments and is shown only once.
+type TournamentRegistration struct {
+	TournamentID string `json:"tournamentId"`
+	PlayerID     string `json:"playerId"`
+	Nickname     string `json:"nickname"`
+	Token        string `json:"token"`
+}
+
+// TournamentAssignment tells a registered player where to play. RoomCode is
+// empty between stages and once they are out.
+type TournamentAssignment struct {
+	TournamentID string           `json:"tournamentId"`
+	PlayerID     string           `json:"playerId"`
+	Nickname     string           `json:"nickname"` // The only nickname Invite joins the room with
+	Status       TournamentStatus `json:"status"`
+	Stage        int              `json:"stage"`
+	RoomCode     string           `json:"roomCode,omitempty"`
+	Invite       string           `json:"invite,omitempty"` // The player's own seat in the room
+	Eliminated   bool             `json:"eliminated"`
+}
+
+// tournament is a tournament's state, guarded by the manager's lock
+type tournament struct {
+	id         string
+	name       string
+	format     TournamentFormat
+	status     TournamentStatus
+	groupSize  int
+	rounds     int
+	stages     int
+	seed       int64
+	rng        domain.Rand
+	stage      int
+	winnerID   string
+	players    []*tournamentPlayer // In registration order
+	matches    []*tournamentMatch
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// tournamentPlayer is a registered player
+type tournamentPlayer struct {
+	id         string
+	nickname   string
+	token      string
+	points     int
+	eliminated bool
+}
+
+// tournamentMatch is a room being played, or played, for a tournament
+type tournamentMatch struct {
+	stage     int
+	roomCode  string
+	invites   map[string]string // Seat invite tokens, by player ID
+	seats     map[string]string // Player IDs, by the ID they joined the room with
+	playerIDs []string
+	points    map[string]int
+	rounds    int
+	finished  bool
+	advancing []string
+}
+
+// TournamentManager runs tournaments: it creates a locked room for every
+// match of a stage, which each registered player joins with their own seat
+// invite, scores them as rounds end, and moves on to the next stage once
+// every match is over.
+// Tournaments are kept in memory and do not survive a restart.
+type TournamentManager struct {
+	NopGameHooks
+
+	hub    *GameHub
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	tournaments map[string]*tournament
+	rooms       map[string]*tournamentMatchRef // Matches being played, by room code
+}
+
+// tournamentMatchRef finds a match being played from its room
+type tournamentMatchRef struct {
+	tournament *tournament
+	match      *tournamentMatch
+}
+
+// NewTournamentManager returns a manager playing tournaments on hub. It
+// registers itself for the hub's hooks and events, so create it once the
+// hub's event bus is set.
+func NewTournamentManager(hub *GameHub, logger *slog.Logger) *TournamentManager {
+	m := &TournamentManager{
+		hub:         hub,
+		logger:      logger,
+		tournaments: make(map[string]*tournament),
+		rooms:       make(map[string]*tournamentMatchRef),
+	}
+	hub.AddHooks(m)
+	hub.Bus().Subscribe(m.onEvent)
+	return m
+}
+
+// Create adds a tournament open for registration
+func (m *TournamentManager) Create(opts TournamentOptions) (*Tournament, error) {
+	settings := domain.DefaultGameSettings()
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		name = "Tournament"
+	}
+	if opts.Format == "" {
+		opts.Format = FormatBracket
+	}
+	if opts.GroupSize == 0 {
+		opts.GroupSize = settings.MaxPlayers
+	}
+	if opts.RoundsPerMatch == 0 {
+		opts.RoundsPerMatch = DefaultTournamentRounds
+	}
+	switch opts.Format {
+	case FormatBracket:
+		opts.Stages = 0
+	case FormatRoundRobin:
+		if opts.Stages == 0 {
+			opts.Stages = DefaultTournamentStages
+		}
+	default:
+		return nil, ErrInvalidTournament
+	}
+	if utf8.RuneCountInString(name) > maxTournamentNameLength ||
+		opts.GroupSize < settings.MinPlayers || opts.GroupSize > settings.MaxPlayers ||
+		opts.RoundsPerMatch < 1 || opts.RoundsPerMatch > MaxTournamentRounds ||
+		opts.Stages < 0 || opts.Stages > MaxTournamentStages {
+		return nil, ErrInvalidTournament
+	}
+
+	rng := domain.NewSeededRand(opts.Seed)
+	if opts.Seed == 0 {
+		rng = domain.NewSeededRand(time.Now().UnixNano())
+	}
+
+	t := &tournament{
+		id:        uuid.New().String(),
+		name:      name,
+		format:    opts.Format,
+		status:    TournamentOpen,
+		groupSize: opts.GroupSize,
+		rounds:    opts.RoundsPerMatch,
+		stages:    opts.Stages,
+		seed:      opts.Seed,
+		rng:       rng,
+		createdAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.tournaments) >= maxTournaments && !m.dropOldestFinished() {
+		return nil, ErrTooManyTournaments
+	}
+	m.tournaments[t.id] = t
+
+	m.logger.Info("tournament created", "tournamentID", t.id, "name", t.name, "format", t.format)
+	return t.snapshot(), nil
+}
+
+// dropOldestFinished forgets the finished tournament created first, if any.
+// Called with m.mu held.
+func (m *TournamentManager) dropOldestFinished() bool {
+	var oldest *tournament
+	for _, t := range m.tournaments {
+		if t.status == TournamentDone && (oldest == nil || t.createdAt.Before(oldest.createdAt)) {
+			oldest = t
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	delete(m.tournaments, oldest.id)
+	return true
+}
+
+// Get returns a snapshot of the tournament
+func (m *TournamentManager) Get(id string) (*Tournament, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tournaments[id]
+	if !ok {
+		return nil, ErrTournamentNotFound
+	}
+	return t.snapshot(), nil
+}
+
+// List returns snapshots of every tournament, newest first
+func (m *TournamentManager) List() []*Tournament {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]*Tournament, 0, len(m.tournaments))
+	for _, t := range m.tournaments {
+		list = append(list, t.snapshot())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Register adds a player to a tournament that has not started. Nicknames
+// are unique in a tournament, ignoring case, and cleaned up as the rooms
+// will show them, since a player's seat invites only join with theirs.
+func (m *TournamentManager) Register(id, nickname string) (*TournamentRegistration, error) {
+	nickname, err := domain.CleanNickname(nickname, m.hub.EmojiPolicy())
+	if err != nil {
+		return nil, ErrInvalidNickname
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tournaments[id]
+	if !ok {
+		return nil, ErrTournamentNotFound
+	}
+	if t.status != TournamentOpen {
+		return nil, ErrTournamentStarted
+	}
+	if len(t.players) >= MaxTournamentPlayers {
+		return nil, ErrTournamentFull
+	}
+	if t.playerByNickname(nickname) != nil {
+		return nil, ErrNicknameTaken
+	}
+
+	player := &tournamentPlayer{
+		id:       uuid.New().String(),
+		nickname: nickname,
+		token:    hex.EncodeToString(b),
+	}
+	t.players = append(t.players, player)
+
+	return &TournamentRegistration{
+		TournamentID: t.id,
+		PlayerID:     player.id,
+		Nickname:     player.nickname,
+		Token:        player.token,
+	}, nil
+}
+
+// Assignment returns where the player the token was issued to plays now
+func (m *TournamentManager) Assignment(id, token string) (*TournamentAssignment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tournaments[id]
+	if !ok {
+		return nil, ErrTournamentNotFound
+	}
+
+	var player *tournamentPlayer
+	for _, p := range t.players {
+		if subtle.ConstantTimeCompare([]byte(p.token), []byte(token)) == 1 {
+			player = p
+		}
+	}
+	if player == nil {
+		return nil, ErrInvalidToken
+	}
+
+	assignment := &TournamentAssignment{
+		TournamentID: t.id,
+		PlayerID:     player.id,
+		Nickname:     player.nickname,
+		Status:       t.status,
+		Stage:        t.stage,
+		Eliminated:   player.eliminated,
+	}
+	for _, match := range t.matches {
+		if match.stage == t.stage && !match.finished && containsString(match.playerIDs, player.id) {
+			assignment.RoomCode = match.roomCode
+			assignment.Invite = match.invites[player.id]
+		}
+	}
+	return assignment, nil
+}
+
+// Start closes registration and creates the rooms of the first stage
+func (m *TournamentManager) Start(id string) (*Tournament, error) {
+	m.mu.Lock()
+	t, ok := m.tournaments[id]
+	switch {
+	case !ok:
+		m.mu.Unlock()
+		return nil, ErrTournamentNotFound
+	case t.status != TournamentOpen:
+		m.mu.Unlock()
+		return nil, ErrTournamentStarted
+	case len(t.players) < domain.DefaultGameSettings().MinPlayers:
+		m.mu.Unlock()
+		return nil, ErrNotEnoughEntrants
+	}
+	t.status = TournamentRunning
+	t.startedAt = time.Now()
+	m.mu.Unlock()
+
+	if err := m.startStage(t); err != nil {
+		m.mu.Lock()
+		t.status = TournamentOpen
+		t.startedAt = time.Time{}
+		m.mu.Unlock()
+		return nil, err
+	}
+	m.logger.Info("tournament started", "tournamentID", t.id, "players", len(t.players))
+
+	return m.Get(id)
+}
+
+// startStage groups the players still in for the next stage and creates a
+// room for each group. Rooms are created without holding m.mu, since the hub
+// may reach other instances to reserve their codes.
+func (m *TournamentManager) startStage(t *tournament) error {
+	m.mu.Lock()
+	stage := t.stage + 1
+	var playerIDs []string
+	for _, p := range t.players {
+		if !p.eliminated {
+			playerIDs = append(playerIDs, p.id)
+		}
+	}
+	t.rng.Shuffle(len(playerIDs), func(i, j int) {
+		playerIDs[i], playerIDs[j] = playerIDs[j], playerIDs[i]
+	})
+	groups := splitGroups(playerIDs, t.groupSize, domain.DefaultGameSettings().MinPlayers)
+	nicknames := make(map[string]string, len(playerIDs))
+	for _, id := range playerIDs {
+		nicknames[id] = t.player(id).nickname
+	}
+	m.mu.Unlock()
+
+	matches := make([]*tournamentMatch, 0, len(groups))
+	for i, group := range groups {
+		match, err := m.createMatch(t, stage, i, group, nicknames)
+		if err != nil {
+			for _, created := range matches {
+				m.hub.DeleteSession(created.roomCode)
+			}
+			return err
+		}
+		matches = append(matches, match)
+	}
+
+	m.mu.Lock()
+	t.stage = stage
+	for _, match := range matches {
+		t.matches = append(t.matches, match)
+		m.rooms[match.roomCode] = &tournamentMatchRef{tournament: t, match: match}
+	}
+	payload := t.update(domain.TournamentStageStarted, "")
+	m.mu.Unlock()
+
+	m.logger.Info("tournament stage started", "tournamentID", t.id, "stage", stage, "matches", len(matches))
+	m.announce(t, payload)
+	return nil
+}
+
+// createMatch creates the locked room a group plays its match in, with a seat
+// invite for each player. The room's password is never handed out, so
+// players only get in with their own invite, under their own nickname.
+func (m *TournamentManager) createMatch(t *tournament, stage, index int, playerIDs []string, nicknames map[string]string) (*tournamentMatch, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	password := hex.EncodeToString(b)
+
+	var seed int64
+	if t.seed != 0 {
+		seed = t.seed + int64(stage)*1000 + int64(index) + 1
+	}
+	session, err := m.hub.CreateGame(RoomOptions{Password: password, Seed: seed})
+	if err != nil {
+		return nil, err
+	}
+
+	points := make(map[string]int, len(playerIDs))
+	invites := make(map[string]string, len(playerIDs))
+	for _, id := range playerIDs {
+		points[id] = 0
+		invites[id] = session.CreateNamedInvite(nicknames[id], MaxInviteTTL).Token
+	}
+	return &tournamentMatch{
+		stage:     stage,
+		roomCode:  session.GetRoomCode(),
+		invites:   invites,
+		seats:     make(map[string]string),
+		playerIDs: playerIDs,
+		points:    points,
+	}, nil
+}
+
+// splitGroups splits players into groups of about size, as even as possible.
+// Groups grow past size rather than fall below minSize.
+func splitGroups(playerIDs []string, size, minSize int) [][]string {
+	n := len(playerIDs)
+	count := int(math.Ceil(float64(n) / float64(size)))
+	for count > 1 && n/count < minSize {
+		count--
+	}
+
+	groups := make([][]string, 0, count)
+	start := 0
+	for i := 0; i < count; i++ {
+		end := start + n/count
+		if i < n%count {
+			end++
+		}
+		groups = append(groups, playerIDs[start:end])
+		start = end
+	}
+	return groups
+}
+
+// OnPlayerJoined seats a player who joined a match room as the entrant whose
+// invite let them in. Seat invites only join with their entrant's nickname,
+// so the nickname names the entrant.
+func (m *TournamentManager) OnPlayerJoined(info *PlayerJoinedInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ref, ok := m.rooms[info.RoomCode]
+	if !ok {
+		return
+	}
+	entrant := ref.tournament.playerByNickname(info.Player.Nickname)
+	if entrant == nil || !containsString(ref.match.playerIDs, entrant.id) {
+		return
+	}
+	ref.match.seats[info.Player.ID] = entrant.id
+}
+
+// OnRoundEnded scores a round played in a match room for the entrants seated
+// in it, ending the match once it has played its rounds. It is called on the
+// session's actor, so anything reaching back into rooms happens in the
+// background.
+func (m *TournamentManager) OnRoundEnded(record *RoundRecord) {
+	m.mu.Lock()
+	ref, ok := m.rooms[record.RoomCode]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	t, match := ref.tournament, ref.match
+
+	// An entrant who joined twice still scores once a round
+	scored := make(map[string]bool)
+	for _, player := range record.Players {
+		entrant := t.player(match.seats[player.ID])
+		if entrant == nil || scored[entrant.id] {
+			continue
+		}
+		scored[entrant.id] = true
+		switch {
+		case containsString(record.ImposterIDs, player.ID) && record.Winner == domain.RoleImposter:
+			match.points[entrant.id] += imposterWinPoints
+			entrant.points += imposterWinPoints
+		case !containsString(record.ImposterIDs, player.ID) && record.Winner == domain.RoleVilek:
+			match.points[entrant.id] += vilekWinPoints
+			entrant.points += vilekWinPoints
+		}
+	}
+	match.rounds++
+
+	if match.rounds < t.rounds {
+		m.mu.Unlock()
+		return
+	}
+	payload, stageOver := m.finishMatch(t, match)
+	m.mu.Unlock()
+
+	go m.matchEnded(t, payload, stageOver)
+}
+
+// onEvent ends the match of a room deleted before it played all its rounds
+func (m *TournamentManager) onEvent(event *domain.GameEvent) {
+	if event.Type != domain.EventGameDeleted {
+		return
+	}
+
+	m.mu.Lock()
+	ref, ok := m.rooms[event.GameID]
+	if !ok {
+		m.mu.Unlock()
+		return
+	}
+	m.logger.Warn("tournament room closed before its match ended", "tournamentID", ref.tournament.id, "roomCode", event.GameID, "rounds", ref.match.rounds)
+	payload, stageOver := m.finishMatch(ref.tournament, ref.match)
+	m.mu.Unlock()
+
+	go m.matchEnded(ref.tournament, payload, stageOver)
+}
+
+// finishMatch ends a match, deciding who advances in a bracket. It returns
+// the update to announce and whether the stage is over. Called with m.mu
+// held.
+func (m *TournamentManager) finishMatch(t *tournament, match *tournamentMatch) (*domain.TournamentUpdatePayload, bool) {
+	match.finished = true
+	delete(m.rooms, match.roomCode)
+
+	ranked := append([]string(nil), match.playerIDs...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if match.points[ranked[i]] != match.points[ranked[j]] {
+			return match.points[ranked[i]] > match.points[ranked[j]]
+		}
+		return t.player(ranked[i]).points > t.player(ranked[j]).points
+	})
+
+	if t.format == FormatBracket {
+		advance := max(1, len(ranked)/2)
+		if t.stageMatches() == 1 {
+			advance = 1 // The final
+		}
+		match.advancing = ranked[:advance]
+		for _, id := range ranked[advance:] {
+			t.player(id).eliminated = true
+		}
+	}
+
+	stageOver := true
+	for _, other := range t.matches {
+		if other.stage == t.stage && !other.finished {
+			stageOver = false
+		}
+	}
+
+	switch {
+	case !stageOver:
+	case t.format == FormatBracket && t.stageMatches() == 1:
+		t.finish(match.advancing[0])
+	case t.format == FormatRoundRobin && t.stage >= t.stages:
+		t.finish(t.standings()[0].PlayerID)
+	}
+
+	if t.status == TournamentDone {
+		m.logger.Info("tournament finished", "tournamentID", t.id, "winnerID", t.winnerID)
+		return t.update(domain.TournamentFinished, match.roomCode), false
+	}
+	return t.update(domain.TournamentMatchEnded, match.roomCode), stageOver
+}
+
+// matchEnded announces a match's end and starts the next stage if it was the
+// last match of its stage
+func (m *TournamentManager) matchEnded(t *tournament, payload *domain.TournamentUpdatePayload, stageOver bool) {
+	m.announce(t, payload)
+	if !stageOver {
+		return
+	}
+	if err := m.startStage(t); err != nil {
+		m.logger.Error("failed to start tournament stage", "tournamentID", t.id, "stage", payload.Stage+1, "error", err)
+	}
+}
+
+// announce sends an update to every room of the tournament still open, so
+// players see the standings wherever they are
+func (m *TournamentManager) announce(t *tournament, payload *domain.TournamentUpdatePayload) {
+	m.mu.Lock()
+	roomCodes := make([]string, 0, len(t.matches))
+	for _, match := range t.matches {
+		roomCodes = append(roomCodes, match.roomCode)
+	}
+	m.mu.Unlock()
+
+	for _, roomCode := range roomCodes {
+		if session := m.hub.lookupSession(roomCode); session != nil {
+			session.Announce(domain.EventTournamentUpdate, payload)
+		}
+	}
+}
+
+// Announce broadcasts an event from outside the game, such as a tournament
+// update, to everyone in the room
+func (s *GameSession) Announce(eventType domain.EventType, payload interface{}) {
+	s.call(func() {
+		s.queueEvent(domain.NewEvent(eventType, s.game.ID, payload))
+	})
+}
+
+// finish ends the tournament with a winner
+func (t *tournament) finish(winnerID string) {
+	t.status = TournamentDone
+	t.winnerID = winnerID
+	t.finishedAt = time.Now()
+}
+
+// stageMatches returns how many matches the current stage has
+func (t *tournament) stageMatches() int {
+	count := 0
+	for _, match := range t.matches {
+		if match.stage == t.stage {
+			count++
+		}
+	}
+	return count
+}
+
+// player returns the registered player with the ID
+func (t *tournament) player(id string) *tournamentPlayer {
+	for _, p := range t.players {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// playerByNickname returns the registered player with the nickname, ignoring
+// case, or nil
+func (t *tournament) playerByNickname(nickname string) *tournamentPlayer {
+	for _, p := range t.players {
+		if strings.EqualFold(p.nickname, strings.TrimSpace(nickname)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// standings ranks the registered players: those still in first, then by
+// points, then in registration order
+func (t *tournament) standings() []domain.TournamentStanding {
+	players := append([]*tournamentPlayer(nil), t.players...)
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].eliminated != players[j].eliminated {
+			return !players[i].eliminated
+		}
+		return players[i].points > players[j].points
+	})
+	if t.winnerID != "" {
+		for i, p := range players {
+			if p.id == t.winnerID {
+				copy(players[1:i+1], players[:i])
+				players[0] = p
+			}
+		}
+	}
+
+	standings := make([]domain.TournamentStanding, len(players))
+	for i, p := range players {
+		standings[i] = domain.TournamentStanding{
+			Rank:       i + 1,
+			PlayerID:   p.id,
+			Nickname:   p.nickname,
+			Points:     p.points,
+			Eliminated: p.eliminated,
+		}
+	}
+	return standings
+}
+
+// update returns the payload announcing a change
+func (t *tournament) update(change domain.TournamentChange, roomCode string) *domain.TournamentUpdatePayload {
+	return &domain.TournamentUpdatePayload{
+		TournamentID: t.id,
+		Name:         t.name,
+		Change:       change,
+		Stage:        t.stage,
+		RoomCode:     roomCode,
+		WinnerID:     t.winnerID,
+		Standings:    t.standings(),
+	}
+}
+
+// snapshot copies the tournament for serving
+func (t *tournament) snapshot() *Tournament {
+	snapshot := &Tournament{
+		ID:             t.id,
+		Name:           t.name,
+		Format:         t.format,
+		Status:         t.status,
+		GroupSize:      t.groupSize,
+		RoundsPerMatch: t.rounds,
+		Stages:         t.stages,
+		Stage:          t.stage,
+		WinnerID:       t.winnerID,
+		Standings:      t.standings(),
+		Matches:        make([]TournamentMatch, 0, len(t.matches)),
+		CreatedAt:      t.createdAt,
+	}
+	if !t.startedAt.IsZero() {
+		startedAt := t.startedAt
+		snapshot.StartedAt = &startedAt
+	}
+	if !t.finishedAt.IsZero() {
+		finishedAt := t.finishedAt
+		snapshot.FinishedAt = &finishedAt
+	}
+
+	for _, match := range t.matches {
+		points := make(map[string]int, len(match.points))
+		for id, p := range match.points {
+			points[id] = p
+		}
+		snapshot.Matches = append(snapshot.Matches, TournamentMatch{
+			Stage:     match.stage,
+			RoomCode:  match.roomCode,
+			PlayerIDs: append([]string(nil), match.playerIDs...),
+			Points:    points,
+			Rounds:    match.rounds,
+			Finished:  match.finished,
+			Advancing: append([]string(nil), match.advancing...),
+		})
+	}
+	return snapshot
+}
+
+// containsString returns true if s is in list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
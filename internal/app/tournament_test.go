@@ -0,0 +1,82 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"imposter/internal/domain"
+)
+
+func TestTournamentSeatsBindToInvites(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := NewGameHub(NewTokenSigner(nil, 0), NewLocalBroadcaster(), logger)
+	defer hub.Close()
+	m := NewTournamentManager(hub, logger)
+
+	tournament, err := m.Create(TournamentOptions{Name: "Cup", RoundsPerMatch: 2})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	var registrations []*TournamentRegistration
+	for i := range 4 {
+		registration, err := m.Register(tournament.ID, fmt.Sprintf("Entrant%d", i))
+		if err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		registrations = append(registrations, registration)
+	}
+	if _, err := m.Start(tournament.ID); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	assignments := make([]*TournamentAssignment, len(registrations))
+	for i, registration := range registrations {
+		assignments[i], err = m.Assignment(tournament.ID, registration.Token)
+		if err != nil {
+			t.Fatalf("Assignment: %v", err)
+		}
+	}
+	session, err := hub.GetSession(assignments[0].RoomCode)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+
+	// A rival cannot take a seat under someone else's nickname
+	rival := assignments[1]
+	if _, err := session.AddPlayer("rival", assignments[0].Nickname, "", rival.Invite); !errors.Is(err, domain.ErrInvalidInvite) {
+		t.Fatalf("joining with a rival's nickname: err = %v, want ErrInvalidInvite", err)
+	}
+
+	var players []RecordedPlayer
+	for i, assignment := range assignments {
+		id := fmt.Sprintf("room-player-%d", i)
+		if _, err := session.AddPlayer(id, assignment.Nickname, "", assignment.Invite); err != nil {
+			t.Fatalf("AddPlayer with own invite: %v", err)
+		}
+		players = append(players, RecordedPlayer{ID: id, Nickname: assignment.Nickname})
+	}
+
+	// The imposter wins a round; only the seated imposter scores
+	m.OnRoundEnded(&RoundRecord{
+		RoomCode:    assignments[0].RoomCode,
+		ImposterIDs: []string{"room-player-0"},
+		Winner:      domain.RoleImposter,
+		Players:     players,
+	})
+	got, err := m.Get(tournament.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	for _, standing := range got.Standings {
+		want := 0
+		if standing.PlayerID == registrations[0].PlayerID {
+			want = imposterWinPoints
+		}
+		if standing.Points != want {
+			t.Errorf("%s has %d points, want %d", standing.Nickname, standing.Points, want)
+		}
+	}
+}
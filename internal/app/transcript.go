@@ -0,0 +1,127 @@
+package app
+
+import (
+	"time"
+
+	"imposter/internal/domain"
+)
+
+// Transcript is a record of a finished game's rounds: who gave which clue,
+// who voted for whom, and who won, for sharing and post-game analysis
+type Transcript struct {
+	RoomCode   string            `json:"roomCode"`
+	Language   string            `json:"language,omitempty"`
+	Rounds     []TranscriptRound `json:"rounds"`
+	ExportedAt time.Time         `json:"exportedAt"`
+}
+
+// TranscriptRound is one finished round of a transcript
+type TranscriptRound struct {
+	Number     int                `json:"number"`
+	SecretWord string             `json:"secretWord"`
+	Imposter   TranscriptPlayer   `json:"imposter"` // The first of imposters
+	Imposters  []TranscriptPlayer `json:"imposters"`
+	Winner     domain.Role        `json:"winner"`
+	Clues      []TranscriptClue   `json:"clues"`
+	Votes      []TranscriptVote   `json:"votes"`
+	StartedAt  time.Time          `json:"startedAt"`
+	EndedAt    time.Time          `json:"endedAt"`
+}
+
+// TranscriptPlayer names a player in a transcript
+type TranscriptPlayer struct {
+	ID       string `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+// TranscriptClue is a word a player submitted, in submission order
+type TranscriptClue struct {
+	Order  int              `json:"order"`
+	Player TranscriptPlayer `json:"player"`
+	Word   string           `json:"word"`
+}
+
+// TranscriptVote is a vote cast at the end of a round
+type TranscriptVote struct {
+	Voter  TranscriptPlayer `json:"voter"`
+	Target TranscriptPlayer `json:"target"`
+}
+
+// IsHost returns true if playerID is the room's host
+func (s *GameSession) IsHost(playerID string) bool {
+	var isHost bool
+	s.call(func() {
+		isHost = s.game.IsHost(playerID)
+	})
+	return isHost
+}
+
+// Transcript returns the room's finished rounds once its game has ended: at
+// least one round has been played and none is under way. Otherwise it
+// returns domain.ErrInvalidPhase.
+func (s *GameSession) Transcript() (*Transcript, error) {
+	var (
+		transcript *Transcript
+		err        error = domain.ErrGameNotFound
+	)
+	s.call(func() {
+		phase := s.game.Phase
+		if len(s.game.RoundHistory) == 0 || (phase != domain.PhaseResults && phase != domain.PhaseLobby) {
+			err = domain.ErrInvalidPhase
+			return
+		}
+		transcript, err = s.transcript(), nil
+	})
+	return transcript, err
+}
+
+// transcript copies the finished rounds. Players who have since left are
+// named by the nickname they submitted a clue under, if they did.
+func (s *GameSession) transcript() *Transcript {
+	nicknames := make(map[string]string, len(s.game.Players))
+	for _, round := range s.game.RoundHistory {
+		for _, sub := range round.Submissions {
+			nicknames[sub.PlayerID] = sub.Nickname
+		}
+	}
+	for id, player := range s.game.Players {
+		nicknames[id] = player.Nickname
+	}
+	player := func(id string) TranscriptPlayer {
+		return TranscriptPlayer{ID: id, Nickname: nicknames[id]}
+	}
+
+	rounds := make([]TranscriptRound, 0, len(s.game.RoundHistory))
+	for _, round := range s.game.RoundHistory {
+		clues := make([]TranscriptClue, 0, len(round.Submissions))
+		for _, sub := range round.Submissions {
+			clues = append(clues, TranscriptClue{Order: sub.Order, Player: player(sub.PlayerID), Word: sub.Word})
+		}
+		imposters := make([]TranscriptPlayer, 0, len(round.Imposters()))
+		for _, id := range round.Imposters() {
+			imposters = append(imposters, player(id))
+		}
+		votes := make([]TranscriptVote, 0, len(round.Votes))
+		for _, vote := range round.Votes {
+			votes = append(votes, TranscriptVote{Voter: player(vote.VoterID), Target: player(vote.TargetID)})
+		}
+		rounds = append(rounds, TranscriptRound{
+			Number:     round.Number,
+			SecretWord: round.SecretWord,
+			Imposter:   player(round.ImposterID),
+			Imposters:  imposters,
+			Winner:     round.Winner,
+			Clues:      clues,
+			Votes:      votes,
+			StartedAt:  round.StartedAt,
+			EndedAt:    round.EndedAt,
+		})
+	}
+
+	return &Transcript{
+		RoomCode:   s.game.ID,
+		Language:   s.game.Language,
+		Rounds:     rounds,
+		ExportedAt: time.Now(),
+	}
+}
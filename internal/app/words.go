@@ -1,6 +1,18 @@
 package app
 
-import "math/rand"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"imposter/internal/domain"
+)
 
 // SecretWords is a curated list of words that work well for the game
 // Themed around cyberpunk/tech but also includes common objects
@@ -45,26 +57,326 @@ var SecretWords = []string{
 	"graffiti", "tattoo", "mosaic", "origami", "kaleidoscope",
 }
 
-// GetRandomWord returns a random word from the secret words list
-func GetRandomWord() string {
-	return SecretWords[rand.Intn(len(SecretWords))]
+// FamilyWords are the secret words fit for children and classrooms, all
+// from SecretWords so bots know clues for them
+var FamilyWords = []string{
+	"robot", "pixel", "keyboard", "joystick", "satellite", "hologram", "laser", "drone",
+	"dragon", "unicorn", "tiger", "dolphin", "octopus", "falcon", "beetle",
+	"pyramid", "bridge", "tunnel", "harbor", "stadium", "tower", "subway",
+	"diamond", "mirror", "compass", "lantern", "umbrella", "hammer", "anchor", "hourglass",
+	"pizza", "burger", "sushi", "chocolate", "vanilla", "honey",
+	"thunder", "volcano", "glacier", "meteor", "eclipse", "aurora",
+	"rhythm", "melody", "canvas", "mosaic", "origami", "kaleidoscope",
+}
+
+// DefaultWordSource names the embedded word list, which every server offers
+const DefaultWordSource = "embedded"
+
+// FamilyWordSource names the family word list, which every server offers and
+// family-friendly rooms always deal from
+const FamilyWordSource = "family"
+
+// EmbeddedWords chooses secret words from SecretWords
+var EmbeddedWords = NewListWordProvider(SecretWords)
+
+// FamilyProvider chooses secret words from FamilyWords
+var FamilyProvider = NewListWordProvider(FamilyWords)
+
+// WordProvider chooses the secret word for each round. Sessions ask for a word
+// on their actor as the round starts, so NextWord must return quickly; a
+// provider backed by a remote service should answer from words fetched ahead.
+type WordProvider interface {
+	// NextWord returns a secret word, avoiding the words in used if it can.
+	// Random choices are made with rng, the game's randomness, so seeded
+	// games pick the same words.
+	NextWord(rng domain.Rand, used []string) (string, error)
+}
+
+// ListWordProvider chooses secret words at random from a fixed list
+type ListWordProvider struct {
+	words []string
+}
+
+// NewListWordProvider creates a provider choosing from words
+func NewListWordProvider(words []string) *ListWordProvider {
+	return &ListWordProvider{words: words}
+}
+
+// LoadWordList reads a word list file with one word per line. Blank lines and
+// lines starting with # are skipped. JSON and YAML files are read like those
+// in a LoadWordDir directory.
+func LoadWordList(path string) (*ListWordProvider, error) {
+	if ext := filepath.Ext(path); ext == ".json" || ext == ".yaml" || ext == ".yml" {
+		return loadWordListFile(path, ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, line := range strings.Split(string(data), "\n") {
+		word := strings.ToLower(strings.TrimSpace(line))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("word list %s is empty", path)
+	}
+
+	return NewListWordProvider(words), nil
+}
+
+// wordListFile is the format of the JSON and YAML files LoadWordDir reads;
+// a bare list of words is accepted too
+type wordListFile struct {
+	Words []string `json:"words" yaml:"words"`
+}
+
+// LoadWordDir reads every .json, .yaml and .yml file in dir as a word list
+// named after the file, so animals.yaml is offered as "animals"
+func LoadWordDir(dir string) (map[string]*ListWordProvider, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make(map[string]*ListWordProvider)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if _, ok := lists[name]; ok {
+			return nil, fmt.Errorf("more than one word list is named %q", name)
+		}
+
+		list, err := loadWordListFile(filepath.Join(dir, entry.Name()), ext)
+		if err != nil {
+			return nil, err
+		}
+		lists[name] = list
+	}
+	return lists, nil
+}
+
+// loadWordListFile reads a JSON or YAML word list
+func loadWordListFile(path, ext string) (*ListWordProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	unmarshal := json.Unmarshal
+	if ext != ".json" {
+		unmarshal = yaml.Unmarshal
+	}
+	var file wordListFile
+	if err := unmarshal(data, &file); err != nil {
+		if err := unmarshal(data, &file.Words); err != nil {
+			return nil, fmt.Errorf("word list %s: %w", path, err)
+		}
+	}
+
+	seen := make(map[string]bool, len(file.Words))
+	words := make([]string, 0, len(file.Words))
+	for _, word := range file.Words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("word list %s is empty", path)
+	}
+
+	return NewListWordProvider(words), nil
+}
+
+// Words returns the words the provider chooses from
+func (p *ListWordProvider) Words() []string {
+	return append([]string(nil), p.words...)
 }
 
-// GetRandomWordExcluding returns a random word that's not in the excluded list
-func GetRandomWordExcluding(excluded []string) string {
-	excludeMap := make(map[string]bool)
-	for _, w := range excluded {
-		excludeMap[w] = true
+// Without returns a provider choosing from the same words except blocked
+func (p *ListWordProvider) Without(blocked []string) *ListWordProvider {
+	if len(blocked) == 0 {
+		return p
+	}
+
+	excluded := make(map[string]bool, len(blocked))
+	for _, w := range blocked {
+		excluded[strings.ToLower(w)] = true
+	}
+
+	words := make([]string, 0, len(p.words))
+	for _, w := range p.words {
+		if !excluded[w] {
+			words = append(words, w)
+		}
+	}
+	return NewListWordProvider(words)
+}
+
+// NextWord returns a random word not in used, or any word once all are used
+func (p *ListWordProvider) NextWord(rng domain.Rand, used []string) (string, error) {
+	if len(p.words) == 0 {
+		return "", fmt.Errorf("word list is empty")
+	}
+
+	excluded := make(map[string]bool, len(used))
+	for _, w := range used {
+		excluded[w] = true
 	}
 
 	// Try to find a non-excluded word
 	for attempts := 0; attempts < 100; attempts++ {
-		word := GetRandomWord()
-		if !excludeMap[word] {
-			return word
+		word := p.words[rng.Intn(len(p.words))]
+		if !excluded[word] {
+			return word, nil
 		}
 	}
 
 	// Fallback: just return any word
-	return GetRandomWord()
+	return p.words[rng.Intn(len(p.words))], nil
+}
+
+// WordLoader loads word providers that may change while the server runs,
+// such as lists operators edit, by name
+type WordLoader func() (map[string]WordProvider, error)
+
+// WordSources are the word providers a server offers rooms, by name. Those
+// from a loader are replaced on Reload; a room whose source has gone uses the
+// default one from its next round.
+type WordSources struct {
+	mu          sync.RWMutex
+	providers   map[string]WordProvider
+	fixed       map[string]WordProvider // Offered whatever the loader returns
+	load        WordLoader
+	defaultName string
+}
+
+// NewWordSources offers providers, those load returns if it is not nil, and
+// the embedded and family lists unless they replace them, with rooms that do
+// not choose using defaultName
+func NewWordSources(providers map[string]WordProvider, load WordLoader, defaultName string) (*WordSources, error) {
+	sources := &WordSources{
+		fixed:       map[string]WordProvider{DefaultWordSource: EmbeddedWords, FamilyWordSource: FamilyProvider},
+		load:        load,
+		defaultName: defaultName,
+	}
+	for name, provider := range providers {
+		sources.fixed[name] = provider
+	}
+
+	if _, err := sources.Reload(); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// DefaultWordSources offers only the embedded list
+func DefaultWordSources() *WordSources {
+	sources, _ := NewWordSources(nil, nil, DefaultWordSource)
+	return sources
+}
+
+// Reload replaces the loaded providers with those the loader returns now and
+// returns the names offered. If loading fails, or would leave no default
+// source, the current providers are kept.
+func (w *WordSources) Reload() ([]string, error) {
+	providers := make(map[string]WordProvider, len(w.fixed))
+	for name, provider := range w.fixed {
+		providers[name] = provider
+	}
+
+	if w.load != nil {
+		loaded, err := w.load()
+		if err != nil {
+			return nil, err
+		}
+		for name, provider := range loaded {
+			if _, ok := w.fixed[name]; ok {
+				return nil, fmt.Errorf("word list %q has the name of a built-in word source", name)
+			}
+			providers[name] = provider
+		}
+	}
+
+	if _, ok := providers[w.defaultName]; !ok {
+		return nil, fmt.Errorf("default word source %q is not configured", w.defaultName)
+	}
+
+	w.mu.Lock()
+	w.providers = providers
+	w.mu.Unlock()
+	return w.Names(), nil
+}
+
+// Has returns true if a provider is offered under name
+func (w *WordSources) Has(name string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.providers[name]
+	return ok
+}
+
+// Names returns the names of the offered providers, sorted
+func (w *WordSources) Names() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	names := make([]string, 0, len(w.providers))
+	for name := range w.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the name of the provider Provider returns for name
+func (w *WordSources) Resolve(name string) string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if _, ok := w.providers[name]; ok {
+		return name
+	}
+	return w.defaultName
+}
+
+// ProviderFor returns the provider for a room playing in language: the
+// variant of the source Resolve picks offered as "<source>.<language>", such
+// as "animals.es" from a WORDS_DIR file named animals.es.yaml, else the one
+// Provider returns. A regional language such as "pt-br" also tries "pt".
+func (w *WordSources) ProviderFor(name, language string) WordProvider {
+	if language == "" {
+		return w.Provider(name)
+	}
+
+	source := w.Resolve(name)
+	base, _, _ := strings.Cut(language, "-")
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, variant := range []string{source + "." + language, source + "." + base} {
+		if provider, ok := w.providers[variant]; ok {
+			return provider
+		}
+	}
+	return w.providers[source]
+}
+
+// Provider returns the provider offered under name, or the default one if
+// name is empty or no longer offered
+func (w *WordSources) Provider(name string) WordProvider {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if provider, ok := w.providers[name]; ok {
+		return provider
+	}
+	return w.providers[w.defaultName]
 }
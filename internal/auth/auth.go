@@ -0,0 +1,18 @@
+// Package auth verifies player identities issued outside the game server.
+package auth
+
+import "context"
+
+// subjectKey is the context key for the authenticated subject
+type subjectKey struct{}
+
+// WithSubject returns a copy of ctx carrying the authenticated subject
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the authenticated subject, if any
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(subjectKey{}).(string)
+	return subject, ok && subject != ""
+}
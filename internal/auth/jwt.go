@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"imposter/internal/config"
+)
+
+const (
+	// jwksRefreshInterval is how long fetched signing keys are trusted before refetching
+	jwksRefreshInterval = time.Hour
+
+	// jwksMinRefreshInterval limits refetches triggered by unknown key IDs, and
+	// retries while the provider is unreachable
+	jwksMinRefreshInterval = time.Minute
+)
+
+// JWT errors
+var (
+	ErrMissingSubject = errors.New("token has no subject")
+	ErrUnknownKey     = errors.New("token signed with unknown key")
+)
+
+// JWTVerifier validates tokens issued by an external identity provider
+// against the provider's published JSON Web Key Set
+type JWTVerifier struct {
+	cfg    config.JWTConfig
+	client *http.Client
+	parser *jwt.Parser
+	logger *slog.Logger
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // kid -> key
+	fetchedAt   time.Time                   // Last successful fetch
+	attemptedAt time.Time                   // Last fetch, successful or not
+	refreshing  chan struct{}               // Closed when the fetch in flight ends; nil if none
+	refreshErr  error                       // Why the last fetch failed
+}
+
+// NewJWTVerifier creates a verifier for the configured issuer, audience and JWKS URL
+func NewJWTVerifier(cfg config.JWTConfig, logger *slog.Logger) *JWTVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTVerifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		parser: jwt.NewParser(opts...),
+		logger: logger,
+		keys:   make(map[string]crypto.PublicKey),
+	}
+}
+
+// Verify validates token and returns its subject claim
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if claims.Subject == "" {
+		return "", ErrMissingSubject
+	}
+	return claims.Subject, nil
+}
+
+// key returns the public key for kid, refetching the key set if it is stale or
+// the key is unknown (e.g. after the provider rotates keys). Concurrent
+// lookups share a single fetch, made without holding the lock, and only
+// lookups of an unknown key wait for it.
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	key, ok := v.keys[kid]
+	if ok && time.Since(v.fetchedAt) < jwksRefreshInterval {
+		v.mu.Unlock()
+		return key, nil
+	}
+
+	if v.refreshing == nil && time.Since(v.attemptedAt) < jwksMinRefreshInterval {
+		v.mu.Unlock()
+		if ok {
+			return key, nil // Keep using the cached key until the provider is reachable
+		}
+		return nil, ErrUnknownKey
+	}
+
+	done := v.refreshing
+	if done == nil {
+		done = make(chan struct{})
+		v.refreshing = done
+		v.attemptedAt = time.Now()
+		go v.refresh(context.WithoutCancel(ctx), done)
+	}
+	v.mu.Unlock()
+
+	// A stale key stays good while the fresh set is fetched
+	if ok {
+		return key, nil
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if v.refreshErr != nil {
+		return nil, v.refreshErr
+	}
+	return nil, ErrUnknownKey
+}
+
+// refresh fetches the key set, storing it if the fetch succeeds, and closes
+// done
+func (v *JWTVerifier) refresh(ctx context.Context, done chan struct{}) {
+	keys, err := v.fetch(ctx)
+	if err != nil {
+		v.logger.Warn("failed to fetch jwks", "url", v.cfg.JWKSURL, "error", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err == nil {
+		v.keys = keys
+		v.fetchedAt = time.Now()
+	}
+	v.refreshErr = err
+	v.refreshing = nil
+	close(done)
+}
+
+// jwk is a single JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch downloads the key set
+func (v *JWTVerifier) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			v.logger.Debug("skipping jwk", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+// publicKey decodes the RSA or EC public key described by k
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeBigInt decodes a base64url-encoded big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"imposter/internal/config"
+)
+
+// jwksServer serves the key set from keys, or a 503 while it is nil, and
+// counts the fetches
+func jwksServer(t *testing.T, keys *atomic.Pointer[rsa.PublicKey]) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		key := keys.Load()
+		if key == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []jwk{{
+				Kid: "k1",
+				Kty: "RSA",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+// lookupConcurrently looks kid up from several goroutines at once
+func lookupConcurrently(v *JWTVerifier, kid string) []error {
+	errs := make([]error, 8)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = v.key(context.Background(), kid)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestJWTVerifierSharesOneFetch(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys atomic.Pointer[rsa.PublicKey]
+	keys.Store(&private.PublicKey)
+	server, fetches := jwksServer(t, &keys)
+
+	v := NewJWTVerifier(config.JWTConfig{JWKSURL: server.URL}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for i, err := range lookupConcurrently(v, "k1") {
+		if err != nil {
+			t.Errorf("lookup %d: %v", i, err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("%d fetches for concurrent lookups, want 1", n)
+	}
+
+	// An unknown key refetches at most once a minute
+	for range 3 {
+		if _, err := v.key(context.Background(), "k2"); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("lookup of an unknown key: err = %v, want ErrUnknownKey", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("%d fetches after looking up an unknown key, want 1", n)
+	}
+}
+
+func TestJWTVerifierFailedFetchWaitsBeforeRetrying(t *testing.T) {
+	var keys atomic.Pointer[rsa.PublicKey]
+	server, fetches := jwksServer(t, &keys)
+
+	v := NewJWTVerifier(config.JWTConfig{JWKSURL: server.URL}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	for i, err := range lookupConcurrently(v, "k1") {
+		if err == nil {
+			t.Errorf("lookup %d succeeded with the provider down", i)
+		}
+	}
+	if _, err := v.key(context.Background(), "k1"); err == nil {
+		t.Errorf("lookup succeeded with the provider down")
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("%d fetches while the provider is down, want 1", n)
+	}
+}
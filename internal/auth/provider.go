@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"imposter/internal/config"
+)
+
+// ErrUnknownProvider is returned for a login provider that is not configured
+var ErrUnknownProvider = errors.New("unknown login provider")
+
+// Identity is a player identity established by signing in with a provider
+type Identity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"` // Provider's stable user ID
+	Name     string `json:"name"`
+}
+
+// PlayerID returns the durable player ID for this identity
+func (i *Identity) PlayerID() string {
+	return i.Provider + ":" + i.Subject
+}
+
+// Provider is an OAuth 2.0 login provider
+type Provider interface {
+	// Name returns the provider's URL-safe name, e.g. "google"
+	Name() string
+
+	// AuthCodeURL returns the URL to send the user to for consent
+	AuthCodeURL(state, redirectURI string) string
+
+	// Exchange trades an authorization code for the signed-in user's identity
+	Exchange(ctx context.Context, code, redirectURI string) (*Identity, error)
+}
+
+// oauthProvider implements the authorization code flow against fixed endpoints
+type oauthProvider struct {
+	name         string
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	userInfoURL  string
+	scopes       []string
+	client       *http.Client
+
+	// parseUser extracts the subject and display name from the user info response
+	parseUser func(info map[string]interface{}) (subject, name string)
+}
+
+// NewGoogleProvider creates the "Sign in with Google" provider (OpenID Connect)
+func NewGoogleProvider(clientID, clientSecret string) Provider {
+	return &oauthProvider{
+		name:         "google",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		tokenURL:     "https://oauth2.googleapis.com/token",
+		userInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		scopes:       []string{"openid", "profile"},
+		client:       &http.Client{Timeout: 10 * time.Second},
+		parseUser: func(info map[string]interface{}) (string, string) {
+			sub, _ := info["sub"].(string)
+			name, _ := info["name"].(string)
+			return sub, name
+		},
+	}
+}
+
+// NewDiscordProvider creates the "Sign in with Discord" provider
+func NewDiscordProvider(clientID, clientSecret string) Provider {
+	return &oauthProvider{
+		name:         "discord",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authURL:      "https://discord.com/oauth2/authorize",
+		tokenURL:     "https://discord.com/api/oauth2/token",
+		userInfoURL:  "https://discord.com/api/users/@me",
+		scopes:       []string{"identify"},
+		client:       &http.Client{Timeout: 10 * time.Second},
+		parseUser: func(info map[string]interface{}) (string, string) {
+			id, _ := info["id"].(string)
+			name, _ := info["global_name"].(string)
+			if name == "" {
+				name, _ = info["username"].(string)
+			}
+			return id, name
+		},
+	}
+}
+
+// NewProviders creates the login providers that have credentials configured
+func NewProviders(cfg config.OAuthConfig) map[string]Provider {
+	providers := make(map[string]Provider)
+	if cfg.GoogleClientID != "" {
+		providers["google"] = NewGoogleProvider(cfg.GoogleClientID, cfg.GoogleClientSecret)
+	}
+	if cfg.DiscordClientID != "" {
+		providers["discord"] = NewDiscordProvider(cfg.DiscordClientID, cfg.DiscordClientSecret)
+	}
+	return providers
+}
+
+func (p *oauthProvider) Name() string {
+	return p.name
+}
+
+func (p *oauthProvider) AuthCodeURL(state, redirectURI string) string {
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"scope":         {strings.Join(p.scopes, " ")},
+		"state":         {state},
+	}
+	return p.authURL + "?" + params.Encode()
+}
+
+func (p *oauthProvider) Exchange(ctx context.Context, code, redirectURI string) (*Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token exchange: no access token")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	var info map[string]interface{}
+	if err := p.doJSON(req, &info); err != nil {
+		return nil, fmt.Errorf("user info: %w", err)
+	}
+
+	subject, name := p.parseUser(info)
+	if subject == "" {
+		return nil, ErrMissingSubject
+	}
+
+	return &Identity{
+		Provider: p.name,
+		Subject:  subject,
+		Name:     name,
+	}, nil
+}
+
+// doJSON performs req and decodes a successful JSON response into v
+func (p *oauthProvider) doJSON(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
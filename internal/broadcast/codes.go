@@ -0,0 +1,185 @@
+package broadcast
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"imposter/internal/config"
+)
+
+// roomCodeTTL bounds how long a code stays reserved after the last renewal,
+// so the codes of an instance that stopped are freed
+const roomCodeTTL = time.Hour
+
+// reserveScript reserves a code for an owner unless another owner holds it
+var reserveScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// renewScript extends a reservation if the given owner still holds it
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript frees a code if the given owner holds it
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisRoomCodes reserves room codes in Redis, so instances sharing it never
+// create rooms with the same code. Each code's key holds the ID of the
+// instance using it and expires unless that instance renews it. It
+// implements app.RoomCodes.
+type RedisRoomCodes struct {
+	client *redis.Client
+	prefix string
+	owner  string
+	ttl    time.Duration
+	logger *slog.Logger
+
+	// Codes this instance holds, whose reservations it renews
+	mu    sync.Mutex
+	codes map[string]bool
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewRedisRoomCodes connects to the Redis server at cfg.RedisURL and reserves
+// codes for cfg.InstanceID
+func NewRedisRoomCodes(cfg config.BroadcastConfig, keyPrefix string, logger *slog.Logger) (*RedisRoomCodes, error) {
+	client, err := dial(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return newRedisRoomCodes(client, keyPrefix, cfg.InstanceID, roomCodeTTL, logger), nil
+}
+
+// newRedisRoomCodes reserves codes for owner on client, each for ttl after
+// its last renewal
+func newRedisRoomCodes(client *redis.Client, keyPrefix, owner string, ttl time.Duration, logger *slog.Logger) *RedisRoomCodes {
+	c := &RedisRoomCodes{
+		client:  client,
+		prefix:  keyPrefix,
+		owner:   owner,
+		ttl:     ttl,
+		logger:  logger,
+		codes:   make(map[string]bool),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go c.renewLoop()
+
+	return c
+}
+
+// Reserve reserves roomCode for this instance unless another one holds it
+func (c *RedisRoomCodes) Reserve(ctx context.Context, roomCode string) (bool, error) {
+	reserved, err := reserveScript.Run(ctx, c.client,
+		[]string{c.key(roomCode)}, c.owner, c.ttl.Milliseconds(),
+	).Bool()
+	if reserved {
+		c.track(roomCode)
+	}
+	return reserved, err
+}
+
+// Release frees roomCode if this instance holds it
+func (c *RedisRoomCodes) Release(ctx context.Context, roomCode string) error {
+	c.mu.Lock()
+	delete(c.codes, roomCode)
+	c.mu.Unlock()
+
+	return releaseScript.Run(ctx, c.client, []string{c.key(roomCode)}, c.owner).Err()
+}
+
+// Check returns an error if Redis cannot currently be reached
+func (c *RedisRoomCodes) Check(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Close stops renewing reservations and closes the connection. The codes of
+// rooms still open stay reserved until they expire, in case they are restored.
+func (c *RedisRoomCodes) Close() error {
+	c.stop()
+	return c.client.Close()
+}
+
+// stop stops renewing reservations
+func (c *RedisRoomCodes) stop() {
+	close(c.done)
+	<-c.stopped
+}
+
+// renewLoop renews the reservations well before they expire
+func (c *RedisRoomCodes) renewLoop() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.ttl / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.renew()
+		}
+	}
+}
+
+// renew extends the reservations of the codes this instance holds
+func (c *RedisRoomCodes) renew() {
+	c.mu.Lock()
+	codes := make([]string, 0, len(c.codes))
+	for roomCode := range c.codes {
+		codes = append(codes, roomCode)
+	}
+	c.mu.Unlock()
+
+	for _, roomCode := range codes {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		held, err := renewScript.Run(ctx, c.client,
+			[]string{c.key(roomCode)}, c.owner, c.ttl.Milliseconds(),
+		).Bool()
+		cancel()
+
+		switch {
+		case err != nil:
+			c.logger.Warn("failed to renew room code reservation", "roomCode", roomCode, "error", err)
+		case !held:
+			// Released, expired, or taken over by another instance
+			c.mu.Lock()
+			delete(c.codes, roomCode)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// track records that this instance holds roomCode
+func (c *RedisRoomCodes) track(roomCode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codes[roomCode] = true
+}
+
+// key returns the key holding roomCode's owner
+func (c *RedisRoomCodes) key(roomCode string) string {
+	return c.prefix + "room:" + roomCode
+}
@@ -0,0 +1,160 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+)
+
+const (
+	// minNodeTTL keeps a node's registration from expiring between renewals
+	minNodeTTL = 3 * time.Second
+
+	// roomClaimTTL bounds how long a room outlives the last renewal of its
+	// claim, long enough for a dead node's rooms to be taken over
+	roomClaimTTL = 24 * time.Hour
+)
+
+// takeOverScript moves a room to a new owner if the dead one still owns it
+var takeOverScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner == ARGV[1] then
+	return 1
+end
+if owner ~= ARGV[2] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`)
+
+// RedisDirectory records room ownership in Redis. Rooms are claimed by
+// reserving their codes, whose keys hold the owner's ID, and each node key
+// holds the node's URL and expires unless the node renews it, so a room whose
+// owner's key is gone belongs to a dead node. It implements app.RoomDirectory.
+type RedisDirectory struct {
+	*RedisRoomCodes
+
+	self app.Node
+	ttl  time.Duration
+
+	stopHeartbeat    chan struct{}
+	heartbeatStopped chan struct{}
+}
+
+// NewRedisDirectory connects to the Redis server at redisURL and registers
+// this node
+func NewRedisDirectory(redisURL string, cfg config.ClusterConfig, self app.Node, logger *slog.Logger) (*RedisDirectory, error) {
+	client, err := dial(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &RedisDirectory{
+		self:             self,
+		ttl:              max(cfg.NodeTTL, minNodeTTL),
+		stopHeartbeat:    make(chan struct{}),
+		heartbeatStopped: make(chan struct{}),
+	}
+
+	d.RedisRoomCodes = newRedisRoomCodes(client, cfg.KeyPrefix, self.ID, roomClaimTTL, logger)
+	if err := d.register(context.Background()); err != nil {
+		d.RedisRoomCodes.Close()
+		return nil, err
+	}
+
+	go d.heartbeat()
+
+	return d, nil
+}
+
+// Self returns this node
+func (d *RedisDirectory) Self() app.Node {
+	return d.self
+}
+
+// TakeOver makes this node the room's owner if deadNodeID still owns it
+func (d *RedisDirectory) TakeOver(ctx context.Context, roomCode, deadNodeID string) (bool, error) {
+	won, err := takeOverScript.Run(ctx, d.client,
+		[]string{d.key(roomCode)}, d.self.ID, deadNodeID, roomClaimTTL.Milliseconds(),
+	).Bool()
+	if won {
+		d.track(roomCode)
+	}
+	return won, err
+}
+
+// Owner returns the room's owner and whether its node is still registered
+func (d *RedisDirectory) Owner(ctx context.Context, roomCode string) (*app.RoomOwner, error) {
+	nodeID, err := d.client.Get(ctx, d.key(roomCode)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &app.RoomOwner{Node: app.Node{ID: nodeID}}
+	url, err := d.client.Get(ctx, d.nodeKey(nodeID)).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+	case err != nil:
+		return nil, err
+	default:
+		owner.URL = url
+		owner.Alive = true
+	}
+	return owner, nil
+}
+
+// Close deregisters the node, so other nodes take its rooms over straight
+// away, and closes the connection. Room claims are kept for the takeover.
+func (d *RedisDirectory) Close() error {
+	close(d.stopHeartbeat)
+	<-d.heartbeatStopped
+	d.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := d.client.Del(ctx, d.nodeKey(d.self.ID)).Err(); err != nil {
+		d.logger.Warn("failed to deregister node", "node", d.self.ID, "error", err)
+	}
+	return d.client.Close()
+}
+
+// register records the node's URL until it next needs renewing
+func (d *RedisDirectory) register(ctx context.Context) error {
+	return d.client.Set(ctx, d.nodeKey(d.self.ID), d.self.URL, d.ttl).Err()
+}
+
+// heartbeat keeps the node registered, renewing well before it expires
+func (d *RedisDirectory) heartbeat() {
+	defer close(d.heartbeatStopped)
+
+	ticker := time.NewTicker(d.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopHeartbeat:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+			if err := d.register(ctx); err != nil {
+				d.logger.Warn("failed to renew node registration", "node", d.self.ID, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// nodeKey returns the key holding a node's URL
+func (d *RedisDirectory) nodeKey(nodeID string) string {
+	return d.prefix + "node:" + nodeID
+}
@@ -0,0 +1,132 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+// publishTimeout bounds publishing a single hub-wide event
+const publishTimeout = 2 * time.Second
+
+// RedisEventBus shares hub-wide events between instances over a single Redis
+// pub/sub channel, so each instance's subscribers see every instance's events.
+// Published events reach local subscribers once they come back from Redis.
+// It implements app.EventBus.
+type RedisEventBus struct {
+	client  *redis.Client
+	pubsub  *redis.PubSub
+	channel string
+	logger  *slog.Logger
+
+	mu       sync.RWMutex
+	handlers []app.EventHandler
+	done     chan struct{}
+}
+
+// NewRedisEventBus connects to the Redis server at cfg.RedisURL and listens
+// on cfg.EventBusChannel
+func NewRedisEventBus(cfg config.BroadcastConfig, logger *slog.Logger) (*RedisEventBus, error) {
+	client, err := dial(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	pubsub := client.Subscribe(context.Background(), cfg.EventBusChannel)
+	if _, err := pubsub.Receive(context.Background()); err != nil {
+		pubsub.Close()
+		client.Close()
+		return nil, err
+	}
+
+	b := &RedisEventBus{
+		client:  client,
+		pubsub:  pubsub,
+		channel: cfg.EventBusChannel,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+
+	go b.receive()
+
+	return b, nil
+}
+
+// Subscribe registers a handler for all subsequently received events
+func (b *RedisEventBus) Subscribe(handler app.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish sends an event to every instance. Failures are logged, since the
+// event has already been delivered to the room.
+func (b *RedisEventBus) Publish(event *domain.GameEvent) {
+	data, err := json.Marshal(wireEvent{GameEvent: event, Delta: event.Delta})
+	if err != nil {
+		b.logger.Warn("failed to encode bus event", "type", event.Type, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		b.logger.Warn("failed to publish bus event", "type", event.Type, "roomCode", event.GameID, "error", err)
+	}
+}
+
+// Check returns an error if Redis cannot currently be reached
+func (b *RedisEventBus) Check(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Close stops receiving and closes the connections
+func (b *RedisEventBus) Close() error {
+	close(b.done)
+	b.pubsub.Close()
+	return b.client.Close()
+}
+
+// receive hands events from the channel to the subscribers until closed
+func (b *RedisEventBus) receive() {
+	messages := b.pubsub.Channel()
+	for {
+		select {
+		case <-b.done:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			b.deliver(msg)
+		}
+	}
+}
+
+// deliver decodes a message and calls every subscriber with it
+func (b *RedisEventBus) deliver(msg *redis.Message) {
+	var wire wireEvent
+	if err := json.Unmarshal([]byte(msg.Payload), &wire); err != nil || wire.GameEvent == nil {
+		b.logger.Warn("invalid bus message", "channel", msg.Channel, "error", err)
+		return
+	}
+
+	event := wire.GameEvent
+	event.Delta = wire.Delta
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,237 @@
+package broadcast
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"imposter/internal/config"
+)
+
+// minPresenceTTL keeps entries from expiring between refreshes
+const minPresenceTTL = 3 * time.Second
+
+// RedisPresence shares which players are connected to each room between
+// instances. Each room is a Redis sorted set of "playerID@instance" members
+// scored by when they expire. Join and Leave only update this instance's
+// view, which a background loop writes to Redis straight away and refreshes
+// periodically, so entries left by an instance that stopped expire on their
+// own. It implements app.Presence.
+type RedisPresence struct {
+	client   *redis.Client
+	prefix   string
+	instance string
+	ttl      time.Duration
+	logger   *slog.Logger
+
+	mu    sync.Mutex
+	rooms map[string]map[string]bool // roomCode -> players connected here
+	left  map[string]map[string]bool // roomCode -> players to remove from Redis
+
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewRedisPresence connects to the Redis server at cfg.RedisURL
+func NewRedisPresence(cfg config.BroadcastConfig, logger *slog.Logger) (*RedisPresence, error) {
+	client, err := dial(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &RedisPresence{
+		client:   client,
+		prefix:   cfg.PresenceKeyPrefix,
+		instance: cfg.InstanceID,
+		ttl:      max(cfg.PresenceTTL, minPresenceTTL),
+		logger:   logger,
+		rooms:    make(map[string]map[string]bool),
+		left:     make(map[string]map[string]bool),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go p.syncLoop()
+
+	return p, nil
+}
+
+// Join records a player's connection to a room on this instance
+func (p *RedisPresence) Join(roomCode, playerID string) {
+	p.mu.Lock()
+	if p.rooms[roomCode] == nil {
+		p.rooms[roomCode] = make(map[string]bool)
+	}
+	p.rooms[roomCode][playerID] = true
+	delete(p.left[roomCode], playerID)
+	p.mu.Unlock()
+
+	p.signal()
+}
+
+// Leave forgets a player's connection to a room on this instance
+func (p *RedisPresence) Leave(roomCode, playerID string) {
+	p.mu.Lock()
+	delete(p.rooms[roomCode], playerID)
+	if len(p.rooms[roomCode]) == 0 {
+		delete(p.rooms, roomCode)
+	}
+	if p.left[roomCode] == nil {
+		p.left[roomCode] = make(map[string]bool)
+	}
+	p.left[roomCode][playerID] = true
+	p.mu.Unlock()
+
+	p.signal()
+}
+
+// Members returns the players connected to a room on any instance
+func (p *RedisPresence) Members(ctx context.Context, roomCode string) ([]string, error) {
+	entries, err := p.client.ZRangeByScore(ctx, p.prefix+roomCode, &redis.ZRangeBy{
+		Min: strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(entries))
+	members := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		playerID, _, _ := strings.Cut(entry, "@")
+		if !seen[playerID] {
+			seen[playerID] = true
+			members = append(members, playerID)
+		}
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// Check returns an error if Redis cannot currently be reached
+func (p *RedisPresence) Check(ctx context.Context) error {
+	return p.client.Ping(ctx).Err()
+}
+
+// Close removes this instance's entries and closes the connection
+func (p *RedisPresence) Close() error {
+	close(p.done)
+	<-p.stopped
+
+	p.mu.Lock()
+	for roomCode, players := range p.rooms {
+		if p.left[roomCode] == nil {
+			p.left[roomCode] = make(map[string]bool)
+		}
+		for playerID := range players {
+			p.left[roomCode][playerID] = true
+		}
+	}
+	clear(p.rooms)
+	p.mu.Unlock()
+
+	p.sync()
+	return p.client.Close()
+}
+
+// signal wakes the sync loop without blocking
+func (p *RedisPresence) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// syncLoop writes changes to Redis as they happen and refreshes this
+// instance's entries well before they expire
+func (p *RedisPresence) syncLoop() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(p.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+		p.sync()
+	}
+}
+
+// sync writes this instance's view to Redis. Removals that fail are kept to
+// be retried on the next sync.
+func (p *RedisPresence) sync() {
+	p.mu.Lock()
+	rooms := make(map[string][]string, len(p.rooms))
+	for roomCode, players := range p.rooms {
+		for playerID := range players {
+			rooms[roomCode] = append(rooms[roomCode], playerID)
+		}
+	}
+	left := p.left
+	p.left = make(map[string]map[string]bool)
+	p.mu.Unlock()
+
+	if len(rooms) == 0 && len(left) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	now := time.Now()
+	expires := float64(now.Add(p.ttl).UnixMilli())
+
+	pipe := p.client.Pipeline()
+	for roomCode, players := range left {
+		members := make([]interface{}, 0, len(players))
+		for playerID := range players {
+			members = append(members, p.member(playerID))
+		}
+		pipe.ZRem(ctx, p.prefix+roomCode, members...)
+	}
+	for roomCode, players := range rooms {
+		key := p.prefix + roomCode
+		members := make([]redis.Z, 0, len(players))
+		for _, playerID := range players {
+			members = append(members, redis.Z{Score: expires, Member: p.member(playerID)})
+		}
+		pipe.ZAdd(ctx, key, members...)
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(now.UnixMilli(), 10))
+		pipe.Expire(ctx, key, p.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		p.logger.Warn("failed to sync room presence", "error", err)
+
+		p.mu.Lock()
+		for roomCode, players := range left {
+			for playerID := range players {
+				if p.rooms[roomCode][playerID] {
+					continue
+				}
+				if p.left[roomCode] == nil {
+					p.left[roomCode] = make(map[string]bool)
+				}
+				p.left[roomCode][playerID] = true
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// member returns the sorted set member for a player connected to this instance
+func (p *RedisPresence) member(playerID string) string {
+	return playerID + "@" + p.instance
+}
@@ -0,0 +1,151 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+// wireEvent is a game event as sent between instances. GameEvent's JSON form
+// omits the delta, so it travels alongside.
+type wireEvent struct {
+	*domain.GameEvent
+	Delta interface{} `json:"delta,omitempty"`
+}
+
+// RedisBroadcaster carries room events between instances over Redis pub/sub,
+// one channel per room. It implements app.Broadcaster.
+type RedisBroadcaster struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	prefix string
+	logger *slog.Logger
+
+	mu    sync.RWMutex
+	rooms map[string]func(event *domain.GameEvent) // channel -> deliver
+	done  chan struct{}
+}
+
+// NewRedisBroadcaster connects to the Redis server at cfg.RedisURL
+func NewRedisBroadcaster(cfg config.BroadcastConfig, logger *slog.Logger) (*RedisBroadcaster, error) {
+	client, err := dial(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &RedisBroadcaster{
+		client: client,
+		pubsub: client.Subscribe(context.Background()),
+		prefix: cfg.ChannelPrefix,
+		logger: logger,
+		rooms:  make(map[string]func(event *domain.GameEvent)),
+		done:   make(chan struct{}),
+	}
+
+	go b.receive()
+
+	return b, nil
+}
+
+// Publish sends event to the room's channel
+func (b *RedisBroadcaster) Publish(ctx context.Context, event *domain.GameEvent) error {
+	data, err := json.Marshal(wireEvent{GameEvent: event, Delta: event.Delta})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.prefix+event.GameID, data).Err()
+}
+
+// Subscribe listens on the room's channel. Each room has a single session per
+// instance, so a new subscription replaces any previous one.
+func (b *RedisBroadcaster) Subscribe(roomCode string, deliver func(event *domain.GameEvent)) func() {
+	channel := b.prefix + roomCode
+
+	b.mu.Lock()
+	b.rooms[channel] = deliver
+	b.mu.Unlock()
+
+	if err := b.pubsub.Subscribe(context.Background(), channel); err != nil {
+		b.logger.Error("failed to subscribe to room channel", "channel", channel, "error", err)
+	}
+
+	return func() {
+		b.mu.Lock()
+		delete(b.rooms, channel)
+		b.mu.Unlock()
+
+		if err := b.pubsub.Unsubscribe(context.Background(), channel); err != nil {
+			b.logger.Warn("failed to unsubscribe from room channel", "channel", channel, "error", err)
+		}
+	}
+}
+
+// Check returns an error if Redis cannot currently be reached
+func (b *RedisBroadcaster) Check(ctx context.Context) error {
+	return b.client.Ping(ctx).Err()
+}
+
+// Close stops receiving and closes the connections
+func (b *RedisBroadcaster) Close() error {
+	close(b.done)
+	b.pubsub.Close()
+	return b.client.Close()
+}
+
+// receive delivers messages from all subscribed channels until closed.
+// Messages arrive in publish order per channel.
+func (b *RedisBroadcaster) receive() {
+	messages := b.pubsub.Channel()
+	for {
+		select {
+		case <-b.done:
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			b.deliver(msg)
+		}
+	}
+}
+
+// deliver decodes a message and hands it to the room's subscriber
+func (b *RedisBroadcaster) deliver(msg *redis.Message) {
+	b.mu.RLock()
+	deliver, ok := b.rooms[msg.Channel]
+	b.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var wire wireEvent
+	if err := json.Unmarshal([]byte(msg.Payload), &wire); err != nil || wire.GameEvent == nil {
+		b.logger.Warn("invalid broadcast message", "channel", msg.Channel, "error", err)
+		return
+	}
+
+	event := wire.GameEvent
+	event.Delta = wire.Delta
+	deliver(event)
+}
+
+// dial connects to the Redis server at url and checks that it responds
+func dial(url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
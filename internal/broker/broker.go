@@ -0,0 +1,121 @@
+package broker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+const (
+	// queueSize is how many events may be pending before new ones are dropped
+	queueSize = 1024
+
+	// publishTimeout bounds a single publish call
+	publishTimeout = 5 * time.Second
+)
+
+// Publisher writes messages to an external message broker
+type Publisher interface {
+	// Publish sends data to the configured topic. key groups related messages
+	// (the room code) so brokers that partition can preserve per-game ordering.
+	Publish(ctx context.Context, key string, data []byte) error
+
+	// Check returns an error if the broker cannot currently be reached
+	Check(ctx context.Context) error
+
+	Close() error
+}
+
+// NewPublisher creates the publisher selected by cfg.Type
+func NewPublisher(cfg config.BrokerConfig) (Publisher, error) {
+	switch cfg.Type {
+	case "nats":
+		return NewNATSPublisher(cfg.URLs, cfg.Topic)
+	case "kafka":
+		return NewKafkaPublisher(cfg.URLs, cfg.Topic), nil
+	default:
+		return nil, fmt.Errorf("unknown broker type %q", cfg.Type)
+	}
+}
+
+// Sink mirrors every game event to a Publisher. Events are queued and
+// published from a background goroutine so the session event loop never blocks.
+type Sink struct {
+	publisher Publisher
+	queue     chan *domain.GameEvent
+	logger    *slog.Logger
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewSink creates a sink for the given publisher and starts its worker
+func NewSink(publisher Publisher, logger *slog.Logger) *Sink {
+	s := &Sink{
+		publisher: publisher,
+		queue:     make(chan *domain.GameEvent, queueSize),
+		logger:    logger,
+		done:      make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.worker()
+
+	return s
+}
+
+// Handle queues an event for publishing. It is an app.EventHandler.
+func (s *Sink) Handle(event *domain.GameEvent) {
+	select {
+	case s.queue <- event:
+	default:
+		s.logger.Warn("broker queue full, dropping event", "type", event.Type, "gameID", event.GameID)
+	}
+}
+
+// Check reports whether the publisher's broker is reachable
+func (s *Sink) Check(ctx context.Context) error {
+	return s.publisher.Check(ctx)
+}
+
+// Close stops the worker and closes the publisher
+func (s *Sink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return s.publisher.Close()
+}
+
+// worker publishes queued events until the sink is closed
+func (s *Sink) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case event := <-s.queue:
+			s.publish(event)
+		}
+	}
+}
+
+// publish encodes and publishes a single event
+func (s *Sink) publish(event *domain.GameEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error("failed to encode broker event", "type", event.Type, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	if err := s.publisher.Publish(ctx, event.GameID, data); err != nil {
+		s.logger.Warn("failed to publish event", "type", event.Type, "gameID", event.GameID, "error", err)
+	}
+}
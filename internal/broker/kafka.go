@@ -0,0 +1,59 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBatchTimeout is how long the writer waits for more messages before
+// sending a batch. The sink publishes one event at a time and waits for each,
+// so the writer's default of a second would cap it at an event a second.
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// KafkaPublisher publishes events to a Kafka topic, keyed by room code
+type KafkaPublisher struct {
+	writer  *kafka.Writer
+	brokers []string
+}
+
+// NewKafkaPublisher creates a publisher for the given brokers and topic
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{}, // Same room code -> same partition
+			BatchTimeout: kafkaBatchTimeout,
+		},
+		brokers: brokers,
+	}
+}
+
+// Publish writes a single message
+func (p *KafkaPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: data,
+	})
+}
+
+// Check dials the brokers until one accepts a connection
+func (p *KafkaPublisher) Check(ctx context.Context) error {
+	err := errors.New("no kafka brokers configured")
+	for _, addr := range p.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
+
+// Close flushes pending messages and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,46 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes events to a NATS subject
+type NATSPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+// NewNATSPublisher connects to the given NATS servers
+func NewNATSPublisher(urls []string, subject string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(strings.Join(urls, ","), nats.Name("imposter"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSPublisher{
+		conn:    conn,
+		subject: subject,
+	}, nil
+}
+
+// Publish sends data to the configured subject. NATS does not partition, so key is unused.
+func (p *NATSPublisher) Publish(ctx context.Context, key string, data []byte) error {
+	return p.conn.Publish(p.subject, data)
+}
+
+// Check returns an error unless the connection is established
+func (p *NATSPublisher) Check(ctx context.Context) error {
+	if status := p.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("nats connection is %s", status)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
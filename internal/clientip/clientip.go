@@ -0,0 +1,19 @@
+// Package clientip carries the address a request came from, resolved through
+// trusted proxies, from the HTTP middleware to the handlers that record it.
+package clientip
+
+import "context"
+
+// ipKey is the context key for the client IP
+type ipKey struct{}
+
+// WithIP returns a copy of ctx carrying ip
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ipKey{}, ip)
+}
+
+// FromContext returns the client IP carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ipKey{}).(string)
+	return ip
+}
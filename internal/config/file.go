@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Variables read from CONFIG_FILE, which take precedence over the environment.
+// Unlike the environment, the file can change while the server runs, so it is
+// what a reload picks up. Overrides, from command-line flags, take precedence
+// over both.
+var (
+	fileMu     sync.RWMutex
+	fileValues map[string]string
+	overrides  = make(map[string]string)
+)
+
+// Override sets a variable for Load, and ReadFile for CONFIG_FILE, in place
+// of the file's and the environment's value
+func Override(key, value string) {
+	fileMu.Lock()
+	overrides[key] = value
+	fileMu.Unlock()
+}
+
+// ReadFile reads the KEY=VALUE lines of the file named by CONFIG_FILE, if it
+// is set, for Load to use. CONFIG_FILE itself is not read from the file. Blank lines and lines starting with # are skipped.
+// If the file cannot be read the values read before stay in effect.
+func ReadFile() error {
+	fileMu.RLock()
+	path, ok := overrides["CONFIG_FILE"]
+	fileMu.RUnlock()
+	if !ok {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, line)
+		}
+		values[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	fileMu.Lock()
+	fileValues = values
+	fileMu.Unlock()
+	return nil
+}
+
+// ReloadResult reports what reloading the configuration changed
+type ReloadResult struct {
+	Applied         []string `json:"applied"`         // Changed settings now in effect
+	RestartRequired []string `json:"restartRequired"` // Changed settings that take effect on restart
+	WordSources     []string `json:"wordSources"`     // Offered after reloading the word lists
+}
+
+// Changed returns the settings that differ between old and new, named by
+// their fields, e.g. "RateLimit.RoomsBurst"
+func Changed(old, new *Config) []string {
+	var changed []string
+	diffFields("", reflect.ValueOf(*old), reflect.ValueOf(*new), &changed)
+	return changed
+}
+
+// diffFields appends the names of the leaf fields of two structs that differ
+func diffFields(prefix string, old, new reflect.Value, changed *[]string) {
+	for i := 0; i < old.NumField(); i++ {
+		name := prefix + old.Type().Field(i).Name
+		if old.Field(i).Kind() == reflect.Struct && old.Field(i).Type().PkgPath() != "time" {
+			diffFields(name+".", old.Field(i), new.Field(i), changed)
+			continue
+		}
+		if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+			*changed = append(*changed, name)
+		}
+	}
+}
+
+// lookupEnv returns a variable from the overrides or CONFIG_FILE, or else from
+// the environment
+func lookupEnv(key string) (string, bool) {
+	fileMu.RLock()
+	defer fileMu.RUnlock()
+	if value, ok := overrides[key]; ok {
+		return value, true
+	}
+	if value, ok := fileValues[key]; ok {
+		return value, true
+	}
+	return os.LookupEnv(key)
+}
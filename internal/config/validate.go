@@ -0,0 +1,282 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Room code lengths the hub accepts
+const (
+	minRoomCodeLength = 4
+	maxRoomCodeLength = 10
+)
+
+// typedVars records the variables Load parses as numbers, lists of numbers
+// or booleans, so
+// Validate can report values that do not parse instead of their defaults
+// being used without a word
+var (
+	typedMu   sync.Mutex
+	typedVars = make(map[string]string) // Name -> "an integer", "a list of integers" or "a boolean"
+)
+
+// Validate reports every problem with the configuration at once, or nil
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	typedMu.Lock()
+	typed := make(map[string]string, len(typedVars))
+	for name, kind := range typedVars {
+		typed[name] = kind
+	}
+	typedMu.Unlock()
+	for name, kind := range typed {
+		value, ok := lookupEnv(name)
+		if !ok {
+			continue
+		}
+		var err error
+		switch kind {
+		case "a boolean":
+			_, err = strconv.ParseBool(value)
+		case "a list of integers":
+			for _, v := range strings.Split(value, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					if _, err = strconv.Atoi(v); err != nil {
+						break
+					}
+				}
+			}
+		default:
+			_, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			fail("%s: %q is not %s", name, value, kind)
+		}
+	}
+
+	for name, port := range map[string]string{
+		"PORT":              c.Server.Port,
+		"GRPC_PORT":         c.Server.GRPCPort,
+		"TLS_REDIRECT_PORT": c.Server.TLS.RedirectPort,
+	} {
+		if port == "" && name != "PORT" {
+			continue
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			fail("%s: %q is not a port number", name, port)
+		}
+	}
+
+	if _, err := strconv.ParseUint(c.Server.SocketMode, 8, 32); err != nil {
+		fail("LISTEN_SOCKET_MODE: %q is not an octal file mode", c.Server.SocketMode)
+	}
+	switch listen := c.Server.Listen; {
+	case listen == "", listen == "systemd":
+	case strings.HasPrefix(listen, "unix:") && len(listen) > len("unix:"):
+	default:
+		fail("LISTEN: must be unix:/path/to.sock or systemd, got %q", listen)
+	}
+
+	if len(c.Server.Listeners) > 0 {
+		if c.Server.Listen != "" {
+			fail("LISTEN: cannot be used with LISTENERS")
+		}
+		if c.Server.TLS.RedirectPort != "" {
+			fail("TLS_REDIRECT_PORT: cannot be used with LISTENERS; add a redirect:// listener")
+		}
+	}
+	var systemdListeners int
+	schemes := make(map[string]bool)
+	for _, l := range c.Server.Listeners {
+		switch l.Scheme {
+		case SchemeHTTP, SchemeHTTPS, SchemeRedirect:
+			schemes[l.Scheme] = true
+		default:
+			fail("LISTENERS: %q must start with http://, https:// or redirect://", l.String())
+			continue
+		}
+		switch {
+		case l.Addr == "systemd":
+			systemdListeners++
+		case strings.HasPrefix(l.Addr, "unix:") && len(l.Addr) > len("unix:"):
+		default:
+			if _, port, err := net.SplitHostPort(l.Addr); err != nil {
+				fail("LISTENERS: %s: address must be host:port, unix:/path/to.sock or systemd", l)
+			} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+				fail("LISTENERS: %s: %q is not a port number", l, port)
+			}
+		}
+	}
+	if systemdListeners > 1 {
+		fail("LISTENERS: systemd passes one socket, so only one listener may use it")
+	}
+	if schemes[SchemeHTTPS] && !c.TLSEnabled() {
+		fail("LISTENERS: https listeners need TLS_CERT_FILE and TLS_KEY_FILE or TLS_AUTOCERT_DOMAINS")
+	}
+	if schemes[SchemeRedirect] && !schemes[SchemeHTTPS] {
+		fail("LISTENERS: redirect listeners need an https listener to redirect to")
+	}
+
+	game := c.Game
+	if game.MinPlayers < 3 {
+		fail("MIN_PLAYERS: must be at least 3, got %d", game.MinPlayers)
+	}
+	if game.MaxPlayers < game.MinPlayers {
+		fail("MAX_PLAYERS: must be at least MIN_PLAYERS (%d), got %d", game.MinPlayers, game.MaxPlayers)
+	}
+	if game.RoomCodeLength < minRoomCodeLength || game.RoomCodeLength > maxRoomCodeLength {
+		fail("ROOM_CODE_LENGTH: must be between %d and %d, got %d", minRoomCodeLength, maxRoomCodeLength, game.RoomCodeLength)
+	}
+	for name, d := range map[string]time.Duration{
+		"VOTING_DURATION_SECONDS":        time.Duration(game.VotingDurationSeconds) * time.Second,
+		"RECONNECT_GRACE_PERIOD_SECONDS": game.ReconnectGracePeriod,
+		"ROOM_CLEANUP_INTERVAL_SECONDS":  game.CleanupInterval,
+		"EMPTY_ROOM_TTL_MINUTES":         game.EmptyRoomTTL,
+		"RECONNECT_TOKEN_TTL_MINUTES":    c.Security.ReconnectTokenTTL,
+	} {
+		if d <= 0 {
+			fail("%s: must be positive", name)
+		}
+	}
+	for name, n := range map[string]int{
+		"ROLE_REVEAL_SECONDS":       game.RoleRevealSeconds,
+		"IDLE_ROOM_TIMEOUT_HOURS":   int(game.IdleRoomTimeout),
+		"IDLE_ROOM_WARNING_MINUTES": int(game.IdleRoomWarning),
+		"MAX_ROOMS":                 game.MaxRooms,
+		"ROOM_MAX_CONNECTIONS":      game.RoomMaxConnections,
+		"WS_MAX_CONNECTIONS":        c.WebSocket.MaxConnections,
+		"BOTS_MAX_PER_ROOM":         game.RoomMaxBots,
+		"SHUTDOWN_DRAIN_SECONDS":    int(c.Server.DrainDelay),
+	} {
+		if n < 0 {
+			fail("%s: must not be negative", name)
+		}
+	}
+	if q := game.VoteQuorum; q != "connected" && q != "all" {
+		fail("VOTE_QUORUM: must be connected or all, got %q", q)
+	}
+	if t := game.TieRule; t != "imposter" && t != "revote" && t != "random" {
+		fail("TIE_RULE: must be imposter, revote or random, got %q", t)
+	}
+	if n, err := strconv.Atoi(game.Imposters); game.Imposters != "auto" && (err != nil || n < 1 || n > 4) {
+		fail("IMPOSTERS: must be auto or 1 to 4, got %q", game.Imposters)
+	}
+	if !validImposterThresholds(game.ImposterThresholds) {
+		fail("IMPOSTER_THRESHOLDS: must be up to 3 increasing player counts of at least 2, got %v", game.ImposterThresholds)
+	}
+	if e := game.EmojiPolicy; e != "allow" && e != "strip" && e != "reject" {
+		fail("EMOJI_POLICY: must be allow, strip or reject, got %q", e)
+	}
+	if p := game.ProfanityFilter; p != "off" && p != "normal" && p != "strict" {
+		fail("PROFANITY_FILTER: must be off, normal or strict, got %q", p)
+	}
+	if b := c.WebSocket.Backend; b != "gorilla" && b != "epoll" {
+		fail("WS_BACKEND: must be gorilla or epoll, got %q", b)
+	}
+	if game.DevFillBots && c.IsProduction() {
+		fail("DEV_FILL_BOTS: not allowed when ENV is production")
+	}
+	if game.IdleRoomTimeout > 0 && game.IdleRoomWarning >= game.IdleRoomTimeout {
+		fail("IDLE_ROOM_WARNING_MINUTES: must be shorter than IDLE_ROOM_TIMEOUT_HOURS")
+	}
+
+	if c.RateLimit.Enabled {
+		for name, n := range map[string]int{
+			"RATE_LIMIT_ROOMS_PER_MINUTE":    c.RateLimit.RoomsPerMinute,
+			"RATE_LIMIT_ROOMS_BURST":         c.RateLimit.RoomsBurst,
+			"RATE_LIMIT_CONNECTS_PER_MINUTE": c.RateLimit.ConnectsPerMinute,
+			"RATE_LIMIT_CONNECTS_BURST":      c.RateLimit.ConnectsBurst,
+		} {
+			if n <= 0 {
+				fail("%s: must be positive while RATE_LIMIT_ENABLED", name)
+			}
+		}
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		fail("TLS_CERT_FILE and TLS_KEY_FILE: set both or neither")
+	}
+	if tls.CertFile != "" && len(tls.AutocertDomains) > 0 {
+		fail("TLS_AUTOCERT_DOMAINS: cannot be used with TLS_CERT_FILE")
+	}
+	for name, path := range map[string]string{"TLS_CERT_FILE": tls.CertFile, "TLS_KEY_FILE": tls.KeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			fail("%s: %v", name, err)
+		}
+	}
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("LOG_LEVEL: must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	if c.Logging.Format != "json" && c.Logging.Format != "text" {
+		fail("LOG_FORMAT: must be json or text, got %q", c.Logging.Format)
+	}
+	if c.Logging.File != "" || c.Logging.AccessFile != "" {
+		if c.Logging.MaxSizeMB <= 0 {
+			fail("LOG_MAX_SIZE_MB: must be positive")
+		}
+		if c.Logging.MaxAge < 0 {
+			fail("LOG_MAX_AGE_HOURS: must not be negative")
+		}
+		if c.Logging.MaxBackups < 0 {
+			fail("LOG_MAX_BACKUPS: must not be negative")
+		}
+	}
+	if c.Logging.File != "" && c.Logging.File == c.Logging.AccessFile {
+		fail("LOG_ACCESS_FILE: must differ from LOG_FILE")
+	}
+
+	if c.Sentry.DSN != "" {
+		if u, err := url.Parse(c.Sentry.DSN); err != nil || u.Host == "" || u.User == nil || strings.Trim(u.Path, "/") == "" {
+			fail("SENTRY_DSN: must look like https://KEY@HOST/PROJECT")
+		}
+		if c.Sentry.SamplePercent < 0 || c.Sentry.SamplePercent > 100 {
+			fail("SENTRY_SAMPLE_PERCENT: must be 0 to 100, got %d", c.Sentry.SamplePercent)
+		}
+	}
+
+	// Map iteration is random; report in a stable order
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// validImposterThresholds returns true if thresholds are up to 3 increasing
+// player counts of at least 2, as domain.ImposterCount requires
+func validImposterThresholds(thresholds []int) bool {
+	if len(thresholds) > 3 {
+		return false
+	}
+	previous := 1
+	for _, threshold := range thresholds {
+		if threshold <= previous {
+			return false
+		}
+		previous = threshold
+	}
+	return true
+}
+
+// recordTyped notes that name is parsed as kind
+func recordTyped(name, kind string) {
+	typedMu.Lock()
+	typedVars[name] = kind
+	typedMu.Unlock()
+}
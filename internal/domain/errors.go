@@ -17,6 +17,26 @@ var (
 	ErrCannotVoteSelf     = errors.New("cannot vote for yourself")
 	ErrInvalidTransition  = errors.New("invalid phase transition")
 	ErrEmptyWord          = errors.New("word cannot be empty")
+	ErrWordTooLong        = errors.New("word is too long")
+	ErrEmptyNickname      = errors.New("nickname cannot be empty")
+	ErrNicknameTooLong    = errors.New("nickname is too long")
+	ErrEmojiNotAllowed    = errors.New("emoji are not allowed")
 	ErrInvalidTargetID    = errors.New("invalid vote target")
+	ErrNotACandidate      = errors.New("vote target is not one of the tied players")
+	ErrWrongPassword      = errors.New("wrong room password")
+	ErrInvalidRoomCode    = errors.New("invalid room code")
+	ErrReservedRoomCode   = errors.New("room code is reserved")
+	ErrRoomCodeTaken      = errors.New("room code is already in use")
+	ErrInvalidInvite      = errors.New("invalid invite")
+	ErrInviteExpired      = errors.New("invite has expired or been used up")
+	ErrInvalidExport      = errors.New("invalid or unsupported room export")
+	ErrServerAtCapacity   = errors.New("server is at its room limit")
+	ErrRoomQuotaExceeded  = errors.New("room is over its resource quota")
+	ErrTooManyBots        = errors.New("room has as many bots as it allows")
+	ErrUnknownWordSource  = errors.New("unknown word source")
+	ErrInvalidImposters   = errors.New("invalid imposter count")
+	ErrPracticeRoom       = errors.New("practice rooms are for a single player")
+	ErrFamilyWordSource   = errors.New("family-friendly rooms deal from the family word list")
+	ErrInappropriateText  = errors.New("text is not allowed in this room")
 )
 
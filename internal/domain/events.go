@@ -6,18 +6,26 @@ import "time"
 type EventType string
 
 const (
-	EventPlayerJoined      EventType = "PLAYER_JOINED"
-	EventPlayerLeft        EventType = "PLAYER_LEFT"
-	EventPlayerReconnected EventType = "PLAYER_RECONNECTED"
-	EventGameStarted       EventType = "GAME_STARTED"
-	EventRolesAssigned     EventType = "ROLES_ASSIGNED"
-	EventSubmissionMade    EventType = "SUBMISSION_MADE"
-	EventAllSubmitted      EventType = "ALL_SUBMITTED"
-	EventVotingStarted     EventType = "VOTING_STARTED"
-	EventVoteCast          EventType = "VOTE_CAST"
-	EventRoundEnded        EventType = "ROUND_ENDED"
-	EventGameEnded         EventType = "GAME_ENDED"
-	EventError             EventType = "ERROR"
+	EventGameCreated            EventType = "GAME_CREATED"
+	EventGameDeleted            EventType = "GAME_DELETED"
+	EventPlayerJoined           EventType = "PLAYER_JOINED"
+	EventPlayerLeft             EventType = "PLAYER_LEFT"
+	EventPlayerReconnected      EventType = "PLAYER_RECONNECTED"
+	EventGameStarted            EventType = "GAME_STARTED"
+	EventPhaseChanged           EventType = "PHASE_CHANGED"
+	EventRolesAssigned          EventType = "ROLES_ASSIGNED"
+	EventSubmissionPhaseStarted EventType = "SUBMISSION_PHASE_STARTED"
+	EventSubmissionMade         EventType = "SUBMISSION_MADE"
+	EventAllSubmitted           EventType = "ALL_SUBMITTED"
+	EventVotingStarted          EventType = "VOTING_STARTED"
+	EventVotingCountdown        EventType = "VOTING_COUNTDOWN"
+	EventVoteCast               EventType = "VOTE_CAST"
+	EventRoundEnded             EventType = "ROUND_ENDED"
+	EventGameEnded              EventType = "GAME_ENDED"
+	EventRoomExpiring           EventType = "ROOM_EXPIRING"
+	EventCheatSuspected         EventType = "CHEAT_SUSPECTED"
+	EventTournamentUpdate       EventType = "TOURNAMENT_UPDATE"
+	EventError                  EventType = "ERROR"
 )
 
 // GameEvent represents an event that occurred in the game
@@ -27,6 +35,18 @@ type GameEvent struct {
 	PlayerID  string      `json:"playerId,omitempty"` // If event is player-specific
 	Payload   interface{} `json:"payload,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
+	Seq       uint64      `json:"seq,omitempty"` // Per-recipient sequence number, set on delivery
+
+	// Compact alternative to Payload for clients that already hold the state
+	// it updates; nil if the event has no delta form
+	Delta interface{} `json:"-"`
+}
+
+// WithSeq returns a copy of the event carrying the recipient's sequence number
+func (e *GameEvent) WithSeq(seq uint64) *GameEvent {
+	sequenced := *e
+	sequenced.Seq = seq
+	return &sequenced
 }
 
 // NewEvent creates a new game event
@@ -50,8 +70,75 @@ func NewPlayerEvent(eventType EventType, gameID, playerID string, payload interf
 	}
 }
 
+// IsLowPriority returns true for events that are superseded by the next one
+// of their kind (vote progress counts) and may be dropped for a lagging client
+func (e *GameEvent) IsLowPriority() bool {
+	return e.Type == EventVoteCast
+}
+
+// IsCritical returns true for events a player cannot do without: their role
+// and the round results. Clients that acknowledge events are sent these again
+// until they do.
+func (e *GameEvent) IsCritical() bool {
+	return e.Type == EventRolesAssigned || e.Type == EventRoundEnded
+}
+
 // Payload types for different events
 
+// RoomExpiringPayload is sent when an idle room is about to be closed
+type RoomExpiringPayload struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SuspicionKind identifies a pattern of play that suggests cheating
+type SuspicionKind string
+
+const (
+	SuspicionSecretWord   SuspicionKind = "SECRET_WORD"   // A vilek submitted the secret word itself
+	SuspicionSharedClue   SuspicionKind = "SHARED_CLUE"   // Players on the same address submitted the same clue
+	SuspicionPerfectVotes SuspicionKind = "PERFECT_VOTES" // A player has only ever voted for the imposter
+)
+
+// CheatSuspectedPayload is sent to the host alone when a round ends with
+// suspicious play. It is a hint for the host, not proof.
+type CheatSuspectedPayload struct {
+	Kind      SuspicionKind `json:"kind"`
+	Round     int           `json:"round"`
+	PlayerIDs []string      `json:"playerIds"`
+	Nicknames []string      `json:"nicknames"`
+	Detail    string        `json:"detail"`
+}
+
+// TournamentChange is what happened in a tournament
+type TournamentChange string
+
+const (
+	TournamentStageStarted TournamentChange = "STAGE_STARTED" // Players were assigned to the stage's rooms
+	TournamentMatchEnded   TournamentChange = "MATCH_ENDED"   // A room played its rounds, or closed early
+	TournamentFinished     TournamentChange = "FINISHED"      // The winner is decided
+)
+
+// TournamentStanding is a registered player's place in a tournament
+type TournamentStanding struct {
+	Rank       int    `json:"rank"`
+	PlayerID   string `json:"playerId"`
+	Nickname   string `json:"nickname"`
+	Points     int    `json:"points"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// TournamentUpdatePayload is sent to every room of a tournament as it
+// progresses, with the standings so far
+type TournamentUpdatePayload struct {
+	TournamentID string               `json:"tournamentId"`
+	Name         string               `json:"name"`
+	Change       TournamentChange     `json:"change"`
+	Stage        int                  `json:"stage"`
+	RoomCode     string               `json:"roomCode,omitempty"` // The room whose match ended
+	WinnerID     string               `json:"winnerId,omitempty"`
+	Standings    []TournamentStanding `json:"standings"`
+}
+
 // LobbyUpdatePayload is sent when lobby state changes
 type LobbyUpdatePayload struct {
 	Players  []PlayerInfo `json:"players"`
@@ -59,49 +146,108 @@ type LobbyUpdatePayload struct {
 	CanStart bool         `json:"canStart"`
 }
 
+// LobbyDeltaPayload is the delta form of LobbyUpdatePayload: only the players
+// that changed are included
+type LobbyDeltaPayload struct {
+	Changed  []PlayerInfo `json:"changed,omitempty"` // Players who joined or whose status changed
+	Removed  []string     `json:"removed,omitempty"` // IDs of players who left the room
+	HostID   string       `json:"hostId"`
+	CanStart bool         `json:"canStart"`
+}
+
+// GameStartedPayload is sent when the host starts the game
+type GameStartedPayload struct {
+	RoundNumber int `json:"roundNumber"`
+	PlayerCount int `json:"playerCount"`
+}
+
+// PhaseChangedPayload is sent whenever the game moves to another phase,
+// ahead of the event that starts the new phase
+type PhaseChangedPayload struct {
+	From  Phase `json:"from"`
+	To    Phase `json:"to"`
+	Round int   `json:"round,omitempty"` // Number of the round the game is in, or is leaving for the lobby
+}
+
 // RoleAssignedPayload is sent to each player with their role
 type RoleAssignedPayload struct {
 	Role       Role   `json:"role"`
 	SecretWord string `json:"secretWord,omitempty"` // Only for VILEKs
 }
 
-// SubmissionPhasePayload is sent when submission phase starts
+// SubmissionPhasePayload is sent as SUBMISSION_PHASE_STARTED when the
+// submission phase starts
 type SubmissionPhasePayload struct {
 	CurrentPlayerID string        `json:"currentPlayerId"`
 	PlayerOrder     []PlayerInfo  `json:"playerOrder"`
 	Submissions     []*Submission `json:"submissions"`
 }
 
-// SubmissionUpdatePayload is sent when a new submission is made
+// SubmissionUpdatePayload is sent as SUBMISSION_MADE when a new submission is made
 type SubmissionUpdatePayload struct {
 	Submissions     []*Submission `json:"submissions"`
 	CurrentPlayerID string        `json:"currentPlayerId"`
 	IsComplete      bool          `json:"isComplete"`
 }
 
-// VotingPhasePayload is sent when voting phase starts
+// SubmissionDeltaPayload is the delta form of SubmissionUpdatePayload: only the
+// newest submission is included
+type SubmissionDeltaPayload struct {
+	Added           *Submission `json:"added"`
+	CurrentPlayerID string      `json:"currentPlayerId"`
+	IsComplete      bool        `json:"isComplete"`
+}
+
+// VotingPhasePayload is sent when voting phase starts. Clients count down to
+// Deadline locally; comparing it with the event timestamp avoids clock skew.
 type VotingPhasePayload struct {
 	RemainingSeconds int          `json:"remainingSeconds"`
+	Deadline         time.Time    `json:"deadline"`
 	Players          []PlayerInfo `json:"players"`
+	Candidates       []string     `json:"candidates,omitempty"` // In a revote after a tie, the only players who may be voted for
 }
 
-// VotingCountdownPayload is sent every second during voting
+// VotingCountdownPayload is sent as VOTING_COUNTDOWN when the voting deadline
+// moves without the phase starting over, e.g. an operator restarting the
+// timer. Votes already cast stand.
 type VotingCountdownPayload struct {
-	RemainingSeconds int `json:"remainingSeconds"`
+	RemainingSeconds int       `json:"remainingSeconds"`
+	Deadline         time.Time `json:"deadline"`
 }
 
-// VoteUpdatePayload is sent when a vote is cast (without revealing who)
+// VoteUpdatePayload is sent as VOTE_CAST when a vote is cast (without revealing
+// who), or when a voter disconnects, reconnects or leaves
 type VoteUpdatePayload struct {
-	VotedCount   int `json:"votedCount"`
-	TotalPlayers int `json:"totalPlayers"`
+	VotedCount   int `json:"votedCount"`   // Voters who have voted
+	TotalPlayers int `json:"totalPlayers"` // Voters the vote waits for, per the room's quorum rule
 }
 
 // RoundResultsPayload is sent when a round ends
 type RoundResultsPayload struct {
-	Votes      []VoteResult `json:"votes"`
-	ImposterID string       `json:"imposterId"`
-	Winner     Role         `json:"winner"`
-	SecretWord string       `json:"secretWord"`
+	Votes       []VoteResult `json:"votes"`
+	ImposterID  string       `json:"imposterId"` // The first of imposterIds, for clients that expect one imposter
+	ImposterIDs []string     `json:"imposterIds"`
+	Winner      Role         `json:"winner"`
+	SecretWord  string       `json:"secretWord"`
+	AccusedID   string       `json:"accusedId,omitempty"` // Player the vote caught; empty if a tie or no votes caught nobody
+	Tied        []string     `json:"tied,omitempty"`      // Players who shared the most votes
+
+	// How the audience voted; omitted if nobody watching voted
+	Audience *AudienceSuspicion `json:"audience,omitempty"`
+}
+
+// AudienceSuspicion is how spectators and viewers voting through a chat
+// bridge voted in a round. Their votes do not count toward the result.
+type AudienceSuspicion struct {
+	Voters  int               `json:"voters"`
+	Players []AudienceSuspect `json:"players"` // Most suspected first; players nobody voted for are left out
+}
+
+// AudienceSuspect is how many of the audience suspected a player
+type AudienceSuspect struct {
+	PlayerID string  `json:"playerId"`
+	Votes    int     `json:"votes"`
+	Share    float64 `json:"share"` // Of the audience's votes, from 0 to 1
 }
 
 // ErrorPayload is sent when an error occurs
@@ -109,4 +255,3 @@ type ErrorPayload struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
-
@@ -1,7 +1,9 @@
 package domain
 
 import (
-	"strings"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"time"
 )
 
@@ -11,8 +13,15 @@ type GameSettings struct {
 	MaxPlayers     int           `json:"maxPlayers"`
 	VotingDuration time.Duration `json:"votingDuration"`
 	RoleRevealTime time.Duration `json:"roleRevealTime"`
+	VoteQuorum     VoteQuorum    `json:"voteQuorum,omitempty"` // Empty is QuorumConnected
+	TieRule        TieRule       `json:"tieRule,omitempty"`    // Empty is TieImposterWins
+	Emoji          EmojiPolicy   `json:"emoji,omitempty"`      // In nicknames and clues; empty is EmojiAllow
+	Imposters      ImposterCount `json:"imposters"`            // How many imposters each round deals
 }
 
+// PracticeMinPlayers is how many players a practice game needs to start
+const PracticeMinPlayers = 2
+
 // DefaultGameSettings returns the default game settings
 func DefaultGameSettings() GameSettings {
 	return GameSettings{
@@ -20,19 +29,91 @@ func DefaultGameSettings() GameSettings {
 		MaxPlayers:     10,
 		VotingDuration: 20 * time.Second,
 		RoleRevealTime: 5 * time.Second,
+		VoteQuorum:     QuorumConnected,
+		TieRule:        TieImposterWins,
+		Emoji:          EmojiAllow,
 	}
 }
 
+// VoteQuorum decides whose votes a round's vote waits for and counts. Players
+// who left the game or were not dealt into the round never vote.
+type VoteQuorum string
+
+const (
+	QuorumConnected VoteQuorum = "connected" // Players in the round who are connected
+	QuorumAll       VoteQuorum = "all"       // Players in the round, connected or not
+)
+
+// IsValid returns true if q is a known quorum rule
+func (q VoteQuorum) IsValid() bool {
+	return q == QuorumConnected || q == QuorumAll
+}
+
+// TieRule decides a vote in which several players share the most votes
+type TieRule string
+
+const (
+	TieImposterWins TieRule = "imposter" // Nobody is caught, so the imposter wins
+	TieRevote       TieRule = "revote"   // The tied players face one revote; if that ties too, the imposter wins
+	TieRandom       TieRule = "random"   // One of the tied players is picked at random
+)
+
+// IsValid returns true if t is a known tie rule
+func (t TieRule) IsValid() bool {
+	return t == TieImposterWins || t == TieRevote || t == TieRandom
+}
+
+// Visibility controls whether a room appears in the public room list
+type Visibility string
+
+const (
+	VisibilityUnlisted Visibility = "unlisted" // Joinable only with the room code
+	VisibilityPublic   Visibility = "public"   // Listed for anyone browsing open games
+)
+
+// IsValid returns true if v is a known visibility
+func (v Visibility) IsValid() bool {
+	return v == VisibilityUnlisted || v == VisibilityPublic
+}
+
 // Game represents a game room
 type Game struct {
-	ID           string             `json:"id"`
-	HostID       string             `json:"hostId"`
-	Players      map[string]*Player `json:"players"`
-	CurrentRound *Round             `json:"currentRound,omitempty"`
-	RoundHistory []*Round           `json:"roundHistory"`
-	Phase        Phase              `json:"phase"`
-	Settings     GameSettings       `json:"settings"`
-	CreatedAt    time.Time          `json:"createdAt"`
+	ID             string             `json:"id"`
+	HostID         string             `json:"hostId"`
+	Players        map[string]*Player `json:"players"`
+	CurrentRound   *Round             `json:"currentRound,omitempty"`
+	RoundHistory   []*Round           `json:"roundHistory"`
+	Phase          Phase              `json:"phase"`
+	Settings       GameSettings       `json:"settings"`
+	Visibility     Visibility         `json:"visibility"`
+	WordSource     string             `json:"wordSource,omitempty"`     // Where secret words come from; empty uses the server default
+	Language       string             `json:"language,omitempty"`       // Language the players speak, e.g. "en", for matchmaking
+	Tags           []string           `json:"tags,omitempty"`           // Chosen by the creator, e.g. "casual", for the room browser
+	FamilyFriendly bool               `json:"familyFriendly,omitempty"` // Family words only, and players' text checked strictly
+	Practice       bool               `json:"practice,omitempty"`       // One player against bots, without the MinPlayers check
+	PracticeRole   Role               `json:"practiceRole,omitempty"`   // The practicing player's role every round; empty deals it at random
+	CreatedAt      time.Time          `json:"createdAt"`
+
+	// Latest phase transitions, oldest first, for debugging
+	PhaseHistory []PhaseTransition `json:"phaseHistory,omitempty"`
+
+	// Salted hash of the room password; empty if the room is not locked
+	passwordSalt []byte
+	passwordHash []byte
+
+	// Outstanding invites by token, created by the host
+	invites map[string]*Invite
+
+	// Seed of the game's randomness, kept from players so they cannot
+	// predict rounds; 0 uses DefaultRand
+	seed int64
+	rng  Rand
+
+	// Receives the events the game raises itself; nil drops them
+	emit func(*GameEvent)
+
+	// Decides whether players may use a cleaned nickname or clue; nil allows any
+	allowsText func(text string) bool
 }
 
 // NewGame creates a new game with the given ID
@@ -45,16 +126,123 @@ func NewGame(id string) *Game {
 		RoundHistory: make([]*Round, 0),
 		Phase:        PhaseLobby,
 		Settings:     DefaultGameSettings(),
+		Visibility:   VisibilityUnlisted,
 		CreatedAt:    time.Now(),
 	}
 }
 
-// AddPlayer adds a player to the game
+// SetPassword locks the room with password. An empty password unlocks it.
+func (g *Game) SetPassword(password string) {
+	if password == "" {
+		g.passwordSalt = nil
+		g.passwordHash = nil
+		return
+	}
+
+	g.passwordSalt = make([]byte, 16)
+	rand.Read(g.passwordSalt)
+	g.passwordHash = hashPassword(g.passwordSalt, password)
+}
+
+// SetEmitter passes the events the game raises itself, such as
+// PHASE_CHANGED, to emit
+func (g *Game) SetEmitter(emit func(*GameEvent)) {
+	g.emit = emit
+}
+
+// SetTextFilter has allows decide whether players may use a nickname or clue,
+// once cleaned up as the game shows it
+func (g *Game) SetTextFilter(allows func(text string) bool) {
+	g.allowsText = allows
+}
+
+// transition moves the game to target if the state machine allows it,
+// recording the change and emitting PHASE_CHANGED. Every phase change goes
+// through here.
+func (g *Game) transition(target Phase) error {
+	if !g.Phase.CanTransitionTo(target) {
+		return ErrInvalidTransition
+	}
+
+	change := PhaseTransition{From: g.Phase, To: target, At: time.Now()}
+	if g.CurrentRound != nil {
+		change.Round = g.CurrentRound.Number
+	}
+	g.Phase = target
+
+	g.PhaseHistory = append(g.PhaseHistory, change)
+	if n := len(g.PhaseHistory) - maxPhaseHistory; n > 0 {
+		g.PhaseHistory = g.PhaseHistory[n:]
+	}
+
+	if g.emit != nil {
+		g.emit(NewEvent(EventPhaseChanged, g.ID, &PhaseChangedPayload{
+			From:  change.From,
+			To:    change.To,
+			Round: change.Round,
+		}))
+	}
+	return nil
+}
+
+// SetSeed makes every round the game deals from now on follow from seed, so
+// games with the same seed and players play out alike. A seed of 0 makes the
+// game random again.
+func (g *Game) SetSeed(seed int64) {
+	g.seed = seed
+	g.rng = nil
+}
+
+// Seed returns the game's seed, or 0 if it has none
+func (g *Game) Seed() int64 {
+	return g.seed
+}
+
+// Rand returns the randomness the game deals rounds with. A seeded game's
+// sequence starts from its seed and the number of rounds played, so a game
+// restored from a snapshot carries on reproducibly.
+func (g *Game) Rand() Rand {
+	if g.seed == 0 {
+		return DefaultRand
+	}
+	if g.rng == nil {
+		g.rng = NewSeededRand(g.seed + int64(len(g.RoundHistory)))
+	}
+	return g.rng
+}
+
+// IsLocked returns true if joining the room requires a password
+func (g *Game) IsLocked() bool {
+	return len(g.passwordHash) > 0
+}
+
+// CheckPassword returns true if password unlocks the room
+func (g *Game) CheckPassword(password string) bool {
+	if !g.IsLocked() {
+		return true
+	}
+	return hmac.Equal(hashPassword(g.passwordSalt, password), g.passwordHash)
+}
+
+// hashPassword returns the salted SHA-256 hash of password
+func hashPassword(salt []byte, password string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+	return h.Sum(nil)
+}
+
+// AddPlayer adds a player to the game, under their nickname cleaned up with CleanNickname
 func (g *Game) AddPlayer(playerID, nickname string) (*Player, error) {
 	if g.Phase != PhaseLobby {
 		return nil, ErrGameAlreadyStarted
 	}
 
+	nickname, err := g.CleanNickname(nickname)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(g.Players) >= g.Settings.MaxPlayers {
 		return nil, ErrGameFull
 	}
@@ -78,11 +266,16 @@ func (g *Game) RemovePlayer(playerID string) error {
 
 	delete(g.Players, playerID)
 
-	// If host left, assign new host
+	// If host left, assign new host, preferring a player who is not a bot
 	if g.HostID == playerID && len(g.Players) > 0 {
-		for id := range g.Players {
-			g.HostID = id
-			break
+		g.HostID = ""
+		for id, player := range g.Players {
+			if g.HostID == "" || !player.IsBot {
+				g.HostID = id
+			}
+			if !player.IsBot {
+				break
+			}
 		}
 	}
 
@@ -118,9 +311,29 @@ func (g *Game) GetConnectedPlayerCount() int {
 	return count
 }
 
+// GetHumanCount returns the number of players who are not bots
+func (g *Game) GetHumanCount() int {
+	count := 0
+	for _, player := range g.Players {
+		if !player.IsBot {
+			count++
+		}
+	}
+	return count
+}
+
+// minPlayers returns how many players the game needs to start. Practice games
+// only need someone to vote for.
+func (g *Game) minPlayers() int {
+	if g.Practice {
+		return PracticeMinPlayers
+	}
+	return g.Settings.MinPlayers
+}
+
 // CanStart checks if the game can be started
 func (g *Game) CanStart() bool {
-	return g.Phase == PhaseLobby && len(g.Players) >= g.Settings.MinPlayers
+	return g.Phase == PhaseLobby && len(g.Players) >= g.minPlayers()
 }
 
 // StartRound starts a new round with the given secret word
@@ -129,7 +342,7 @@ func (g *Game) StartRound(secretWord string) error {
 		return ErrInvalidPhase
 	}
 
-	if len(g.Players) < g.Settings.MinPlayers {
+	if len(g.Players) < g.minPlayers() {
 		return ErrNotEnoughPlayers
 	}
 
@@ -140,29 +353,46 @@ func (g *Game) StartRound(secretWord string) error {
 
 	// Create new round
 	roundNumber := len(g.RoundHistory) + 1
-	g.CurrentRound = NewRound(roundNumber, secretWord, g.GetPlayerIDs())
+	imposters := g.Settings.Imposters.For(len(g.Players))
+	g.CurrentRound = NewRound(roundNumber, secretWord, g.GetPlayerIDs(), imposters, g.Rand())
+	if g.Practice && g.PracticeRole != "" {
+		g.dealPracticeRole()
+	}
 
 	// Assign roles to players
 	for playerID, player := range g.Players {
-		if playerID == g.CurrentRound.ImposterID {
+		if g.CurrentRound.IsImposter(playerID) {
 			player.Role = RoleImposter
 		} else {
 			player.Role = RoleVilek
 		}
 	}
 
-	g.Phase = PhaseRoleAssignment
+	return g.transition(PhaseRoleAssignment)
+}
 
-	return nil
+// dealPracticeRole makes the human player the imposter, or a bot if they
+// practice as a Vilek. A practice round deals one imposter.
+func (g *Game) dealPracticeRole() {
+	var humans, bots []string
+	for id, player := range g.Players {
+		if player.IsBot {
+			bots = append(bots, id)
+		} else {
+			humans = append(humans, id)
+		}
+	}
+
+	candidates := bots
+	if g.PracticeRole == RoleImposter {
+		candidates = humans
+	}
+	g.CurrentRound.chooseImposter(g.Rand(), candidates)
 }
 
 // TransitionToSubmission moves to submission phase
 func (g *Game) TransitionToSubmission() error {
-	if g.Phase != PhaseRoleAssignment {
-		return ErrInvalidTransition
-	}
-	g.Phase = PhaseSubmission
-	return nil
+	return g.transition(PhaseSubmission)
 }
 
 // SubmitWord submits a word for the current player
@@ -175,9 +405,9 @@ func (g *Game) SubmitWord(playerID, word string) error {
 		return ErrInvalidPhase
 	}
 
-	word = strings.TrimSpace(word)
-	if word == "" {
-		return ErrEmptyWord
+	word, err := g.CleanWord(word)
+	if err != nil {
+		return err
 	}
 
 	player, err := g.GetPlayer(playerID)
@@ -209,11 +439,7 @@ func (g *Game) AllSubmitted() bool {
 
 // TransitionToVoting moves to voting phase
 func (g *Game) TransitionToVoting() error {
-	if g.Phase != PhaseSubmission {
-		return ErrInvalidTransition
-	}
-	g.Phase = PhaseVoting
-	return nil
+	return g.transition(PhaseVoting)
 }
 
 // CastVote casts a vote from one player for another
@@ -230,6 +456,10 @@ func (g *Game) CastVote(voterID, targetID string) error {
 		return ErrCannotVoteSelf
 	}
 
+	if !g.CurrentRound.IsCandidate(targetID) {
+		return ErrNotACandidate
+	}
+
 	voter, err := g.GetPlayer(voterID)
 	if err != nil {
 		return err
@@ -254,12 +484,53 @@ func (g *Game) CastVote(voterID, targetID string) error {
 	return nil
 }
 
-// AllVoted checks if all players have voted
+// Voters returns the IDs of the players whose votes the current round waits
+// for and counts: those dealt into it who are still in the game and, unless
+// the quorum is QuorumAll, connected
+func (g *Game) Voters() []string {
+	if g.CurrentRound == nil {
+		return nil
+	}
+
+	voters := make([]string, 0, len(g.CurrentRound.PlayerOrder))
+	for _, id := range g.CurrentRound.PlayerOrder {
+		player, ok := g.Players[id]
+		if !ok || (g.Settings.VoteQuorum != QuorumAll && !player.IsConnected()) {
+			continue
+		}
+		voters = append(voters, id)
+	}
+	return voters
+}
+
+// AllVoted checks if every voter has voted
 func (g *Game) AllVoted() bool {
 	if g.CurrentRound == nil {
 		return false
 	}
-	return g.CurrentRound.AllVoted(len(g.Players))
+	return g.CurrentRound.AllVoted(g.Voters())
+}
+
+// StartRevote holds the vote again among the players who share the most
+// votes, if the room's tie rule calls for it and the round has not had its
+// revote yet. It returns false if the round should end instead.
+func (g *Game) StartRevote() bool {
+	round := g.CurrentRound
+	if g.Phase != PhaseVoting || round == nil || g.Settings.TieRule != TieRevote || len(round.Candidates) > 0 {
+		return false
+	}
+
+	tied := leaders(round.tally(g.Players, g.Voters()))
+	if len(tied) < 2 {
+		return false
+	}
+
+	round.Candidates = tied
+	round.Votes = make([]*Vote, 0)
+	for _, player := range g.Players {
+		player.HasVoted = false
+	}
+	return true
 }
 
 // EndRound ends the current round and calculates results
@@ -272,13 +543,30 @@ func (g *Game) EndRound() ([]VoteResult, Role, error) {
 		return nil, "", ErrInvalidPhase
 	}
 
-	results, winner := g.CurrentRound.CalculateResults(g.Players)
+	g.CurrentRound.Voters = g.Voters()
+	results, winner := g.CurrentRound.CalculateResults(g.Players, g.Settings.TieRule, g.Rand())
 	g.RoundHistory = append(g.RoundHistory, g.CurrentRound)
-	g.Phase = PhaseResults
+	if err := g.transition(PhaseResults); err != nil {
+		return nil, "", err
+	}
 
 	return results, winner, nil
 }
 
+// ReturnToLobby ends the game after a round and reopens the lobby
+func (g *Game) ReturnToLobby() error {
+	if err := g.transition(PhaseLobby); err != nil {
+		return err
+	}
+
+	for _, player := range g.Players {
+		player.ResetForNewRound()
+	}
+	g.CurrentRound = nil
+
+	return nil
+}
+
 // GetLobbyState returns the current lobby state for broadcasting
 func (g *Game) GetLobbyState() *LobbyUpdatePayload {
 	players := make([]PlayerInfo, 0, len(g.Players))
@@ -293,6 +581,22 @@ func (g *Game) GetLobbyState() *LobbyUpdatePayload {
 	}
 }
 
+// GetLobbyDelta returns the lobby changes for the given joined or updated
+// players and removed player IDs
+func (g *Game) GetLobbyDelta(changed []string, removed []string) *LobbyDeltaPayload {
+	delta := &LobbyDeltaPayload{
+		Removed:  removed,
+		HostID:   g.HostID,
+		CanStart: g.CanStart(),
+	}
+	for _, id := range changed {
+		if p, ok := g.Players[id]; ok {
+			delta.Changed = append(delta.Changed, p.ToInfo())
+		}
+	}
+	return delta
+}
+
 // GetSubmissionState returns the current submission phase state
 func (g *Game) GetSubmissionState() *SubmissionUpdatePayload {
 	if g.CurrentRound == nil {
@@ -306,15 +610,30 @@ func (g *Game) GetSubmissionState() *SubmissionUpdatePayload {
 	}
 }
 
+// GetSubmissionDelta returns the newest submission and the resulting turn state
+func (g *Game) GetSubmissionDelta() *SubmissionDeltaPayload {
+	if g.CurrentRound == nil || len(g.CurrentRound.Submissions) == 0 {
+		return nil
+	}
+
+	submissions := g.CurrentRound.Submissions
+	return &SubmissionDeltaPayload{
+		Added:           submissions[len(submissions)-1],
+		CurrentPlayerID: g.CurrentRound.GetCurrentPlayerID(),
+		IsComplete:      g.CurrentRound.AllSubmitted(),
+	}
+}
+
 // GetVoteProgress returns the current voting progress
 func (g *Game) GetVoteProgress() *VoteUpdatePayload {
 	if g.CurrentRound == nil {
 		return nil
 	}
 
+	voters := g.Voters()
 	return &VoteUpdatePayload{
-		VotedCount:   g.CurrentRound.GetVotedCount(),
-		TotalPlayers: len(g.Players),
+		VotedCount:   g.CurrentRound.CountVotes(voters),
+		TotalPlayers: len(voters),
 	}
 }
 
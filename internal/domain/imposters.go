@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+)
+
+// MaxImposters is the most imposters a room may ask a round to deal
+const MaxImposters = 4
+
+// DefaultImposterThresholds deal 1 imposter up to 6 players, 2 up to 10 and
+// 3 beyond
+var DefaultImposterThresholds = []int{6, 10}
+
+// ImposterCount decides how many imposters each round deals: a fixed number,
+// or in auto mode one more each time the players dealt in pass a threshold.
+// The zero value deals one.
+type ImposterCount struct {
+	Auto       bool  `json:"auto,omitempty"`
+	Fixed      int   `json:"fixed,omitempty"`      // Unless Auto; 0 is 1
+	Thresholds []int `json:"thresholds,omitempty"` // Auto: the most players dealt 1 imposter, then 2, and so on; empty uses DefaultImposterThresholds
+}
+
+// ParseImposterCount reads mode, "auto" or a number of imposters, with the
+// thresholds auto mode scales by. An empty mode is the zero value.
+func ParseImposterCount(mode string, thresholds []int) (ImposterCount, error) {
+	var count ImposterCount
+	switch mode = strings.TrimSpace(mode); mode {
+	case "":
+	case "auto":
+		count.Auto = true
+		count.Thresholds = thresholds
+	default:
+		n, err := strconv.Atoi(mode)
+		if err != nil {
+			return ImposterCount{}, ErrInvalidImposters
+		}
+		count.Fixed = n
+	}
+	if err := count.Validate(); err != nil {
+		return ImposterCount{}, err
+	}
+	return count, nil
+}
+
+// Validate returns ErrInvalidImposters unless the count is between 1 and
+// MaxImposters, or auto mode has up to MaxImposters-1 increasing thresholds
+// of at least PracticeMinPlayers
+func (c ImposterCount) Validate() error {
+	if !c.Auto {
+		if c.Fixed < 0 || c.Fixed > MaxImposters || len(c.Thresholds) > 0 {
+			return ErrInvalidImposters
+		}
+		return nil
+	}
+
+	if c.Fixed != 0 || len(c.Thresholds) > MaxImposters-1 {
+		return ErrInvalidImposters
+	}
+	previous := PracticeMinPlayers - 1
+	for _, threshold := range c.Thresholds {
+		if threshold <= previous {
+			return ErrInvalidImposters
+		}
+		previous = threshold
+	}
+	return nil
+}
+
+// For returns how many imposters a round with players dealt in deals. The
+// imposters are always outnumbered, so small rounds deal fewer than asked,
+// though never none.
+func (c ImposterCount) For(players int) int {
+	n := max(c.Fixed, 1)
+	if c.Auto {
+		thresholds := c.Thresholds
+		if len(thresholds) == 0 {
+			thresholds = DefaultImposterThresholds
+		}
+		n = 1
+		for _, threshold := range thresholds {
+			if players > threshold {
+				n++
+			}
+		}
+	}
+	return max(min(n, (players-1)/2), 1)
+}
+
+// String returns the count as ParseImposterCount reads it
+func (c ImposterCount) String() string {
+	if c.Auto {
+		return "auto"
+	}
+	return strconv.Itoa(max(c.Fixed, 1))
+}
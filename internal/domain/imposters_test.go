@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestImposterCountFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   ImposterCount
+		players int
+		want    int
+	}{
+		{"zero value", ImposterCount{}, 8, 1},
+		{"fixed", ImposterCount{Fixed: 2}, 8, 2},
+		{"fixed outnumbered", ImposterCount{Fixed: 3}, 5, 2},
+		{"fixed never none", ImposterCount{Fixed: 2}, 2, 1},
+		{"auto at first threshold", ImposterCount{Auto: true}, 6, 1},
+		{"auto past first threshold", ImposterCount{Auto: true}, 7, 2},
+		{"auto at second threshold", ImposterCount{Auto: true}, 10, 2},
+		{"auto past second threshold", ImposterCount{Auto: true}, 11, 3},
+		{"auto custom thresholds", ImposterCount{Auto: true, Thresholds: []int{4, 6, 8}}, 9, 4},
+		{"auto outnumbered", ImposterCount{Auto: true, Thresholds: []int{2, 3}}, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.count.For(tt.players); got != tt.want {
+				t.Errorf("For(%d) = %d, want %d", tt.players, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImposterCount(t *testing.T) {
+	tests := []struct {
+		mode       string
+		thresholds []int
+		want       string
+		wantErr    bool
+	}{
+		{mode: "", want: "1"},
+		{mode: "3", want: "3"},
+		{mode: " auto ", want: "auto"},
+		{mode: "auto", thresholds: []int{5, 9, 12}, want: "auto"},
+		{mode: "0", want: "1"},
+		{mode: "5", wantErr: true},
+		{mode: "-1", wantErr: true},
+		{mode: "many", wantErr: true},
+		{mode: "2", thresholds: []int{6}, want: "2"}, // Only auto mode scales by thresholds
+		{mode: "auto", thresholds: []int{1}, wantErr: true},
+		{mode: "auto", thresholds: []int{6, 6}, wantErr: true},
+		{mode: "auto", thresholds: []int{10, 6}, wantErr: true},
+		{mode: "auto", thresholds: []int{4, 6, 8, 10}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q %v", tt.mode, tt.thresholds), func(t *testing.T) {
+			count, err := ParseImposterCount(tt.mode, tt.thresholds)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidImposters) {
+					t.Fatalf("err = %v, want ErrInvalidImposters", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseImposterCount: %v", err)
+			}
+			if got := count.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRoundDealsDistinctImposters(t *testing.T) {
+	players := []string{"a", "b", "c", "d", "e", "f", "g"}
+	for seed := int64(0); seed < 50; seed++ {
+		round := NewRound(1, "apple", players, 3, NewSeededRand(seed))
+
+		imposters := round.Imposters()
+		if len(imposters) != 3 {
+			t.Fatalf("seed %d: %d imposters, want 3", seed, len(imposters))
+		}
+		if round.ImposterID != imposters[0] {
+			t.Errorf("seed %d: ImposterID %q is not the first of %v", seed, round.ImposterID, imposters)
+		}
+		seen := make(map[string]bool)
+		for _, id := range imposters {
+			if seen[id] {
+				t.Fatalf("seed %d: imposter %q dealt twice in %v", seed, id, imposters)
+			}
+			seen[id] = true
+		}
+	}
+}
+
+func TestNewRoundSingleImposterMatchesSeed(t *testing.T) {
+	// One imposter draws from the seeded rng exactly as before, so seeded
+	// rooms replay the same rounds
+	players := []string{"a", "b", "c", "d"}
+	for seed := int64(0); seed < 20; seed++ {
+		round := NewRound(1, "apple", players, 1, NewSeededRand(seed))
+		again := NewRound(1, "apple", players, 1, NewSeededRand(seed))
+		if round.ImposterID != again.ImposterID || len(round.Imposters()) != 1 {
+			t.Fatalf("seed %d: imposters %v and %v", seed, round.Imposters(), again.Imposters())
+		}
+	}
+}
+
+// autoGame returns a game with n players whose rounds deal imposters in auto
+// mode with the default thresholds
+func autoGame(t *testing.T, n int) *Game {
+	t.Helper()
+
+	game := NewGame("AUTO01")
+	game.SetSeed(42)
+	game.Settings.Imposters = ImposterCount{Auto: true}
+	game.Settings.MaxPlayers = max(n, game.Settings.MaxPlayers)
+	for i := range n {
+		id := fmt.Sprintf("player-%d", i)
+		if _, err := game.AddPlayer(id, fmt.Sprintf("Player %d", i)); err != nil {
+			t.Fatalf("AddPlayer: %v", err)
+		}
+	}
+	if err := game.StartRound("apple"); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	return game
+}
+
+func TestStartRoundAutoImposters(t *testing.T) {
+	for _, tt := range []struct{ players, want int }{{4, 1}, {6, 1}, {7, 2}, {10, 2}, {11, 3}} {
+		t.Run(fmt.Sprint(tt.players), func(t *testing.T) {
+			game := autoGame(t, tt.players)
+
+			if got := len(game.CurrentRound.Imposters()); got != tt.want {
+				t.Fatalf("round dealt %d imposters, want %d", got, tt.want)
+			}
+			roles := 0
+			for id, player := range game.Players {
+				if player.Role == RoleImposter {
+					roles++
+					if !game.CurrentRound.IsImposter(id) {
+						t.Errorf("%s has the imposter role but is not a round imposter", id)
+					}
+				}
+			}
+			if roles != tt.want {
+				t.Errorf("%d players have the imposter role, want %d", roles, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateResultsCatchingAnyImposter(t *testing.T) {
+	game := autoGame(t, 8)
+	round := game.CurrentRound
+	imposters := round.Imposters()
+	if len(imposters) != 2 {
+		t.Fatalf("round dealt %d imposters, want 2", len(imposters))
+	}
+
+	// Everyone else accuses the second imposter
+	for id := range game.Players {
+		if id != imposters[1] {
+			if err := round.AddVote(id, imposters[1]); err != nil {
+				t.Fatalf("AddVote: %v", err)
+			}
+		}
+	}
+	results, winner := round.CalculateResults(game.Players, TieImposterWins, game.Rand())
+	if winner != RoleVilek {
+		t.Errorf("winner = %s, want %s for catching the second imposter", winner, RoleVilek)
+	}
+	flagged := 0
+	for _, result := range results {
+		if result.IsImposter {
+			flagged++
+		}
+	}
+	if flagged != 2 {
+		t.Errorf("%d results flag an imposter, want 2", flagged)
+	}
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Invite lets players into a locked room without the password. It stops
+// working once it expires or has been used MaxUses times, so a leaked link
+// cannot be used to join hours later.
+type Invite struct {
+	Token     string    `json:"token"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxUses   int       `json:"maxUses"` // 0 means unlimited until expiry
+	Uses      int       `json:"uses"`
+	Nickname  string    `json:"nickname,omitempty"` // Only a player joining with it may redeem the invite
+}
+
+// IsActive returns true if the invite can still be redeemed at now
+func (i *Invite) IsActive(now time.Time) bool {
+	return now.Before(i.ExpiresAt) && (i.MaxUses == 0 || i.Uses < i.MaxUses)
+}
+
+// CreateInvite issues an invite that expires after ttl and allows maxUses joins
+func (g *Game) CreateInvite(createdBy string, ttl time.Duration, maxUses int) *Invite {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	now := time.Now()
+	invite := &Invite{
+		Token:     base64.RawURLEncoding.EncodeToString(b),
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		MaxUses:   maxUses,
+	}
+
+	if g.invites == nil {
+		g.invites = make(map[string]*Invite)
+	}
+	g.pruneInvites(now)
+	g.invites[invite.Token] = invite
+
+	return invite
+}
+
+// CreateNamedInvite issues an invite that expires after ttl, which only a
+// player joining with nickname can redeem, as often as they like
+func (g *Game) CreateNamedInvite(createdBy, nickname string, ttl time.Duration) *Invite {
+	invite := g.CreateInvite(createdBy, ttl, 0)
+	invite.Nickname = nickname
+	return invite
+}
+
+// CheckInvite returns nil if token is an active invite for this room that a
+// player joining with nickname may redeem
+func (g *Game) CheckInvite(token, nickname string) error {
+	invite, err := g.activeInvite(token)
+	if err != nil {
+		return err
+	}
+	if invite.Nickname != "" {
+		nickname, _ = CleanNickname(nickname, g.Settings.Emoji)
+		if !strings.EqualFold(nickname, invite.Nickname) {
+			return ErrInvalidInvite
+		}
+	}
+	return nil
+}
+
+// RedeemInvite counts a join against the invite
+func (g *Game) RedeemInvite(token string) error {
+	invite, err := g.activeInvite(token)
+	if err != nil {
+		return err
+	}
+	invite.Uses++
+	return nil
+}
+
+// activeInvite returns the invite for token if it can still be redeemed
+func (g *Game) activeInvite(token string) (*Invite, error) {
+	invite, ok := g.invites[token]
+	if !ok {
+		return nil, ErrInvalidInvite
+	}
+	if !invite.IsActive(time.Now()) {
+		return nil, ErrInviteExpired
+	}
+	return invite, nil
+}
+
+// RevokeInvite deletes an invite, returning false if it does not exist
+func (g *Game) RevokeInvite(token string) bool {
+	if _, ok := g.invites[token]; !ok {
+		return false
+	}
+	delete(g.invites, token)
+	return true
+}
+
+// ActiveInvites returns copies of the invites that can still be redeemed, oldest first
+func (g *Game) ActiveInvites() []Invite {
+	now := time.Now()
+	invites := make([]Invite, 0, len(g.invites))
+	for _, invite := range g.invites {
+		if invite.IsActive(now) {
+			invites = append(invites, *invite)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.Before(invites[j].CreatedAt)
+	})
+	return invites
+}
+
+// pruneInvites forgets invites that can no longer be redeemed
+func (g *Game) pruneInvites(now time.Time) {
+	for token, invite := range g.invites {
+		if !invite.IsActive(now) {
+			delete(g.invites, token)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // Phase represents the current phase of a game
 type Phase string
 
@@ -16,6 +18,31 @@ func (p Phase) String() string {
 	return string(p)
 }
 
+// IsValid returns true if p is a known phase
+func (p Phase) IsValid() bool {
+	switch p {
+	case PhaseLobby, PhaseRoleAssignment, PhaseSubmission, PhaseVoting, PhaseResults:
+		return true
+	}
+	return false
+}
+
+// Next returns the phase a game normally moves to from p; from results that
+// is another round
+func (p Phase) Next() Phase {
+	switch p {
+	case PhaseLobby, PhaseResults:
+		return PhaseRoleAssignment
+	case PhaseRoleAssignment:
+		return PhaseSubmission
+	case PhaseSubmission:
+		return PhaseVoting
+	case PhaseVoting:
+		return PhaseResults
+	}
+	return p
+}
+
 // CanTransitionTo checks if a transition from current phase to target phase is valid
 func (p Phase) CanTransitionTo(target Phase) bool {
 	validTransitions := map[Phase][]Phase{
@@ -39,3 +66,13 @@ func (p Phase) CanTransitionTo(target Phase) bool {
 	return false
 }
 
+// maxPhaseHistory is how many of its latest phase transitions a game keeps
+const maxPhaseHistory = 50
+
+// PhaseTransition records one change of a game's phase, for debugging
+type PhaseTransition struct {
+	From  Phase     `json:"from"`
+	To    Phase     `json:"to"`
+	Round int       `json:"round,omitempty"` // Number of the round the game was in, if any
+	At    time.Time `json:"at"`
+}
@@ -19,6 +19,7 @@ type Player struct {
 	HasSubmitted bool             `json:"hasSubmitted"`
 	Status       ConnectionStatus `json:"status"`
 	JoinedAt     time.Time        `json:"joinedAt"`
+	IsBot        bool             `json:"isBot,omitempty"` // Played by the server
 }
 
 // NewPlayer creates a new player with the given ID and nickname
@@ -63,6 +64,7 @@ type PlayerInfo struct {
 	HasVoted     bool             `json:"hasVoted"`
 	HasSubmitted bool             `json:"hasSubmitted"`
 	Status       ConnectionStatus `json:"status"`
+	IsBot        bool             `json:"isBot,omitempty"`
 }
 
 // ToInfo converts a Player to PlayerInfo (without role)
@@ -73,6 +75,7 @@ func (p *Player) ToInfo() PlayerInfo {
 		HasVoted:     p.HasVoted,
 		HasSubmitted: p.HasSubmitted,
 		Status:       p.Status,
+		IsBot:        p.IsBot,
 	}
 }
 
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"math/rand"
+)
+
+// Rand is the randomness a game deals rounds with: the submission order, the
+// imposter and the secret word. *rand.Rand satisfies it.
+type Rand interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
+
+// globalRand is a Rand backed by the global math/rand source
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (globalRand) Shuffle(n int, swap func(i, j int)) {
+	rand.Shuffle(n, swap)
+}
+
+// DefaultRand is the Rand of games without a seed. It is safe for concurrent use.
+var DefaultRand Rand = globalRand{}
+
+// NewSeededRand returns a Rand that makes the same choices every time for the
+// same seed. It is not safe for concurrent use.
+func NewSeededRand(seed int64) Rand {
+	return rand.New(rand.NewSource(seed))
+}
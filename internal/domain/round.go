@@ -1,7 +1,8 @@
 package domain
 
 import (
-	"math/rand"
+	"slices"
+	"sort"
 	"time"
 )
 
@@ -9,33 +10,47 @@ import (
 type Round struct {
 	Number           int           `json:"number"`
 	SecretWord       string        `json:"secretWord"`
-	ImposterID       string        `json:"imposterId"`
+	ImposterID       string        `json:"imposterId"`            // The first of ImposterIDs, for clients that expect one imposter
+	ImposterIDs      []string      `json:"imposterIds,omitempty"` // Every imposter; empty in rounds saved before rounds dealt several
 	Submissions      []*Submission `json:"submissions"`
 	Votes            []*Vote       `json:"votes"`
 	CurrentPlayerIdx int           `json:"currentPlayerIdx"` // Index in PlayerOrder
 	PlayerOrder      []string      `json:"playerOrder"`      // Order of player IDs for submission
 	Winner           Role          `json:"winner,omitempty"`
+	Voters           []string      `json:"voters,omitempty"` // Players whose votes counted, fixed when the round ends; nil counts every vote
+	Candidates       []string      `json:"candidates,omitempty"` // In a revote, the tied players who may be voted for
+	Tied             []string      `json:"tied,omitempty"`       // Players who shared the most votes when the round ended
+	AccusedID        string        `json:"accusedId,omitempty"`  // Player the vote caught, after any tie-break; empty if nobody
 	StartedAt        time.Time     `json:"startedAt"`
 	EndedAt          time.Time     `json:"endedAt,omitempty"`
 }
 
-// NewRound creates a new round with the given parameters
-func NewRound(number int, secretWord string, playerIDs []string) *Round {
+// NewRound creates a new round with the given parameters, dealing the
+// submission order and imposters with rng. The same rng state and players
+// always deal the same round, whatever order playerIDs are in.
+func NewRound(number int, secretWord string, playerIDs []string, imposters int, rng Rand) *Round {
 	// Shuffle player order for submission
 	order := make([]string, len(playerIDs))
 	copy(order, playerIDs)
-	rand.Shuffle(len(order), func(i, j int) {
+	sort.Strings(order)
+	rng.Shuffle(len(order), func(i, j int) {
 		order[i], order[j] = order[j], order[i]
 	})
 
-	// Pick a random imposter
-	imposterIdx := rand.Intn(len(playerIDs))
-	imposterID := playerIDs[imposterIdx]
+	// Pick random imposters, the first as a single imposter always was
+	imposterIDs := []string{order[rng.Intn(len(order))]}
+	for len(imposterIDs) < min(imposters, len(order)) {
+		rest := slices.DeleteFunc(slices.Clone(order), func(id string) bool {
+			return slices.Contains(imposterIDs, id)
+		})
+		imposterIDs = append(imposterIDs, rest[rng.Intn(len(rest))])
+	}
 
 	return &Round{
 		Number:           number,
 		SecretWord:       secretWord,
-		ImposterID:       imposterID,
+		ImposterID:       imposterIDs[0],
+		ImposterIDs:      imposterIDs,
 		Submissions:      make([]*Submission, 0),
 		Votes:            make([]*Vote, 0),
 		CurrentPlayerIdx: 0,
@@ -44,6 +59,32 @@ func NewRound(number int, secretWord string, playerIDs []string) *Round {
 	}
 }
 
+// chooseImposter makes a random one of candidates the imposter, keeping the
+// current one if there are none
+func (r *Round) chooseImposter(rng Rand, candidates []string) {
+	if len(candidates) > 0 {
+		sort.Strings(candidates)
+		r.ImposterID = candidates[rng.Intn(len(candidates))]
+		r.ImposterIDs = []string{r.ImposterID}
+	}
+}
+
+// Imposters returns the IDs of the round's imposters
+func (r *Round) Imposters() []string {
+	if len(r.ImposterIDs) > 0 {
+		return r.ImposterIDs
+	}
+	if r.ImposterID != "" {
+		return []string{r.ImposterID}
+	}
+	return nil
+}
+
+// IsImposter returns true if playerID is one of the round's imposters
+func (r *Round) IsImposter(playerID string) bool {
+	return slices.Contains(r.Imposters(), playerID)
+}
+
 // GetCurrentPlayerID returns the ID of the player whose turn it is to submit
 func (r *Round) GetCurrentPlayerID() string {
 	if r.CurrentPlayerIdx >= len(r.PlayerOrder) {
@@ -90,9 +131,21 @@ func (r *Round) AddVote(voterID, targetID string) error {
 	return nil
 }
 
-// AllVoted returns true if all players have voted
-func (r *Round) AllVoted(totalPlayers int) bool {
-	return len(r.Votes) >= totalPlayers
+// AllVoted returns true if every one of voters has voted. Without voters the
+// vote never completes early, and waits for its timer instead.
+func (r *Round) AllVoted(voters []string) bool {
+	return len(voters) > 0 && r.CountVotes(voters) == len(voters)
+}
+
+// CountVotes returns the number of voters who have voted
+func (r *Round) CountVotes(voters []string) int {
+	count := 0
+	for _, id := range voters {
+		if r.HasPlayerVoted(id) {
+			count++
+		}
+	}
+	return count
 }
 
 // GetVotedCount returns the number of players who have voted
@@ -100,13 +153,37 @@ func (r *Round) GetVotedCount() int {
 	return len(r.Votes)
 }
 
-// CalculateResults calculates the voting results and determines the winner
-func (r *Round) CalculateResults(players map[string]*Player) ([]VoteResult, Role) {
+// IsCandidate returns true if playerID may be voted for: anyone, except in a
+// revote, where only the tied players may be
+func (r *Round) IsCandidate(playerID string) bool {
+	return len(r.Candidates) == 0 || slices.Contains(r.Candidates, playerID)
+}
+
+// Results lists the votes each player received, counting only the votes of
+// r.Voters when it is set
+func (r *Round) Results(players map[string]*Player) []VoteResult {
+	return r.tally(players, r.Voters)
+}
+
+// tally lists the votes each player received from voters, or from everyone
+// if voters is nil
+func (r *Round) tally(players map[string]*Player, voters []string) []VoteResult {
+	var counted map[string]bool
+	if voters != nil {
+		counted = make(map[string]bool, len(voters))
+		for _, id := range voters {
+			counted[id] = true
+		}
+	}
+
 	// Count votes per player
 	voteCounts := make(map[string]int)
 	voterNames := make(map[string][]string) // targetID -> voter nicknames
 
 	for _, vote := range r.Votes {
+		if counted != nil && !counted[vote.VoterID] {
+			continue
+		}
 		voteCounts[vote.TargetID]++
 		voterNickname := ""
 		if voter, ok := players[vote.VoterID]; ok {
@@ -115,34 +192,64 @@ func (r *Round) CalculateResults(players map[string]*Player) ([]VoteResult, Role
 		voterNames[vote.TargetID] = append(voterNames[vote.TargetID], voterNickname)
 	}
 
-	// Build results
 	results := make([]VoteResult, 0, len(players))
-	maxVotes := 0
-	maxVotedPlayerID := ""
-
 	for playerID, player := range players {
-		count := voteCounts[playerID]
-		result := VoteResult{
+		results = append(results, VoteResult{
 			PlayerID:   playerID,
 			Nickname:   player.Nickname,
-			VoteCount:  count,
+			VoteCount:  voteCounts[playerID],
 			VotedBy:    voterNames[playerID],
-			IsImposter: playerID == r.ImposterID,
+			IsImposter: r.IsImposter(playerID),
+		})
+	}
+	return results
+}
+
+// leaders returns the IDs of the players with the most votes in results,
+// sorted; none if nobody was voted for
+func leaders(results []VoteResult) []string {
+	maxVotes := 0
+	var ids []string
+	for _, result := range results {
+		switch {
+		case result.VoteCount > maxVotes:
+			maxVotes = result.VoteCount
+			ids = []string{result.PlayerID}
+		case result.VoteCount == maxVotes && maxVotes > 0:
+			ids = append(ids, result.PlayerID)
 		}
-		results = append(results, result)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CalculateResults tallies the vote and determines the winner. The player
+// with the most votes is caught; a tie catches nobody, unless rule is
+// TieRandom and rng picks one of the tied players. A revote is held before
+// this, so one that ties again catches nobody. The Vileks win by catching
+// any one of the imposters.
+func (r *Round) CalculateResults(players map[string]*Player, rule TieRule, rng Rand) ([]VoteResult, Role) {
+	results := r.Results(players)
 
-		if count > maxVotes {
-			maxVotes = count
-			maxVotedPlayerID = playerID
+	top := leaders(results)
+	r.Tied = nil
+	r.AccusedID = ""
+	switch {
+	case len(top) == 1:
+		r.AccusedID = top[0]
+	case len(top) > 1:
+		r.Tied = top
+		if rule == TieRandom {
+			r.AccusedID = top[rng.Intn(len(top))]
 		}
 	}
 
 	// Determine winner
 	var winner Role
-	if maxVotedPlayerID == r.ImposterID {
-		winner = RoleVilek // Vileks caught the imposter!
+	if r.AccusedID != "" && r.IsImposter(r.AccusedID) {
+		winner = RoleVilek // Vileks caught an imposter!
 	} else {
-		winner = RoleImposter // Imposter wasn't caught
+		winner = RoleImposter // No imposter was caught
 	}
 
 	r.Winner = winner
@@ -0,0 +1,52 @@
+package domain
+
+// GameSnapshot is a game's complete state, including what its JSON form
+// leaves out, so the game can be saved and restored after a restart
+type GameSnapshot struct {
+	Game         *Game    `json:"game"`
+	PasswordSalt []byte   `json:"passwordSalt,omitempty"`
+	PasswordHash []byte   `json:"passwordHash,omitempty"`
+	Invites      []Invite `json:"invites,omitempty"`
+	Seed         int64    `json:"seed,omitempty"`
+}
+
+// Snapshot captures the game's state. The snapshot shares the game's data, so
+// it must be encoded before the game changes again.
+func (g *Game) Snapshot() *GameSnapshot {
+	return &GameSnapshot{
+		Game:         g,
+		PasswordSalt: g.passwordSalt,
+		PasswordHash: g.passwordHash,
+		Invites:      g.ActiveInvites(),
+		Seed:         g.seed,
+	}
+}
+
+// RestoreGame rebuilds a game from a decoded snapshot. Every player starts out
+// disconnected until they reconnect.
+func RestoreGame(snapshot *GameSnapshot) *Game {
+	g := snapshot.Game
+	if g.Players == nil {
+		g.Players = make(map[string]*Player)
+	}
+	if g.RoundHistory == nil {
+		g.RoundHistory = make([]*Round, 0)
+	}
+	g.passwordSalt = snapshot.PasswordSalt
+	g.passwordHash = snapshot.PasswordHash
+	g.seed = snapshot.Seed
+
+	for _, player := range g.Players {
+		player.Disconnect()
+	}
+
+	if len(snapshot.Invites) > 0 {
+		g.invites = make(map[string]*Invite, len(snapshot.Invites))
+		for i := range snapshot.Invites {
+			invite := snapshot.Invites[i]
+			g.invites[invite.Token] = &invite
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Limits on players' text, in characters as people count them: an accented
+// letter or a flag is one, however many code points make it up
+const (
+	MaxNicknameLength = 20
+	MaxWordLength     = 30
+
+	// maxMarksPerCharacter caps the combining marks stacked on one character.
+	// Languages need two at most; piles of them spill over the lines around.
+	maxMarksPerCharacter = 3
+)
+
+// zeroWidthJoiner joins emoji into one, as in the family emoji
+const zeroWidthJoiner = '\u200d'
+
+// EmojiPolicy decides what happens to emoji in nicknames and clues
+type EmojiPolicy string
+
+const (
+	EmojiAllow  EmojiPolicy = "allow"  // Kept
+	EmojiStrip  EmojiPolicy = "strip"  // Removed
+	EmojiReject EmojiPolicy = "reject" // The text is refused
+)
+
+// IsValid returns true if p is a known policy
+func (p EmojiPolicy) IsValid() bool {
+	return p == EmojiAllow || p == EmojiStrip || p == EmojiReject
+}
+
+// CleanNickname returns nickname as the game shows it, or an error if
+// nothing is left of it, it is too long or the text filter refuses it
+func (g *Game) CleanNickname(nickname string) (string, error) {
+	nickname, err := CleanNickname(nickname, g.Settings.Emoji)
+	if err != nil {
+		return "", err
+	}
+	return nickname, g.checkText(nickname)
+}
+
+// CleanWord returns a clue as the game shows it, or an error if nothing is
+// left of it, it is too long or the text filter refuses it
+func (g *Game) CleanWord(word string) (string, error) {
+	word, err := CleanWord(word, g.Settings.Emoji)
+	if err != nil {
+		return "", err
+	}
+	return word, g.checkText(word)
+}
+
+// checkText returns ErrInappropriateText if the text filter refuses text.
+// Text is checked as shown, so invisible characters cannot hide a word.
+func (g *Game) checkText(text string) error {
+	if g.allowsText != nil && !g.allowsText(text) {
+		return ErrInappropriateText
+	}
+	return nil
+}
+
+// CleanNickname returns nickname as a game with the emoji policy shows it
+func CleanNickname(nickname string, emoji EmojiPolicy) (string, error) {
+	nickname, err := cleanText(nickname, emoji)
+	switch {
+	case err != nil:
+		return "", err
+	case nickname == "":
+		return "", ErrEmptyNickname
+	case characterCount(nickname) > MaxNicknameLength:
+		return "", ErrNicknameTooLong
+	}
+	return nickname, nil
+}
+
+// CleanWord returns a clue as a game with the emoji policy shows it
+func CleanWord(word string, emoji EmojiPolicy) (string, error) {
+	word, err := cleanText(word, emoji)
+	switch {
+	case err != nil:
+		return "", err
+	case word == "":
+		return "", ErrEmptyWord
+	case characterCount(word) > MaxWordLength:
+		return "", ErrWordTooLong
+	}
+	return word, nil
+}
+
+// cleanText normalizes text to NFC, drops control, invisible and
+// direction-changing characters, thins out stacked combining marks, applies
+// the emoji policy and collapses whitespace to single spaces
+func cleanText(text string, emoji EmojiPolicy) (string, error) {
+	text = norm.NFC.String(text)
+
+	var b strings.Builder
+	for _, char := range characters(text) {
+		first, _ := utf8.DecodeRuneInString(char)
+		switch {
+		case unicode.IsSpace(first) || unicode.IsControl(first):
+			b.WriteByte(' ')
+		case isEmoji(first) && emoji == EmojiReject:
+			return "", ErrEmojiNotAllowed
+		case isEmoji(first) && emoji == EmojiStrip:
+		default:
+			b.WriteString(cleanCharacter(char))
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// characters splits text into the characters people see: a base followed by
+// its combining marks and variation selectors, emoji with their skin tones
+// and the emoji zero-width joiners attach to them, and flags of two regional
+// indicators. A close reading of Unicode's grapheme clusters, enough to count
+// and clean text by.
+func characters(text string) []string {
+	var (
+		chars   []string
+		start   = -1
+		emoji   bool // The character is an emoji
+		joining bool // The last rune was a zero-width joiner in an emoji
+		flag    bool // The character is one regional indicator so far
+	)
+	for i, r := range text {
+		extends := start >= 0 && ((joining && isEmoji(r)) || isExtender(r) || (flag && isRegionalIndicator(r)))
+		if !extends {
+			if start >= 0 {
+				chars = append(chars, text[start:i])
+			}
+			start = i
+			emoji = isEmoji(r)
+			flag = isRegionalIndicator(r)
+		} else if isRegionalIndicator(r) {
+			flag = false
+		}
+		joining = emoji && r == zeroWidthJoiner
+	}
+	if start >= 0 {
+		chars = append(chars, text[start:])
+	}
+	return chars
+}
+
+// cleanCharacter drops the invisible runes from char, leaving at most
+// maxMarksPerCharacter combining marks; nothing is left of an invisible one.
+// An emoji keeps the tags of its flag and the zero-width joiners between it
+// and the emoji joined to it.
+func cleanCharacter(char string) string {
+	first, _ := utf8.DecodeRuneInString(char)
+	emoji := isEmoji(first)
+
+	var b strings.Builder
+	marks := 0
+	for i, r := range char {
+		switch {
+		case emoji && r == zeroWidthJoiner:
+			next, _ := utf8.DecodeRuneInString(char[i+utf8.RuneLen(r):])
+			if !isEmoji(next) {
+				continue
+			}
+		case emoji && isTag(r):
+		case isInvisible(r):
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me) && !unicode.Is(unicode.Variation_Selector, r):
+			if marks++; marks > maxMarksPerCharacter {
+				continue
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// characterCount returns how many characters people see in text
+func characterCount(text string) int {
+	return len(characters(text))
+}
+
+// isExtender returns true if r belongs to the character before it
+func isExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		unicode.Is(unicode.Variation_Selector, r) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // Skin tones
+		isTag(r) ||
+		r == zeroWidthJoiner
+}
+
+// isTag returns true if r is a tag, as in the flags of England and Scotland
+func isTag(r rune) bool {
+	return r >= 0xe0020 && r <= 0xe007f
+}
+
+// isInvisible returns true if r is a formatting character that shows nothing
+// but can hide text, reorder it or break it up, such as a zero-width space or
+// a right-to-left override
+func isInvisible(r rune) bool {
+	return unicode.Is(unicode.Cf, r) ||
+		r == '\u115f' || r == '\u1160' || r == '\u3164' || r == '\uffa0' // Hangul fillers, which show as blanks
+}
+
+// isRegionalIndicator returns true if r is half of a flag
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isEmoji returns true if a character starting with r is an emoji
+func isEmoji(r rune) bool {
+	return (r >= 0x1f000 && r <= 0x1faff) || // Pictographs, emoticons, transport, flags and more
+		(r >= 0x2600 && r <= 0x27bf) || // Miscellaneous symbols and dingbats
+		(r >= 0x2b00 && r <= 0x2bff) || // Arrows and stars such as ⭐
+		(r >= 0x2300 && r <= 0x23ff) // Technical symbols such as ⌚
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// noBadword is a text filter refusing "badword"
+func noBadword(text string) bool {
+	return text != "badword"
+}
+
+func TestAddPlayerCleansNickname(t *testing.T) {
+	game := NewGame("TEXT01")
+	game.SetTextFilter(noBadword)
+
+	player, err := game.AddPlayer("p1", "  Ana\u200b  Lopez ")
+	if err != nil {
+		t.Fatalf("AddPlayer: %v", err)
+	}
+	if player.Nickname != "Ana Lopez" {
+		t.Errorf("nickname = %q, want %q", player.Nickname, "Ana Lopez")
+	}
+
+	// The filter sees the nickname as shown, without the zero-width space
+	if _, err := game.AddPlayer("p2", "bad\u200bword"); !errors.Is(err, ErrInappropriateText) {
+		t.Errorf("AddPlayer with a hidden bad word: err = %v, want ErrInappropriateText", err)
+	}
+	if _, err := game.AddPlayer("p3", "\u200b"); !errors.Is(err, ErrEmptyNickname) {
+		t.Errorf("AddPlayer with an invisible nickname: err = %v, want ErrEmptyNickname", err)
+	}
+	if len(game.Players) != 1 {
+		t.Errorf("%d players joined, want 1", len(game.Players))
+	}
+}
+
+func TestSubmitWordCleansWord(t *testing.T) {
+	game := NewGame("TEXT02")
+	game.SetTextFilter(noBadword)
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		if _, err := game.AddPlayer(id, "Player "+id); err != nil {
+			t.Fatalf("AddPlayer: %v", err)
+		}
+	}
+	if err := game.StartRound("apple"); err != nil {
+		t.Fatalf("StartRound: %v", err)
+	}
+	if err := game.TransitionToSubmission(); err != nil {
+		t.Fatalf("TransitionToSubmission: %v", err)
+	}
+
+	current := game.CurrentRound.GetCurrentPlayerID()
+	if err := game.SubmitWord(current, "bad\u200bword"); !errors.Is(err, ErrInappropriateText) {
+		t.Errorf("SubmitWord with a hidden bad word: err = %v, want ErrInappropriateText", err)
+	}
+	if err := game.SubmitWord(current, " red\u200b  fruit "); err != nil {
+		t.Fatalf("SubmitWord: %v", err)
+	}
+	if got := game.CurrentRound.Submissions[0].Word; got != "red fruit" {
+		t.Errorf("word = %q, want %q", got, "red fruit")
+	}
+}
+
+func TestCleanTextEmojiClusters(t *testing.T) {
+	family := "\U0001f468\u200d\U0001f469\u200d\U0001f467"
+	england := "\U0001f3f4\U000e0067\U000e0062\U000e0065\U000e006e\U000e0067\U000e007f"
+
+	for _, tt := range []struct {
+		name, in, want string
+	}{
+		{"family", family, family},
+		{"flag with tags", england, england},
+		{"skin tone", "\U0001f44b\U0001f3fd", "\U0001f44b\U0001f3fd"},
+		{"joiner before a letter", "\U0001f600\u200dA", "\U0001f600A"},
+		{"joiner before an override", "\U0001f600\u200d\u202e evil", "\U0001f600 evil"},
+		{"stacked marks", "\U0001f600" + strings.Repeat("\u0301", 500), "\U0001f600\u0301\u0301\u0301"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanText(tt.in, EmojiAllow)
+			if err != nil {
+				t.Fatalf("cleanText: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("cleanText(%+q) = %+q, want %+q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanTextJoinedEmojiCountsEachCharacter(t *testing.T) {
+	nickname := "\U0001f600" + strings.Repeat("\u200dA", 200) + "\u200d\u202e evil"
+	if _, err := CleanNickname(nickname, EmojiAllow); !errors.Is(err, ErrNicknameTooLong) {
+		t.Errorf("CleanNickname with letters joined to an emoji: err = %v, want ErrNicknameTooLong", err)
+	}
+	if n := characterCount("\U0001f600\u200dA"); n != 2 {
+		t.Errorf("an emoji joined to a letter counts as %d characters, want 2", n)
+	}
+
+	word, err := CleanWord("\U0001f600"+strings.Repeat("\u0301", 500), EmojiAllow)
+	if err != nil {
+		t.Fatalf("CleanWord: %v", err)
+	}
+	if n := utf8.RuneCountInString(word); n != 1+maxMarksPerCharacter {
+		t.Errorf("clue kept %d runes of an emoji and 500 marks, want %d", n, 1+maxMarksPerCharacter)
+	}
+}
@@ -0,0 +1,122 @@
+// Package i18n translates the messages the server shows players: error
+// messages and system notices. Messages are looked up by their English text,
+// so one without a translation is shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language messages are written in
+const Default = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a locale to its translations, keyed by the English message
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded locales/<locale>.json files
+func loadCatalogs() map[string]map[string]string {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic("i18n: " + err.Error())
+	}
+
+	catalogs := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFS.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic("i18n: " + err.Error())
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic("i18n: " + entry.Name() + ": " + err.Error())
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+	return catalogs
+}
+
+// Supported returns the locales messages can be shown in, sorted
+func Supported() []string {
+	locales := []string{Default}
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Match returns the supported locale for a language tag such as "pt-BR", or
+// an empty string if there is none
+func Match(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	if base == Default {
+		return Default
+	}
+	if _, ok := catalogs[base]; ok {
+		return base
+	}
+	return ""
+}
+
+// Negotiate returns the supported locale a client prefers according to an
+// Accept-Language header, or an empty string if it accepts none of them
+func Negotiate(acceptLanguage string) string {
+	type choice struct {
+		locale string
+		q      float64
+	}
+
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if locale := Match(tag); locale != "" && q > 0 {
+			choices = append(choices, choice{locale, q})
+		}
+	}
+
+	// Stable, so tags of equal weight keep the client's order
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	if len(choices) == 0 {
+		return ""
+	}
+	return choices[0].locale
+}
+
+// Translate returns message in locale, or as is if it has no translation
+func Translate(locale, message string) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// FromRequest returns the locale for messages in reply to r: the supported
+// one named by its locale query parameter, else the one its Accept-Language
+// header prefers, else Default
+func FromRequest(r *http.Request) string {
+	if locale := Match(r.URL.Query().Get("locale")); locale != "" {
+		return locale
+	}
+	if locale := Negotiate(r.Header.Get("Accept-Language")); locale != "" {
+		return locale
+	}
+	return Default
+}
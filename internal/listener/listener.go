@@ -0,0 +1,118 @@
+// Package listener opens the sockets the server accepts connections on: TCP,
+// a Unix socket for a reverse proxy on the same host, or one inherited from
+// systemd socket activation, which keeps accepting connections while the
+// server restarts.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// systemdFirstFD is the first file descriptor systemd passes (SD_LISTEN_FDS_START)
+const systemdFirstFD = 3
+
+// Errors opening systemd's socket
+var (
+	ErrNoSystemdSocket     = errors.New("listening on systemd but systemd passed no socket (is the service socket-activated?)")
+	ErrTooManySystemdFiles = errors.New("systemd passed more than one socket; the server listens on one")
+)
+
+// Listen opens a listener on addr: host:port, unix:/path/to.sock (created
+// with socketMode permissions, in octal) or systemd. Where is a description
+// of it for logs.
+func Listen(addr, socketMode string) (l net.Listener, where string, err error) {
+	switch {
+	case addr == "systemd":
+		l, err = systemd()
+		if err != nil {
+			return nil, "", err
+		}
+		return l, "systemd:" + l.Addr().String(), nil
+
+	case strings.HasPrefix(addr, "unix:"):
+		path := strings.TrimPrefix(addr, "unix:")
+		mode, err := strconv.ParseUint(socketMode, 8, 32)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid socket mode %q: %w", socketMode, err)
+		}
+		l, err = unix(path, os.FileMode(mode))
+		if err != nil {
+			return nil, "", err
+		}
+		return l, addr, nil
+
+	default:
+		l, err = net.Listen("tcp", addr)
+		if err != nil {
+			return nil, "", err
+		}
+		return l, addr, nil
+	}
+}
+
+// unix listens on a Unix socket at path, replacing one left behind by a
+// server that did not shut down cleanly. The socket is removed when the
+// listener closes.
+func unix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		// A socket that accepts connections belongs to a running server
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// systemd returns the listening socket systemd passed the process, as
+// described by LISTEN_PID and LISTEN_FDS (see sd_listen_fds(3)). The
+// variables are unset so processes the server starts do not claim it.
+func systemd() (net.Listener, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	if pid == "" || pid != strconv.Itoa(os.Getpid()) {
+		return nil, ErrNoSystemdSocket
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return nil, ErrNoSystemdSocket
+	}
+	if n > 1 {
+		return nil, ErrTooManySystemdFiles
+	}
+
+	syscall.CloseOnExec(systemdFirstFD)
+	file := os.NewFile(systemdFirstFD, "systemd-socket")
+	defer file.Close()
+
+	// FileListener duplicates the descriptor, so the file can be closed
+	l, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket: %w", err)
+	}
+	return l, nil
+}
@@ -0,0 +1,176 @@
+// Package logfile writes logs to files that are rotated by size and age, so a
+// server run without a supervisor keeps its logs without filling the disk.
+package logfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files, e.g. server-20261016T044812.000.log;
+// it sorts in the order the files were rotated
+const backupTimeFormat = "20060102T150405.000"
+
+// Options configures rotation
+type Options struct {
+	MaxSize    int64         // Bytes written before the file is rotated; 0 never rotates by size
+	MaxAge     time.Duration // Time after which the file is rotated; 0 never rotates by age
+	MaxBackups int           // Rotated files kept, newest first; 0 keeps them all
+}
+
+// File is a log file that is renamed with a timestamp and replaced by a new
+// one when it grows past MaxSize or gets older than MaxAge. It is safe for
+// concurrent use.
+type File struct {
+	mu       sync.Mutex
+	path     string
+	opts     Options
+	file     *os.File // nil after a failed rotation, until it can be reopened
+	closed   bool
+	size     int64
+	openedAt time.Time
+}
+
+// Open opens path for appending, creating it and its directory if needed
+func Open(path string, opts Options) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f := &File{path: path, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating the file first if p would take it past MaxSize
+// or it is older than MaxAge. A rotation that fails is tried again on the
+// next write; meanwhile p goes to whichever file is open.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	full := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	old := f.opts.MaxAge > 0 && time.Since(f.openedAt) > f.opts.MaxAge
+	if full || old {
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the file at f.path. Called with f.mu held.
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	// A file kept from an earlier run ages from when it was last rotated
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// rotate renames the file after the current time, opens a new one and removes
+// backups past MaxBackups. If the file cannot be renamed it is reopened, so
+// logging carries on in it; f.file is only left nil if no file can be opened.
+// Backups that cannot be removed are left for the next rotation. Called with
+// f.mu held.
+func (f *File) rotate() error {
+	closeErr := f.file.Close()
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), time.Now().Format(backupTimeFormat), ext)
+	renameErr := os.Rename(f.path, backup)
+	if err := f.open(); err != nil {
+		return errors.Join(closeErr, renameErr, err)
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes the oldest backups past MaxBackups. Called with f.mu held.
+func (f *File) prune() {
+	if f.opts.MaxBackups <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	backups, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+	sort.Strings(backups)
+
+	for len(backups) > f.opts.MaxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// Tee returns a writer that writes to each of writers, carrying on past one
+// that fails, so logs still reach a file once the terminal that stdout wrote
+// to has closed. It reports the first error.
+func Tee(writers ...io.Writer) io.Writer {
+	return tee(writers)
+}
+
+type tee []io.Writer
+
+func (t tee) Write(p []byte) (int, error) {
+	var first error
+	for _, w := range t {
+		if _, err := w.Write(p); err != nil && first == nil {
+			first = err
+		}
+	}
+	return len(p), first
+}
@@ -0,0 +1,83 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAfterFailedRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+	f, err := Open(path, Options{MaxSize: 10})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("first line\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// The file cannot be renamed once it is gone, so the rotation fails
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("second line\n")); err != nil {
+		t.Fatalf("Write after a failed rotation: %v", err)
+	}
+	if _, err := f.Write([]byte("third line\n")); err != nil {
+		t.Fatalf("Write after recovering: %v", err)
+	}
+
+	// The line written during the failed rotation has since been rotated
+	var logs strings.Builder
+	files, _ := filepath.Glob(filepath.Join(dir, "server*.log"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		logs.Write(data)
+	}
+	for _, line := range []string{"second line", "third line"} {
+		if !strings.Contains(logs.String(), line) {
+			t.Errorf("logs are %q, want %q in them", logs.String(), line)
+		}
+	}
+}
+
+func TestWriteWhenPruneFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "server.log")
+
+	// A backup that cannot be removed
+	stuck := filepath.Join(dir, "server-00000000T000000.000.log")
+	if err := os.MkdirAll(filepath.Join(stuck, "child"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(path, Options{MaxSize: 10, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first line\n", "second line\n", "third line\n"} {
+		if n, err := f.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	f, err := Open(filepath.Join(t.TempDir(), "server.log"), Options{})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	f.Close()
+	if _, err := f.Write([]byte("line\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close: err = %v, want os.ErrClosed", err)
+	}
+}
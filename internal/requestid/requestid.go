@@ -0,0 +1,47 @@
+// Package requestid correlates the log lines and responses of a single HTTP
+// request or streaming connection.
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header carries the request ID on HTTP requests and responses
+const Header = "X-Request-ID"
+
+// maxLength caps IDs accepted from upstream proxies
+const maxLength = 64
+
+// idKey is the context key for the request ID
+type idKey struct{}
+
+// New generates a request ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid returns true if id is safe to accept from an upstream proxy and echo in logs
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
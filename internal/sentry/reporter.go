@@ -0,0 +1,216 @@
+// Package sentry reports panics and unexpected errors to Sentry, or any
+// service that accepts Sentry's store API, so operators hear about them
+// without searching logs.
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+)
+
+const (
+	// queueSize is how many events may be pending before new ones are dropped
+	queueSize = 100
+
+	// requestTimeout bounds sending a single event
+	requestTimeout = 10 * time.Second
+
+	// clientName identifies the reporter to the service
+	clientName = "imposter-sentry/1.0"
+)
+
+// event is the part of Sentry's event payload the reporter fills in
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Exception   exceptions        `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type exceptions struct {
+	Values []exception `json:"values"`
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Reporter sends errors to the store endpoint of a Sentry DSN from a
+// background worker. It is an app.ErrorReporter.
+type Reporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+	sampleRate  float64
+	client      *http.Client
+	queue       chan *event
+	logger      *slog.Logger
+	done        chan struct{}
+	stopped     chan struct{}
+}
+
+// NewReporter creates a reporter for cfg.DSN and starts its worker. release
+// identifies the build errors are reported from.
+func NewReporter(cfg config.SentryConfig, release string, logger *slog.Logger) (*Reporter, error) {
+	dsn, err := url.Parse(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if dsn.User == nil || dsn.Host == "" {
+		return nil, errors.New("invalid DSN: expected https://KEY@HOST/PROJECT")
+	}
+
+	// The project ID is the last path segment; anything before it is a prefix
+	path := strings.Trim(dsn.Path, "/")
+	prefix, project := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		prefix, project = "/"+path[:i], path[i+1:]
+	}
+	if project == "" {
+		return nil, errors.New("invalid DSN: missing project ID")
+	}
+
+	serverName, _ := os.Hostname()
+	r := &Reporter{
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/store/", dsn.Scheme, dsn.Host, prefix, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, dsn.User.Username()),
+		environment: cfg.Environment,
+		release:     release,
+		serverName:  serverName,
+		sampleRate:  float64(cfg.SamplePercent) / 100,
+		client:      &http.Client{Timeout: requestTimeout},
+		queue:       make(chan *event, queueSize),
+		logger:      logger,
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+
+	go r.worker()
+
+	return r, nil
+}
+
+// Report queues err to be sent with tags. Panics are sent as fatal with the
+// stack they were raised on.
+func (r *Reporter) Report(err error, tags map[string]string) {
+	if rand.Float64() >= r.sampleRate {
+		return
+	}
+
+	e := &event{
+		EventID:     strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "imposter",
+		ServerName:  r.serverName,
+		Release:     r.release,
+		Environment: r.environment,
+		Exception: exceptions{Values: []exception{{
+			Type:  fmt.Sprintf("%T", err),
+			Value: err.Error(),
+		}}},
+		Tags: tags,
+	}
+
+	var panicErr *app.PanicError
+	if errors.As(err, &panicErr) {
+		e.Level = "fatal"
+		e.Exception.Values[0].Type = "panic"
+		e.Extra = map[string]string{"stack": string(panicErr.Stack)}
+	}
+
+	select {
+	case r.queue <- e:
+	default:
+		r.logger.Warn("error report queue full, dropping report", "error", err)
+	}
+}
+
+// Close sends the reports already queued, giving up after timeout, and stops
+// the worker
+func (r *Reporter) Close(timeout time.Duration) {
+	close(r.done)
+
+	select {
+	case <-r.stopped:
+	case <-time.After(timeout):
+		r.logger.Warn("timed out sending error reports", "pending", len(r.queue))
+	}
+}
+
+// worker sends queued events one at a time, and what is left once closed
+func (r *Reporter) worker() {
+	defer close(r.stopped)
+
+	for {
+		select {
+		case e := <-r.queue:
+			r.send(e)
+		case <-r.done:
+			for {
+				select {
+				case e := <-r.queue:
+					r.send(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send POSTs an event to the store endpoint. Reports are best effort, so a
+// failed one is logged rather than retried.
+func (r *Reporter) send(e *event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		r.logger.Error("failed to encode error report", "error", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		r.logger.Warn("failed to send error report", "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.logger.Warn("failed to send error report", "error", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		r.logger.Warn("error report rejected", "status", resp.StatusCode, "eventID", e.EventID)
+		return
+	}
+	r.logger.Debug("error reported", "eventID", e.EventID)
+}
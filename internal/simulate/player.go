@@ -0,0 +1,298 @@
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// clues are the words simulated players submit
+var clues = []string{"round", "bright", "loud", "soft", "cold", "quick", "heavy", "sharp", "green", "sweet"}
+
+// message is a server message or game event, as far as players read it
+type message struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// player is one simulated client, reading its events on its own goroutine
+type player struct {
+	room     *room
+	index    int
+	id       string
+	conn     *websocket.Conn
+	messages chan message  // Read from the connection by readLoop
+	done     chan struct{} // Closed by close to stop readLoop
+
+	writeMu sync.Mutex
+
+	// Operations awaiting the event that completes them, by name, with the
+	// time they were sent
+	pending map[string]time.Time
+
+	sent     int
+	received int
+}
+
+// join connects player i to the room and joins its lobby
+func (r *room) join(ctx context.Context, i int) (*player, error) {
+	wsURL := *r.target
+	wsURL.Scheme = map[string]string{"http": "ws", "https": "wss"}[r.target.Scheme]
+	wsURL.Path += "/ws"
+	wsURL.RawQuery = url.Values{"roomCode": {r.code}}.Encode()
+
+	began := time.Now()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL.String(), nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &simError{kind: "connect", err: err}
+	}
+	r.stats.observe("connect", time.Since(began))
+
+	p := &player{
+		room:     r,
+		index:    i,
+		conn:     conn,
+		messages: make(chan message, 64),
+		done:     make(chan struct{}),
+		pending:  make(map[string]time.Time),
+	}
+	go p.readLoop()
+
+	began = time.Now()
+	if err := p.send("join_lobby", map[string]string{"nickname": fmt.Sprintf("Sim %d-%d", r.index, i)}); err != nil {
+		p.close()
+		return nil, err
+	}
+
+	for {
+		msg, err := p.next(ctx)
+		if err != nil {
+			p.close()
+			return nil, err
+		}
+		if msg.Type != "connected" {
+			continue
+		}
+
+		var payload struct {
+			PlayerID string `json:"playerId"`
+		}
+		json.Unmarshal(msg.Payload, &payload)
+		p.id = payload.PlayerID
+		r.stats.observe("join", time.Since(began))
+		return p, nil
+	}
+}
+
+// readLoop reads messages until the connection closes, splitting frames that
+// carry several newline-separated messages
+func (p *player) readLoop() {
+	defer close(p.messages)
+
+	for {
+		_, data, err := p.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			var msg message
+			if json.Unmarshal(line, &msg) != nil {
+				continue
+			}
+			select {
+			case p.messages <- msg:
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+// next returns the next message, or an error if the connection closes or ctx
+// is done first
+func (p *player) next(ctx context.Context) (message, error) {
+	select {
+	case <-ctx.Done():
+		return message{}, ctx.Err()
+	case msg, ok := <-p.messages:
+		if !ok {
+			return message{}, &simError{kind: "disconnected", err: fmt.Errorf("player %d disconnected", p.index)}
+		}
+		p.received++
+		return msg, nil
+	}
+}
+
+// send writes a client message
+func (p *player) send(msgType string, payload interface{}) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	if err := p.conn.WriteJSON(map[string]interface{}{"type": msgType, "payload": payload}); err != nil {
+		return &simError{kind: "send", err: err}
+	}
+	p.sent++
+	return nil
+}
+
+// startGame has the host start the game, timing it until GAME_STARTED
+func (p *player) startGame() error {
+	p.writeMu.Lock()
+	p.pending["start"] = time.Now()
+	p.writeMu.Unlock()
+
+	return p.send("start_game", nil)
+}
+
+// complete records the latency of op if it was awaiting a reply
+func (p *player) complete(op string) {
+	p.writeMu.Lock()
+	began, ok := p.pending[op]
+	delete(p.pending, op)
+	p.writeMu.Unlock()
+
+	if ok {
+		p.room.stats.observe(op, time.Since(began))
+	}
+}
+
+// await marks op as sent now, so the event that completes it is timed
+func (p *player) await(op string) {
+	p.writeMu.Lock()
+	p.pending[op] = time.Now()
+	p.writeMu.Unlock()
+}
+
+// think waits as long as a person might before acting
+func (p *player) think(ctx context.Context) error {
+	if p.room.opts.Think <= 0 {
+		return nil
+	}
+
+	// Vary it by up to half either way so players do not act in lockstep
+	d := p.room.opts.Think/2 + time.Duration(rand.Int63n(int64(p.room.opts.Think)+1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// play takes the player's turns until the room's last round ends. Server
+// errors are counted, not fatal, since a load test should carry on through
+// them.
+func (p *player) play(ctx context.Context) error {
+	defer func() {
+		p.room.stats.count(p.sent, p.received)
+	}()
+
+	isHost := p.index == 0
+	rounds := 0
+	var roundBegan time.Time
+
+	for {
+		msg, err := p.next(ctx)
+		if err != nil {
+			return err
+		}
+
+		switch msg.Type {
+		case "error":
+			var payload struct {
+				Code string `json:"code"`
+			}
+			json.Unmarshal(msg.Payload, &payload)
+			p.room.stats.fail(payload.Code)
+
+		case "GAME_STARTED":
+			p.complete("start")
+			roundBegan = time.Now()
+
+		case "SUBMISSION_PHASE_STARTED", "SUBMISSION_MADE":
+			p.complete("submit")
+
+			var payload struct {
+				CurrentPlayerID string `json:"currentPlayerId"`
+			}
+			json.Unmarshal(msg.Payload, &payload)
+			if payload.CurrentPlayerID != p.id {
+				continue
+			}
+			if err := p.think(ctx); err != nil {
+				return err
+			}
+			p.await("submit")
+			if err := p.send("submit_word", map[string]string{"word": clues[rand.Intn(len(clues))]}); err != nil {
+				return err
+			}
+
+		case "VOTING_STARTED":
+			var payload struct {
+				Players []struct {
+					ID string `json:"id"`
+				} `json:"players"`
+			}
+			json.Unmarshal(msg.Payload, &payload)
+
+			var others []string
+			for _, pl := range payload.Players {
+				if pl.ID != p.id {
+					others = append(others, pl.ID)
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			if err := p.think(ctx); err != nil {
+				return err
+			}
+			p.await("vote")
+			if err := p.send("cast_vote", map[string]string{"targetPlayerId": others[rand.Intn(len(others))]}); err != nil {
+				return err
+			}
+
+		case "VOTE_CAST":
+			p.complete("vote")
+
+		case "ROUND_ENDED":
+			p.complete("vote")
+			rounds++
+			if !isHost {
+				if rounds >= p.room.opts.Rounds {
+					return nil
+				}
+				continue
+			}
+
+			p.room.stats.observe("round", time.Since(roundBegan))
+			p.room.stats.mu.Lock()
+			p.room.stats.roundsPlayed++
+			p.room.stats.mu.Unlock()
+
+			if rounds >= p.room.opts.Rounds {
+				return nil
+			}
+			roundBegan = time.Now()
+			if err := p.send("request_new_round", nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// close closes the connection and stops reading from it
+func (p *player) close() {
+	close(p.done)
+	p.conn.Close()
+}
@@ -0,0 +1,315 @@
+// Package simulate load-tests a server by playing full games with synthetic
+// WebSocket clients and measuring how quickly it responds
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Options configures a simulation
+type Options struct {
+	Target  string        // Server base URL, e.g. http://localhost:8080
+	Rooms   int           // Rooms played at once
+	Players int           // Players per room, at least minPlayers
+	Rounds  int           // Rounds played in each room
+	RampUp  time.Duration // Room starts are spread evenly over this
+	Think   time.Duration // How long players wait before acting
+	Timeout time.Duration // Rooms not finished by then fail
+}
+
+// Report summarizes a simulation
+type Report struct {
+	Rooms          int                     `json:"rooms"`
+	RoomsCompleted int                     `json:"roomsCompleted"`
+	RoundsPlayed   int                     `json:"roundsPlayed"`
+	Duration       time.Duration           `json:"durationNs"`
+	MessagesSent   int                     `json:"messagesSent"`
+	EventsReceived int                     `json:"eventsReceived"`
+	Latencies      map[string]LatencyStats `json:"latencies"`
+	Errors         map[string]int          `json:"errors"` // By kind, e.g. "connect" or a server error code
+}
+
+// LatencyStats summarizes the latencies of one operation
+type LatencyStats struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"meanNs"`
+	P50   time.Duration `json:"p50Ns"`
+	P95   time.Duration `json:"p95Ns"`
+	P99   time.Duration `json:"p99Ns"`
+	Max   time.Duration `json:"maxNs"`
+}
+
+// minPlayers is how many players a room needs to start a game
+const minPlayers = 4
+
+// Operations whose latency is measured, in report order
+var operations = []string{
+	"create_room", // POST /api/rooms
+	"connect",     // WebSocket handshake
+	"join",        // join_lobby until connected
+	"start",       // start_game until GAME_STARTED
+	"submit",      // submit_word until the next SUBMISSION_MADE
+	"vote",        // cast_vote until the next VOTE_CAST or ROUND_ENDED
+	"round",       // GAME_STARTED or a new round until ROUND_ENDED, as the host sees it
+}
+
+// Operations returns the names of the measured operations, in report order
+func Operations() []string {
+	return append([]string(nil), operations...)
+}
+
+// stats collects measurements from every simulated client
+type stats struct {
+	mu             sync.Mutex
+	latencies      map[string][]time.Duration
+	errors         map[string]int
+	roomsCompleted int
+	roundsPlayed   int
+	messagesSent   int
+	eventsReceived int
+}
+
+func newStats() *stats {
+	return &stats{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]int),
+	}
+}
+
+func (s *stats) observe(op string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies[op] = append(s.latencies[op], d)
+}
+
+func (s *stats) fail(kind string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[kind]++
+}
+
+func (s *stats) count(sent, received int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messagesSent += sent
+	s.eventsReceived += received
+}
+
+// Run plays opts.Rooms games against the target server at once and reports
+// how they went. It returns early with what was measured if ctx is canceled.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Rooms < 1 || opts.Players < minPlayers || opts.Rounds < 1 {
+		return nil, fmt.Errorf("need at least 1 room, %d players and 1 round", minPlayers)
+	}
+	target, err := url.Parse(strings.TrimSuffix(opts.Target, "/"))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return nil, fmt.Errorf("target must be an http or https URL")
+	}
+
+	st := newStats()
+	client := &http.Client{Timeout: 30 * time.Second}
+	started := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Rooms; i++ {
+		delay := time.Duration(0)
+		if opts.Rooms > 1 {
+			delay = opts.RampUp * time.Duration(i) / time.Duration(opts.Rooms-1)
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			roomCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+			defer cancel()
+
+			r := &room{opts: opts, target: target, http: client, stats: st, index: i}
+			if err := r.play(roomCtx); err != nil {
+				st.fail(errorKind(err))
+				return
+			}
+
+			st.mu.Lock()
+			st.roomsCompleted++
+			st.mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	return st.report(opts.Rooms, time.Since(started)), nil
+}
+
+// report summarizes the collected measurements
+func (s *stats) report(rooms int, elapsed time.Duration) *Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &Report{
+		Rooms:          rooms,
+		RoomsCompleted: s.roomsCompleted,
+		RoundsPlayed:   s.roundsPlayed,
+		Duration:       elapsed,
+		MessagesSent:   s.messagesSent,
+		EventsReceived: s.eventsReceived,
+		Latencies:      make(map[string]LatencyStats, len(s.latencies)),
+		Errors:         s.errors,
+	}
+	for op, samples := range s.latencies {
+		report.Latencies[op] = summarize(samples)
+	}
+	return report
+}
+
+// summarize computes the statistics of samples
+func summarize(samples []time.Duration) LatencyStats {
+	if len(samples) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		return sorted[min(int(p*float64(len(sorted))), len(sorted)-1)]
+	}
+
+	return LatencyStats{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   percentile(0.50),
+		P95:   percentile(0.95),
+		P99:   percentile(0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// room plays one game with opts.Players simulated clients
+type room struct {
+	opts   Options
+	target *url.URL
+	http   *http.Client
+	stats  *stats
+	index  int
+	code   string
+}
+
+// play creates the room, connects its players one at a time so the first is
+// the host, and waits for every player to see the last round end
+func (r *room) play(ctx context.Context) error {
+	if err := r.create(ctx); err != nil {
+		return err
+	}
+
+	players := make([]*player, 0, r.opts.Players)
+	defer func() {
+		for _, p := range players {
+			p.close()
+		}
+	}()
+
+	for i := 0; i < r.opts.Players; i++ {
+		p, err := r.join(ctx, i)
+		if err != nil {
+			return err
+		}
+		players = append(players, p)
+	}
+
+	errs := make(chan error, len(players))
+	for _, p := range players {
+		go func(p *player) {
+			errs <- p.play(ctx)
+		}(p)
+	}
+
+	// The host starts once everyone is in
+	if err := players[0].startGame(); err != nil {
+		return err
+	}
+
+	var firstErr error
+	for range players {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// create makes the room over the REST API
+func (r *room) create(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.target.String()+"/api/rooms", strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	began := time.Now()
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return &simError{kind: "create_room", err: err}
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Success bool `json:"success"`
+		Data    struct {
+			RoomCode string `json:"roomCode"`
+		} `json:"data"`
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &body); err != nil || !body.Success {
+		if body.Error.Code != "" {
+			return &simError{kind: body.Error.Code, err: fmt.Errorf("create room: %s", body.Error.Code)}
+		}
+		return &simError{kind: "create_room", err: fmt.Errorf("create room: status %d", resp.StatusCode)}
+	}
+	r.stats.observe("create_room", time.Since(began))
+
+	r.code = body.Data.RoomCode
+	return nil
+}
+
+// simError is a failure counted under kind in the report
+type simError struct {
+	kind string
+	err  error
+}
+
+func (e *simError) Error() string {
+	return e.err.Error()
+}
+
+// errorKind returns the report's name for why a room failed
+func errorKind(err error) string {
+	if se, ok := err.(*simError); ok {
+		return se.kind
+	}
+	if err == context.DeadlineExceeded {
+		return "timeout"
+	}
+	return "other"
+}
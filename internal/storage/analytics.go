@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"imposter/internal/analytics"
+)
+
+// DBAnalyticsStore keeps analytics totals in the database, where instances
+// sharing it add up their rounds. It implements analytics.Store.
+type DBAnalyticsStore struct {
+	db *sql.DB
+}
+
+// NewDBAnalyticsStore returns a store on a database opened with Open
+func NewDBAnalyticsStore(db *sql.DB) *DBAnalyticsStore {
+	return &DBAnalyticsStore{db: db}
+}
+
+// LoadAggregates returns the stored totals, with the most used and most
+// played words
+func (s *DBAnalyticsStore) LoadAggregates(ctx context.Context) (*analytics.Aggregates, error) {
+	aggregates := analytics.NewAggregates()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT day, games, rounds, round_seconds FROM analytics_days`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day string
+		var totals analytics.DayTotals
+		if err := rows.Scan(&day, &totals.Games, &totals.Rounds, &totals.RoundSeconds); err != nil {
+			return nil, err
+		}
+		aggregates.Days[day] = &totals
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT player_count, rounds, imposter_wins FROM analytics_player_counts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var players int
+		var totals analytics.PlayerCountTotals
+		if err := rows.Scan(&players, &totals.Rounds, &totals.ImposterWins); err != nil {
+			return nil, err
+		}
+		aggregates.PlayerCounts[players] = &totals
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT word, rounds, imposter_wins, vote_spread_sum FROM analytics_secret_words ORDER BY rounds DESC LIMIT $1`, analytics.MaxWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word string
+		var totals analytics.WordTotals
+		if err := rows.Scan(&word, &totals.Rounds, &totals.ImposterWins, &totals.VoteSpreadSum); err != nil {
+			return nil, err
+		}
+		aggregates.SecretWords[word] = &totals
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `SELECT word, uses FROM analytics_words ORDER BY uses DESC LIMIT $1`, analytics.MaxWords)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var word string
+		var uses int
+		if err := rows.Scan(&word, &uses); err != nil {
+			return nil, err
+		}
+		aggregates.Words[word] = uses
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return aggregates, nil
+}
+
+// AddAggregates adds delta to the stored totals in one transaction
+func (s *DBAnalyticsStore) AddAggregates(ctx context.Context, delta *analytics.Aggregates) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for day, totals := range delta.Days {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO analytics_days (day, games, rounds, round_seconds)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (day) DO UPDATE SET
+				games = analytics_days.games + EXCLUDED.games,
+				rounds = analytics_days.rounds + EXCLUDED.rounds,
+				round_seconds = analytics_days.round_seconds + EXCLUDED.round_seconds`,
+			day, totals.Games, totals.Rounds, totals.RoundSeconds,
+		); err != nil {
+			return err
+		}
+	}
+
+	for players, totals := range delta.PlayerCounts {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO analytics_player_counts (player_count, rounds, imposter_wins)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (player_count) DO UPDATE SET
+				rounds = analytics_player_counts.rounds + EXCLUDED.rounds,
+				imposter_wins = analytics_player_counts.imposter_wins + EXCLUDED.imposter_wins`,
+			players, totals.Rounds, totals.ImposterWins,
+		); err != nil {
+			return err
+		}
+	}
+
+	for word, uses := range delta.Words {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO analytics_words (word, uses)
+			VALUES ($1, $2)
+			ON CONFLICT (word) DO UPDATE SET uses = analytics_words.uses + EXCLUDED.uses`,
+			word, uses,
+		); err != nil {
+			return err
+		}
+	}
+
+	for word, totals := range delta.SecretWords {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO analytics_secret_words (word, rounds, imposter_wins, vote_spread_sum)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (word) DO UPDATE SET
+				rounds = analytics_secret_words.rounds + EXCLUDED.rounds,
+				imposter_wins = analytics_secret_words.imposter_wins + EXCLUDED.imposter_wins,
+				vote_spread_sum = analytics_secret_words.vote_spread_sum + EXCLUDED.vote_spread_sum`,
+			word, totals.Rounds, totals.ImposterWins, totals.VoteSpreadSum,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
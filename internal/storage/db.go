@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+
+	"imposter/internal/config"
+)
+
+// migrateTimeout bounds applying migrations at startup
+const migrateTimeout = time.Minute
+
+// Open connects to the configured database and applies pending migrations.
+// The returned database is shared by the stores built on it.
+func Open(cfg config.DatabaseConfig, logger *slog.Logger) (*sql.DB, error) {
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch cfg.Driver {
+	case dialectPostgres:
+		db, err = openPostgres(cfg)
+	case dialectSQLite:
+		db, err = openSQLite(cfg)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	applied, err := migrate(ctx, db, cfg.Driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if len(applied) > 0 {
+		logger.Info("database migrated", "driver", cfg.Driver, "applied", applied)
+	}
+
+	return db, nil
+}
+
+// openPostgres opens a connection pool to cfg.URL
+func openPostgres(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxConns)
+	db.SetMaxIdleConns(cfg.MaxConns)
+	return db, nil
+}
+
+// openSQLite opens the database file at cfg.URL, creating it if needed. A
+// single connection serializes writes, which SQLite allows only one at a time.
+func openSQLite(cfg config.DatabaseConfig) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.URL), 0o700); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + cfg.URL + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_time_format=sqlite"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
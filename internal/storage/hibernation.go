@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileHibernationStore keeps each hibernated room in its own file in a
+// directory. It implements app.HibernationStore.
+type FileHibernationStore struct {
+	dir string
+}
+
+// NewFileHibernationStore returns a store writing to dir, creating it if needed
+func NewFileHibernationStore(dir string) (*FileHibernationStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileHibernationStore{dir: dir}, nil
+}
+
+// SaveRoom replaces the room's file atomically
+func (s *FileHibernationStore) SaveRoom(ctx context.Context, roomCode string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, roomCode+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(roomCode))
+}
+
+// LoadRoom reads the room's file, returning nil if it does not exist
+func (s *FileHibernationStore) LoadRoom(ctx context.Context, roomCode string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(roomCode))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteRoom removes the room's file if it exists
+func (s *FileHibernationStore) DeleteRoom(ctx context.Context, roomCode string) error {
+	err := os.Remove(s.path(roomCode))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// ListRooms returns the rooms in the directory with their files' modification times
+func (s *FileHibernationStore) ListRooms(ctx context.Context) (map[string]time.Time, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make(map[string]time.Time)
+	for _, entry := range entries {
+		roomCode, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		rooms[roomCode] = info.ModTime()
+	}
+	return rooms, nil
+}
+
+// path returns the file holding roomCode
+func (s *FileHibernationStore) path(roomCode string) string {
+	return filepath.Join(s.dir, roomCode+".json")
+}
+
+// DBHibernationStore keeps hibernated rooms in the database under a key
+// unique to the instance. It implements app.HibernationStore.
+type DBHibernationStore struct {
+	db  *sql.DB
+	key string
+}
+
+// NewDBHibernationStore returns a store on a database opened with Open
+func NewDBHibernationStore(db *sql.DB, key string) *DBHibernationStore {
+	return &DBHibernationStore{db: db, key: key}
+}
+
+// SaveRoom replaces the room's state
+func (s *DBHibernationStore) SaveRoom(ctx context.Context, roomCode string, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO hibernated_rooms (key, room_code, data, saved_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key, room_code) DO UPDATE SET data = EXCLUDED.data, saved_at = EXCLUDED.saved_at`,
+		s.key, roomCode, data, time.Now(),
+	)
+	return err
+}
+
+// LoadRoom returns the room's state, or nil if it is not stored
+func (s *DBHibernationStore) LoadRoom(ctx context.Context, roomCode string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data FROM hibernated_rooms WHERE key = $1 AND room_code = $2`, s.key, roomCode,
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteRoom removes the room's state if it is stored
+func (s *DBHibernationStore) DeleteRoom(ctx context.Context, roomCode string) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM hibernated_rooms WHERE key = $1 AND room_code = $2`, s.key, roomCode,
+	)
+	return err
+}
+
+// ListRooms returns the instance's stored rooms and when each was saved
+func (s *DBHibernationStore) ListRooms(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT room_code, saved_at FROM hibernated_rooms WHERE key = $1`, s.key,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rooms := make(map[string]time.Time)
+	for rows.Next() {
+		var (
+			roomCode string
+			savedAt  time.Time
+		)
+		if err := rows.Scan(&roomCode, &savedAt); err != nil {
+			return nil, err
+		}
+		rooms[roomCode] = savedAt
+	}
+	return rooms, rows.Err()
+}
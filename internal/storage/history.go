@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"imposter/internal/app"
+)
+
+const (
+	// queueSize is how many records may be pending before new ones are dropped
+	queueSize = 256
+
+	// writeTimeout bounds writing a single record
+	writeTimeout = 10 * time.Second
+)
+
+// HistoryStore writes completed rounds and games to the database. Records are
+// queued and written from a background goroutine so sessions never wait on
+// the database. It implements app.GameRecorder.
+type HistoryStore struct {
+	db     *sql.DB
+	queue  chan interface{} // *app.RoundRecord or *app.GameRecord
+	logger *slog.Logger
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewHistoryStore starts the write worker on a database opened with Open
+func NewHistoryStore(db *sql.DB, logger *slog.Logger) *HistoryStore {
+	s := &HistoryStore{
+		db:     db,
+		queue:  make(chan interface{}, queueSize),
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.worker()
+
+	return s
+}
+
+// RecordRound queues a finished round for writing
+func (s *HistoryStore) RecordRound(record *app.RoundRecord) {
+	s.enqueue(record, "roomCode", record.RoomCode, "round", record.Number)
+}
+
+// RecordGame queues a finished game for writing
+func (s *HistoryStore) RecordGame(record *app.GameRecord) {
+	s.enqueue(record, "roomCode", record.RoomCode)
+}
+
+// enqueue adds a record to the queue, dropping it if the queue is full
+func (s *HistoryStore) enqueue(record interface{}, logArgs ...interface{}) {
+	select {
+	case s.queue <- record:
+	default:
+		s.logger.Warn("database queue full, dropping record", logArgs...)
+	}
+}
+
+// Close writes the records still queued. The database is left open for its
+// owner to close.
+func (s *HistoryStore) Close() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// worker writes queued records until the store is closed
+func (s *HistoryStore) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case record := <-s.queue:
+			s.write(record)
+		case <-s.done:
+			// Games closed during shutdown are queued just before this
+			for {
+				select {
+				case record := <-s.queue:
+					s.write(record)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write stores a single record
+func (s *HistoryStore) write(record interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	switch r := record.(type) {
+	case *app.RoundRecord:
+		if err := s.writeRound(ctx, r); err != nil {
+			s.logger.Warn("failed to record round", "roomCode", r.RoomCode, "round", r.Number, "error", err)
+		}
+	case *app.GameRecord:
+		if err := s.writeGame(ctx, r); err != nil {
+			s.logger.Warn("failed to record game", "roomCode", r.RoomCode, "error", err)
+		}
+	}
+}
+
+// writeRound inserts a round with its players, submissions and votes
+func (s *HistoryStore) writeRound(ctx context.Context, r *app.RoundRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO games (id, room_code, created_at, round_count)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (id) DO UPDATE SET round_count = games.round_count + 1`,
+		r.GameID, r.RoomCode, r.GameCreatedAt,
+	); err != nil {
+		return err
+	}
+
+	for _, player := range r.Players {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO game_players (game_id, player_id, nickname)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (game_id, player_id) DO UPDATE SET nickname = EXCLUDED.nickname`,
+			r.GameID, player.ID, player.Nickname,
+		); err != nil {
+			return err
+		}
+	}
+
+	var roundID int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO rounds (game_id, number, secret_word, imposter_id, winner, started_at, ended_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		r.GameID, r.Number, r.SecretWord, r.ImposterID, string(r.Winner), r.StartedAt, r.EndedAt,
+	).Scan(&roundID); err != nil {
+		return err
+	}
+
+	for _, imposterID := range r.ImposterIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO round_imposters (round_id, player_id)
+			VALUES ($1, $2)`,
+			roundID, imposterID,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range r.Submissions {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO round_submissions (round_id, player_id, word, position, submitted_at)
+			VALUES ($1, $2, $3, $4, $5)`,
+			roundID, sub.PlayerID, sub.Word, sub.Order, sub.Timestamp,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, vote := range r.Votes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO round_votes (round_id, voter_id, target_id, cast_at)
+			VALUES ($1, $2, $3, $4)`,
+			roundID, vote.VoterID, vote.TargetID, vote.Timestamp,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// writeGame marks a game as ended
+func (s *HistoryStore) writeGame(ctx context.Context, r *app.GameRecord) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO games (id, room_code, created_at, ended_at, round_count)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET ended_at = EXCLUDED.ended_at, round_count = EXCLUDED.round_count`,
+		r.GameID, r.RoomCode, r.CreatedAt, r.EndedAt, r.Rounds,
+	)
+	return err
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// Database dialects, each with its own migrations directory
+const (
+	dialectPostgres = "postgres"
+	dialectSQLite   = "sqlite"
+)
+
+// migrationLockID serializes migrations when several instances start at once
+const migrationLockID = 7_254_310_201
+
+// migrate applies the dialect's embedded migrations that have not run yet, in
+// file name order, each in its own transaction
+func migrate(ctx context.Context, db *sql.DB, dialect string) ([]string, error) {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return nil, err
+	}
+
+	dir := "migrations/" + dialect + "/"
+	names, err := fs.Glob(migrationFiles, dir+"*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	applied := make([]string, 0)
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, dir), ".sql")
+		ok, err := applyMigration(ctx, db, dialect, name, version)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied = append(applied, version)
+		}
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration unless it was already applied
+func applyMigration(ctx context.Context, db *sql.DB, dialect, name, version string) (bool, error) {
+	script, err := migrationFiles.ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// SQLite has a single writer, so only Postgres needs the lock
+	if dialect == dialectPostgres {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return false, err
+		}
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version,
+	).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,8 @@
+-- Snapshots of active sessions, restored when an instance restarts. Each
+-- instance keeps a single row under its own key.
+
+CREATE TABLE session_snapshots (
+    key      TEXT PRIMARY KEY,
+    data     BYTEA NOT NULL,
+    saved_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+-- Rooms evicted from memory while nobody was connected, restored when a
+-- player comes back. Each instance keeps its rooms under its own key.
+
+CREATE TABLE hibernated_rooms (
+    key       TEXT NOT NULL,
+    room_code TEXT NOT NULL,
+    data      BYTEA NOT NULL,
+    saved_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (key, room_code)
+);
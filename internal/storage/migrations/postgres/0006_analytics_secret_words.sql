@@ -0,0 +1,9 @@
+-- Outcomes by secret word, for rating and pruning words. Vote spread is 0 for
+-- a unanimous vote and approaches 1 as votes scatter.
+
+CREATE TABLE analytics_secret_words (
+    word            TEXT PRIMARY KEY,
+    rounds          BIGINT NOT NULL DEFAULT 0,
+    imposter_wins   BIGINT NOT NULL DEFAULT 0,
+    vote_spread_sum DOUBLE PRECISION NOT NULL DEFAULT 0
+);
@@ -0,0 +1,52 @@
+-- Completed games and their rounds. Player IDs are the server's player IDs,
+-- which are stable across games only for players signed in with an identity.
+
+CREATE TABLE games (
+    id          TEXT PRIMARY KEY,
+    room_code   TEXT NOT NULL,
+    created_at  TIMESTAMP NOT NULL,
+    ended_at    TIMESTAMP,
+    round_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX games_created_at_idx ON games (created_at);
+
+CREATE TABLE game_players (
+    game_id   TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    player_id TEXT NOT NULL,
+    nickname  TEXT NOT NULL,
+    PRIMARY KEY (game_id, player_id)
+);
+
+CREATE INDEX game_players_player_id_idx ON game_players (player_id);
+
+CREATE TABLE rounds (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    game_id     TEXT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    number      INTEGER NOT NULL,
+    secret_word TEXT NOT NULL,
+    imposter_id TEXT NOT NULL,
+    winner      TEXT NOT NULL,
+    started_at  TIMESTAMP NOT NULL,
+    ended_at    TIMESTAMP NOT NULL,
+    UNIQUE (game_id, number)
+);
+
+CREATE INDEX rounds_secret_word_idx ON rounds (secret_word);
+
+CREATE TABLE round_submissions (
+    round_id     INTEGER NOT NULL REFERENCES rounds (id) ON DELETE CASCADE,
+    player_id    TEXT NOT NULL,
+    word         TEXT NOT NULL,
+    position     INTEGER NOT NULL,
+    submitted_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (round_id, player_id)
+);
+
+CREATE TABLE round_votes (
+    round_id  INTEGER NOT NULL REFERENCES rounds (id) ON DELETE CASCADE,
+    voter_id  TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    cast_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (round_id, voter_id)
+);
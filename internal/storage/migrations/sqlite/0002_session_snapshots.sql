@@ -0,0 +1,8 @@
+-- Snapshots of active sessions, restored when the server restarts. Each
+-- instance keeps a single row under its own key.
+
+CREATE TABLE session_snapshots (
+    key      TEXT PRIMARY KEY,
+    data     BLOB NOT NULL,
+    saved_at TIMESTAMP NOT NULL
+);
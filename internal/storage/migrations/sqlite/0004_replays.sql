@@ -0,0 +1,10 @@
+-- Recorded games for playback, saved as each round ends. Replays are shared
+-- by every instance, since IDs are unique per game.
+
+CREATE TABLE replays (
+    id       TEXT PRIMARY KEY,
+    data     BLOB NOT NULL,
+    saved_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX replays_saved_at_idx ON replays (saved_at);
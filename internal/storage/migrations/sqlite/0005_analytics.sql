@@ -0,0 +1,22 @@
+-- Running totals over finished rounds, added to by every instance as it
+-- flushes. Days are UTC dates; a game counts on the day its first round ended.
+
+CREATE TABLE analytics_days (
+    day           TEXT PRIMARY KEY,
+    games         INTEGER NOT NULL DEFAULT 0,
+    rounds        INTEGER NOT NULL DEFAULT 0,
+    round_seconds REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE analytics_player_counts (
+    player_count  INTEGER PRIMARY KEY,
+    rounds        INTEGER NOT NULL DEFAULT 0,
+    imposter_wins INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE analytics_words (
+    word TEXT PRIMARY KEY,
+    uses INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX analytics_words_uses_idx ON analytics_words (uses);
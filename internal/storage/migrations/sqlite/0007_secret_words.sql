@@ -0,0 +1,9 @@
+-- Secret words admins added to the word catalog, and built-in words they
+-- changed. Built-in words without a row are enabled and uncategorized.
+
+CREATE TABLE secret_words (
+    word       TEXT PRIMARY KEY,
+    category   TEXT NOT NULL DEFAULT '',
+    disabled   INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,9 @@
+-- Every imposter of a round, now that a round may deal several;
+-- rounds.imposter_id keeps the first. Rounds recorded before this table
+-- have no rows here.
+
+CREATE TABLE round_imposters (
+    round_id  INTEGER NOT NULL REFERENCES rounds (id) ON DELETE CASCADE,
+    player_id TEXT NOT NULL,
+    PRIMARY KEY (round_id, player_id)
+);
@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// validReplayID reports whether id is safe to use as a file name. Replay IDs
+// are UUIDs, but the ones asked for come from clients.
+func validReplayID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// FileReplayStore keeps each replay in its own file in a directory. It
+// implements app.ReplayStore.
+type FileReplayStore struct {
+	dir string
+}
+
+// NewFileReplayStore returns a store writing to dir, creating it if needed
+func NewFileReplayStore(dir string) (*FileReplayStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileReplayStore{dir: dir}, nil
+}
+
+// SaveReplay replaces the replay's file atomically
+func (s *FileReplayStore) SaveReplay(ctx context.Context, id string, data []byte) error {
+	if !validReplayID(id) {
+		return errors.New("invalid replay ID")
+	}
+
+	tmp, err := os.CreateTemp(s.dir, id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(id))
+}
+
+// LoadReplay reads the replay's file, returning nil if it does not exist
+func (s *FileReplayStore) LoadReplay(ctx context.Context, id string) ([]byte, error) {
+	if !validReplayID(id) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteReplays removes the files last modified before cutoff
+func (s *FileReplayStore) DeleteReplays(ctx context.Context, cutoff time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// path returns the file holding the replay
+func (s *FileReplayStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// DBReplayStore keeps replays in the database, where every instance can play
+// them back. It implements app.ReplayStore.
+type DBReplayStore struct {
+	db *sql.DB
+}
+
+// NewDBReplayStore returns a store on a database opened with Open
+func NewDBReplayStore(db *sql.DB) *DBReplayStore {
+	return &DBReplayStore{db: db}
+}
+
+// SaveReplay replaces the replay
+func (s *DBReplayStore) SaveReplay(ctx context.Context, id string, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO replays (id, data, saved_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data, saved_at = EXCLUDED.saved_at`,
+		id, data, time.Now(),
+	)
+	return err
+}
+
+// LoadReplay returns the replay, or nil if it is not stored
+func (s *DBReplayStore) LoadReplay(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM replays WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DeleteReplays removes the replays last saved before cutoff
+func (s *DBReplayStore) DeleteReplays(ctx context.Context, cutoff time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM replays WHERE saved_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileSnapshotStore keeps the session snapshot in a file. It implements
+// app.SnapshotStore.
+type FileSnapshotStore struct {
+	path string
+}
+
+// NewFileSnapshotStore returns a store writing to path, creating its
+// directory if needed
+func NewFileSnapshotStore(path string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &FileSnapshotStore{path: path}, nil
+}
+
+// SaveSnapshot replaces the file atomically so a crash mid-write never leaves
+// a truncated snapshot
+func (s *FileSnapshotStore) SaveSnapshot(ctx context.Context, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// LoadSnapshot reads the file, returning nil if it does not exist
+func (s *FileSnapshotStore) LoadSnapshot(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// DBSnapshotStore keeps the session snapshot in the database under a key
+// unique to the instance. It implements app.SnapshotStore.
+type DBSnapshotStore struct {
+	db  *sql.DB
+	key string
+}
+
+// NewDBSnapshotStore returns a store on a database opened with Open
+func NewDBSnapshotStore(db *sql.DB, key string) *DBSnapshotStore {
+	return &DBSnapshotStore{db: db, key: key}
+}
+
+// SaveSnapshot replaces the instance's snapshot
+func (s *DBSnapshotStore) SaveSnapshot(ctx context.Context, data []byte) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO session_snapshots (key, data, saved_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET data = EXCLUDED.data, saved_at = EXCLUDED.saved_at`,
+		s.key, data, time.Now(),
+	)
+	return err
+}
+
+// LoadSnapshot returns the instance's snapshot, or nil if it has none
+func (s *DBSnapshotStore) LoadSnapshot(ctx context.Context) ([]byte, error) {
+	return s.LoadNodeSnapshot(ctx, s.key)
+}
+
+// LoadNodeSnapshot returns the snapshot another instance saved under its key,
+// or nil if it has none. It implements app.NodeSnapshots.
+func (s *DBSnapshotStore) LoadNodeSnapshot(ctx context.Context, key string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT data FROM session_snapshots WHERE key = $1`, key,
+	).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
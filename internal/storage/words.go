@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"imposter/internal/app"
+)
+
+// FileWordStore keeps a word catalog's changes in one JSON file. It
+// implements app.WordStore.
+type FileWordStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileWordStore returns a store writing to path, creating its directory if
+// needed
+func NewFileWordStore(path string) (*FileWordStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return &FileWordStore{path: path}, nil
+}
+
+// LoadWords reads the file, returning no entries if it does not exist
+func (s *FileWordStore) LoadWords(ctx context.Context) ([]app.WordEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// SaveWord replaces the entry in the file, rewriting it atomically
+func (s *FileWordStore) SaveWord(ctx context.Context, entry app.WordEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range entries {
+		if entries[i].Word == entry.Word {
+			entries[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Word < entries[j].Word })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// load reads the file. Called with s.mu held.
+func (s *FileWordStore) load() ([]app.WordEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []app.WordEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DBWordStore keeps a word catalog's changes in the database, where every
+// instance loads them on start. It implements app.WordStore.
+type DBWordStore struct {
+	db *sql.DB
+}
+
+// NewDBWordStore returns a store on a database opened with Open
+func NewDBWordStore(db *sql.DB) *DBWordStore {
+	return &DBWordStore{db: db}
+}
+
+// LoadWords returns every saved entry
+func (s *DBWordStore) LoadWords(ctx context.Context) ([]app.WordEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT word, category, disabled, updated_at FROM secret_words`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []app.WordEntry
+	for rows.Next() {
+		var entry app.WordEntry
+		if err := rows.Scan(&entry.Word, &entry.Category, &entry.Disabled, &entry.UpdatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SaveWord replaces the entry for the word
+func (s *DBWordStore) SaveWord(ctx context.Context, entry app.WordEntry) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO secret_words (word, category, disabled, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (word) DO UPDATE SET
+			category = EXCLUDED.category,
+			disabled = EXCLUDED.disabled,
+			updated_at = EXCLUDED.updated_at`,
+		entry.Word, entry.Category, entry.Disabled, entry.UpdatedAt,
+	)
+	return err
+}
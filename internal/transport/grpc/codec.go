@@ -0,0 +1,31 @@
+package grpc
+
+import "fmt"
+
+// rawCodec passes pre-encoded frames through unchanged. It registers under the
+// "proto" name so clients using generated stubs interoperate transparently.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch b := v.(type) {
+	case []byte:
+		return b, nil
+	case *[]byte:
+		return *b, nil
+	default:
+		return nil, fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec: unexpected message type %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
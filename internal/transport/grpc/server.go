@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+
+	"imposter/internal/app"
+	"imposter/internal/auth"
+	"imposter/internal/config"
+)
+
+// Server represents the gRPC server
+type Server struct {
+	server *grpc.Server
+	hub    *app.GameHub
+	addr   string
+	logger *slog.Logger
+
+	// Send queue sizing and slow-client policy, shared with WebSocket connections
+	wsConfig config.WebSocketConfig
+
+	// External identity verification (nil when disabled)
+	verifier *auth.JWTVerifier
+
+	// The instance's connection limit, shared with WebSocket connections (nil
+	// when none is set)
+	limiter ConnectionLimiter
+}
+
+// ConnectionLimiter counts the instance's open connections against its limit
+type ConnectionLimiter interface {
+	// Acquire takes a connection, returning false if they are all in use
+	Acquire() bool
+	Release()
+
+	// Refused counts a connection refused for a room's limit
+	Refused()
+}
+
+// NewServer creates a new gRPC server exposing the GameService
+func NewServer(cfg *config.Config, hub *app.GameHub, logger *slog.Logger) *Server {
+	s := &Server{
+		hub:      hub,
+		addr:     cfg.GetGRPCAddr(),
+		logger:   logger,
+		wsConfig: cfg.WebSocket,
+	}
+
+	if cfg.JWTEnabled() {
+		s.verifier = auth.NewJWTVerifier(cfg.JWT, logger)
+	}
+
+	// Frames are already protobuf-encoded Envelopes, so bypass the default codec
+	s.server = grpc.NewServer(grpc.ForceServerCodec(rawCodec{}))
+	s.server.RegisterService(&gameServiceDesc, s)
+
+	return s
+}
+
+// SetConnectionLimiter counts streams against limiter, so they share the
+// instance's connection limit
+func (s *Server) SetConnectionLimiter(limiter ConnectionLimiter) {
+	s.limiter = limiter
+}
+
+// Start starts the gRPC server
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("grpc server starting", "addr", s.addr)
+	return s.server.Serve(lis)
+}
+
+// Shutdown gracefully shuts down the server, forcing it to stop if ctx expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.logger.Info("grpc server shutting down")
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
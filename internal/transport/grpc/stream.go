@@ -0,0 +1,353 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcpeer "google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"imposter/internal/i18n"
+	"imposter/internal/requestid"
+	"imposter/internal/transport/ws"
+)
+
+// gameServiceDesc describes imposter.ws.v1.GameService (see ws/messages.proto)
+var gameServiceDesc = grpc.ServiceDesc{
+	ServiceName: "imposter.ws.v1.GameService",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PlayGame",
+			Handler:       playGameHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "messages.proto",
+}
+
+// playGameHandler adapts the generic stream handler signature to the Server
+func playGameHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(*Server).playGame(stream)
+}
+
+// playGame handles a PlayGame stream. The room code, and the reconnect token
+// when reconnecting, are passed as "room-code" and "reconnect-token" metadata.
+// Reconnecting clients may add "last-seq" to have missed events replayed.
+// Error messages are sent in the language "locale" or "accept-language" names.
+// When JWT authentication is enabled, "authorization: Bearer <jwt>" is required instead.
+func (s *Server) playGame(stream grpc.ServerStream) error {
+	if s.limiter != nil {
+		if !s.limiter.Acquire() {
+			return status.Error(codes.ResourceExhausted, "too many connections, try again shortly")
+		}
+		defer s.limiter.Release()
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+
+	roomCode := firstValue(md, "room-code")
+	if roomCode == "" {
+		return status.Error(codes.InvalidArgument, "room-code metadata is required")
+	}
+
+	codec, err := ws.NegotiateCodec(firstValue(md, "protocol-version"), string(ws.EncodingProtobuf))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	session, err := s.hub.GetSession(roomCode)
+	if err != nil {
+		return status.Error(codes.NotFound, "game not found")
+	}
+
+	// Reconnecting players prove their identity with a signed token
+	var playerID string
+	var isReconnect bool
+	token := firstValue(md, "reconnect-token")
+	if s.verifier != nil {
+		jwt, _ := strings.CutPrefix(firstValue(md, "authorization"), "Bearer ")
+		if jwt == "" {
+			return status.Error(codes.Unauthenticated, "identity token is required")
+		}
+		playerID, err = s.verifier.Verify(stream.Context(), jwt)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, "invalid identity token")
+		}
+		isReconnect = session.HasPlayer(playerID)
+	} else if token != "" {
+		isReconnect = true
+		playerID, err = session.VerifyReconnectToken(token)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, "invalid or expired reconnect token")
+		}
+	} else {
+		playerID = uuid.New().String()
+	}
+
+	if !isReconnect && !session.CanJoin() {
+		return status.Error(codes.PermissionDenied, "cannot join this game")
+	}
+
+	// Players already in the game always get back in
+	if !isReconnect && !session.HasConnectionCapacity() {
+		if s.limiter != nil {
+			s.limiter.Refused()
+		}
+		return status.Error(codes.ResourceExhausted, "room connection limit reached")
+	}
+
+	// A reconnecting client can resume from the last event sequence number it saw
+	var lastSeq uint64
+	resume := false
+	if raw := firstValue(md, "last-seq"); raw != "" && isReconnect {
+		lastSeq, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "last-seq must be a non-negative integer")
+		}
+		resume = true
+	}
+
+	connID := requestid.New()
+	logger := s.logger.With("requestID", connID, "roomCode", roomCode, "playerID", playerID)
+
+	session.SetClientIP(playerID, remoteIP(stream))
+
+	peer := newStreamPeer(stream, playerID, connID, codec, ws.NewOutbox(s.wsConfig), logger)
+	locale := i18n.Match(firstValue(md, "locale"))
+	if locale == "" {
+		locale = i18n.Negotiate(firstValue(md, "accept-language"))
+	}
+	peer.SetLocale(locale)
+	dispatcher := ws.NewDispatcher(session, peer)
+	resumed := resume && session.ResumeClient(playerID, peer, lastSeq)
+	if !resumed {
+		session.RegisterClient(playerID, peer)
+	}
+
+	defer func() {
+		session.UnregisterClient(playerID)
+		session.DisconnectPlayer(playerID)
+		peer.Close()
+	}()
+
+	logger.Info("grpc stream connected",
+		"isReconnect", isReconnect,
+		"resumed", resumed,
+	)
+
+	go peer.writeLoop()
+
+	if isReconnect {
+		if _, err := session.ReconnectPlayer(playerID); err != nil {
+			logger.Debug("reconnect failed, treating as new", "error", err)
+		} else if resumed {
+			dispatcher.SendResumed()
+		} else {
+			dispatcher.SendConnected()
+		}
+	}
+
+	// Returning ends the stream, which also unblocks a pending receive
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- peer.readLoop(dispatcher)
+	}()
+
+	select {
+	case err := <-recvErr:
+		return err
+	case <-peer.done:
+		return status.Error(codes.ResourceExhausted, "client is not keeping up")
+	}
+}
+
+// readLoop dispatches incoming frames until the client closes the stream
+func (p *streamPeer) readLoop(dispatcher *ws.Dispatcher) error {
+	for {
+		var frame []byte
+		if err := p.stream.RecvMsg(&frame); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		dispatcher.Receive(frame)
+	}
+}
+
+// streamPeer adapts a gRPC server stream to ws.Peer
+type streamPeer struct {
+	stream   grpc.ServerStream
+	playerID string
+	connID   string
+	codec    ws.Codec
+	locale   string
+	acks     bool
+	outbox   *ws.Outbox
+	done     chan struct{}
+	logger   *slog.Logger
+	mu       sync.Mutex
+	closed   bool
+}
+
+// newStreamPeer creates a peer for the given stream
+func newStreamPeer(stream grpc.ServerStream, playerID, connID string, codec ws.Codec, outbox *ws.Outbox, logger *slog.Logger) *streamPeer {
+	return &streamPeer{
+		stream:   stream,
+		playerID: playerID,
+		connID:   connID,
+		codec:    codec,
+		outbox:   outbox,
+		done:     make(chan struct{}),
+		logger:   logger,
+	}
+}
+
+// GetPlayerID returns the player ID for this stream
+func (p *streamPeer) GetPlayerID() string {
+	return p.playerID
+}
+
+// GetConnectionID returns the ID correlating this stream's logs and errors
+func (p *streamPeer) GetConnectionID() string {
+	return p.connID
+}
+
+// GetCodec returns the codec negotiated for this stream
+func (p *streamPeer) GetCodec() ws.Codec {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.codec
+}
+
+// SetCodec switches the codec used for subsequent messages
+func (p *streamPeer) SetCodec(codec ws.Codec) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codec = codec
+}
+
+// GetLocale returns the language error messages are sent in
+func (p *streamPeer) GetLocale() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.locale
+}
+
+// SetLocale switches the language of subsequent error messages
+func (p *streamPeer) SetLocale(locale string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.locale = locale
+}
+
+// AcksEvents returns true if the client acknowledges critical events
+func (p *streamPeer) AcksEvents() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.acks
+}
+
+// SetAcks sets whether the client acknowledges critical events
+func (p *streamPeer) SetAcks(acks bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.acks = acks
+}
+
+// Send implements app.ClientConnection interface
+func (p *streamPeer) Send(message interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	data, err := ws.EncodeMessage(p.codec, message)
+	if err != nil {
+		return err
+	}
+
+	dropped, err := p.outbox.Push(data, ws.IsLowPriority(message))
+	if err != nil {
+		// Ending the stream makes the client reconnect and resync
+		p.logger.Warn("send buffer full, disconnecting slow client")
+		p.closeLocked()
+		return err
+	}
+	if dropped {
+		p.logger.Debug("send buffer full, low-priority message dropped")
+	}
+	return nil
+}
+
+// Close implements app.ClientConnection interface
+func (p *streamPeer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeLocked()
+}
+
+// closeLocked marks the peer closed and stops the write loop (caller must hold mu)
+func (p *streamPeer) closeLocked() error {
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+	close(p.done)
+	return nil
+}
+
+// writeLoop sends queued messages on the stream until the peer is closed
+func (p *streamPeer) writeLoop() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.stream.Context().Done():
+			return
+		case <-p.outbox.Ready():
+			for _, data := range p.outbox.Drain() {
+				if err := p.stream.SendMsg(data); err != nil {
+					p.logger.Debug("grpc send failed", "error", err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// firstValue returns the first metadata value for key, or ""
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// remoteIP returns the address a stream's client connected from
+func remoteIP(stream grpc.ServerStream) string {
+	p, ok := grpcpeer.FromContext(stream.Context())
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,385 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"imposter/internal/apierror"
+	"imposter/internal/app"
+	"imposter/internal/auth"
+	"imposter/internal/clientip"
+	"imposter/internal/domain"
+	"imposter/internal/requestid"
+)
+
+// JoinRequest is the request body for joining a room over REST
+type JoinRequest struct {
+	Nickname string `json:"nickname"`
+	Password string `json:"password,omitempty"` // Required for locked rooms
+	Invite   string `json:"invite,omitempty"`   // Admits the player to a locked room instead of the password
+}
+
+// JoinResponse is the response for joining a room over REST
+type JoinResponse struct {
+	PlayerID string `json:"playerId"`
+	Token    string `json:"token"` // Send as "Authorization: Bearer <token>"
+}
+
+// SubmitWordRequest is the request body for submitting a word
+type SubmitWordRequest struct {
+	Word string `json:"word"`
+}
+
+// CastVoteRequest is the request body for casting a vote
+type CastVoteRequest struct {
+	TargetPlayerID string `json:"targetPlayerId"`
+}
+
+// maxAudienceVotesPerRequest bounds how many votes a chat bridge may relay at once
+const maxAudienceVotesPerRequest = 500
+
+// AudienceVotesRequest is the request body for relaying audience votes from a chat bridge
+type AudienceVotesRequest struct {
+	Votes []app.AudienceVote `json:"votes"`
+}
+
+// AudienceVotesResponse is the response for relaying audience votes
+type AudienceVotesResponse struct {
+	Recorded int `json:"recorded"` // Votes naming no player who can be voted for are skipped
+}
+
+// CreateInviteRequest is the optional request body for creating an invite
+type CreateInviteRequest struct {
+	TTLSeconds int `json:"ttlSeconds,omitempty"` // Defaults to one hour, at most 24 hours
+	MaxUses    int `json:"maxUses,omitempty"`    // 0 allows any number of joins until expiry
+}
+
+// CreateInviteResponse is the response for creating an invite
+type CreateInviteResponse struct {
+	*domain.Invite
+	InviteLink string `json:"inviteLink"`
+}
+
+// ActionResponse is the response for a successful game action
+type ActionResponse struct {
+	Phase string `json:"phase"`
+}
+
+// handleJoinAction handles POST /api/rooms/{roomCode}/actions/join
+func (s *Server) handleJoinAction(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req JoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	nickname := strings.TrimSpace(req.Nickname)
+	if nickname == "" {
+		s.sendFieldError(w, r, "INVALID_MESSAGE", "nickname", "Nickname is required")
+		return
+	}
+
+	// Players with an external identity keep it as their player ID
+	playerID, hasIdentity := auth.SubjectFromContext(r.Context())
+	if !hasIdentity {
+		playerID = uuid.New().String()
+	}
+
+	if !session.HasPlayer(playerID) {
+		if _, err := session.AddPlayer(playerID, nickname, req.Password, req.Invite); err != nil {
+			s.sendActionError(w, r, err)
+			return
+		}
+	}
+
+	session.SetClientIP(playerID, clientip.FromContext(r.Context()))
+
+	token, err := session.IssuePlayerToken(playerID)
+	if err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendSuccess(w, &JoinResponse{
+		PlayerID: playerID,
+		Token:    token,
+	})
+}
+
+// handleStartAction handles POST /api/rooms/{roomCode}/actions/start
+func (s *Server) handleStartAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := session.StartGame(playerID); err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleAddBotAction handles POST /api/rooms/{roomCode}/actions/add-bot
+func (s *Server) handleAddBotAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	bot, err := session.AddBot(playerID)
+	if err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendSuccess(w, bot.ToInfo())
+}
+
+// handleFillBotsAction handles POST /api/rooms/{roomCode}/actions/fill-bots,
+// which is only registered outside production
+func (s *Server) handleFillBotsAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	bots, err := session.FillBots(playerID)
+	if err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	infos := make([]domain.PlayerInfo, 0, len(bots))
+	for _, bot := range bots {
+		infos = append(infos, bot.ToInfo())
+	}
+	s.sendSuccess(w, infos)
+}
+
+// handleNewRoundAction handles POST /api/rooms/{roomCode}/actions/new-round
+func (s *Server) handleNewRoundAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	if err := session.StartNewRound(playerID); err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleSubmitAction handles POST /api/rooms/{roomCode}/actions/submit
+func (s *Server) handleSubmitAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req SubmitWordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	if err := session.SubmitWord(playerID, req.Word); err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleVoteAction handles POST /api/rooms/{roomCode}/actions/vote
+func (s *Server) handleVoteAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req CastVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	if req.TargetPlayerID == "" {
+		s.sendFieldError(w, r, "INVALID_MESSAGE", "targetPlayerId", "Target player ID is required")
+		return
+	}
+
+	if err := session.CastVote(playerID, req.TargetPlayerID); err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleAudienceVotesAction handles POST /api/rooms/{roomCode}/actions/audience-votes,
+// which the host's chat bridge calls with viewers' votes
+func (s *Server) handleAudienceVotesAction(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req AudienceVotesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	if len(req.Votes) == 0 || len(req.Votes) > maxAudienceVotesPerRequest {
+		s.sendFieldError(w, r, "INVALID_MESSAGE", "votes", "Send between 1 and 500 votes")
+		return
+	}
+
+	recorded, err := session.CastBridgeVotes(playerID, req.Votes)
+	if err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendSuccess(w, &AudienceVotesResponse{Recorded: recorded})
+}
+
+// handleCreateInvite handles POST /api/rooms/{roomCode}/invites
+func (s *Server) handleCreateInvite(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateInviteRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+			return
+		}
+	}
+
+	if req.TTLSeconds < 0 || req.MaxUses < 0 {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "ttlSeconds and maxUses cannot be negative")
+		return
+	}
+
+	ttl := time.Duration(min(req.TTLSeconds, int(app.MaxInviteTTL.Seconds()))) * time.Second
+	invite, err := session.CreateInvite(playerID, ttl, req.MaxUses)
+	if err != nil {
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	s.sendSuccess(w, &CreateInviteResponse{
+		Invite:     invite,
+		InviteLink: s.baseURL(r) + "/join/" + session.GetRoomCode() + "?invite=" + invite.Token,
+	})
+}
+
+// handleGetState handles GET /api/rooms/{roomCode}/state
+func (s *Server) handleGetState(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+
+	s.sendSuccess(w, session.GetGameState(playerID))
+}
+
+// lookupSession resolves the room from the path, writing an error response if it does not exist
+func (s *Server) lookupSession(w http.ResponseWriter, r *http.Request) (*app.GameSession, bool) {
+	roomCode := r.PathValue("roomCode")
+	if roomCode == "" {
+		s.sendError(w, r, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
+		return nil, false
+	}
+
+	session, err := s.hub.GetSession(strings.ToUpper(roomCode))
+	if err != nil {
+		s.sendError(w, r, http.StatusNotFound, "ROOM_NOT_FOUND", "Room not found")
+		return nil, false
+	}
+
+	return session, true
+}
+
+// authenticateAction resolves the room and the player identified by the bearer token,
+// or by the external identity when JWT authentication is enabled
+func (s *Server) authenticateAction(w http.ResponseWriter, r *http.Request) (*app.GameSession, string, bool) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return nil, "", false
+	}
+
+	if playerID, ok := auth.SubjectFromContext(r.Context()); ok {
+		if !session.HasPlayer(playerID) {
+			s.sendError(w, r, http.StatusNotFound, "PLAYER_NOT_FOUND", "Player not found")
+			return nil, "", false
+		}
+		return session, playerID, true
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Bearer token is required")
+		return nil, "", false
+	}
+
+	playerID, err := session.AuthenticatePlayer(token)
+	if err != nil {
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid token")
+		return nil, "", false
+	}
+
+	return session, playerID, true
+}
+
+// sendActionSuccess reports the phase the game is in after a successful action
+func (s *Server) sendActionSuccess(w http.ResponseWriter, session *app.GameSession) {
+	s.sendSuccess(w, &ActionResponse{
+		Phase: string(session.GetPhase()),
+	})
+}
+
+// sendActionError maps a domain error from a game action to an HTTP error
+// response, reporting errors no action is expected to return
+func (s *Server) sendActionError(w http.ResponseWriter, r *http.Request, err error) {
+	e, ok := apierror.Classify(err)
+	if !ok {
+		s.reportError(r, err)
+	}
+	s.sendError(w, r, e.Status, e.Code, e.Message)
+}
+
+// reportError reports an unexpected error from handling r, with the room's
+// phase if r is for a room on this instance
+func (s *Server) reportError(r *http.Request, err error) {
+	s.requestLogger(r).Error("unexpected error", "path", r.URL.Path, "error", err)
+
+	tags := map[string]string{
+		"source":    "http",
+		"path":      r.URL.Path,
+		"requestID": requestid.FromContext(r.Context()),
+	}
+	if roomCode := r.PathValue("roomCode"); roomCode != "" {
+		if session, lookupErr := s.hub.GetSession(roomCode); lookupErr == nil {
+			session.ReportError(err, tags)
+			return
+		}
+	}
+	s.hub.ReportError(err, tags)
+}
@@ -0,0 +1,521 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"imposter/internal/analytics"
+	"imposter/internal/app"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+	"imposter/internal/i18n"
+	"imposter/internal/transport/ws"
+)
+
+// maxAnnouncementLength caps a system notice, in bytes
+const maxAnnouncementLength = 280
+
+// AdminRoomsResponse is the response for listing all rooms
+type AdminRoomsResponse struct {
+	Rooms []*AdminRoom `json:"rooms"`
+}
+
+// AdminRoom is a room in the admin room list
+type AdminRoom struct {
+	*app.RoomSnapshot
+	Connections  int             `json:"connections"`
+	Metrics      app.RoomMetrics `json:"metrics"`
+	LastActivity time.Time       `json:"lastActivity"`
+}
+
+// AdminOverviewResponse is the response for the admin dashboard's overview
+type AdminOverviewResponse struct {
+	Rooms       int             `json:"rooms"`
+	Hibernated  int             `json:"hibernated"`
+	Players     int             `json:"players"`
+	Connections int             `json:"connections"`
+	History     []app.HubSample `json:"history"` // Oldest first
+}
+
+// AdminWordStatsResponse is the response for listing secret word outcomes
+type AdminWordStatsResponse struct {
+	Words []analytics.WordStats `json:"words"` // Most played first
+}
+
+// ReloadWordsResponse is the response for reloading word lists
+type ReloadWordsResponse struct {
+	WordSources []string `json:"wordSources"`
+}
+
+// DeleteRoomResponse is the response for force-deleting a room
+type DeleteRoomResponse struct {
+	RoomCode string `json:"roomCode"`
+	Deleted  bool   `json:"deleted"`
+}
+
+// ForcePhaseRequest is the request body for forcing a phase transition
+type ForcePhaseRequest struct {
+	Phase domain.Phase `json:"phase"`
+}
+
+// AdminInvitesResponse is the response for listing a room's active invites
+type AdminInvitesResponse struct {
+	RoomCode string          `json:"roomCode"`
+	Invites  []domain.Invite `json:"invites"`
+}
+
+// RevokeInviteResponse is the response for revoking an invite
+type RevokeInviteResponse struct {
+	Token   string `json:"token"`
+	Revoked bool   `json:"revoked"`
+}
+
+// ConfigReloader reloads the configuration, reporting what changed
+type ConfigReloader func() (*config.ReloadResult, error)
+
+// LogLevelRequest is the request body for changing the log level
+type LogLevelRequest struct {
+	Level string `json:"level"` // "debug", "info", "warn" or "error"
+}
+
+// LogLevelResponse reports the log level
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// AnnouncementRequest is the request body for broadcasting a system notice
+type AnnouncementRequest struct {
+	Message  string            `json:"message"`
+	Severity ws.NoticeSeverity `json:"severity,omitempty"` // "info" (default), "warning" or "critical"
+
+	// Translations of Message by locale, e.g. {"es": "..."}, sent to
+	// connections in that locale
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+// AnnouncementResponse is the response for broadcasting a system notice
+type AnnouncementResponse struct {
+	Severity   ws.NoticeSeverity `json:"severity"`
+	Recipients int               `json:"recipients"` // Connections on this instance it was sent to
+}
+
+// ImportRoomResponse is the response for importing a room
+type ImportRoomResponse struct {
+	RoomCode    string `json:"roomCode"`
+	PlayerCount int    `json:"playerCount"`
+	Phase       string `json:"phase"`
+}
+
+// requireAdmin wraps a handler so only requests bearing the admin token reach it
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAdmin(r) {
+			s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Admin token is required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// isAdmin returns true if r carries the admin token, which must be configured
+func (s *Server) isAdmin(r *http.Request) bool {
+	expected := s.config.Security.AdminToken
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// handleAdminListRooms handles GET /api/admin/rooms
+func (s *Server) handleAdminListRooms(w http.ResponseWriter, r *http.Request) {
+	sessions := s.hub.ListSessions()
+	rooms := make([]*AdminRoom, 0, len(sessions))
+	for _, session := range sessions {
+		rooms = append(rooms, &AdminRoom{
+			RoomSnapshot: session.Snapshot(),
+			Connections:  session.ConnectionCount(),
+			Metrics:      session.Metrics(),
+			LastActivity: session.LastActivity(),
+		})
+	}
+
+	s.sendSuccess(w, &AdminRoomsResponse{
+		Rooms: rooms,
+	})
+}
+
+// handleAdminOverview handles GET /api/admin/overview
+func (s *Server) handleAdminOverview(w http.ResponseWriter, r *http.Request) {
+	resp := &AdminOverviewResponse{
+		Hibernated: s.hub.HibernatedCount(),
+		History:    s.hub.StatsHistory(),
+	}
+	for _, session := range s.hub.ListSessions() {
+		resp.Rooms++
+		resp.Players += session.GetPlayerCount()
+		resp.Connections += session.ConnectionCount()
+	}
+
+	s.sendSuccess(w, resp)
+}
+
+// handleAdminWordStats handles GET /api/admin/words
+func (s *Server) handleAdminWordStats(w http.ResponseWriter, r *http.Request) {
+	if s.analytics == nil {
+		s.sendError(w, r, http.StatusNotFound, "ANALYTICS_DISABLED", "Analytics are not enabled")
+		return
+	}
+
+	s.sendSuccess(w, &AdminWordStatsResponse{
+		Words: s.analytics.WordStats(),
+	})
+}
+
+// handleAdminReloadWords handles POST /api/admin/words/reload
+func (s *Server) handleAdminReloadWords(w http.ResponseWriter, r *http.Request) {
+	names, err := s.hub.ReloadWords()
+	if err != nil {
+		s.requestLogger(r).Warn("failed to reload word lists", "error", err)
+		s.sendError(w, r, http.StatusUnprocessableEntity, "RELOAD_FAILED", err.Error())
+		return
+	}
+	s.requestLogger(r).Info("word lists reloaded by admin", "sources", names, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &ReloadWordsResponse{
+		WordSources: names,
+	})
+}
+
+// SetConfigReloader lets admins reload the configuration at
+// /api/admin/config/reload
+func (s *Server) SetConfigReloader(reload ConfigReloader) {
+	s.reloadConfig = reload
+}
+
+// SetLogLevel lets admins read and change level at /api/admin/loglevel
+func (s *Server) SetLogLevel(level *slog.LevelVar) {
+	s.logLevel = level
+}
+
+// handleAdminGetLogLevel handles GET /api/admin/loglevel
+func (s *Server) handleAdminGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if s.logLevel == nil {
+		s.sendError(w, r, http.StatusNotFound, "LOG_LEVEL_DISABLED", "The log level cannot be changed at runtime")
+		return
+	}
+
+	s.sendSuccess(w, &LogLevelResponse{
+		Level: strings.ToLower(s.logLevel.Level().String()),
+	})
+}
+
+// handleAdminSetLogLevel handles POST /api/admin/loglevel. The level holds
+// until changed again or the configuration is reloaded.
+func (s *Server) handleAdminSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	if s.logLevel == nil {
+		s.sendError(w, r, http.StatusNotFound, "LOG_LEVEL_DISABLED", "The log level cannot be changed at runtime")
+		return
+	}
+
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		s.sendFieldError(w, r, "INVALID_LEVEL", "level", "Level must be debug, info, warn or error")
+		return
+	}
+
+	previous := s.logLevel.Level()
+	s.logLevel.Set(level)
+	// Logged at warn so the change shows at any level
+	s.requestLogger(r).Warn("log level changed by admin",
+		"from", previous.String(), "to", level.String(), "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &LogLevelResponse{
+		Level: strings.ToLower(level.String()),
+	})
+}
+
+// handleAdminReloadConfig handles POST /api/admin/config/reload
+func (s *Server) handleAdminReloadConfig(w http.ResponseWriter, r *http.Request) {
+	if s.reloadConfig == nil {
+		s.sendError(w, r, http.StatusNotFound, "CONFIG_RELOAD_DISABLED", "Configuration reload is not enabled")
+		return
+	}
+
+	result, err := s.reloadConfig()
+	if err != nil {
+		s.requestLogger(r).Warn("failed to reload configuration", "error", err)
+		s.sendError(w, r, http.StatusUnprocessableEntity, "RELOAD_FAILED", err.Error())
+		return
+	}
+	s.requestLogger(r).Info("configuration reloaded by admin",
+		"applied", result.Applied, "restartRequired", result.RestartRequired, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, result)
+}
+
+// handleAdminAnnounce handles POST /api/admin/announcements
+func (s *Server) handleAdminAnnounce(w http.ResponseWriter, r *http.Request) {
+	var req AnnouncementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" || len(req.Message) > maxAnnouncementLength {
+		s.sendFieldError(w, r, "INVALID_MESSAGE", "message", "Message must be 1 to 280 characters")
+		return
+	}
+	if req.Severity == "" {
+		req.Severity = ws.SeverityInfo
+	}
+	if !req.Severity.IsValid() {
+		s.sendFieldError(w, r, "INVALID_SEVERITY", "severity", "Severity must be info, warning or critical")
+		return
+	}
+
+	translations := make(map[string]string, len(req.Translations))
+	for tag, message := range req.Translations {
+		locale := i18n.Match(tag)
+		message = strings.TrimSpace(message)
+		if locale == "" {
+			s.sendFieldError(w, r, "INVALID_LOCALE", "translations", "Translations must be for one of: "+strings.Join(i18n.Supported(), ", "))
+			return
+		}
+		if message == "" || len(message) > maxAnnouncementLength {
+			s.sendFieldError(w, r, "INVALID_MESSAGE", "message", "Message must be 1 to 280 characters")
+			return
+		}
+		translations[locale] = message
+	}
+
+	recipients := s.hub.BroadcastNotice(func(locale string) interface{} {
+		message, ok := translations[locale]
+		if !ok {
+			message = req.Message
+		}
+		return ws.NewServerMessage(ws.MsgSystemNotice, &ws.SystemNoticePayload{
+			Message:  message,
+			Severity: req.Severity,
+		})
+	})
+	s.requestLogger(r).Info("announcement broadcast by admin",
+		"severity", req.Severity, "recipients", recipients, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &AnnouncementResponse{
+		Severity:   req.Severity,
+		Recipients: recipients,
+	})
+}
+
+// handleAdminGetRoom handles GET /api/admin/rooms/{roomCode}
+func (s *Server) handleAdminGetRoom(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	s.sendSuccess(w, session.Inspect())
+}
+
+// handleAdminDumpRoom handles GET /api/admin/rooms/{roomCode}/dump, returning
+// the session's raw state for debugging a stuck room. ?redact=true hides the
+// secret words.
+func (s *Server) handleAdminDumpRoom(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	redact, _ := strconv.ParseBool(r.URL.Query().Get("redact"))
+	dump, err := session.Dump(redact)
+	if err != nil {
+		s.sendAdminError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("room state dumped by admin", "roomCode", session.GetRoomCode(), "redacted", redact, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, dump)
+}
+
+// handleAdminDeleteRoom handles DELETE /api/admin/rooms/{roomCode}
+// Hibernated rooms are deleted from the store without being woken.
+func (s *Server) handleAdminDeleteRoom(w http.ResponseWriter, r *http.Request) {
+	roomCode := strings.ToUpper(r.PathValue("roomCode"))
+	if !s.hub.DeleteSession(roomCode) {
+		s.sendError(w, r, http.StatusNotFound, "ROOM_NOT_FOUND", "Room not found")
+		return
+	}
+	s.requestLogger(r).Info("room deleted by admin", "roomCode", roomCode, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &DeleteRoomResponse{
+		RoomCode: roomCode,
+		Deleted:  true,
+	})
+}
+
+// handleAdminForcePhase handles POST /api/admin/rooms/{roomCode}/phase
+func (s *Server) handleAdminForcePhase(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req ForcePhaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	if err := session.ForcePhase(domain.Phase(strings.ToUpper(string(req.Phase)))); err != nil {
+		s.sendAdminError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleAdminAdvancePhase handles POST /api/admin/rooms/{roomCode}/advance,
+// moving a stuck room on to the phase that would normally follow
+func (s *Server) handleAdminAdvancePhase(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	phase, err := session.AdvancePhase()
+	if err != nil {
+		s.sendAdminError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("room advanced by admin", "roomCode", session.GetRoomCode(), "phase", phase, "clientIP", s.clientIP(r))
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleAdminRestartTimer handles POST /api/admin/rooms/{roomCode}/timer/restart
+func (s *Server) handleAdminRestartTimer(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	if err := session.RestartTimer(); err != nil {
+		s.sendAdminError(w, r, err)
+		return
+	}
+
+	s.sendActionSuccess(w, session)
+}
+
+// handleAdminListInvites handles GET /api/admin/rooms/{roomCode}/invites
+func (s *Server) handleAdminListInvites(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	s.sendSuccess(w, &AdminInvitesResponse{
+		RoomCode: session.GetRoomCode(),
+		Invites:  session.ListInvites(),
+	})
+}
+
+// handleAdminRevokeInvite handles DELETE /api/admin/rooms/{roomCode}/invites/{token}
+func (s *Server) handleAdminRevokeInvite(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	token := r.PathValue("token")
+	if !session.RevokeInvite(token) {
+		s.sendError(w, r, http.StatusNotFound, "INVITE_NOT_FOUND", "Invite not found")
+		return
+	}
+	s.requestLogger(r).Info("invite revoked by admin", "roomCode", session.GetRoomCode(), "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &RevokeInviteResponse{
+		Token:   token,
+		Revoked: true,
+	})
+}
+
+// handleAdminExportRoom handles GET /api/admin/rooms/{roomCode}/export
+func (s *Server) handleAdminExportRoom(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := session.Export()
+	if err != nil {
+		s.requestLogger(r).Error("failed to export room", "roomCode", session.GetRoomCode(), "error", err)
+		s.sendError(w, r, http.StatusInternalServerError, "EXPORT_FAILED", "Failed to export room")
+		return
+	}
+	s.requestLogger(r).Info("room exported by admin", "roomCode", session.GetRoomCode(), "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, export)
+}
+
+// handleAdminImportRoom handles POST /api/admin/rooms/import
+func (s *Server) handleAdminImportRoom(w http.ResponseWriter, r *http.Request) {
+	var export app.RoomExport
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	session, err := s.hub.ImportSession(&export)
+	switch err {
+	case nil:
+	case domain.ErrInvalidExport:
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_EXPORT", "Invalid or unsupported room export")
+		return
+	case domain.ErrRoomCodeTaken:
+		s.sendError(w, r, http.StatusConflict, "ROOM_CODE_TAKEN", "This room code is already in use")
+		return
+	default:
+		s.sendError(w, r, http.StatusInternalServerError, "IMPORT_FAILED", "Failed to import room")
+		return
+	}
+	s.requestLogger(r).Info("room imported by admin", "roomCode", session.GetRoomCode(), "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, &ImportRoomResponse{
+		RoomCode:    session.GetRoomCode(),
+		PlayerCount: session.GetPlayerCount(),
+		Phase:       string(session.GetPhase()),
+	})
+}
+
+// handleAdminDashboard handles GET /admin. The page itself is public; it asks
+// for the admin token and sends it with every API request.
+func (s *Server) handleAdminDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if s.assets == nil || !s.assets.serve(w, r, "admin.html") {
+		w.Header().Del("Content-Type")
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// sendAdminError maps a domain error from an admin action to an HTTP error response
+func (s *Server) sendAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case domain.ErrInvalidTransition:
+		s.sendError(w, r, http.StatusConflict, "INVALID_TRANSITION", "Transition not allowed from the current phase")
+	case domain.ErrInvalidPhase:
+		s.sendError(w, r, http.StatusConflict, "INVALID_ACTION", "No timer runs in the current phase")
+	default:
+		s.sendActionError(w, r, err)
+	}
+}
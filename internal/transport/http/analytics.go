@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"imposter/internal/analytics"
+)
+
+// Days of games per day an analytics report lists
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
+// SetAnalytics serves collector's aggregates at /api/analytics
+func (s *Server) SetAnalytics(collector *analytics.Collector) {
+	s.analytics = collector
+}
+
+// handleAnalytics handles GET /api/analytics?days=
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	if s.analytics == nil {
+		s.sendError(w, r, http.StatusNotFound, "ANALYTICS_DISABLED", "Analytics are not enabled")
+		return
+	}
+
+	days := defaultAnalyticsDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxAnalyticsDays {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_DAYS", "days must be between 1 and 365")
+			return
+		}
+	}
+
+	s.sendSuccess(w, s.analytics.Report(days))
+}
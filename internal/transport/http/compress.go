@@ -0,0 +1,156 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// gzipWriters reuses gzip writers across responses
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+// acceptsEncoding returns true if the request's Accept-Encoding allows encoding
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// compressResponses gzips responses of at least minSize bytes for clients that
+// accept it. Smaller responses are sent as is, since compressing them costs
+// more than it saves.
+func compressResponses(next http.Handler, minSize int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsEncoding(r, "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			minSize:        minSize,
+			status:         http.StatusOK,
+		}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the start of a response until it knows whether the
+// response is large enough to compress
+type compressWriter struct {
+	http.ResponseWriter
+	minSize int
+	status  int
+	buf     []byte
+	started bool
+	gz      *gzip.Writer
+}
+
+// WriteHeader records the status; it is sent once the encoding is decided
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.started {
+		return
+	}
+	cw.status = code
+}
+
+// Write buffers until minSize bytes have been written, then starts compressing
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.started {
+		if cw.gz != nil {
+			return cw.gz.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// start sends the headers and buffered body, compressed if compress is set
+// and the handler did not encode the body itself
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+
+	h := cw.Header()
+	if compress && h.Get("Content-Encoding") == "" && bodyAllowed(cw.status) {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		cw.gz = gzipWriters.Get().(*gzip.Writer)
+		cw.gz.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if cw.gz != nil {
+		_, err := cw.gz.Write(buf)
+		return err
+	}
+	_, err := cw.ResponseWriter.Write(buf)
+	return err
+}
+
+// Close finishes the response, sending a short one uncompressed
+func (cw *compressWriter) Close() error {
+	if !cw.started {
+		return cw.start(false)
+	}
+	if cw.gz == nil {
+		return nil
+	}
+
+	err := cw.gz.Close()
+	gzipWriters.Put(cw.gz)
+	cw.gz = nil
+	return err
+}
+
+// Flush sends what has been written so far, deciding the encoding if needed
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		cw.start(false)
+	}
+	if cw.gz != nil {
+		cw.gz.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// bodyAllowed returns true if a response with status may carry a body
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
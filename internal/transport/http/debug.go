@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"imposter/internal/app"
+)
+
+// debugWriteTimeout bounds profile and trace requests, which run longer than
+// the server-wide write timeout
+const debugWriteTimeout = 2 * time.Minute
+
+// RuntimeStatsResponse is a snapshot of the Go runtime and hub state
+type RuntimeStatsResponse struct {
+	Goroutines   int     `json:"goroutines"`
+	HeapAlloc    uint64  `json:"heapAllocBytes"`
+	HeapInuse    uint64  `json:"heapInuseBytes"`
+	HeapObjects  uint64  `json:"heapObjects"`
+	Sys          uint64  `json:"sysBytes"`
+	NumGC        uint32  `json:"numGC"`
+	LastGCPause  float64 `json:"lastGCPauseMs"`
+	ActiveGames  int     `json:"activeGames"`
+	TotalPlayers int     `json:"totalPlayers"`
+	GoVersion    string  `json:"goVersion"`
+
+	// Events active rooms did not broadcast as queued
+	EventQueue app.EventQueueStats `json:"eventQueue"`
+}
+
+// setupDebugRoutes registers net/http/pprof and the runtime stats endpoint behind the admin token
+func (s *Server) setupDebugRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /debug/pprof/", s.requireAdmin(s.debugHandler(pprof.Index)))
+	mux.HandleFunc("GET /debug/pprof/cmdline", s.requireAdmin(s.debugHandler(pprof.Cmdline)))
+	mux.HandleFunc("GET /debug/pprof/profile", s.requireAdmin(s.debugHandler(pprof.Profile)))
+	mux.HandleFunc("GET /debug/pprof/symbol", s.requireAdmin(s.debugHandler(pprof.Symbol)))
+	mux.HandleFunc("POST /debug/pprof/symbol", s.requireAdmin(s.debugHandler(pprof.Symbol)))
+	mux.HandleFunc("GET /debug/pprof/trace", s.requireAdmin(s.debugHandler(pprof.Trace)))
+	mux.HandleFunc("GET /debug/runtime", s.requireAdmin(s.handleRuntimeStats))
+}
+
+// debugHandler lifts the write deadline for long-running profiling requests
+func (s *Server) debugHandler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.NewResponseController(w).SetWriteDeadline(time.Now().Add(debugWriteTimeout))
+		next(w, r)
+	}
+}
+
+// handleRuntimeStats handles GET /debug/runtime
+func (s *Server) handleRuntimeStats(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	s.sendSuccess(w, &RuntimeStatsResponse{
+		Goroutines:   runtime.NumGoroutine(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		LastGCPause:  float64(mem.PauseNs[(mem.NumGC+255)%256]) / float64(time.Millisecond),
+		ActiveGames:  s.hub.GetSessionCount(),
+		TotalPlayers: s.hub.GetTotalPlayerCount(),
+		GoVersion:    runtime.Version(),
+		EventQueue:   s.hub.EventQueueStats(),
+	})
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// GraphQLRequest is the request body for the GraphQL endpoint
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// newGraphQLSchema builds the read-only schema over rooms, players, round history and stats.
+// Object fields resolve from the json tags of the app snapshot types.
+func newGraphQLSchema(hub *app.GameHub) (graphql.Schema, error) {
+	playerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Player",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"nickname":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"status":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"hasVoted":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasSubmitted": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	submissionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Submission",
+		Fields: graphql.Fields{
+			"playerId":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"nickname":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"word":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"order":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"timestamp": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	roundType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Round",
+		Fields: graphql.Fields{
+			"number":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"secretWord":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"imposterId":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"imposterIds": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			"winner":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"submissions": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(submissionType)))},
+			"startedAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"endedAt":     &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	roomType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Room",
+		Fields: graphql.Fields{
+			"roomCode": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"phase":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"hostId":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"canJoin":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"locked":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"visibility": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return string(p.Source.(*app.RoomSnapshot).Visibility), nil
+				},
+			},
+			"createdAt":  &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"players":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(playerType)))},
+			"rounds":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roundType)))},
+			"spectators": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"playerCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(p.Source.(*app.RoomSnapshot).Players), nil
+				},
+			},
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Stats",
+		Fields: graphql.Fields{
+			"activeGames":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalPlayers":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"connectedPlayers": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"spectators":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"rooms": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roomType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					// Only public rooms are listed, as over REST; unlisted rooms
+					// are reachable by code alone
+					rooms := make([]*app.RoomSnapshot, 0)
+					for _, session := range hub.ListSessions() {
+						if room := session.Snapshot(); room.Visibility == domain.VisibilityPublic {
+							rooms = append(rooms, room)
+						}
+					}
+					return rooms, nil
+				},
+			},
+			"room": &graphql.Field{
+				Type: roomType,
+				Args: graphql.FieldConfigArgument{
+					"roomCode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					roomCode, _ := p.Args["roomCode"].(string)
+					// A query does not wake a hibernated room
+					session, err := hub.PeekSession(strings.ToUpper(roomCode))
+					if err != nil {
+						return nil, nil
+					}
+					return session.Snapshot(), nil
+				},
+			},
+			"stats": &graphql.Field{
+				Type: graphql.NewNonNull(statsType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					counts := hub.Counts()
+					return &StatsResponse{
+						ActiveGames:      hub.GetSessionCount(),
+						TotalPlayers:     counts.Players,
+						ConnectedPlayers: counts.ConnectedPlayers,
+						Spectators:       counts.Spectators,
+					}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// handleGraphQL handles GET and POST /api/graphql
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				s.sendError(w, r, http.StatusBadRequest, "INVALID_VARIABLES", "Invalid variables")
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	if req.Query == "" {
+		s.sendError(w, r, http.StatusBadRequest, "MISSING_QUERY", "Query is required")
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.graphqlSchema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        r.Context(),
+	})
+
+	// GraphQL responses use their own envelope ({data, errors}) rather than Response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
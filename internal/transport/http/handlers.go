@@ -1,12 +1,44 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"imposter/internal/apierror"
+	"imposter/internal/app"
+	"imposter/internal/auth"
+	"imposter/internal/buildinfo"
 	"imposter/internal/domain"
+	"imposter/internal/i18n"
+	"imposter/internal/requestid"
+)
+
+const (
+	// defaultPollTimeout is how long a long-poll request waits when no timeout is given
+	defaultPollTimeout = 25 * time.Second
+
+	// maxPollTimeout caps the timeout a long-poll client may request
+	maxPollTimeout = 60 * time.Second
+
+	// maxPasswordLength caps the length of a room password
+	maxPasswordLength = 64
+
+	// defaultRoomPageSize is the number of rooms per page when no pageSize is given
+	defaultRoomPageSize = 20
+
+	// maxRoomPageSize caps the pageSize a client may request
+	maxRoomPageSize = 50
+
+	// capacityRetryAfter is when clients are told to retry creating a room
+	// while the server is at its room limit
+	capacityRetryAfter = 30 * time.Second
 )
 
 // Response is a standard API response
@@ -18,8 +50,40 @@ type Response struct {
 
 // ErrorInfo contains error details
 type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Field     string                 `json:"field,omitempty"`   // The request field at fault, if any
+	Retryable bool                   `json:"retryable"`         // Repeating the request later, unchanged, may succeed
+	Details   map[string]interface{} `json:"details,omitempty"` // Facts about the error specific to its code
+	RequestID string                 `json:"requestId,omitempty"` // Quote when reporting a problem
+}
+
+// CreateRoomRequest is the optional request body for room creation
+type CreateRoomRequest struct {
+	Password   string            `json:"password,omitempty"`   // Locks the room when set
+	Visibility domain.Visibility `json:"visibility,omitempty"` // "public" or "unlisted" (default)
+	Code       string            `json:"code,omitempty"`       // Custom room code, e.g. "PIZZA"
+	CodeLength int               `json:"codeLength,omitempty"` // Length of the generated code
+	WordSource string            `json:"wordSource,omitempty"` // Where secret words come from, e.g. "llm"; defaults to the server's
+	Language   string            `json:"language,omitempty"`   // e.g. "en"; lets quick join match players to the room
+	Tags       []string          `json:"tags,omitempty"`       // Up to 5 labels for the room browser, e.g. "casual"
+	MaxPlayers int               `json:"maxPlayers,omitempty"` // Room size; defaults to the server's
+	VoteQuorum domain.VoteQuorum `json:"voteQuorum,omitempty"` // "connected" or "all"; defaults to the server's
+	TieRule    domain.TieRule    `json:"tieRule,omitempty"`    // "imposter", "revote" or "random"; defaults to the server's
+	Seed       int64             `json:"seed,omitempty"`       // Makes rounds reproducible; whoever knows it can predict them
+
+	// How many imposters each round deals, "auto" or 1 to 4; defaults to the
+	// server's. Auto deals one more each time the players pass a threshold.
+	Imposters          string `json:"imposters,omitempty"`
+	ImposterThresholds []int  `json:"imposterThresholds,omitempty"` // For auto, e.g. [6, 10]: 1 imposter up to 6 players, 2 up to 10, 3 beyond
+
+	// A family-friendly room deals from the family word list and checks
+	// nicknames and clues strictly for profanity
+	FamilyFriendly bool `json:"familyFriendly,omitempty"`
+
+	// A practice room pits its one player against bots
+	Practice     bool        `json:"practice,omitempty"`
+	PracticeRole domain.Role `json:"practiceRole,omitempty"` // "IMPOSTER" or "VILEK" every round; random by default
 }
 
 // CreateRoomResponse is the response for room creation
@@ -30,10 +94,40 @@ type CreateRoomResponse struct {
 
 // GetRoomResponse is the response for getting room info
 type GetRoomResponse struct {
-	RoomCode    string `json:"roomCode"`
-	PlayerCount int    `json:"playerCount"`
-	Phase       string `json:"phase"`
-	CanJoin     bool   `json:"canJoin"`
+	RoomCode         string `json:"roomCode"`
+	PlayerCount      int    `json:"playerCount"`
+	ConnectedPlayers int    `json:"connectedPlayers"` // Players currently connected, bots included
+	Spectators       int    `json:"spectators"`       // Watching through this instance
+	Phase            string `json:"phase"`
+	CanJoin          bool   `json:"canJoin"`
+	Locked           bool   `json:"locked"`
+}
+
+// PublicRoom describes a public room in the room list
+type PublicRoom struct {
+	RoomCode           string       `json:"roomCode"`
+	Phase              domain.Phase `json:"phase"`
+	PlayerCount        int          `json:"playerCount"`
+	ConnectedPlayers   int          `json:"connectedPlayers"` // Players currently connected, bots included
+	Spectators         int          `json:"spectators"`       // Watching through this instance
+	MinPlayers         int          `json:"minPlayers"`
+	MaxPlayers         int          `json:"maxPlayers"`
+	VotingDurationSecs int          `json:"votingDurationSecs"`
+	Locked             bool         `json:"locked"`
+	Language           string       `json:"language,omitempty"`
+	WordSource         string       `json:"wordSource,omitempty"` // Empty uses the server default
+	Tags               []string     `json:"tags,omitempty"`
+	FamilyFriendly     bool         `json:"familyFriendly"`
+	CreatedAt          time.Time    `json:"createdAt"`
+}
+
+// ListRoomsResponse is the response for listing public rooms
+type ListRoomsResponse struct {
+	Rooms      []PublicRoom `json:"rooms"`
+	Page       int          `json:"page,omitempty"` // Omitted when paging by cursor
+	PageSize   int          `json:"pageSize"`
+	Total      int          `json:"total"`                // Rooms matching the filters
+	NextCursor string       `json:"nextCursor,omitempty"` // Pass as cursor for the next page; omitted on the last
 }
 
 // RoomExistsResponse is the response for checking if room exists
@@ -41,32 +135,161 @@ type RoomExistsResponse struct {
 	Exists bool `json:"exists"`
 }
 
+// PollEventsResponse is the response for long-polling events
+type PollEventsResponse struct {
+	Events  []app.SequencedEvent `json:"events"`
+	LastSeq uint64               `json:"lastSeq"`
+	Missed  bool                 `json:"missed"` // Events were dropped; resync via WebSocket or reload
+}
+
 // HealthResponse is the response for health check
 type HealthResponse struct {
-	Status string `json:"status"`
+	Status       string                 `json:"status"` // "ok" or "degraded"
+	Dependencies map[string]CheckResult `json:"dependencies,omitempty"`
 }
 
 // StatsResponse is the response for stats endpoint
 type StatsResponse struct {
-	ActiveGames   int `json:"activeGames"`
-	TotalPlayers  int `json:"totalPlayers"`
+	ActiveGames      int                  `json:"activeGames"`
+	HibernatedGames  int                  `json:"hibernatedGames"`
+	GamesByPhase     map[domain.Phase]int `json:"gamesByPhase"` // Active games in each phase; phases without any are left out
+	TotalPlayers     int                  `json:"totalPlayers"`
+	ConnectedPlayers int                  `json:"connectedPlayers"` // Players currently connected, bots included
+	Spectators       int                  `json:"spectators"`
+	RateLimits       *RateLimitMetrics    `json:"rateLimits,omitempty"`
+}
+
+// RateLimitMetrics reports per-endpoint rate limiter activity
+type RateLimitMetrics struct {
+	Rooms    RateLimiterStats `json:"rooms"`
+	Connects RateLimiterStats `json:"connects"`
 }
 
 // handleCreateRoom handles POST /api/rooms
 func (s *Server) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
-	session, err := s.hub.CreateGame()
-	if err != nil {
-		s.sendError(w, http.StatusInternalServerError, "CREATION_FAILED", "Failed to create room")
+	// The body is optional; an empty body creates an unlocked room
+	var req CreateRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
 		return
 	}
 
-	// Build invite link
-	scheme := "http"
-	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
-		scheme = "https"
+	if len(req.Password) > maxPasswordLength {
+		s.sendFieldError(w, r, "INVALID_PASSWORD", "password", "Password is too long")
+		return
+	}
+
+	if req.Visibility != "" && !req.Visibility.IsValid() {
+		s.sendFieldError(w, r, "INVALID_VISIBILITY", "visibility", "Visibility must be public or unlisted")
+		return
+	}
+
+	if req.VoteQuorum != "" && !req.VoteQuorum.IsValid() {
+		s.sendFieldError(w, r, "INVALID_VOTE_QUORUM", "voteQuorum", "voteQuorum must be connected or all")
+		return
+	}
+
+	if req.TieRule != "" && !req.TieRule.IsValid() {
+		s.sendFieldError(w, r, "INVALID_TIE_RULE", "tieRule", "tieRule must be imposter, revote or random")
+		return
+	}
+
+	var imposters *domain.ImposterCount
+	if req.Imposters != "" || len(req.ImposterThresholds) > 0 {
+		count, err := domain.ParseImposterCount(req.Imposters, req.ImposterThresholds)
+		if err != nil {
+			s.sendFieldError(w, r, "INVALID_IMPOSTERS", "imposters", "imposters must be auto or 1 to 4, with up to 3 increasing thresholds of at least 2 players for auto")
+			return
+		}
+		imposters = &count
+	}
+
+	if req.PracticeRole != "" && (!req.Practice || (req.PracticeRole != domain.RoleImposter && req.PracticeRole != domain.RoleVilek)) {
+		s.sendFieldError(w, r, "INVALID_PRACTICE_ROLE", "practiceRole", "practiceRole must be IMPOSTER or VILEK, for practice rooms only")
+		return
+	}
+
+	language, ok := normalizeLanguage(req.Language)
+	if !ok {
+		s.sendFieldError(w, r, "INVALID_LANGUAGE", "language", "Language must be a tag such as en or pt-br")
+		return
+	}
+
+	tags, ok := normalizeTags(req.Tags)
+	if !ok {
+		s.sendFieldError(w, r, "INVALID_TAGS", "tags",
+			fmt.Sprintf("Up to %d tags of letters, digits and hyphens, each at most %d long", maxRoomTags, maxTagLength))
+		return
+	}
+
+	if !validMaxPlayers(req.MaxPlayers) {
+		s.sendFieldError(w, r, "INVALID_MAX_PLAYERS", "maxPlayers", maxPlayersMessage())
+		return
 	}
-	host := r.Host
-	inviteLink := scheme + "://" + host + "/join/" + session.GetRoomCode()
+
+	if req.Code != "" && req.CodeLength != 0 {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Specify either code or codeLength, not both")
+		return
+	}
+
+	if req.CodeLength != 0 && (req.CodeLength < app.MinRoomCodeLength || req.CodeLength > app.MaxRoomCodeLength) {
+		s.sendFieldError(w, r, "INVALID_CODE_LENGTH", "codeLength",
+			fmt.Sprintf("codeLength must be between %d and %d", app.MinRoomCodeLength, app.MaxRoomCodeLength))
+		return
+	}
+
+	session, err := s.hub.CreateGame(app.RoomOptions{
+		Password:   req.Password,
+		Visibility: req.Visibility,
+		Code:       req.Code,
+		CodeLength: req.CodeLength,
+		WordSource: req.WordSource,
+		Language:   language,
+		Tags:       tags,
+		MaxPlayers: req.MaxPlayers,
+		VoteQuorum: req.VoteQuorum,
+		TieRule:    req.TieRule,
+		Seed:       req.Seed,
+		Imposters:  imposters,
+
+		Practice:     req.Practice,
+		PracticeRole: req.PracticeRole,
+
+		FamilyFriendly: req.FamilyFriendly,
+
+		FillBots: s.config.Game.DevFillBots && !s.config.IsProduction(),
+	})
+	switch err {
+	case nil:
+	case domain.ErrInvalidRoomCode:
+		s.sendFieldError(w, r, "INVALID_ROOM_CODE", "code",
+			fmt.Sprintf("Room code must be %d to %d letters or digits", app.MinRoomCodeLength, app.MaxRoomCodeLength))
+		return
+	case domain.ErrUnknownWordSource:
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_WORD_SOURCE", "This server does not offer that word source")
+		return
+	case domain.ErrFamilyWordSource:
+		s.sendFieldError(w, r, "INVALID_WORD_SOURCE", "wordSource", "Family-friendly rooms deal words from the family list")
+		return
+	case domain.ErrInappropriateText:
+		s.sendFieldError(w, r, "INAPPROPRIATE_TEXT", "tags", "That is not allowed in this room, please choose other words")
+		return
+	case domain.ErrReservedRoomCode:
+		s.sendFieldError(w, r, "ROOM_CODE_RESERVED", "code", "This room code is reserved")
+		return
+	case domain.ErrRoomCodeTaken:
+		s.sendError(w, r, http.StatusConflict, "ROOM_CODE_TAKEN", "This room code is already in use")
+		return
+	case domain.ErrServerAtCapacity:
+		s.sendRetryAfter(w, r, http.StatusServiceUnavailable, "SERVER_AT_CAPACITY", "Too many rooms are open right now, please try again shortly", capacityRetryAfter)
+		return
+	default:
+		s.sendError(w, r, http.StatusInternalServerError, "CREATION_FAILED", "Failed to create room")
+		return
+	}
+
+	// Build invite link
+	inviteLink := s.baseURL(r) + "/join/" + session.GetRoomCode()
 
 	s.sendSuccess(w, &CreateRoomResponse{
 		RoomCode:   session.GetRoomCode(),
@@ -74,37 +297,146 @@ func (s *Server) handleCreateRoom(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleListRooms handles GET /api/rooms?public=true. Rooms can be filtered
+// by language, wordSource, minPlayerCount, maxPlayerCount, tags (comma
+// separated, all required) and phase, and sorted newest first or by fill.
+// Pages are numbered, or follow the previous page's nextCursor, which stays
+// in place as rooms open and close.
+func (s *Server) handleListRooms(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Only public rooms may be listed; unlisted rooms are reachable by code alone
+	if public, _ := strconv.ParseBool(query.Get("public")); !public {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "Only public=true listings are supported")
+		return
+	}
+
+	filter, message := parseRoomFilter(query)
+	if message != "" {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", message)
+		return
+	}
+
+	order, ok := parseRoomOrder(query)
+	if !ok {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "sort must be newest or fill")
+		return
+	}
+
+	var cursor *app.RoomCursor
+	if raw := query.Get("cursor"); raw != "" {
+		if query.Get("page") != "" {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "Specify either page or cursor, not both")
+			return
+		}
+		parsed, ok := app.ParseRoomCursor(raw)
+		if !ok || parsed.Order != order {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "Invalid cursor")
+			return
+		}
+		cursor = &parsed
+	}
+
+	page := 1
+	if raw := query.Get("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "Invalid page")
+			return
+		}
+		page = n
+	}
+
+	pageSize := defaultRoomPageSize
+	if raw := query.Get("pageSize"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_QUERY", "Invalid pageSize")
+			return
+		}
+		pageSize = min(n, maxRoomPageSize)
+	}
+
+	snapshots, err := s.hub.BrowseRooms(filter, order)
+	if err == domain.ErrUnknownWordSource {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_WORD_SOURCE", "This server does not offer that word source")
+		return
+	}
+
+	var remaining []*app.RoomSnapshot
+	if cursor != nil {
+		remaining = app.RoomsAfter(snapshots, *cursor)
+		page = 0
+	} else {
+		remaining = snapshots[min((page-1)*pageSize, len(snapshots)):]
+	}
+	pageRooms := remaining[:min(pageSize, len(remaining))]
+
+	rooms := make([]PublicRoom, 0, len(pageRooms))
+	for _, snapshot := range pageRooms {
+		rooms = append(rooms, publicRoom(snapshot))
+	}
+
+	var nextCursor string
+	if len(remaining) > len(pageRooms) {
+		nextCursor = app.NewRoomCursor(pageRooms[len(pageRooms)-1], order).Encode()
+	}
+
+	s.sendSuccess(w, &ListRoomsResponse{
+		Rooms:      rooms,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      len(snapshots),
+		NextCursor: nextCursor,
+	})
+}
+
 // handleGetRoom handles GET /api/rooms/{roomCode}
 func (s *Server) handleGetRoom(w http.ResponseWriter, r *http.Request) {
 	roomCode := r.PathValue("roomCode")
 	if roomCode == "" {
-		s.sendError(w, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
+		s.sendError(w, r, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
 		return
 	}
 
 	session, err := s.hub.GetSession(strings.ToUpper(roomCode))
 	if err != nil {
 		if err == domain.ErrGameNotFound {
-			s.sendError(w, http.StatusNotFound, "ROOM_NOT_FOUND", "Room not found")
+			s.sendError(w, r, http.StatusNotFound, "ROOM_NOT_FOUND", "Room not found")
 		} else {
-			s.sendError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+			s.sendError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
 		}
 		return
 	}
 
+	counts := session.Counts()
 	s.sendSuccess(w, &GetRoomResponse{
-		RoomCode:    session.GetRoomCode(),
-		PlayerCount: session.GetPlayerCount(),
-		Phase:       string(session.GetPhase()),
-		CanJoin:     session.CanJoin(),
+		RoomCode:         session.GetRoomCode(),
+		PlayerCount:      counts.Players,
+		ConnectedPlayers: counts.ConnectedPlayers,
+		Spectators:       counts.Spectators,
+		Phase:            string(counts.Phase),
+		CanJoin:          session.CanJoin(),
+		Locked:           session.IsLocked(),
 	})
 }
 
+// connectedPlayers counts the connected players among players
+func connectedPlayers(players []domain.PlayerInfo) int {
+	count := 0
+	for _, player := range players {
+		if player.Status == domain.StatusConnected {
+			count++
+		}
+	}
+	return count
+}
+
 // handleRoomExists handles GET /api/rooms/{roomCode}/exists
 func (s *Server) handleRoomExists(w http.ResponseWriter, r *http.Request) {
 	roomCode := r.PathValue("roomCode")
 	if roomCode == "" {
-		s.sendError(w, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
+		s.sendError(w, r, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
 		return
 	}
 
@@ -116,43 +448,171 @@ func (s *Server) handleRoomExists(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleHealth handles GET /api/health
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	s.sendSuccess(w, &HealthResponse{
-		Status: "ok",
+// handlePollEvents handles GET /api/rooms/{roomCode}/events, for the player
+// identified by authenticatePoll
+func (s *Server) handlePollEvents(w http.ResponseWriter, r *http.Request) {
+	roomCode := r.PathValue("roomCode")
+	if roomCode == "" {
+		s.sendError(w, r, http.StatusBadRequest, "MISSING_ROOM_CODE", "Room code is required")
+		return
+	}
+
+	query := r.URL.Query()
+	var since uint64
+	if raw := query.Get("since"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_SINCE", "since must be a non-negative integer")
+			return
+		}
+		since = parsed
+	}
+
+	timeout := defaultPollTimeout
+	if raw := query.Get("timeout"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			s.sendError(w, r, http.StatusBadRequest, "INVALID_TIMEOUT", "timeout must be a non-negative number of seconds")
+			return
+		}
+		timeout = min(time.Duration(seconds)*time.Second, maxPollTimeout)
+	}
+
+	session, err := s.hub.GetSession(strings.ToUpper(roomCode))
+	if err != nil {
+		s.sendError(w, r, http.StatusNotFound, "ROOM_NOT_FOUND", "Room not found")
+		return
+	}
+
+	// The buffer holds the player's role and secret word, so the poller proves
+	// who they are; player IDs are public and not enough
+	playerID, ok := s.authenticatePoll(w, r, session)
+	if !ok {
+		return
+	}
+
+	// Long polls outlive the server-wide write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Now().Add(timeout + 5*time.Second))
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	events, lastSeq, missed, err := session.PollEvents(ctx, playerID, since)
+	if err != nil {
+		if err == domain.ErrPlayerNotFound {
+			s.sendError(w, r, http.StatusNotFound, "PLAYER_NOT_FOUND", "Player not found")
+		} else {
+			s.sendError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+		return
+	}
+
+	if events == nil {
+		events = []app.SequencedEvent{}
+	}
+
+	s.sendSuccess(w, &PollEventsResponse{
+		Events:  events,
+		LastSeq: lastSeq,
+		Missed:  missed,
 	})
 }
 
+// authenticatePoll returns the player polling for events: the external
+// identity when JWT authentication is enabled, otherwise the player a REST
+// bearer token or a WebSocket reconnect token (?token=) was issued to
+func (s *Server) authenticatePoll(w http.ResponseWriter, r *http.Request, session *app.GameSession) (string, bool) {
+	if subject, ok := auth.SubjectFromContext(r.Context()); ok {
+		return subject, true
+	}
+
+	if bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found && bearer != "" {
+		playerID, err := session.AuthenticatePlayer(bearer)
+		if err != nil {
+			s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid token")
+			return "", false
+		}
+		return playerID, true
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Bearer token is required")
+		return "", false
+	}
+	playerID, err := session.VerifyReconnectToken(token)
+	if err != nil {
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or expired reconnect token")
+		return "", false
+	}
+	return playerID, true
+}
+
+// handleHealth handles GET /api/health, checking the configured external
+// dependencies. It responds 503 if any of them fails.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	resp := &HealthResponse{
+		Status:       "ok",
+		Dependencies: s.checkDependencies(r.Context()),
+	}
+	for name, result := range resp.Dependencies {
+		if result.Status != "ok" {
+			resp.Status = "degraded"
+			s.requestLogger(r).Warn("dependency check failed", "dependency", name, "error", result.Error)
+		}
+	}
+
+	if resp.Status != "ok" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(&Response{Success: true, Data: resp})
+		return
+	}
+	s.sendSuccess(w, resp)
+}
+
+// handleVersion handles GET /api/version
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	s.sendSuccess(w, buildinfo.Get())
+}
+
 // handleStats handles GET /api/stats
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-	s.sendSuccess(w, &StatsResponse{
-		ActiveGames:  s.hub.GetSessionCount(),
-		TotalPlayers: s.hub.GetTotalPlayerCount(),
-	})
+	counts := s.hub.Counts()
+	stats := &StatsResponse{
+		ActiveGames:      s.hub.GetSessionCount(),
+		HibernatedGames:  s.hub.HibernatedCount(),
+		GamesByPhase:     counts.GamesByPhase,
+		TotalPlayers:     counts.Players,
+		ConnectedPlayers: counts.ConnectedPlayers,
+		Spectators:       counts.Spectators,
+	}
+
+	if s.roomLimiter.Enabled() {
+		stats.RateLimits = &RateLimitMetrics{
+			Rooms:    s.roomLimiter.Stats(),
+			Connects: s.connectLimiter.Stats(),
+		}
+	}
+
+	s.sendSuccess(w, stats)
 }
 
-// handleStatic serves static files
+// handleStatic serves static files, fingerprinted paths with a long-lived cache
 func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
-	// Strip /static/ prefix
-	path := strings.TrimPrefix(r.URL.Path, "/static/")
-
-	// Try to open from webFS
-	file, err := s.webFS.Open("static/" + path)
-	if err != nil {
+	if s.assets == nil || !s.assets.serve(w, r, strings.TrimPrefix(r.URL.Path, "/")) {
 		http.NotFound(w, r)
-		return
 	}
-	defer file.Close()
+}
 
-	// Get file info for content type and modification time
-	stat, err := file.Stat()
-	if err != nil {
+// handleAssetManifest handles GET /static/manifest.json
+func (s *Server) handleAssetManifest(w http.ResponseWriter, r *http.Request) {
+	if s.assets == nil {
 		http.NotFound(w, r)
 		return
 	}
-
-	// Serve the file
-	http.ServeContent(w, r, stat.Name(), stat.ModTime(), file.(io.ReadSeeker))
+	s.assets.serveManifest(w, r)
 }
 
 // handleSPA serves the single-page application
@@ -160,21 +620,11 @@ func (s *Server) handleSPA(w http.ResponseWriter, r *http.Request) {
 	// For all non-API, non-static, non-WS routes, serve index.html
 	// This enables client-side routing (e.g., /join/ABC123)
 
-	file, err := s.webFS.Open("index.html")
-	if err != nil {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if s.assets == nil || !s.assets.serve(w, r, "index.html") {
+		w.Header().Del("Content-Type")
 		http.Error(w, "Not found", http.StatusNotFound)
-		return
 	}
-	defer file.Close()
-
-	stat, err := file.Stat()
-	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
-	}
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	http.ServeContent(w, r, "index.html", stat.ModTime(), file.(io.ReadSeeker))
 }
 
 // sendSuccess sends a successful JSON response
@@ -186,16 +636,43 @@ func (s *Server) sendSuccess(w http.ResponseWriter, data interface{}) {
 	})
 }
 
-// sendError sends an error JSON response
-func (s *Server) sendError(w http.ResponseWriter, status int, code, message string) {
+// sendError sends an error JSON response, with the message in the language
+// the client asked for
+func (s *Server) sendError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	s.sendErrorInfo(w, r, status, &ErrorInfo{Code: code, Message: message})
+}
+
+// sendFieldError sends a 400 response for an invalid request body field
+func (s *Server) sendFieldError(w http.ResponseWriter, r *http.Request, code, field, message string) {
+	s.sendErrorInfo(w, r, http.StatusBadRequest, &ErrorInfo{Code: code, Message: message, Field: field})
+}
+
+// sendRetryAfter sends an error asking the client to wait before trying
+// again, in the Retry-After header and the retryAfterSeconds detail
+func (s *Server) sendRetryAfter(w http.ResponseWriter, r *http.Request, status int, code, message string, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	s.sendErrorInfo(w, r, status, &ErrorInfo{
+		Code:    code,
+		Message: message,
+		Details: map[string]interface{}{"retryAfterSeconds": seconds},
+	})
+}
+
+// sendErrorInfo sends an error JSON response, translating the message and
+// filling in whether the error is retryable and the request ID
+func (s *Server) sendErrorInfo(w http.ResponseWriter, r *http.Request, status int, info *ErrorInfo) {
+	locale := i18n.FromRequest(r)
+	info.Message = i18n.Translate(locale, info.Message)
+	info.Retryable = apierror.IsRetryable(info.Code)
+	info.RequestID = w.Header().Get(requestid.Header)
+
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", locale)
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(&Response{
 		Success: false,
-		Error: &ErrorInfo{
-			Code:    code,
-			Message: message,
-		},
+		Error:   info,
 	})
 }
 
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"imposter/internal/auth"
+)
+
+// requireIdentity wraps a handler so requests must carry a valid JWT from the
+// external identity provider. The token's subject becomes the player ID.
+// Browsers cannot set headers on WebSocket upgrades, so access_token is also accepted.
+// Without JWT authentication, a login session cookie supplies the player ID if
+// present, and anonymous requests pass through unchanged.
+func (s *Server) requireIdentity(next http.Handler) http.Handler {
+	if s.verifier == nil && s.logins == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.verifier == nil {
+			if identity := s.loginIdentity(r); identity != nil {
+				r = r.WithContext(auth.WithSubject(r.Context(), identity.PlayerID()))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			token = r.URL.Query().Get("access_token")
+		}
+		if token == "" {
+			s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Identity token is required")
+			return
+		}
+
+		subject, err := s.verifier.Verify(r.Context(), token)
+		if err != nil {
+			s.requestLogger(r).Debug("identity token rejected", "error", err)
+			s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid identity token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithSubject(r.Context(), subject)))
+	})
+}
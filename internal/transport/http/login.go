@@ -0,0 +1,168 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"time"
+
+	"imposter/internal/auth"
+)
+
+const (
+	// sessionCookieName holds the signed login session
+	sessionCookieName = "imposter_session"
+
+	// stateCookieName holds the OAuth state while the user is at the provider
+	stateCookieName = "imposter_oauth_state"
+
+	// stateCookieTTL is how long a user has to complete sign-in at the provider
+	stateCookieTTL = 10 * time.Minute
+)
+
+// MeResponse describes the signed-in user, if any, and the available login providers
+type MeResponse struct {
+	Authenticated bool     `json:"authenticated"`
+	PlayerID      string   `json:"playerId,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Provider      string   `json:"provider,omitempty"`
+	Providers     []string `json:"providers"`
+}
+
+// handleLogin handles GET /auth/{provider}/login
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := s.providers[r.PathValue("provider")]
+	if !ok {
+		s.sendError(w, r, http.StatusNotFound, "UNKNOWN_PROVIDER", "Login provider is not configured")
+		return
+	}
+
+	stateBytes := make([]byte, 16)
+	rand.Read(stateBytes)
+	state := base64.RawURLEncoding.EncodeToString(stateBytes)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     "/auth/",
+		MaxAge:   int(stateCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   s.isSecure(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, provider.AuthCodeURL(state, s.callbackURL(r, provider)), http.StatusFound)
+}
+
+// handleLoginCallback handles GET /auth/{provider}/callback
+func (s *Server) handleLoginCallback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := s.providers[r.PathValue("provider")]
+	if !ok {
+		s.sendError(w, r, http.StatusNotFound, "UNKNOWN_PROVIDER", "Login provider is not configured")
+		return
+	}
+
+	// The state must match the one set when the flow started
+	cookie, err := r.Cookie(stateCookieName)
+	state := r.URL.Query().Get("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_STATE", "Login request expired, please try again")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookieName, Path: "/auth/", MaxAge: -1})
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		// The user declined consent
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), code, s.callbackURL(r, provider))
+	if err != nil {
+		s.requestLogger(r).Warn("login failed", "provider", provider.Name(), "error", err)
+		s.sendError(w, r, http.StatusBadGateway, "LOGIN_FAILED", "Could not sign in with "+provider.Name())
+		return
+	}
+
+	value, err := s.logins.Encode(identity)
+	if err != nil {
+		s.sendError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(s.logins.TTL().Seconds()),
+		HttpOnly: true,
+		Secure:   s.isSecure(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	s.requestLogger(r).Info("player signed in", "provider", provider.Name(), "playerID", identity.PlayerID())
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handleLogout handles POST /auth/logout
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+	s.sendSuccess(w, &MeResponse{Providers: s.providerNames()})
+}
+
+// handleMe handles GET /api/me
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	resp := &MeResponse{Providers: s.providerNames()}
+	if identity := s.loginIdentity(r); identity != nil {
+		resp.Authenticated = true
+		resp.PlayerID = identity.PlayerID()
+		resp.Name = identity.Name
+		resp.Provider = identity.Provider
+	}
+	s.sendSuccess(w, resp)
+}
+
+// loginIdentity returns the identity from the request's session cookie, if valid
+func (s *Server) loginIdentity(r *http.Request) *auth.Identity {
+	if s.logins == nil {
+		return nil
+	}
+
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil
+	}
+
+	identity, err := s.logins.Decode(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	return identity
+}
+
+// providerNames returns the configured login providers, sorted by name
+func (s *Server) providerNames() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// callbackURL returns the redirect URI registered with the provider
+func (s *Server) callbackURL(r *http.Request, provider auth.Provider) string {
+	base := s.config.OAuth.RedirectBaseURL
+	if base == "" {
+		base = s.baseURL(r)
+	}
+	return base + "/auth/" + provider.Name() + "/callback"
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"imposter/internal/app"
+)
+
+// labelEscaper escapes a Prometheus label value
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// roomCounter is a per-room counter in the Prometheus exposition
+type roomCounter struct {
+	name  string
+	help  string
+	value func(m app.RoomMetrics) float64
+}
+
+// roomCounters are exported for every room, labelled with its code
+var roomCounters = []roomCounter{
+	{"imposter_room_events_broadcast_total", "Events the room broadcast.",
+		func(m app.RoomMetrics) float64 { return float64(m.EventsBroadcast) }},
+	{"imposter_room_events_dropped_total", "Events the room shed or failed to deliver to a client.",
+		func(m app.RoomMetrics) float64 { return float64(m.EventsDropped) }},
+	{"imposter_room_messages_received_total", "Messages received from the room's streaming clients.",
+		func(m app.RoomMetrics) float64 { return float64(m.MessagesReceived) }},
+	{"imposter_room_reconnects_total", "Players who reconnected to the room.",
+		func(m app.RoomMetrics) float64 { return float64(m.Reconnects) }},
+}
+
+// handleMetrics handles GET /metrics, exposing hub totals and per-room
+// counters in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	sessions := s.hub.ListSessions()
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].GetRoomCode() < sessions[j].GetRoomCode()
+	})
+
+	rooms := make([]string, len(sessions))
+	metrics := make([]app.RoomMetrics, len(sessions))
+	connections := make([]int, len(sessions))
+	for i, session := range sessions {
+		rooms[i] = labelEscaper.Replace(session.GetRoomCode())
+		metrics[i] = session.Metrics()
+		connections[i] = session.ConnectionCount()
+	}
+	conns := s.websocket.Stats()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	writeGauge(out, "imposter_active_games", "Rooms in memory.", float64(s.hub.GetSessionCount()))
+	writeGauge(out, "imposter_hibernated_games", "Rooms hibernated until a player returns.", float64(s.hub.HibernatedCount()))
+	writeGauge(out, "imposter_players", "Players in rooms in memory.", float64(s.hub.GetTotalPlayerCount()))
+	writeGauge(out, "imposter_ws_connections", "Open WebSocket connections: players, spectators and replays.", float64(conns.Active))
+	writeGauge(out, "imposter_ws_connections_limit", "WebSocket connections allowed at once; 0 is unlimited.", float64(conns.Limit))
+	writeCounter(out, "imposter_ws_connections_rejected_total", "WebSocket upgrades refused by the instance's or a room's connection limit.", float64(conns.Rejected))
+
+	for _, metric := range roomCounters {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for i, room := range rooms {
+			fmt.Fprintf(out, "%s{room=\"%s\"} %g\n", metric.name, room, metric.value(metrics[i]))
+		}
+	}
+
+	const roomConnections = "imposter_room_connections"
+	fmt.Fprintf(out, "# HELP %s Players and spectators connected to the room.\n# TYPE %s gauge\n", roomConnections, roomConnections)
+	for i, room := range rooms {
+		fmt.Fprintf(out, "%s{room=\"%s\"} %d\n", roomConnections, room, connections[i])
+	}
+
+	const voteLatency = "imposter_room_vote_latency_seconds"
+	fmt.Fprintf(out, "# HELP %s Time from the start of voting to each vote.\n# TYPE %s summary\n", voteLatency, voteLatency)
+	for i, room := range rooms {
+		fmt.Fprintf(out, "%s_sum{room=\"%s\"} %g\n", voteLatency, room, metrics[i].VoteLatencySum)
+		fmt.Fprintf(out, "%s_count{room=\"%s\"} %d\n", voteLatency, room, metrics[i].VotesCast)
+	}
+}
+
+// writeGauge writes a single unlabelled gauge
+func writeGauge(out *bufio.Writer, name, help string, value float64) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
+
+// writeCounter writes a single unlabelled counter
+func writeCounter(out *bufio.Writer, name, help string, value float64) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n%s %g\n", name, help, name, name, value)
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// readinessCheckTimeout bounds each dependency check. Checks run at once, so
+// a probe takes no longer than the slowest.
+const readinessCheckTimeout = 1 * time.Second
+
+// Readiness errors
+var (
+	errDraining   = errors.New("server is draining")
+	errHubStopped = errors.New("game hub is stopped")
+)
+
+// ReadinessCheck returns an error if a dependency is not ready to serve traffic
+type ReadinessCheck func(ctx context.Context) error
+
+// namedCheck is a registered readiness check
+type namedCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// ProbeResponse is the body of the liveness and readiness probes
+type ProbeResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status    string `json:"status"` // "ok" or "failed"
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latencyMs"`
+}
+
+// AddReadinessCheck registers a dependency check reported by /readyz.
+// Must be called before the server starts.
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.readinessChecks = append(s.readinessChecks, namedCheck{name: name, check: check})
+}
+
+// Drain marks the server as not ready so load balancers stop routing new
+// traffic to it, while existing connections keep being served
+func (s *Server) Drain() {
+	if !s.draining.Swap(true) {
+		s.logger.Info("server draining")
+	}
+}
+
+// handleLiveness handles GET /healthz. It only reports that the process is serving requests.
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	s.sendProbe(w, http.StatusOK, &ProbeResponse{Status: "ok"})
+}
+
+// handleReadiness handles GET /readyz
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	resp := &ProbeResponse{
+		Status: "ready",
+		Checks: make(map[string]CheckResult, len(s.readinessChecks)+2),
+	}
+
+	record := func(name string, err error) {
+		if err != nil {
+			resp.Status = "not_ready"
+			resp.Checks[name] = CheckResult{Status: "failed", Error: err.Error()}
+			return
+		}
+		resp.Checks[name] = CheckResult{Status: "ok"}
+	}
+
+	if s.draining.Load() {
+		record("drain", errDraining)
+	} else {
+		record("drain", nil)
+	}
+
+	if s.hub.IsRunning() {
+		record("hub", nil)
+	} else {
+		record("hub", errHubStopped)
+	}
+
+	for name, result := range s.checkDependencies(r.Context()) {
+		if result.Status != "ok" {
+			resp.Status = "not_ready"
+		}
+		resp.Checks[name] = result
+	}
+
+	status := http.StatusOK
+	if resp.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	s.sendProbe(w, status, resp)
+}
+
+// checkDependencies runs the registered dependency checks concurrently, each
+// bounded by readinessCheckTimeout, and returns their results by name
+func (s *Server) checkDependencies(ctx context.Context) map[string]CheckResult {
+	results := make([]CheckResult, len(s.readinessChecks))
+
+	var wg sync.WaitGroup
+	for i, c := range s.readinessChecks {
+		wg.Add(1)
+		go func(i int, check ReadinessCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			results[i] = CheckResult{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				results[i].Status = "failed"
+				results[i].Error = err.Error()
+			}
+		}(i, c.check)
+	}
+	wg.Wait()
+
+	byName := make(map[string]CheckResult, len(results))
+	for i, c := range s.readinessChecks {
+		byName[c.name] = results[i]
+	}
+	return byName
+}
+
+// sendProbe writes a probe response. Probes use their own body rather than Response
+// so the status is readable without unwrapping.
+func (s *Server) sendProbe(w http.ResponseWriter, status int, resp *ProbeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies parses proxy CIDRs and bare IP addresses, skipping invalid entries
+func parseTrustedProxies(entries []string, logger *slog.Logger) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		logger.Warn("ignoring invalid trusted proxy", "entry", entry)
+	}
+	return prefixes
+}
+
+// isTrustedProxy returns true if ip belongs to a configured proxy
+func (s *Server) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromTrustedPeer returns true if the directly connected peer is a trusted
+// proxy. Peers on a Unix socket are trusted: only processes the socket's
+// permissions allow can connect, i.e. the reverse proxy it was created for.
+func (s *Server) fromTrustedPeer(r *http.Request) bool {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "unix" {
+		return true
+	}
+	return s.isTrustedProxy(remoteIP(r))
+}
+
+// remoteIP returns the IP address of the directly connected peer
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the IP address of the client making the request.
+// X-Forwarded-For is only honored when the request arrives from a trusted
+// proxy; it is walked right to left, skipping proxies, so clients cannot
+// spoof their address by prepending entries.
+func (s *Server) clientIP(r *http.Request) string {
+	ip := remoteIP(r)
+	if !s.fromTrustedPeer(r) {
+		return ip
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !s.isTrustedProxy(hop) {
+			return hop
+		}
+		ip = hop
+	}
+	return ip
+}
+
+// isSecure returns true if the client reached the server over HTTPS, either
+// directly or through a trusted TLS-terminating proxy
+func (s *Server) isSecure(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return s.fromTrustedPeer(r) && r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// baseURL returns the scheme and host the client used to reach the server
+func (s *Server) baseURL(r *http.Request) string {
+	scheme := "http"
+	if s.isSecure(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
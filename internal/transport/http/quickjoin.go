@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// maxLanguageLength caps a room's language tag, e.g. "en" or "pt-br"
+const maxLanguageLength = 16
+
+// QuickJoinRequest is the optional request body for quick join; omitted
+// preferences match any room
+type QuickJoinRequest struct {
+	Language   string `json:"language,omitempty"`   // e.g. "en"
+	MaxPlayers int    `json:"maxPlayers,omitempty"` // Room size
+	WordSource string `json:"wordSource,omitempty"`
+}
+
+// QuickJoinResponse is the response for quick join. The player joins the room
+// as they would any other, by its code.
+type QuickJoinResponse struct {
+	RoomCode   string `json:"roomCode"`
+	InviteLink string `json:"inviteLink"`
+	Created    bool   `json:"created"` // No open room fit, so a new one was made
+}
+
+// handleQuickJoin handles POST /api/quickjoin
+func (s *Server) handleQuickJoin(w http.ResponseWriter, r *http.Request) {
+	var req QuickJoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	language, ok := normalizeLanguage(req.Language)
+	if !ok {
+		s.sendFieldError(w, r, "INVALID_LANGUAGE", "language", "Language must be a tag such as en or pt-br")
+		return
+	}
+	if !validMaxPlayers(req.MaxPlayers) {
+		s.sendFieldError(w, r, "INVALID_MAX_PLAYERS", "maxPlayers", maxPlayersMessage())
+		return
+	}
+
+	session, created, err := s.hub.QuickJoin(app.QuickJoinPreferences{
+		Language:   language,
+		MaxPlayers: req.MaxPlayers,
+		WordSource: req.WordSource,
+	})
+	switch err {
+	case nil:
+	case domain.ErrUnknownWordSource:
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_WORD_SOURCE", "This server does not offer that word source")
+		return
+	case domain.ErrServerAtCapacity:
+		s.sendRetryAfter(w, r, http.StatusServiceUnavailable, "SERVER_AT_CAPACITY", "Too many rooms are open right now, please try again shortly", capacityRetryAfter)
+		return
+	default:
+		s.sendError(w, r, http.StatusInternalServerError, "QUICK_JOIN_FAILED", "Failed to find a room")
+		return
+	}
+
+	s.requestLogger(r).Info("Quick join",
+		"room", session.GetRoomCode(),
+		"created", created,
+		"language", language,
+		"maxPlayers", req.MaxPlayers,
+		"wordSource", req.WordSource)
+
+	s.sendSuccess(w, &QuickJoinResponse{
+		RoomCode:   session.GetRoomCode(),
+		InviteLink: s.baseURL(r) + "/join/" + session.GetRoomCode(),
+		Created:    created,
+	})
+}
+
+// normalizeLanguage lowercases a language tag, reporting whether it is empty
+// or letters separated by hyphens
+func normalizeLanguage(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if len(tag) > maxLanguageLength || strings.HasPrefix(tag, "-") || strings.HasSuffix(tag, "-") {
+		return tag, false
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && r != '-' {
+			return tag, false
+		}
+	}
+	return tag, true
+}
+
+// validMaxPlayers reports whether n is 0 (the default) or a room size a game
+// can be played with
+func validMaxPlayers(n int) bool {
+	defaults := domain.DefaultGameSettings()
+	return n == 0 || (n >= defaults.MinPlayers && n <= defaults.MaxPlayers)
+}
+
+func maxPlayersMessage() string {
+	defaults := domain.DefaultGameSettings()
+	return fmt.Sprintf("maxPlayers must be between %d and %d", defaults.MinPlayers, defaults.MaxPlayers)
+}
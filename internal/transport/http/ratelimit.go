@@ -0,0 +1,147 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"imposter/internal/config"
+)
+
+// idleBucketTTL is how long a client's bucket is kept after its last request
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimiter is a per-client-IP token bucket limiter
+type RateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	rate      float64 // Tokens added per second
+	burst     float64 // Bucket capacity
+	disabled  bool    // Allows every request without counting it
+	lastSweep time.Time
+
+	allowed  uint64
+	rejected uint64
+}
+
+// bucket holds the token state for one client
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiterStats reports limiter activity since startup
+type RateLimiterStats struct {
+	Allowed        uint64 `json:"allowed"`
+	Rejected       uint64 `json:"rejected"`
+	TrackedClients int    `json:"trackedClients"`
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per client with the given burst
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	return &RateLimiter{
+		buckets:   make(map[string]*bucket),
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		lastSweep: time.Now(),
+	}
+}
+
+// Configure changes the limit, keeping each client's remaining tokens up to the
+// new burst. A disabled limiter allows every request.
+func (l *RateLimiter) Configure(perMinute, burst int, enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rate = float64(perMinute) / 60
+	l.burst = float64(burst)
+	l.disabled = !enabled
+	for _, b := range l.buckets {
+		b.tokens = math.Min(l.burst, b.tokens)
+	}
+}
+
+// Enabled reports whether the limiter limits requests
+func (l *RateLimiter) Enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.disabled
+}
+
+// Allow consumes a token for key, returning false and the time until the
+// next token if the bucket is empty
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.disabled {
+		return true, 0
+	}
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*l.rate)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		l.rejected++
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	l.allowed++
+	return true, 0
+}
+
+// Stats returns the limiter's counters
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return RateLimiterStats{
+		Allowed:        l.allowed,
+		Rejected:       l.rejected,
+		TrackedClients: len(l.buckets),
+	}
+}
+
+// sweep drops buckets idle for longer than idleBucketTTL (caller must hold lock)
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ConfigureRateLimits applies new rate limits to requests from now on
+func (s *Server) ConfigureRateLimits(cfg config.RateLimitConfig) {
+	s.roomLimiter.Configure(cfg.RoomsPerMinute, cfg.RoomsBurst, cfg.Enabled)
+	s.connectLimiter.Configure(cfg.ConnectsPerMinute, cfg.ConnectsBurst, cfg.Enabled)
+}
+
+// rateLimit wraps a handler so each client IP is limited by l
+func (s *Server) rateLimit(l *RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, wait := l.Allow(s.clientIP(r))
+		if !allowed {
+			s.sendRetryAfter(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "Too many requests, please slow down", wait)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"net/http"
+
+	"imposter/internal/app"
+)
+
+// handleGetReplay handles GET /api/replays/{replayId}, returning a recorded
+// game's public events. Watch it with original timing at /ws/replay.
+func (s *Server) handleGetReplay(w http.ResponseWriter, r *http.Request) {
+	replay, err := s.hub.LoadReplay(r.Context(), r.PathValue("replayId"))
+	switch err {
+	case nil:
+	case app.ErrReplayNotFound:
+		s.sendError(w, r, http.StatusNotFound, "REPLAY_NOT_FOUND", "Replay not found")
+		return
+	default:
+		s.requestLogger(r).Error("failed to load replay", "replayID", r.PathValue("replayId"), "error", err)
+		s.sendError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		return
+	}
+
+	s.sendSuccess(w, replay)
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+const (
+	// maxRoomTags caps how many tags a room's creator may give it
+	maxRoomTags = 5
+
+	// maxTagLength caps each tag, e.g. "casual" or "no-swearing"
+	maxTagLength = 20
+)
+
+// normalizeTags lowercases and deduplicates a room's tags, reporting whether
+// there are few enough and each is letters, digits and inner hyphens
+func normalizeTags(tags []string) ([]string, bool) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !validTag(tag) {
+			return nil, false
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxRoomTags {
+		return nil, false
+	}
+	return normalized, true
+}
+
+func validTag(tag string) bool {
+	if tag == "" || len(tag) > maxTagLength || strings.HasPrefix(tag, "-") || strings.HasSuffix(tag, "-") {
+		return false
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRoomFilter reads the room browser's filters from a listing's query,
+// returning a message for the first invalid one
+func parseRoomFilter(query url.Values) (app.RoomFilter, string) {
+	var filter app.RoomFilter
+
+	language, ok := normalizeLanguage(query.Get("language"))
+	if !ok {
+		return filter, "Invalid language"
+	}
+	filter.Language = language
+	filter.WordSource = query.Get("wordSource")
+
+	var err error
+	if filter.MinPlayerCount, err = countParam(query, "minPlayerCount"); err != nil {
+		return filter, "Invalid minPlayerCount"
+	}
+	if filter.MaxPlayerCount, err = countParam(query, "maxPlayerCount"); err != nil {
+		return filter, "Invalid maxPlayerCount"
+	}
+	if filter.MaxPlayerCount > 0 && filter.MaxPlayerCount < filter.MinPlayerCount {
+		return filter, "maxPlayerCount must not be less than minPlayerCount"
+	}
+
+	if raw := query.Get("tags"); raw != "" {
+		if filter.Tags, ok = normalizeTags(strings.Split(raw, ",")); !ok {
+			return filter, "Invalid tags"
+		}
+	}
+
+	if raw := query.Get("familyFriendly"); raw != "" {
+		if filter.FamilyFriendly, err = strconv.ParseBool(raw); err != nil {
+			return filter, "Invalid familyFriendly"
+		}
+	}
+
+	if raw := query.Get("phase"); raw != "" {
+		filter.Phase = domain.Phase(strings.ToUpper(raw))
+		if !filter.Phase.IsValid() {
+			return filter, "Invalid phase"
+		}
+	}
+
+	return filter, ""
+}
+
+// countParam reads a non-negative count from the query, 0 if it is absent
+func countParam(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err == nil && n < 0 {
+		err = strconv.ErrRange
+	}
+	return n, err
+}
+
+// parseRoomOrder reads the listing's sort, newest first by default
+func parseRoomOrder(query url.Values) (app.RoomOrder, bool) {
+	order := app.RoomOrder(strings.ToLower(query.Get("sort")))
+	if order == "" {
+		return app.OrderNewest, true
+	}
+	return order, order.IsValid()
+}
+
+// publicRoom describes snapshot for the room list
+func publicRoom(snapshot *app.RoomSnapshot) PublicRoom {
+	return PublicRoom{
+		RoomCode:           snapshot.RoomCode,
+		Phase:              snapshot.Phase,
+		PlayerCount:        len(snapshot.Players),
+		ConnectedPlayers:   connectedPlayers(snapshot.Players),
+		Spectators:         snapshot.Spectators,
+		MinPlayers:         snapshot.Settings.MinPlayers,
+		MaxPlayers:         snapshot.Settings.MaxPlayers,
+		VotingDurationSecs: int(snapshot.Settings.VotingDuration.Seconds()),
+		Locked:             snapshot.Locked,
+		Language:           snapshot.Language,
+		WordSource:         snapshot.WordSource,
+		Tags:               snapshot.Tags,
+		FamilyFriendly:     snapshot.FamilyFriendly,
+		CreatedAt:          snapshot.CreatedAt,
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"imposter/internal/app"
+)
+
+const (
+	// routedHeader marks a request another node routed here, so it is served
+	// locally instead of being routed again
+	routedHeader = "X-Imposter-Routed-By"
+
+	// nodeHeader tells redirected clients which node holds the room
+	nodeHeader = "X-Imposter-Node"
+
+	// routeTimeout bounds looking up, or taking over, a room's owner
+	routeTimeout = 5 * time.Second
+)
+
+// routeRoom serves requests for rooms held by another node from that node,
+// proxying or redirecting as CLUSTER_ROUTING says. The room code comes from
+// the path or the roomCode query parameter.
+func (s *Server) routeRoom(next http.Handler) http.Handler {
+	if !s.config.ClusterEnabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roomCode := r.PathValue("roomCode")
+		if roomCode == "" {
+			roomCode = r.URL.Query().Get("roomCode")
+		}
+		if roomCode == "" || r.Header.Get(routedHeader) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), routeTimeout)
+		owner, err := s.hub.RouteRoom(ctx, roomCode)
+		cancel()
+		if err != nil {
+			s.requestLogger(r).Warn("failed to route room, serving locally", "roomCode", roomCode, "error", err)
+		}
+		if owner == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set(nodeHeader, owner.ID)
+		if s.config.Cluster.Routing == "redirect" {
+			http.Redirect(w, r, owner.URL+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+			return
+		}
+
+		proxy, err := s.nodeProxy(owner.Node)
+		if err != nil {
+			s.requestLogger(r).Error("invalid node url", "node", owner.ID, "url", owner.URL, "error", err)
+			s.sendError(w, r, http.StatusBadGateway, "NODE_UNAVAILABLE", "The server holding this room is unavailable")
+			return
+		}
+		r.Header.Set(routedHeader, s.config.Broadcast.InstanceID)
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// nodeProxy returns a reverse proxy to node, which also carries WebSocket upgrades
+func (s *Server) nodeProxy(node app.Node) (*httputil.ReverseProxy, error) {
+	if proxy, ok := s.nodeProxies.Load(node.URL); ok {
+		return proxy.(*httputil.ReverseProxy), nil
+	}
+
+	target, err := url.Parse(node.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		s.requestLogger(r).Warn("failed to proxy to node", "node", node.ID, "error", err)
+		s.sendError(w, r, http.StatusBadGateway, "NODE_UNAVAILABLE", "The server holding this room is unavailable")
+	}
+
+	actual, _ := s.nodeProxies.LoadOrStore(node.URL, proxy)
+	return actual.(*httputil.ReverseProxy), nil
+}
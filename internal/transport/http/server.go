@@ -4,24 +4,80 @@ import (
 	"bufio"
 	"context"
 	"embed"
+	"fmt"
 	"io/fs"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/graphql-go/graphql"
+
+	"imposter/internal/analytics"
 	"imposter/internal/app"
+	"imposter/internal/auth"
+	"imposter/internal/clientip"
 	"imposter/internal/config"
+	"imposter/internal/listener"
+	"imposter/internal/requestid"
 	"imposter/internal/transport/ws"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	server  *http.Server
-	hub     *app.GameHub
-	config  *config.Config
-	logger  *slog.Logger
-	webFS   fs.FS
+	server *http.Server
+
+	// Proxies whose X-Forwarded-* headers are honored
+	trustedProxies []netip.Prefix
+
+	// Serves redirect listeners, sending plain HTTP to HTTPS (nil without TLS)
+	redirectServer *http.Server
+
+	hub    *app.GameHub
+	config *config.Config
+	logger *slog.Logger
+
+	// Embedded web files with precomputed ETags
+	assets *assetCache
+
+	graphqlSchema graphql.Schema
+
+	// Game aggregates (nil when disabled)
+	analytics *analytics.Collector
+
+	// Secret words admins can change (nil when not set)
+	catalog *app.WordCatalog
+
+	// Tournaments (nil when not set)
+	tournaments *app.TournamentManager
+
+	// Per-IP rate limiters (nil when disabled)
+	roomLimiter    *RateLimiter
+	connectLimiter *RateLimiter
+	reloadConfig   ConfigReloader // nil unless set
+	logLevel       *slog.LevelVar // nil unless set
+	accessLogger   *slog.Logger   // Logs each request
+
+	// External identity verification (nil when disabled)
+	verifier *auth.JWTVerifier
+
+	// "Sign in with ..." login (logins is nil when no provider is configured)
+	providers map[string]auth.Provider
+	logins    *auth.SessionCodec
+
+	// Serves WebSocket connections, counting them for metrics
+	websocket *ws.Handler
+
+	// Reverse proxies to other nodes by URL, for cluster routing
+	nodeProxies sync.Map
+
+	// Readiness probe state
+	readinessChecks []namedCheck
+	draining        atomic.Bool
 }
 
 // NewServer creates a new HTTP server
@@ -31,12 +87,39 @@ func NewServer(cfg *config.Config, hub *app.GameHub, logger *slog.Logger, webFS
 	if err != nil {
 		logger.Error("failed to get web subdirectory", "error", err)
 	}
+	assets, err := newAssetCache(webContent)
+	if err != nil {
+		logger.Error("failed to load web assets", "error", err)
+	}
+
+	schema, err := newGraphQLSchema(hub)
+	if err != nil {
+		logger.Error("failed to build graphql schema", "error", err)
+	}
 
 	s := &Server{
-		hub:    hub,
-		config: cfg,
-		logger: logger,
-		webFS:  webContent,
+		hub:            hub,
+		config:         cfg,
+		logger:         logger,
+		accessLogger:   logger,
+		assets:         assets,
+		graphqlSchema:  schema,
+		trustedProxies: parseTrustedProxies(cfg.Server.TrustedProxies, logger),
+	}
+
+	// Limiters are created disabled rather than left out, so a config reload can
+	// turn them on
+	s.roomLimiter = NewRateLimiter(cfg.RateLimit.RoomsPerMinute, cfg.RateLimit.RoomsBurst)
+	s.connectLimiter = NewRateLimiter(cfg.RateLimit.ConnectsPerMinute, cfg.RateLimit.ConnectsBurst)
+	s.ConfigureRateLimits(cfg.RateLimit)
+
+	if cfg.JWTEnabled() {
+		s.verifier = auth.NewJWTVerifier(cfg.JWT, logger)
+	}
+
+	s.providers = auth.NewProviders(cfg.OAuth)
+	if len(s.providers) > 0 {
+		s.logins = auth.NewSessionCodec([]byte(cfg.Security.TokenSecret), cfg.OAuth.SessionTTL)
 	}
 
 	// Set up routes
@@ -51,36 +134,139 @@ func NewServer(cfg *config.Config, hub *app.GameHub, logger *slog.Logger, webFS
 		IdleTimeout:  60 * time.Second,
 	}
 
+	if cfg.TLSEnabled() {
+		s.setupTLS()
+	}
+
 	return s
 }
 
-// setupRoutes configures all HTTP routes
+// WebSocket returns the WebSocket handler, whose connection limit other
+// transports share
+func (s *Server) WebSocket() *ws.Handler {
+	return s.websocket
+}
+
+// SetAccessLogger logs requests to logger rather than the server's logger
+func (s *Server) SetAccessLogger(logger *slog.Logger) {
+	s.accessLogger = logger
+}
+
+// setupRoutes configures all HTTP routes. Routes for a single room are
+// wrapped in routeRoom, so they reach the node holding the room.
 func (s *Server) setupRoutes(mux *http.ServeMux) {
 	// API routes
-	mux.HandleFunc("POST /api/rooms", s.handleCreateRoom)
-	mux.HandleFunc("GET /api/rooms/{roomCode}", s.handleGetRoom)
-	mux.HandleFunc("GET /api/rooms/{roomCode}/exists", s.handleRoomExists)
+	mux.HandleFunc("GET /api/rooms", s.handleListRooms)
+	mux.Handle("POST /api/rooms", s.rateLimit(s.roomLimiter, s.requireIdentity(http.HandlerFunc(s.handleCreateRoom))))
+	mux.Handle("POST /api/quickjoin", s.rateLimit(s.roomLimiter, s.requireIdentity(http.HandlerFunc(s.handleQuickJoin))))
+	mux.Handle("GET /api/rooms/{roomCode}", s.routeRoom(http.HandlerFunc(s.handleGetRoom)))
+	mux.Handle("GET /api/rooms/{roomCode}/exists", s.routeRoom(http.HandlerFunc(s.handleRoomExists)))
+	mux.Handle("GET /api/rooms/{roomCode}/events", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handlePollEvents))))
+	mux.Handle("GET /api/rooms/{roomCode}/state", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleGetState))))
+
+	// Game actions (REST alternative to the WebSocket protocol)
+	mux.Handle("POST /api/rooms/{roomCode}/actions/join", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleJoinAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/actions/start", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleStartAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/actions/add-bot", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleAddBotAction))))
+	if !s.config.IsProduction() {
+		mux.Handle("POST /api/rooms/{roomCode}/actions/fill-bots", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleFillBotsAction))))
+	}
+	mux.Handle("POST /api/rooms/{roomCode}/actions/new-round", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleNewRoundAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/actions/submit", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleSubmitAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/actions/vote", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleVoteAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/actions/audience-votes", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleAudienceVotesAction))))
+	mux.Handle("POST /api/rooms/{roomCode}/invites", s.routeRoom(s.requireIdentity(http.HandlerFunc(s.handleCreateInvite))))
+	mux.Handle("GET /api/rooms/{roomCode}/export", s.routeRoom(http.HandlerFunc(s.handleExportTranscript)))
+	mux.HandleFunc("GET /api/replays/{replayId}", s.handleGetReplay)
 	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/version", s.handleVersion)
+	mux.HandleFunc("GET /healthz", s.handleLiveness)
+	mux.HandleFunc("GET /readyz", s.handleReadiness)
 	mux.HandleFunc("GET /api/stats", s.handleStats)
+	mux.HandleFunc("GET /api/analytics", s.handleAnalytics)
+	mux.HandleFunc("GET /api/tournaments", s.handleListTournaments)
+	mux.HandleFunc("GET /api/tournaments/{tournamentId}", s.handleGetTournament)
+	mux.HandleFunc("POST /api/tournaments/{tournamentId}/players", s.handleRegisterTournament)
+	mux.HandleFunc("GET /api/tournaments/{tournamentId}/assignment", s.handleTournamentAssignment)
+
+	// Login (anonymous play remains available)
+	mux.HandleFunc("GET /api/me", s.handleMe)
+	mux.HandleFunc("GET /auth/{provider}/login", s.handleLogin)
+	mux.HandleFunc("GET /auth/{provider}/callback", s.handleLoginCallback)
+	mux.HandleFunc("POST /auth/logout", s.handleLogout)
+
+	// GraphQL (read-only)
+	mux.HandleFunc("GET /api/graphql", s.handleGraphQL)
+	mux.HandleFunc("POST /api/graphql", s.handleGraphQL)
+
+	// Admin API (disabled unless ADMIN_TOKEN is set)
+	if s.config.Security.AdminToken != "" {
+		mux.HandleFunc("GET /admin", s.handleAdminDashboard)
+		mux.HandleFunc("GET /api/admin/overview", s.requireAdmin(s.handleAdminOverview))
+		mux.HandleFunc("GET /api/admin/rooms", s.requireAdmin(s.handleAdminListRooms))
+		mux.HandleFunc("GET /api/admin/words", s.requireAdmin(s.handleAdminWordStats))
+		mux.HandleFunc("POST /api/admin/words/reload", s.requireAdmin(s.handleAdminReloadWords))
+		mux.HandleFunc("POST /api/admin/config/reload", s.requireAdmin(s.handleAdminReloadConfig))
+		mux.HandleFunc("GET /api/admin/loglevel", s.requireAdmin(s.handleAdminGetLogLevel))
+		mux.HandleFunc("POST /api/admin/loglevel", s.requireAdmin(s.handleAdminSetLogLevel))
+		mux.HandleFunc("POST /api/admin/announcements", s.requireAdmin(s.handleAdminAnnounce))
+		mux.HandleFunc("GET /api/admin/wordlist", s.requireAdmin(s.handleAdminListWords))
+		mux.HandleFunc("POST /api/admin/wordlist", s.requireAdmin(s.handleAdminAddWord))
+		mux.HandleFunc("POST /api/admin/wordlist/{word}", s.requireAdmin(s.handleAdminUpdateWord))
+		mux.HandleFunc("POST /api/admin/tournaments", s.requireAdmin(s.handleAdminCreateTournament))
+		mux.HandleFunc("POST /api/admin/tournaments/{tournamentId}/start", s.requireAdmin(s.handleAdminStartTournament))
+		mux.Handle("GET /api/admin/rooms/{roomCode}", s.routeRoom(s.requireAdmin(s.handleAdminGetRoom)))
+		mux.Handle("GET /api/admin/rooms/{roomCode}/dump", s.routeRoom(s.requireAdmin(s.handleAdminDumpRoom)))
+		mux.Handle("GET /api/admin/rooms/{roomCode}/export", s.routeRoom(s.requireAdmin(s.handleAdminExportRoom)))
+		mux.HandleFunc("POST /api/admin/rooms/import", s.requireAdmin(s.handleAdminImportRoom))
+		mux.Handle("DELETE /api/admin/rooms/{roomCode}", s.routeRoom(s.requireAdmin(s.handleAdminDeleteRoom)))
+		mux.Handle("POST /api/admin/rooms/{roomCode}/phase", s.routeRoom(s.requireAdmin(s.handleAdminForcePhase)))
+		mux.Handle("POST /api/admin/rooms/{roomCode}/advance", s.routeRoom(s.requireAdmin(s.handleAdminAdvancePhase)))
+		mux.Handle("POST /api/admin/rooms/{roomCode}/timer/restart", s.routeRoom(s.requireAdmin(s.handleAdminRestartTimer)))
+		mux.Handle("GET /api/admin/rooms/{roomCode}/invites", s.routeRoom(s.requireAdmin(s.handleAdminListInvites)))
+		mux.Handle("DELETE /api/admin/rooms/{roomCode}/invites/{token}", s.routeRoom(s.requireAdmin(s.handleAdminRevokeInvite)))
+		mux.HandleFunc("GET /metrics", s.requireAdmin(s.handleMetrics))
+
+		if s.config.Server.Debug {
+			s.setupDebugRoutes(mux)
+		}
+	} else if s.config.Server.Debug {
+		s.logger.Warn("debug endpoints require ADMIN_TOKEN, not enabling")
+	}
 
 	// WebSocket
-	wsHandler := ws.NewHandler(s.hub, s.logger)
-	mux.Handle("GET /ws", wsHandler)
+	s.websocket = ws.NewHandler(s.hub, s.config.WebSocket, s.logger)
+	mux.Handle("GET /ws", s.routeRoom(s.rateLimit(s.connectLimiter, s.requireIdentity(s.websocket))))
+	mux.Handle("GET /ws/spectate", s.routeRoom(s.rateLimit(s.connectLimiter, http.HandlerFunc(s.websocket.Spectate))))
+	mux.Handle("GET /ws/replay", s.rateLimit(s.connectLimiter, http.HandlerFunc(s.websocket.Replay)))
 
 	// Static files and SPA
 	mux.HandleFunc("GET /static/", s.handleStatic)
+	mux.HandleFunc("GET /static/manifest.json", s.handleAssetManifest)
 	mux.HandleFunc("GET /", s.handleSPA)
 }
 
 // middleware wraps the handler with logging and other middleware
 func (s *Server) middleware(next http.Handler) http.Handler {
+	compressed := compressResponses(next, s.config.Server.CompressionMinBytes)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
 		// Add CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+requestid.Header)
+		w.Header().Set("Access-Control-Expose-Headers", requestid.Header)
+
+		// Correlate logs and error responses; trusted proxies may supply the ID
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) || !s.fromTrustedPeer(r) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+		ip := s.clientIP(r)
+		r = r.WithContext(clientip.WithIP(requestid.WithID(r.Context(), id), ip))
 
 		// Handle preflight
 		if r.Method == "OPTIONS" {
@@ -91,29 +277,89 @@ func (s *Server) middleware(next http.Handler) http.Handler {
 		// Wrap response writer to capture status code
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
-		next.ServeHTTP(wrapped, r)
+		// Static assets are precompressed; API responses are compressed on the fly
+		if s.config.Server.CompressionMinBytes > 0 && strings.HasPrefix(r.URL.Path, "/api/") {
+			compressed.ServeHTTP(wrapped, r)
+		} else {
+			next.ServeHTTP(wrapped, r)
+		}
 
 		// Log request (skip static files in production)
 		if s.config.IsDevelopment() || !isStaticRequest(r.URL.Path) {
-			s.logger.Info("request",
+			s.accessLogger.Info("request",
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", wrapped.statusCode,
 				"duration", time.Since(start),
+				"clientIP", ip,
+				"requestID", id,
 			)
 		}
 	})
 }
 
-// Start starts the HTTP server
+// requestLogger returns the server logger tagged with the request's ID
+func (s *Server) requestLogger(r *http.Request) *slog.Logger {
+	return s.logger.With("requestID", requestid.FromContext(r.Context()))
+}
+
+// Start serves every configured listener until the server is shut down, and
+// returns the error the first one to stop returned
 func (s *Server) Start() error {
-	s.logger.Info("server starting", "addr", s.server.Addr)
-	return s.server.ListenAndServe()
+	type opened struct {
+		l      net.Listener
+		scheme string
+		where  string
+	}
+
+	// Open every listener before serving any, so a bad address fails startup
+	configs := s.config.ListenerConfigs()
+	listeners := make([]opened, 0, len(configs))
+	for _, lc := range configs {
+		l, where, err := listener.Listen(lc.Addr, s.config.Server.SocketMode)
+		if err != nil {
+			for _, o := range listeners {
+				o.l.Close()
+			}
+			return fmt.Errorf("listen on %s: %w", lc, err)
+		}
+		listeners = append(listeners, opened{l: l, scheme: lc.Scheme, where: where})
+	}
+
+	errs := make(chan error, len(listeners))
+	for _, o := range listeners {
+		go func() {
+			errs <- s.serve(o.l, o.scheme, o.where)
+		}()
+	}
+	return <-errs
+}
+
+// serve accepts connections on l until the server is shut down
+func (s *Server) serve(l net.Listener, scheme, where string) error {
+	switch scheme {
+	case config.SchemeHTTPS:
+		return s.serveTLS(l, where)
+	case config.SchemeRedirect:
+		s.logger.Info("http redirect listener starting", "addr", where)
+		return s.redirectServer.Serve(l)
+	default:
+		s.logger.Info("server starting", "addr", where)
+		return s.server.Serve(l)
+	}
 }
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("server shutting down")
+	s.Drain()
+
+	if s.redirectServer != nil {
+		if err := s.redirectServer.Shutdown(ctx); err != nil {
+			s.logger.Warn("http redirect listener shutdown error", "error", err)
+		}
+	}
+
 	return s.server.Shutdown(ctx)
 }
 
@@ -128,6 +374,11 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // Hijack implements http.Hijacker for WebSocket support
 func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	if hijacker, ok := rw.ResponseWriter.(http.Hijacker); ok {
@@ -147,4 +398,3 @@ func (rw *responseWriter) Flush() {
 func isStaticRequest(path string) bool {
 	return len(path) > 8 && path[:8] == "/static/"
 }
-
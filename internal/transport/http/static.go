@@ -0,0 +1,210 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// cacheImmutable is sent for fingerprinted paths, whose content never changes
+	cacheImmutable = "public, max-age=31536000, immutable"
+
+	// cacheRevalidate is sent for everything else, so browsers check the ETag first
+	cacheRevalidate = "no-cache"
+)
+
+// staticAsset is an embedded file prepared for serving with cache validators
+type staticAsset struct {
+	name      string // Base name, which determines the content type
+	content   []byte
+	etag      string
+	immutable bool // Served under a fingerprinted path
+
+	// Compressed representations; nil if unavailable or not worth it
+	gzip   []byte
+	brotli []byte
+}
+
+// assetCache holds the embedded web files, read and hashed once at startup.
+// Every file under static/ is also available at a fingerprinted path such as
+// static/js/app.3f9a1c2b.js, and HTML files are rewritten to reference those
+// paths so browsers can cache them indefinitely.
+//
+// Assets are gzipped at startup. Brotli needs an encoder outside the standard
+// library, so it is only offered for files precompressed by
+// "make compress-assets", which embeds e.g. static/js/app.3f9a1c2b.js.br;
+// the fingerprint keeps an outdated .br file from being served.
+type assetCache struct {
+	files   map[string]*staticAsset // Path within the web FS -> asset
+	modTime time.Time
+
+	// URL path -> fingerprinted URL path, e.g. "/static/js/app.js" -> "/static/js/app.3f9a1c2b.js"
+	manifest map[string]string
+}
+
+// newAssetCache reads and compresses every file in webFS
+func newAssetCache(webFS fs.FS) (*assetCache, error) {
+	c := &assetCache{
+		files:    make(map[string]*staticAsset),
+		modTime:  buildTime(),
+		manifest: make(map[string]string),
+	}
+
+	err := fs.WalkDir(webFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(name) == ".br" {
+			return err
+		}
+
+		content, err := fs.ReadFile(webFS, name)
+		if err != nil {
+			return err
+		}
+
+		asset := newStaticAsset(name, content)
+		c.files[name] = asset
+
+		if strings.HasPrefix(name, "static/") {
+			fingerprinted := fingerprintPath(name, asset.etag)
+			if brotli, err := fs.ReadFile(webFS, fingerprinted+".br"); err == nil {
+				asset.brotli = brotli
+			}
+
+			immutable := *asset
+			immutable.immutable = true
+			c.files[fingerprinted] = &immutable
+			c.manifest["/"+name] = "/" + fingerprinted
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for name, asset := range c.files {
+		if path.Ext(name) != ".html" {
+			continue
+		}
+		content := asset.content
+		for original, fingerprinted := range c.manifest {
+			content = bytes.ReplaceAll(content, []byte(`"`+original+`"`), []byte(`"`+fingerprinted+`"`))
+		}
+		c.files[name] = newStaticAsset(name, content)
+	}
+
+	return c, nil
+}
+
+// newStaticAsset hashes content into a strong ETag and gzips it
+func newStaticAsset(name string, content []byte) *staticAsset {
+	sum := sha256.Sum256(content)
+	return &staticAsset{
+		name:    path.Base(name),
+		content: content,
+		etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		gzip:    gzipAsset(content),
+	}
+}
+
+// gzipAsset compresses content, returning nil unless it saves at least 10%
+func gzipAsset(content []byte) []byte {
+	var buf bytes.Buffer
+	gz, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	gz.Write(content)
+	gz.Close()
+
+	if buf.Len() > len(content)*9/10 {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// fingerprintPath inserts the first 8 hex digits of the ETag before the extension
+func fingerprintPath(name, etag string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + strings.Trim(etag, `"`)[:8] + ext
+}
+
+// unfingerprintPath strips a fingerprint inserted by fingerprintPath, returning
+// false if name does not have one
+func unfingerprintPath(name string) (string, bool) {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	i := strings.LastIndexByte(base, '.')
+	if i < 0 || len(base)-i-1 != 8 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(base[i+1:]); err != nil {
+		return "", false
+	}
+	return base[:i] + ext, true
+}
+
+// buildTime approximates when the embedded files were built from the
+// executable's modification time, falling back to the process start time
+func buildTime() time.Time {
+	if exe, err := os.Executable(); err == nil {
+		if stat, err := os.Stat(exe); err == nil {
+			return stat.ModTime()
+		}
+	}
+	return time.Now()
+}
+
+// serveManifest writes the asset manifest, for scripts that load assets by name
+func (c *assetCache) serveManifest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", cacheRevalidate)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c.manifest)
+}
+
+// serve writes the named asset in the best encoding the client accepts,
+// answering conditional requests with 304. It returns false if there is no
+// such asset.
+func (c *assetCache) serve(w http.ResponseWriter, r *http.Request, name string) bool {
+	asset, ok := c.files[name]
+	if !ok {
+		// Pages loaded before a deploy may still ask for the previous
+		// fingerprint; give them the current file, but not as immutable
+		original, fingerprinted := unfingerprintPath(name)
+		if asset, ok = c.files[original]; !fingerprinted || !ok {
+			return false
+		}
+	}
+
+	content, encoding := asset.content, ""
+	switch {
+	case asset.brotli != nil && acceptsEncoding(r, "br"):
+		content, encoding = asset.brotli, "br"
+	case asset.gzip != nil && acceptsEncoding(r, "gzip"):
+		content, encoding = asset.gzip, "gzip"
+	}
+
+	if asset.gzip != nil || asset.brotli != nil {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		// Each representation needs its own strong validator
+		w.Header().Set("Content-Encoding", encoding)
+		w.Header().Set("ETag", strings.TrimSuffix(asset.etag, `"`)+"-"+encoding+`"`)
+	} else {
+		w.Header().Set("ETag", asset.etag)
+	}
+
+	if asset.immutable {
+		w.Header().Set("Cache-Control", cacheImmutable)
+	} else {
+		w.Header().Set("Cache-Control", cacheRevalidate)
+	}
+
+	http.ServeContent(w, r, asset.name, c.modTime, bytes.NewReader(content))
+	return true
+}
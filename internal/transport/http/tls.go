@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// setupTLS configures the server to serve HTTPS, and builds the plain HTTP
+// server redirect listeners use for redirects and ACME challenges
+func (s *Server) setupTLS() {
+	tlsCfg := s.config.Server.TLS
+
+	// Without autocert, plain HTTP requests are simply redirected
+	redirect := http.HandlerFunc(s.redirectToHTTPS)
+	var handler http.Handler = redirect
+
+	if s.config.AutocertEnabled() {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(tlsCfg.AutocertDomains...),
+			Cache:      autocert.DirCache(tlsCfg.AutocertCacheDir),
+			Email:      tlsCfg.AutocertEmail,
+		}
+		s.server.TLSConfig = manager.TLSConfig()
+
+		// Answer HTTP-01 challenges, redirecting everything else
+		handler = manager.HTTPHandler(redirect)
+	}
+
+	s.redirectServer = &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// serveTLS serves HTTPS on l until the server is shut down
+func (s *Server) serveTLS(l net.Listener, where string) error {
+	s.logger.Info("server starting", "addr", where, "tls", true, "autocert", s.config.AutocertEnabled())
+
+	// Autocert supplies certificates through TLSConfig, so no files are passed
+	tlsCfg := s.config.Server.TLS
+	if s.config.AutocertEnabled() {
+		return s.server.ServeTLS(l, "", "")
+	}
+	return s.server.ServeTLS(l, tlsCfg.CertFile, tlsCfg.KeyFile)
+}
+
+// redirectToHTTPS redirects a plain HTTP request to the HTTPS listener
+func (s *Server) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if port := s.config.HTTPSPort(); port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"imposter/internal/app"
+)
+
+// TournamentsResponse is the response for listing tournaments
+type TournamentsResponse struct {
+	Tournaments []*app.Tournament `json:"tournaments"` // Newest first
+}
+
+// RegisterTournamentRequest is the request body for registering for a
+// tournament
+type RegisterTournamentRequest struct {
+	Nickname string `json:"nickname"`
+}
+
+// SetTournaments serves manager's tournaments at /api/tournaments
+func (s *Server) SetTournaments(manager *app.TournamentManager) {
+	s.tournaments = manager
+}
+
+// handleListTournaments handles GET /api/tournaments
+func (s *Server) handleListTournaments(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	s.sendSuccess(w, &TournamentsResponse{
+		Tournaments: s.tournaments.List(),
+	})
+}
+
+// handleGetTournament handles GET /api/tournaments/{tournamentId}, with the
+// standings and the room of every match
+func (s *Server) handleGetTournament(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	tournament, err := s.tournaments.Get(r.PathValue("tournamentId"))
+	if err != nil {
+		s.sendTournamentError(w, r, err)
+		return
+	}
+	s.sendSuccess(w, tournament)
+}
+
+// handleRegisterTournament handles POST /api/tournaments/{tournamentId}/players
+func (s *Server) handleRegisterTournament(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	var req RegisterTournamentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	registration, err := s.tournaments.Register(r.PathValue("tournamentId"), req.Nickname)
+	if err != nil {
+		s.sendTournamentError(w, r, err)
+		return
+	}
+	s.sendSuccess(w, registration)
+}
+
+// handleTournamentAssignment handles GET /api/tournaments/{tournamentId}/assignment,
+// telling the registered player whose token is sent as
+// "Authorization: Bearer <token>" which room to join
+func (s *Server) handleTournamentAssignment(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Bearer token is required")
+		return
+	}
+
+	assignment, err := s.tournaments.Assignment(r.PathValue("tournamentId"), token)
+	if err != nil {
+		s.sendTournamentError(w, r, err)
+		return
+	}
+	s.sendSuccess(w, assignment)
+}
+
+// handleAdminCreateTournament handles POST /api/admin/tournaments
+func (s *Server) handleAdminCreateTournament(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	var opts app.TournamentOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	tournament, err := s.tournaments.Create(opts)
+	if err != nil {
+		s.sendTournamentError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("tournament created by admin", "tournamentID", tournament.ID, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, tournament)
+}
+
+// handleAdminStartTournament handles POST /api/admin/tournaments/{tournamentId}/start
+func (s *Server) handleAdminStartTournament(w http.ResponseWriter, r *http.Request) {
+	if s.tournaments == nil {
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENTS_DISABLED", "Tournaments are not enabled")
+		return
+	}
+
+	tournament, err := s.tournaments.Start(r.PathValue("tournamentId"))
+	if err != nil {
+		s.sendTournamentError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("tournament started by admin", "tournamentID", tournament.ID, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, tournament)
+}
+
+// sendTournamentError sends the response for a tournament error
+func (s *Server) sendTournamentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, app.ErrTournamentNotFound):
+		s.sendError(w, r, http.StatusNotFound, "TOURNAMENT_NOT_FOUND", "Tournament not found")
+	case errors.Is(err, app.ErrTournamentStarted):
+		s.sendError(w, r, http.StatusConflict, "TOURNAMENT_STARTED", "The tournament has already started")
+	case errors.Is(err, app.ErrTournamentFull):
+		s.sendError(w, r, http.StatusConflict, "TOURNAMENT_FULL", "The tournament is full")
+	case errors.Is(err, app.ErrTooManyTournaments):
+		s.sendError(w, r, http.StatusServiceUnavailable, "TOO_MANY_TOURNAMENTS", "Too many tournaments are running")
+	case errors.Is(err, app.ErrNicknameTaken):
+		s.sendError(w, r, http.StatusConflict, "NICKNAME_TAKEN", "This nickname is already registered")
+	case errors.Is(err, app.ErrInvalidNickname):
+		s.sendFieldError(w, r, "INVALID_NICKNAME", "nickname", "Nickname must be 1 to 20 characters")
+	case errors.Is(err, app.ErrNotEnoughEntrants):
+		s.sendError(w, r, http.StatusConflict, "NOT_ENOUGH_PLAYERS", "At least 4 players must register")
+	case errors.Is(err, app.ErrInvalidTournament):
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_TOURNAMENT", "Invalid format, group size (4-10), rounds per match (1-10) or stages (1-10)")
+	case errors.Is(err, app.ErrInvalidToken):
+		s.sendError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid registration token")
+	default:
+		s.sendActionError(w, r, err)
+	}
+}
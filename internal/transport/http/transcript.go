@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// transcriptCSVHeader names the columns of a CSV transcript, which has a row
+// per clue and per vote. A round with several imposters lists them all in
+// the imposter column, separated by commas.
+var transcriptCSVHeader = []string{
+	"round", "secret_word", "imposter", "winner", "type", "order", "player", "word", "voted_for",
+}
+
+// handleExportTranscript handles GET /api/rooms/{roomCode}/export, returning
+// the finished game's rounds as JSON or, with ?format=csv, as a CSV download.
+// The admin token or the host's player token is required.
+func (s *Server) handleExportTranscript(w http.ResponseWriter, r *http.Request) {
+	if s.isAdmin(r) {
+		session, ok := s.lookupSession(w, r)
+		if !ok {
+			return
+		}
+		s.sendTranscript(w, r, session)
+		return
+	}
+
+	s.requireIdentity(http.HandlerFunc(s.handleHostExportTranscript)).ServeHTTP(w, r)
+}
+
+// handleHostExportTranscript serves a transcript to the room's host
+func (s *Server) handleHostExportTranscript(w http.ResponseWriter, r *http.Request) {
+	session, playerID, ok := s.authenticateAction(w, r)
+	if !ok {
+		return
+	}
+	if !session.IsHost(playerID) {
+		s.sendError(w, r, http.StatusForbidden, "NOT_HOST", "Only the host can export the transcript")
+		return
+	}
+
+	s.sendTranscript(w, r, session)
+}
+
+// sendTranscript writes the session's transcript in the requested format
+func (s *Server) sendTranscript(w http.ResponseWriter, r *http.Request, session *app.GameSession) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_FORMAT", "Format must be json or csv")
+		return
+	}
+
+	transcript, err := session.Transcript()
+	switch err {
+	case nil:
+	case domain.ErrInvalidPhase:
+		s.sendError(w, r, http.StatusConflict, "GAME_NOT_ENDED", "The transcript is available once the game has ended")
+		return
+	default:
+		s.sendActionError(w, r, err)
+		return
+	}
+
+	if format != "csv" {
+		s.sendSuccess(w, transcript)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="imposter-`+transcript.RoomCode+`.csv"`)
+	if err := writeTranscriptCSV(w, transcript); err != nil {
+		s.requestLogger(r).Warn("failed to write transcript", "roomCode", transcript.RoomCode, "error", err)
+	}
+}
+
+// writeTranscriptCSV writes a transcript's rows under transcriptCSVHeader.
+// Nicknames, words and clues are players' own text, so every cell is escaped
+// against spreadsheet formulas.
+func writeTranscriptCSV(w io.Writer, transcript *app.Transcript) error {
+	out := csv.NewWriter(w)
+	out.Write(transcriptCSVHeader)
+	row := func(cells ...string) {
+		for i, cell := range cells {
+			cells[i] = escapeCSVCell(cell)
+		}
+		out.Write(cells)
+	}
+	for _, round := range transcript.Rounds {
+		imposters := make([]string, 0, len(round.Imposters))
+		for _, imposter := range round.Imposters {
+			imposters = append(imposters, imposter.Nickname)
+		}
+		number, imposter := strconv.Itoa(round.Number), strings.Join(imposters, ", ")
+		for _, clue := range round.Clues {
+			row(number, round.SecretWord, imposter, string(round.Winner), "clue", strconv.Itoa(clue.Order), clue.Player.Nickname, clue.Word, "")
+		}
+		for i, vote := range round.Votes {
+			row(number, round.SecretWord, imposter, string(round.Winner), "vote", strconv.Itoa(i+1), vote.Voter.Nickname, "", vote.Target.Nickname)
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// escapeCSVCell prefixes a cell a spreadsheet would read as a formula with a
+// quote, so it is shown as text instead
+func escapeCSVCell(cell string) string {
+	if cell != "" && strings.IndexByte("=+-@\t\r", cell[0]) >= 0 {
+		return "'" + cell
+	}
+	return cell
+}
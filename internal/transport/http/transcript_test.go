@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"apple", "apple"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+	for _, tt := range tests {
+		if got := escapeCSVCell(tt.cell); got != tt.want {
+			t.Errorf("escapeCSVCell(%q) = %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTranscriptCSVEscapesFormulas(t *testing.T) {
+	evil := app.TranscriptPlayer{ID: "p1", Nickname: "=cmd|'/c calc'!A1"}
+	ana := app.TranscriptPlayer{ID: "p2", Nickname: "Ana"}
+	transcript := &app.Transcript{
+		RoomCode: "ABC123",
+		Rounds: []app.TranscriptRound{{
+			Number:     1,
+			SecretWord: "apple",
+			Imposter:   evil,
+			Imposters:  []app.TranscriptPlayer{evil},
+			Winner:     domain.RoleVilek,
+			Clues: []app.TranscriptClue{
+				{Order: 1, Player: evil, Word: "@SUM(1+1)"},
+				{Order: 2, Player: ana, Word: "fruit"},
+			},
+			Votes: []app.TranscriptVote{{Voter: ana, Target: evil}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTranscriptCSV(&buf, transcript); err != nil {
+		t.Fatalf("writeTranscriptCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+
+	want := [][]string{
+		transcriptCSVHeader,
+		{"1", "apple", "'=cmd|'/c calc'!A1", "VILEK", "clue", "1", "'=cmd|'/c calc'!A1", "'@SUM(1+1)", ""},
+		{"1", "apple", "'=cmd|'/c calc'!A1", "VILEK", "clue", "2", "Ana", "fruit", ""},
+		{"1", "apple", "'=cmd|'/c calc'!A1", "VILEK", "vote", "1", "Ana", "", "'=cmd|'/c calc'!A1"},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal[[]string]) {
+		t.Errorf("rows = %q\nwant %q", rows, want)
+	}
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"imposter/internal/app"
+)
+
+// WordListResponse is the response for listing the word catalog
+type WordListResponse struct {
+	Words []app.WordEntry `json:"words"` // Built-in words first, then those added
+}
+
+// AddWordRequest is the request body for adding a word to the catalog
+type AddWordRequest struct {
+	Word     string `json:"word"`
+	Category string `json:"category"`
+}
+
+// SetWordCatalog lets admins change catalog at /api/admin/wordlist
+func (s *Server) SetWordCatalog(catalog *app.WordCatalog) {
+	s.catalog = catalog
+}
+
+// handleAdminListWords handles GET /api/admin/wordlist?category=
+func (s *Server) handleAdminListWords(w http.ResponseWriter, r *http.Request) {
+	if s.catalog == nil {
+		s.sendError(w, r, http.StatusNotFound, "WORDLIST_DISABLED", "The word list cannot be managed")
+		return
+	}
+
+	category := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("category")))
+	s.sendSuccess(w, &WordListResponse{
+		Words: s.catalog.List(category),
+	})
+}
+
+// handleAdminAddWord handles POST /api/admin/wordlist
+func (s *Server) handleAdminAddWord(w http.ResponseWriter, r *http.Request) {
+	if s.catalog == nil {
+		s.sendError(w, r, http.StatusNotFound, "WORDLIST_DISABLED", "The word list cannot be managed")
+		return
+	}
+
+	var req AddWordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	entry, err := s.catalog.Add(r.Context(), req.Word, req.Category)
+	if err != nil {
+		s.sendWordListError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("word added by admin", "word", entry.Word, "category", entry.Category, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, entry)
+}
+
+// handleAdminUpdateWord handles POST /api/admin/wordlist/{word}, which
+// disables, enables or categorizes the word
+func (s *Server) handleAdminUpdateWord(w http.ResponseWriter, r *http.Request) {
+	if s.catalog == nil {
+		s.sendError(w, r, http.StatusNotFound, "WORDLIST_DISABLED", "The word list cannot be managed")
+		return
+	}
+
+	var update app.WordUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_BODY", "Invalid request body")
+		return
+	}
+
+	entry, err := s.catalog.Update(r.Context(), r.PathValue("word"), update)
+	if err != nil {
+		s.sendWordListError(w, r, err)
+		return
+	}
+	s.requestLogger(r).Info("word updated by admin", "word", entry.Word, "category", entry.Category, "disabled", entry.Disabled, "clientIP", s.clientIP(r))
+
+	s.sendSuccess(w, entry)
+}
+
+// sendWordListError sends the response for a word catalog error
+func (s *Server) sendWordListError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case app.ErrInvalidWord:
+		s.sendError(w, r, http.StatusBadRequest, "INVALID_WORD", err.Error())
+	case app.ErrWordBlocked:
+		s.sendError(w, r, http.StatusUnprocessableEntity, "WORD_BLOCKED", "This word is blocklisted")
+	case app.ErrWordExists:
+		s.sendError(w, r, http.StatusConflict, "WORD_EXISTS", "This word is already in the word list")
+	case app.ErrWordNotFound:
+		s.sendError(w, r, http.StatusNotFound, "WORD_NOT_FOUND", "This word is not in the word list")
+	case app.ErrLastWord:
+		s.sendError(w, r, http.StatusConflict, "LAST_WORD", "The last enabled word cannot be disabled")
+	default:
+		s.requestLogger(r).Error("failed to save word", "error", err)
+		s.sendError(w, r, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+	}
+}
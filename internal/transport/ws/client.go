@@ -1,7 +1,6 @@
 package ws
 
 import (
-	"encoding/json"
 	"log/slog"
 	"sync"
 	"time"
@@ -9,7 +8,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"imposter/internal/app"
-	"imposter/internal/domain"
+	"imposter/internal/config"
 )
 
 const (
@@ -24,33 +23,61 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 4096
-
-	// Size of the send channel buffer
-	sendBufferSize = 256
 )
 
+// newline separates batched JSON messages
+var newline = []byte{'\n'}
+
 // Client represents a WebSocket client connection
 type Client struct {
 	conn     *websocket.Conn
 	session  *app.GameSession
 	playerID string
-	send     chan []byte
-	done     chan struct{}
-	logger   *slog.Logger
-	mu       sync.Mutex
-	closed   bool
+	connID   string
+	codec    Codec
+	locale   string
+	acks     bool
+	outbox   *Outbox
+
+	dispatcher *Dispatcher
+
+	// Read-only connection that is not a player
+	spectator bool
+
+	// Messages smaller than this are written uncompressed
+	compressionThreshold int
+
+	done   chan struct{}
+	logger *slog.Logger
+	mu     sync.Mutex
+	closed bool
 }
 
-// NewClient creates a new WebSocket client
-func NewClient(conn *websocket.Conn, session *app.GameSession, playerID string, logger *slog.Logger) *Client {
-	return &Client{
-		conn:     conn,
-		session:  session,
-		playerID: playerID,
-		send:     make(chan []byte, sendBufferSize),
-		done:     make(chan struct{}),
-		logger:   logger,
+// NewClient creates a new WebSocket client. connID correlates the connection's
+// log lines and error messages.
+func NewClient(conn *websocket.Conn, session *app.GameSession, playerID, connID string, codec Codec, cfg config.WebSocketConfig, logger *slog.Logger) *Client {
+	c := &Client{
+		conn:                 conn,
+		session:              session,
+		playerID:             playerID,
+		connID:               connID,
+		codec:                codec,
+		outbox:               NewOutbox(cfg),
+		compressionThreshold: cfg.CompressionThreshold,
+		done:                 make(chan struct{}),
+		logger:               logger,
 	}
+	c.dispatcher = NewDispatcher(session, c)
+	return c
+}
+
+// NewSpectatorClient creates a read-only WebSocket client that receives public
+// events without joining the game
+func NewSpectatorClient(conn *websocket.Conn, session *app.GameSession, connID string, codec Codec, cfg config.WebSocketConfig, logger *slog.Logger) *Client {
+	c := NewClient(conn, session, "", connID, codec, cfg, logger)
+	c.spectator = true
+	c.dispatcher = NewSpectatorDispatcher(session, c)
+	return c
 }
 
 // GetPlayerID returns the player ID for this client
@@ -58,13 +85,13 @@ func (c *Client) GetPlayerID() string {
 	return c.playerID
 }
 
+// GetConnectionID returns the ID correlating this connection's logs and errors
+func (c *Client) GetConnectionID() string {
+	return c.connID
+}
+
 // Send implements app.ClientConnection interface
 func (c *Client) Send(message interface{}) error {
-	data, err := json.Marshal(message)
-	if err != nil {
-		return err
-	}
-
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -72,21 +99,36 @@ func (c *Client) Send(message interface{}) error {
 		return nil
 	}
 
-	select {
-	case c.send <- data:
-		return nil
-	default:
-		// Buffer full, message dropped
-		c.logger.Warn("send buffer full, message dropped", "playerID", c.playerID)
-		return nil
+	data, pooled, err := encodePooled(c.codec, message)
+	if err != nil {
+		return err
+	}
+
+	dropped, err := c.outbox.push(outboxEntry{data: data, lowPriority: IsLowPriority(message), pooled: pooled})
+	if (err != nil || dropped) && pooled != nil {
+		putBuffer(pooled)
+	}
+	if err != nil {
+		// Losing a critical event would desync the client; it resyncs on reconnect instead
+		c.logger.Warn("send buffer full, disconnecting slow client")
+		c.closeLocked()
+		return err
+	}
+	if dropped {
+		c.logger.Debug("send buffer full, low-priority message dropped")
 	}
+	return nil
 }
 
 // Close implements app.ClientConnection interface
 func (c *Client) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.closeLocked()
+}
 
+// closeLocked closes the connection (caller must hold mu)
+func (c *Client) closeLocked() error {
 	if c.closed {
 		return nil
 	}
@@ -102,11 +144,32 @@ func (c *Client) Run() {
 	c.readPump()
 }
 
+// serve runs the client until its connection closes, then calls done
+func (c *Client) serve(done func()) {
+	c.Run()
+	done()
+}
+
+// refuse closes a connection that is not served, telling the client why
+func (c *Client) refuse(code int, reason string) {
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason))
+	c.conn.Close()
+}
+
+// getDispatcher returns the dispatcher handling the connection's messages
+func (c *Client) getDispatcher() *Dispatcher {
+	return c.dispatcher
+}
+
 // readPump pumps messages from the WebSocket connection
 func (c *Client) readPump() {
 	defer func() {
-		c.session.UnregisterClient(c.playerID)
-		c.session.DisconnectPlayer(c.playerID)
+		if c.spectator {
+			c.session.RemoveSpectator(c.connID)
+		} else {
+			c.session.UnregisterClient(c.playerID)
+			c.session.DisconnectPlayer(c.playerID)
+		}
 		c.Close()
 	}()
 
@@ -142,29 +205,16 @@ func (c *Client) writePump() {
 		select {
 		case <-c.done:
 			return
-		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
+		case <-c.outbox.Ready():
+			batch := c.outbox.drain()
+			if len(batch) == 0 {
+				continue
 			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
+			err := c.writeBatch(batch)
+			c.outbox.recycle(batch)
 			if err != nil {
 				return
 			}
-			w.Write(message)
-
-			// Add queued messages to the current websocket message
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
-				return
-			}
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -174,182 +224,90 @@ func (c *Client) writePump() {
 	}
 }
 
-// handleMessage processes an incoming message from the client
-func (c *Client) handleMessage(data []byte) {
-	var msg ClientMessage
-	if err := json.Unmarshal(data, &msg); err != nil {
-		c.sendError(ErrCodeInvalidMessage, "Invalid message format")
-		return
-	}
-
-	switch msg.Type {
-	case MsgJoinLobby:
-		c.handleJoinLobby(msg.Payload)
-	case MsgStartGame:
-		c.handleStartGame()
-	case MsgSubmitWord:
-		c.handleSubmitWord(msg.Payload)
-	case MsgCastVote:
-		c.handleCastVote(msg.Payload)
-	case MsgRequestNewRound:
-		c.handleRequestNewRound()
-	case MsgPing:
-		c.sendPong()
-	default:
-		c.sendError(ErrCodeInvalidMessage, "Unknown message type")
-	}
-}
+// writeBatch writes queued messages to the connection
+func (c *Client) writeBatch(batch []outboxEntry) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 
-// handleJoinLobby handles a join_lobby message
-func (c *Client) handleJoinLobby(payload interface{}) {
-	payloadMap, ok := payload.(map[string]interface{})
-	if !ok {
-		c.sendError(ErrCodeInvalidMessage, "Invalid payload")
-		return
+	// Binary encodings are not self-delimiting, so send one message per frame
+	if c.GetCodec().Encoding().IsBinary() {
+		for _, e := range batch {
+			c.conn.EnableWriteCompression(len(e.data) >= c.compressionThreshold)
+			if err := c.conn.WriteMessage(websocket.BinaryMessage, e.data); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	nickname, ok := payloadMap["nickname"].(string)
-	if !ok || nickname == "" {
-		c.sendError(ErrCodeInvalidMessage, "Nickname is required")
-		return
+	// Send all queued messages as one websocket message
+	size := len(batch) - 1
+	for _, e := range batch {
+		size += len(e.data)
 	}
 
-	// Try to add player to game
-	_, err := c.session.AddPlayer(c.playerID, nickname)
+	c.conn.EnableWriteCompression(size >= c.compressionThreshold)
+	w, err := c.conn.NextWriter(websocket.TextMessage)
 	if err != nil {
-		switch err {
-		case domain.ErrGameFull:
-			c.sendError(ErrCodeGameFull, "Game is full")
-		case domain.ErrGameAlreadyStarted:
-			c.sendError(ErrCodeInvalidAction, "Game has already started")
-		default:
-			c.sendError(ErrCodeInternalError, err.Error())
+		return err
+	}
+	for i, e := range batch {
+		if i > 0 {
+			w.Write(newline)
 		}
-		return
+		w.Write(e.data)
 	}
 
-	// Send connected confirmation
-	c.sendConnected()
+	return w.Close()
 }
 
-// handleStartGame handles a start_game message
-func (c *Client) handleStartGame() {
-	err := c.session.StartGame(c.playerID)
-	if err != nil {
-		switch err {
-		case domain.ErrNotHost:
-			c.sendError(ErrCodeNotHost, "Only the host can start the game")
-		case domain.ErrNotEnoughPlayers:
-			c.sendError(ErrCodeInvalidAction, "Not enough players to start")
-		default:
-			c.sendError(ErrCodeInternalError, err.Error())
-		}
-		return
-	}
+// handleMessage decodes an incoming message and dispatches it
+func (c *Client) handleMessage(data []byte) {
+	c.dispatcher.Receive(data)
 }
 
-// handleSubmitWord handles a submit_word message
-func (c *Client) handleSubmitWord(payload interface{}) {
-	payloadMap, ok := payload.(map[string]interface{})
-	if !ok {
-		c.sendError(ErrCodeInvalidMessage, "Invalid payload")
-		return
-	}
-
-	word, ok := payloadMap["word"].(string)
-	if !ok || word == "" {
-		c.sendError(ErrCodeInvalidMessage, "Word is required")
-		return
-	}
-
-	err := c.session.SubmitWord(c.playerID, word)
-	if err != nil {
-		switch err {
-		case domain.ErrNotYourTurn:
-			c.sendError(ErrCodeNotYourTurn, "It's not your turn")
-		case domain.ErrAlreadySubmitted:
-			c.sendError(ErrCodeInvalidAction, "You have already submitted")
-		case domain.ErrInvalidPhase:
-			c.sendError(ErrCodeInvalidAction, "Cannot submit now")
-		default:
-			c.sendError(ErrCodeInternalError, err.Error())
-		}
-		return
-	}
+// GetCodec returns the codec negotiated for this connection
+func (c *Client) GetCodec() Codec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codec
 }
 
-// handleCastVote handles a cast_vote message
-func (c *Client) handleCastVote(payload interface{}) {
-	payloadMap, ok := payload.(map[string]interface{})
-	if !ok {
-		c.sendError(ErrCodeInvalidMessage, "Invalid payload")
-		return
-	}
-
-	targetID, ok := payloadMap["targetPlayerId"].(string)
-	if !ok || targetID == "" {
-		c.sendError(ErrCodeInvalidMessage, "Target player ID is required")
-		return
-	}
-
-	err := c.session.CastVote(c.playerID, targetID)
-	if err != nil {
-		switch err {
-		case domain.ErrAlreadyVoted:
-			c.sendError(ErrCodeAlreadyVoted, "You have already voted")
-		case domain.ErrCannotVoteSelf:
-			c.sendError(ErrCodeCannotVoteSelf, "Cannot vote for yourself")
-		case domain.ErrInvalidPhase:
-			c.sendError(ErrCodeInvalidAction, "Cannot vote now")
-		default:
-			c.sendError(ErrCodeInternalError, err.Error())
-		}
-		return
-	}
+// SetCodec switches the codec used for subsequent messages
+func (c *Client) SetCodec(codec Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codec = codec
 }
 
-// handleRequestNewRound handles a request_new_round message
-func (c *Client) handleRequestNewRound() {
-	err := c.session.StartNewRound(c.playerID)
-	if err != nil {
-		switch err {
-		case domain.ErrNotHost:
-			c.sendError(ErrCodeNotHost, "Only the host can start a new round")
-		case domain.ErrInvalidPhase:
-			c.sendError(ErrCodeInvalidAction, "Cannot start new round now")
-		default:
-			c.sendError(ErrCodeInternalError, err.Error())
-		}
-		return
-	}
+// GetLocale returns the language error messages are sent in
+func (c *Client) GetLocale() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.locale
 }
 
-// sendConnected sends the connected message to the client
-func (c *Client) sendConnected() {
-	payload := &ConnectedPayload{
-		PlayerID:  c.playerID,
-		GameID:    c.session.GetRoomCode(),
-		GameState: c.session.GetGameState(c.playerID),
-	}
-
-	msg := NewServerMessage(MsgConnected, payload)
-	c.Send(msg)
+// SetLocale switches the language of subsequent error messages
+func (c *Client) SetLocale(locale string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locale = locale
 }
 
-// sendError sends an error message to the client
-func (c *Client) sendError(code, message string) {
-	payload := &ErrorPayload{
-		Code:    code,
-		Message: message,
-	}
-
-	msg := NewServerMessage(MsgError, payload)
-	c.Send(msg)
+// AcksEvents returns true if the client acknowledges critical events
+func (c *Client) AcksEvents() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.acks
 }
 
-// sendPong sends a pong message in response to ping
-func (c *Client) sendPong() {
-	msg := NewServerMessage(MsgPong, nil)
-	c.Send(msg)
+// SetAcks sets whether the client acknowledges critical events
+func (c *Client) SetAcks(acks bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acks = acks
 }
 
+// SendConnected sends the connected message with the current game state
+func (c *Client) SendConnected() {
+	c.dispatcher.SendConnected()
+}
@@ -0,0 +1,31 @@
+package ws
+
+import "imposter/internal/domain"
+
+// deltaCodec speaks protocol version 2 over a version 1 codec. Messages are
+// unchanged except that game events with a delta form send it as the payload.
+type deltaCodec struct {
+	Codec
+}
+
+func (deltaCodec) Version() ProtocolVersion {
+	return ProtocolV2
+}
+
+func (c deltaCodec) Encode(message interface{}) ([]byte, error) {
+	if event, ok := message.(*domain.GameEvent); ok && event.Delta != nil {
+		compact := getEvent(event)
+		defer putEvent(compact)
+		compact.Payload = event.Delta
+		message = compact
+	}
+	return c.Codec.Encode(message)
+}
+
+func (c deltaCodec) AppendSeq(dst, data []byte, seq uint64) ([]byte, error) {
+	appender, ok := c.Codec.(seqAppender)
+	if !ok {
+		return nil, errUnsequenceable
+	}
+	return appender.AppendSeq(dst, data, seq)
+}
@@ -0,0 +1,438 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime/debug"
+	"time"
+
+	"imposter/internal/apierror"
+	"imposter/internal/app"
+	"imposter/internal/buildinfo"
+	"imposter/internal/domain"
+	"imposter/internal/i18n"
+)
+
+// Peer is a streaming client connection that game messages are dispatched for.
+// It is implemented by the WebSocket Client and by other streaming transports.
+type Peer interface {
+	app.ClientConnection
+	GetCodec() Codec
+	SetCodec(codec Codec)
+
+	// GetConnectionID returns the ID correlating this connection's logs and errors
+	GetConnectionID() string
+
+	// GetLocale and SetLocale hold the language error messages are sent in
+	GetLocale() string
+	SetLocale(locale string)
+
+	// AcksEvents and SetAcks hold whether the client acknowledges critical
+	// events (see app.AckingClient)
+	AcksEvents() bool
+	SetAcks(acks bool)
+}
+
+// Dispatcher routes decoded client messages to a game session on behalf of a peer
+type Dispatcher struct {
+	session *app.GameSession
+	peer    Peer
+
+	// Spectators may only negotiate the protocol, ping and vote as the audience
+	readOnly bool
+}
+
+// NewDispatcher creates a dispatcher for the given session and peer
+func NewDispatcher(session *app.GameSession, peer Peer) *Dispatcher {
+	return &Dispatcher{
+		session: session,
+		peer:    peer,
+	}
+}
+
+// NewSpectatorDispatcher creates a dispatcher that rejects all game actions
+func NewSpectatorDispatcher(session *app.GameSession, peer Peer) *Dispatcher {
+	return &Dispatcher{
+		session:  session,
+		peer:     peer,
+		readOnly: true,
+	}
+}
+
+// Receive decodes a raw message from the peer with its codec and dispatches it
+func (d *Dispatcher) Receive(data []byte) {
+	d.session.RecordMessage()
+
+	msg, err := d.peer.GetCodec().Decode(data)
+	if err != nil {
+		d.SendError(ErrCodeInvalidMessage, "Invalid message format")
+		return
+	}
+	d.Dispatch(msg)
+}
+
+// Dispatch routes a decoded client message to the matching handler. A handler
+// that panics is reported and the client told, rather than closing the connection.
+func (d *Dispatcher) Dispatch(msg *ClientMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := &app.PanicError{Value: r, Stack: debug.Stack()}
+			d.session.ReportError(err, map[string]string{
+				"source":       "ws",
+				"messageType":  string(msg.Type),
+				"connectionID": d.peer.GetConnectionID(),
+			})
+			d.SendError(ErrCodeInternalError, "Something went wrong, please try again")
+		}
+	}()
+
+	if d.readOnly && msg.Type != MsgHello && msg.Type != MsgPing && msg.Type != MsgAudienceVote {
+		d.SendError(ErrCodeReadOnly, "Spectators cannot perform game actions")
+		return
+	}
+
+	switch msg.Type {
+	case MsgHello:
+		d.handleHello(msg.Payload)
+	case MsgJoinLobby:
+		d.handleJoinLobby(msg.Payload)
+	case MsgStartGame:
+		d.handleStartGame()
+	case MsgSubmitWord:
+		d.handleSubmitWord(msg.Payload)
+	case MsgCastVote:
+		d.handleCastVote(msg.Payload)
+	case MsgAudienceVote:
+		d.handleAudienceVote(msg.Payload)
+	case MsgRequestNewRound:
+		d.handleRequestNewRound()
+	case MsgCreateInvite:
+		d.handleCreateInvite(msg.Payload)
+	case MsgAddBot:
+		d.handleAddBot()
+	case MsgAck:
+		d.handleAck(msg.Payload)
+	case MsgPing:
+		d.sendPong()
+	default:
+		d.SendError(ErrCodeInvalidMessage, "Unknown message type")
+	}
+}
+
+// handleHello handles a hello message, switching to the requested protocol version
+func (d *Dispatcher) handleHello(raw json.RawMessage) {
+	var payload HelloPayload
+	err := decodePayload(raw, &payload)
+
+	// Clients may declare a locale instead of relying on Accept-Language,
+	// which also applies to the error about the rest of the payload
+	if locale := i18n.Match(payload.Locale); locale != "" {
+		d.peer.SetLocale(locale)
+	}
+	if err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	codec, err := CodecFor(payload.ProtocolVersion, d.peer.GetCodec().Encoding())
+	if err != nil {
+		d.SendError(ErrCodeUnsupportedProtocol, "Unsupported protocol version")
+		d.sendProtocol()
+		return
+	}
+
+	d.peer.SetCodec(codec)
+	if !d.readOnly {
+		d.peer.SetAcks(payload.Acks)
+	}
+
+	d.sendProtocol()
+}
+
+// handleAck handles an ack message, so the critical events it covers are not resent
+func (d *Dispatcher) handleAck(raw json.RawMessage) {
+	var payload AckPayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	d.session.Ack(d.peer.GetPlayerID(), payload.Seq)
+}
+
+// handleJoinLobby handles a join_lobby message
+func (d *Dispatcher) handleJoinLobby(raw json.RawMessage) {
+	var payload JoinLobbyPayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	// Signed-in players may already be in the game from another connection
+	if d.session.HasPlayer(d.peer.GetPlayerID()) {
+		d.SendConnected()
+		return
+	}
+
+	// Try to add player to game. Password or invite is only checked for locked rooms.
+	_, err := d.session.AddPlayer(d.peer.GetPlayerID(), payload.Nickname, payload.Password, payload.Invite)
+	if err != nil {
+		d.sendActionError(err)
+		return
+	}
+
+	// Send connected confirmation
+	d.SendConnected()
+}
+
+// handleStartGame handles a start_game message
+func (d *Dispatcher) handleStartGame() {
+	err := d.session.StartGame(d.peer.GetPlayerID())
+	if err != nil {
+		switch err {
+		case domain.ErrNotHost:
+			d.SendError(ErrCodeNotHost, "Only the host can start the game")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// handleSubmitWord handles a submit_word message
+func (d *Dispatcher) handleSubmitWord(raw json.RawMessage) {
+	var payload SubmitWordPayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	err := d.session.SubmitWord(d.peer.GetPlayerID(), payload.Word)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPhase:
+			d.SendError(ErrCodeInvalidAction, "Cannot submit now")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// handleCastVote handles a cast_vote message
+func (d *Dispatcher) handleCastVote(raw json.RawMessage) {
+	var payload CastVotePayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	err := d.session.CastVote(d.peer.GetPlayerID(), payload.TargetPlayerID)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPhase:
+			d.SendError(ErrCodeInvalidAction, "Cannot vote now")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// handleAudienceVote handles an audience_vote message, a spectator's vote
+// that does not count toward the result
+func (d *Dispatcher) handleAudienceVote(raw json.RawMessage) {
+	if !d.readOnly {
+		d.SendError(ErrCodeInvalidAction, "Players vote with cast_vote")
+		return
+	}
+
+	var payload AudienceVotePayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	err := d.session.CastSpectatorVote(d.peer.GetConnectionID(), payload.TargetPlayerID)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidPhase:
+			d.SendError(ErrCodeInvalidAction, "Cannot vote now")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// handleRequestNewRound handles a request_new_round message
+func (d *Dispatcher) handleRequestNewRound() {
+	err := d.session.StartNewRound(d.peer.GetPlayerID())
+	if err != nil {
+		switch err {
+		case domain.ErrNotHost:
+			d.SendError(ErrCodeNotHost, "Only the host can start a new round")
+		case domain.ErrInvalidPhase:
+			d.SendError(ErrCodeInvalidAction, "Cannot start new round now")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// handleCreateInvite handles a create_invite message
+func (d *Dispatcher) handleCreateInvite(raw json.RawMessage) {
+	// Both fields are optional, so a missing payload is fine
+	var payload CreateInvitePayload
+	if err := decodePayload(raw, &payload); err != nil {
+		d.sendPayloadError(err)
+		return
+	}
+
+	ttl := time.Duration(min(payload.TTLSeconds, int(app.MaxInviteTTL.Seconds()))) * time.Second
+	invite, err := d.session.CreateInvite(d.peer.GetPlayerID(), ttl, payload.MaxUses)
+	if err != nil {
+		switch err {
+		case domain.ErrNotHost:
+			d.SendError(ErrCodeNotHost, "Only the host can create invites")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+
+	msg := NewServerMessage(MsgInviteCreated, &InviteCreatedPayload{
+		Token:     invite.Token,
+		ExpiresAt: invite.ExpiresAt,
+		MaxUses:   invite.MaxUses,
+	})
+	d.peer.Send(msg)
+}
+
+// handleAddBot handles an add_bot message. The bot shows up in the next
+// lobby update.
+func (d *Dispatcher) handleAddBot() {
+	_, err := d.session.AddBot(d.peer.GetPlayerID())
+	if err != nil {
+		switch err {
+		case domain.ErrNotHost:
+			d.SendError(ErrCodeNotHost, "Only the host can add bots")
+		case domain.ErrGameAlreadyStarted:
+			d.SendError(ErrCodeInvalidAction, "Bots can only be added in the lobby")
+		default:
+			d.sendActionError(err)
+		}
+		return
+	}
+}
+
+// SendConnected sends the connected message to the client
+func (d *Dispatcher) SendConnected() {
+	payload := &ConnectedPayload{
+		PlayerID:           d.peer.GetPlayerID(),
+		GameID:             d.session.GetRoomCode(),
+		ReconnectToken:     d.session.IssueReconnectToken(d.peer.GetPlayerID()),
+		LastSeq:            d.session.LastSeq(d.peer.GetPlayerID()), // Read before the snapshot so no event is skipped
+		GameState:          d.session.GetGameState(d.peer.GetPlayerID()),
+		ProtocolVersion:    d.peer.GetCodec().Version(),
+		SupportedVersions:  SupportedProtocolVersions(),
+		Encoding:           d.peer.GetCodec().Encoding(),
+		SupportedEncodings: SupportedEncodings(),
+		ServerVersion:      buildinfo.Version,
+	}
+
+	msg := NewServerMessage(MsgConnected, payload)
+	d.peer.Send(msg)
+}
+
+// SendSpectating sends a spectator the public game state
+func (d *Dispatcher) SendSpectating() {
+	payload := &SpectatingPayload{
+		GameID:             d.session.GetRoomCode(),
+		GameState:          d.session.GetPublicState(),
+		ProtocolVersion:    d.peer.GetCodec().Version(),
+		SupportedVersions:  SupportedProtocolVersions(),
+		Encoding:           d.peer.GetCodec().Encoding(),
+		SupportedEncodings: SupportedEncodings(),
+	}
+
+	msg := NewServerMessage(MsgSpectating, payload)
+	d.peer.Send(msg)
+}
+
+// SendResumed confirms a resumed connection; the missed events were already replayed
+func (d *Dispatcher) SendResumed() {
+	payload := &ResumedPayload{
+		PlayerID:       d.peer.GetPlayerID(),
+		GameID:         d.session.GetRoomCode(),
+		ReconnectToken: d.session.IssueReconnectToken(d.peer.GetPlayerID()),
+	}
+
+	msg := NewServerMessage(MsgResumed, payload)
+	d.peer.Send(msg)
+}
+
+// sendProtocol sends the negotiated and supported protocol versions to the client
+func (d *Dispatcher) sendProtocol() {
+	payload := &ProtocolPayload{
+		ProtocolVersion:    d.peer.GetCodec().Version(),
+		SupportedVersions:  SupportedProtocolVersions(),
+		Encoding:           d.peer.GetCodec().Encoding(),
+		SupportedEncodings: SupportedEncodings(),
+	}
+
+	msg := NewServerMessage(MsgProtocol, payload)
+	d.peer.Send(msg)
+}
+
+// SendError sends an error message to the client, translated to the room's
+// locale or else the client's
+func (d *Dispatcher) SendError(code, message string) {
+	d.sendError(&ErrorPayload{Code: code, Message: message})
+}
+
+// sendPayloadError tells the client which field of its message's payload is
+// missing or invalid
+func (d *Dispatcher) sendPayloadError(err error) {
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		d.SendError(ErrCodeInvalidMessage, "Invalid payload")
+		return
+	}
+	d.sendError(&ErrorPayload{Code: ErrCodeInvalidMessage, Message: fieldErr.Message, Field: fieldErr.Field})
+}
+
+// sendError sends an error message, translated for the room or client
+func (d *Dispatcher) sendError(payload *ErrorPayload) {
+	locale := d.session.Locale()
+	if locale == "" {
+		locale = d.peer.GetLocale()
+	}
+	payload.Message = i18n.Translate(locale, payload.Message)
+	payload.Retryable = apierror.IsRetryable(payload.Code)
+	payload.RequestID = d.peer.GetConnectionID()
+
+	msg := NewServerMessage(MsgError, payload)
+	d.peer.Send(msg)
+}
+
+// sendActionError tells the client why its action failed, as classified by
+// apierror. Errors no action is expected to return are reported, and the
+// client is not shown their text.
+func (d *Dispatcher) sendActionError(err error) {
+	e, ok := apierror.Classify(err)
+	if !ok {
+		d.session.ReportError(err, map[string]string{
+			"source":       "ws",
+			"connectionID": d.peer.GetConnectionID(),
+		})
+	}
+	d.SendError(e.Code, e.Message)
+}
+
+// sendPong sends a pong message in response to ping
+func (d *Dispatcher) sendPong() {
+	msg := NewServerMessage(MsgPong, nil)
+	d.peer.Send(msg)
+}
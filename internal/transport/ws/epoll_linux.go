@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// epoll reports file descriptors that have become readable. Descriptors are
+// added one-shot: once reported, one is not reported again until rearmed, so
+// a connection is only ever read by one worker at a time.
+type epoll struct {
+	fd     int
+	events []unix.EpollEvent
+}
+
+// newEpoll creates an epoll instance
+func newEpoll() (*epoll, error) {
+	fd, err := unix.EpollCreate1(unix.EPOLL_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	return &epoll{fd: fd, events: make([]unix.EpollEvent, 128)}, nil
+}
+
+// add starts watching fd
+func (e *epoll) add(fd int) error {
+	return unix.EpollCtl(e.fd, unix.EPOLL_CTL_ADD, fd, e.event(fd))
+}
+
+// rearm watches fd again after it was reported
+func (e *epoll) rearm(fd int) error {
+	return unix.EpollCtl(e.fd, unix.EPOLL_CTL_MOD, fd, e.event(fd))
+}
+
+// remove stops watching fd
+func (e *epoll) remove(fd int) error {
+	return unix.EpollCtl(e.fd, unix.EPOLL_CTL_DEL, fd, nil)
+}
+
+// event is what epoll is asked to report for fd: data to read, or the peer
+// hanging up, which the read then notices
+func (e *epoll) event(fd int) *unix.EpollEvent {
+	return &unix.EpollEvent{
+		Events: unix.EPOLLIN | unix.EPOLLRDHUP | unix.EPOLLONESHOT,
+		Fd:     int32(fd),
+	}
+}
+
+// wait blocks until descriptors are readable and appends them to ready
+func (e *epoll) wait(ready []int) ([]int, error) {
+	n, err := unix.EpollWait(e.fd, e.events, -1)
+	if err == unix.EINTR {
+		return ready, nil
+	}
+	if err != nil {
+		return ready, err
+	}
+	for _, event := range e.events[:n] {
+		ready = append(ready, int(event.Fd))
+	}
+	return ready, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package ws
+
+import "errors"
+
+// epoll is only available on Linux; elsewhere the epoll backend falls back to gorilla
+type epoll struct{}
+
+func newEpoll() (*epoll, error) {
+	return nil, errors.New("the epoll WebSocket backend requires Linux")
+}
+
+func (e *epoll) add(fd int) error                { return nil }
+func (e *epoll) rearm(fd int) error              { return nil }
+func (e *epoll) remove(fd int) error             { return nil }
+func (e *epoll) wait(ready []int) ([]int, error) { return ready, nil }
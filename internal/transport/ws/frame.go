@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"errors"
+
+	"imposter/internal/app"
+)
+
+// errUnsequenceable is returned when encoded bytes are not an event a
+// sequence number can be added to
+var errUnsequenceable = errors.New("encoded message cannot take a sequence number")
+
+// seqAppender is implemented by codecs that can add a sequence number to an
+// event encoded without one, producing the same bytes as encoding it with one.
+// The result is appended to dst.
+type seqAppender interface {
+	AppendSeq(dst, data []byte, seq uint64) ([]byte, error)
+}
+
+// EncodeMessage encodes a message with codec. A frame is encoded once per
+// codec and the bytes are shared with the frame's other recipients.
+func EncodeMessage(codec Codec, message interface{}) ([]byte, error) {
+	frame, ok := message.(*app.Frame)
+	if !ok {
+		return codec.Encode(message)
+	}
+
+	appender, ok := codec.(seqAppender)
+	if !ok {
+		return codec.Encode(frame.Event.WithSeq(frame.Seq))
+	}
+
+	data, err := frame.Encoded(codec, codec.Encode)
+	if err != nil || frame.Seq == 0 {
+		return data, err
+	}
+	return appender.AppendSeq(nil, data, frame.Seq)
+}
+
+// encodePooled is EncodeMessage for connections that release what they send
+// once written. A frame's copy for a player is built in a pooled buffer, which
+// is returned as pooled and must be released with putBuffer; other messages
+// are not pooled.
+func encodePooled(codec Codec, message interface{}) (data []byte, pooled *[]byte, err error) {
+	frame, ok := message.(*app.Frame)
+	appender, canAppend := codec.(seqAppender)
+	if !ok || !canAppend || frame.Seq == 0 {
+		data, err = EncodeMessage(codec, message)
+		return data, nil, err
+	}
+
+	shared, err := frame.Encoded(codec, codec.Encode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pooled = getBuffer()
+	data, err = appender.AppendSeq(*pooled, shared, frame.Seq)
+	if err != nil {
+		putBuffer(pooled)
+		return nil, nil, err
+	}
+	*pooled = data
+	return data, pooled, nil
+}
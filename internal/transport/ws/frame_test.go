@@ -0,0 +1,64 @@
+package ws
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// benchmarkEvent returns a lobby update for a full room, a typical event to
+// fan out
+func benchmarkEvent(players int) *domain.GameEvent {
+	lobby := &domain.LobbyUpdatePayload{HostID: "player-0", CanStart: true}
+	for i := range players {
+		lobby.Players = append(lobby.Players, domain.PlayerInfo{
+			ID:       fmt.Sprintf("player-%d", i),
+			Nickname: fmt.Sprintf("Player %d", i),
+			Status:   domain.StatusConnected,
+		})
+	}
+	event := domain.NewEvent(domain.EventPlayerJoined, "BENCH1", lobby)
+	event.Timestamp = time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	return event
+}
+
+// BenchmarkBroadcast fans one event out to a room's recipients, encoding it
+// once per codec and adding each recipient's sequence number, against
+// encoding it again for every recipient
+func BenchmarkBroadcast(b *testing.B) {
+	for _, encoding := range []Encoding{EncodingJSON, EncodingProtobuf, EncodingMessagePack} {
+		codec, err := CodecFor(ProtocolV1, encoding)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, recipients := range []int{8, 100} {
+			event := benchmarkEvent(10)
+
+			b.Run(fmt.Sprintf("%s/%d/encode-once", encoding, recipients), func(b *testing.B) {
+				b.ReportAllocs()
+				for range b.N {
+					frame := app.NewFrame(event)
+					for seq := range recipients {
+						if _, err := EncodeMessage(codec, frame.WithSeq(uint64(seq+1))); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/%d/per-recipient", encoding, recipients), func(b *testing.B) {
+				b.ReportAllocs()
+				for range b.N {
+					for seq := range recipients {
+						if _, err := codec.Encode(event.WithSeq(uint64(seq + 1))); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+		}
+	}
+}
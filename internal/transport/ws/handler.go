@@ -3,83 +3,189 @@ package ws
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 
+	"github.com/gobwas/ws"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
 	"imposter/internal/app"
+	"imposter/internal/auth"
+	"imposter/internal/clientip"
+	"imposter/internal/config"
+	"imposter/internal/i18n"
+	"imposter/internal/requestid"
 )
 
 // Handler handles WebSocket connections
 type Handler struct {
 	hub      *app.GameHub
 	upgrader websocket.Upgrader
+	config   config.WebSocketConfig
 	logger   *slog.Logger
+
+	// Serves game and spectator connections on the epoll backend; nil for gorilla
+	poller *netpoller
+
+	// Open connections, and upgrades refused for load
+	active   atomic.Int64
+	rejected atomic.Uint64
+}
+
+// connection is a game or spectator connection on either backend
+type connection interface {
+	Peer
+	getDispatcher() *Dispatcher
+
+	// serve runs the connection until it closes and has left the session,
+	// then calls done. It may return before then.
+	serve(done func())
+
+	// refuse closes a connection that is not served, telling the client why
+	refuse(code int, reason string)
 }
 
 // NewHandler creates a new WebSocket handler
-func NewHandler(hub *app.GameHub, logger *slog.Logger) *Handler {
-	return &Handler{
+func NewHandler(hub *app.GameHub, cfg config.WebSocketConfig, logger *slog.Logger) *Handler {
+	if !SlowClientPolicy(cfg.SlowClientPolicy).IsValid() {
+		logger.Warn("unknown slow client policy, using drop-low-priority", "policy", cfg.SlowClientPolicy)
+	}
+
+	h := &Handler{
 		hub: hub,
 		upgrader: websocket.Upgrader{
-			ReadBufferSize:  1024,
-			WriteBufferSize: 1024,
+			ReadBufferSize:    1024,
+			WriteBufferSize:   1024,
+			WriteBufferPool:   writeBufferPool,
+			EnableCompression: cfg.CompressionEnabled,
 			CheckOrigin: func(r *http.Request) bool {
 				// Allow all origins for development
 				// In production, you should validate the origin
 				return true
 			},
 		},
+		config: cfg,
 		logger: logger,
 	}
+
+	if cfg.Backend == "epoll" {
+		poller, err := newNetpoller(logger)
+		if err != nil {
+			logger.Warn("epoll websocket backend unavailable, using gorilla", "error", err)
+		} else {
+			h.poller = poller
+			logger.Info("serving websocket connections with epoll")
+		}
+	}
+	return h
 }
 
 // ServeHTTP handles WebSocket upgrade requests
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.acquire() {
+		h.rejectOverloaded(w, r, "SERVER_AT_CAPACITY", "Too many connections right now, please try again shortly")
+		return
+	}
+	// Once served, the connection releases itself when it closes
+	served := false
+	defer func() {
+		if !served {
+			h.release()
+		}
+	}()
+
 	// Get room code from query params
 	roomCode := r.URL.Query().Get("roomCode")
 	if roomCode == "" {
-		http.Error(w, "roomCode is required", http.StatusBadRequest)
+		httpError(w, r, "roomCode is required", http.StatusBadRequest)
 		return
 	}
 
-	// Get or create player ID
-	playerID := r.URL.Query().Get("playerId")
-	isReconnect := playerID != ""
-	if !isReconnect {
-		playerID = uuid.New().String()
+	// Negotiate protocol version and encoding before upgrading
+	codec, ok := negotiate(w, r)
+	if !ok {
+		return
 	}
 
 	// Get the game session
 	session, err := h.hub.GetSession(roomCode)
 	if err != nil {
-		http.Error(w, "Game not found", http.StatusNotFound)
+		httpError(w, r, "Game not found", http.StatusNotFound)
 		return
 	}
 
+	// Reconnecting players prove their identity with a signed token; a bare
+	// player ID is not enough
+	var playerID string
+	var isReconnect bool
+	token := r.URL.Query().Get("token")
+	if subject, ok := auth.SubjectFromContext(r.Context()); ok {
+		// An external identity is durable, so it doubles as the player ID
+		playerID = subject
+		isReconnect = session.HasPlayer(playerID)
+	} else if token != "" {
+		isReconnect = true
+		playerID, err = session.VerifyReconnectToken(token)
+		if err != nil {
+			httpError(w, r, "Invalid or expired reconnect token", http.StatusUnauthorized)
+			return
+		}
+	} else {
+		if r.URL.Query().Get("playerId") != "" {
+			httpError(w, r, "Reconnect token is required", http.StatusUnauthorized)
+			return
+		}
+		playerID = uuid.New().String()
+	}
+
+	// A reconnecting client that tracked event sequence numbers can resume from the last one it saw
+	var lastSeq uint64
+	resume := false
+	if raw := r.URL.Query().Get("lastSeq"); raw != "" && isReconnect {
+		lastSeq, err = strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			httpError(w, r, "lastSeq must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		resume = true
+	}
+
 	// Check if can join (for new players)
 	if !isReconnect && !session.CanJoin() {
-		http.Error(w, "Cannot join this game", http.StatusForbidden)
+		httpError(w, r, "Cannot join this game", http.StatusForbidden)
 		return
 	}
 
-	// Upgrade connection to WebSocket
-	conn, err := h.upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		h.logger.Error("websocket upgrade failed", "error", err)
+	// Players already in the game always get back in
+	if !isReconnect && !session.HasConnectionCapacity() {
+		h.rejectOverloaded(w, r, "ROOM_AT_CAPACITY", "This room has too many connections, please try again shortly")
+		return
+	}
+
+	connID := connectionID(r)
+	logger := h.logger.With("requestID", connID, "roomCode", roomCode, "playerID", playerID)
+
+	// Upgrade connection to WebSocket and create the client
+	client, ok := h.connect(w, r, session, playerID, connID, codec, false, logger)
+	if !ok {
 		return
 	}
+	client.SetLocale(i18n.FromRequest(r))
 
-	// Create client
-	client := NewClient(conn, session, playerID, h.logger)
+	session.SetClientIP(playerID, clientip.FromContext(r.Context()))
 
-	// Register client with session
-	session.RegisterClient(playerID, client)
+	// Register client with session, replaying what it missed if possible
+	resumed := resume && session.ResumeClient(playerID, client, lastSeq)
+	if !resumed {
+		session.RegisterClient(playerID, client)
+	}
 
-	h.logger.Info("websocket connected",
-		"roomCode", roomCode,
-		"playerID", playerID,
+	logger.Info("websocket connected",
 		"isReconnect", isReconnect,
+		"resumed", resumed,
+		"protocolVersion", codec.Version(),
+		"encoding", codec.Encoding(),
 	)
 
 	// Handle reconnection
@@ -87,14 +193,154 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_, err := session.ReconnectPlayer(playerID)
 		if err != nil {
 			// Player not found, treat as new connection
-			h.logger.Debug("reconnect failed, treating as new", "playerID", playerID, "error", err)
+			logger.Debug("reconnect failed, treating as new", "error", err)
+		} else if resumed {
+			client.getDispatcher().SendResumed()
 		} else {
 			// Send current game state
-			client.sendConnected()
+			client.getDispatcher().SendConnected()
 		}
 	}
 
 	// Start the client
-	client.Run()
+	served = true
+	client.serve(h.release)
 }
 
+// Spectate handles read-only WebSocket connections that stream a room's public
+// events without joining it. Locked rooms require the password as ?password=.
+func (h *Handler) Spectate(w http.ResponseWriter, r *http.Request) {
+	if !h.acquire() {
+		h.rejectOverloaded(w, r, "SERVER_AT_CAPACITY", "Too many connections right now, please try again shortly")
+		return
+	}
+	served := false
+	defer func() {
+		if !served {
+			h.release()
+		}
+	}()
+
+	roomCode := r.URL.Query().Get("roomCode")
+	if roomCode == "" {
+		httpError(w, r, "roomCode is required", http.StatusBadRequest)
+		return
+	}
+
+	codec, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.hub.GetSession(roomCode)
+	if err != nil {
+		httpError(w, r, "Game not found", http.StatusNotFound)
+		return
+	}
+
+	if !session.CheckPassword(r.URL.Query().Get("password")) {
+		httpError(w, r, "Wrong room password", http.StatusForbidden)
+		return
+	}
+	if !session.HasConnectionCapacity() {
+		h.rejectOverloaded(w, r, "ROOM_AT_CAPACITY", "This room has too many connections, please try again shortly")
+		return
+	}
+
+	connID := connectionID(r)
+	logger := h.logger.With("requestID", connID, "roomCode", roomCode, "spectator", true)
+
+	client, ok := h.connect(w, r, session, "", connID, codec, true, logger)
+	if !ok {
+		return
+	}
+	client.SetLocale(i18n.FromRequest(r))
+	if !session.AddSpectator(connID, client, h.config.MaxSpectators) {
+		client.refuse(websocket.CloseTryAgainLater, "room connection limit reached")
+		return
+	}
+
+	logger.Info("spectator connected",
+		"protocolVersion", codec.Version(),
+		"encoding", codec.Encoding(),
+	)
+
+	client.getDispatcher().SendSpectating()
+	served = true
+	client.serve(h.release)
+}
+
+// negotiate resolves the protocol version and encoding requested in the query,
+// writing a 400 response if either is unsupported
+func negotiate(w http.ResponseWriter, r *http.Request) (Codec, bool) {
+	codec, err := NegotiateCodec(r.URL.Query().Get("protocolVersion"), r.URL.Query().Get("encoding"))
+	if err == ErrUnsupportedEncoding {
+		httpError(w, r, "Unsupported encoding, supported: "+formatEncodings(SupportedEncodings()), http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		httpError(w, r, "Unsupported protocol version, supported: "+formatVersions(SupportedProtocolVersions()), http.StatusBadRequest)
+		return nil, false
+	}
+	return codec, true
+}
+
+// httpError replies to a request that was not upgraded with message in the
+// client's locale
+func httpError(w http.ResponseWriter, r *http.Request, message string, code int) {
+	locale := i18n.FromRequest(r)
+	w.Header().Set("Content-Language", locale)
+	http.Error(w, i18n.Translate(locale, message), code)
+}
+
+// connect upgrades the request and creates its connection on the configured
+// backend. A spectator's connection is read-only.
+func (h *Handler) connect(w http.ResponseWriter, r *http.Request, session *app.GameSession, playerID, connID string, codec Codec, spectator bool, logger *slog.Logger) (connection, bool) {
+	if h.poller != nil {
+		conn, rw, _, err := ws.UpgradeHTTP(r, w)
+		if err != nil {
+			h.logger.Error("websocket upgrade failed", "error", err)
+			return nil, false
+		}
+		if spectator {
+			return newNetpollSpectator(conn, rw.Reader, h.poller, session, connID, codec, h.config, logger), true
+		}
+		return newNetpollClient(conn, rw.Reader, h.poller, session, playerID, connID, codec, h.config, logger), true
+	}
+
+	conn, ok := h.upgrade(w, r)
+	if !ok {
+		return nil, false
+	}
+	if spectator {
+		return NewSpectatorClient(conn, session, connID, codec, h.config, logger), true
+	}
+	return NewClient(conn, session, playerID, connID, codec, h.config, logger), true
+}
+
+// upgrade switches the request to the WebSocket protocol
+func (h *Handler) upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, bool) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("websocket upgrade failed", "error", err)
+		return nil, false
+	}
+
+	// Compression only applies if the client negotiated permessage-deflate
+	if h.config.CompressionEnabled {
+		if err := conn.SetCompressionLevel(h.config.CompressionLevel); err != nil {
+			h.logger.Warn("invalid websocket compression level", "level", h.config.CompressionLevel, "error", err)
+		}
+	}
+
+	return conn, true
+}
+
+// connectionID returns the ID of the upgrade request, which every log line and
+// error from the connection carries
+func connectionID(r *http.Request) string {
+	if id := requestid.FromContext(r.Context()); id != "" {
+		return id
+	}
+	return requestid.New()
+}
@@ -0,0 +1,106 @@
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"imposter/internal/apierror"
+	"imposter/internal/i18n"
+	"imposter/internal/requestid"
+)
+
+// overloadRetryAfter is how many seconds a client refused for load is told to
+// wait before trying again
+const overloadRetryAfter = 5
+
+// ConnectionStats describes the handler's connections, for metrics
+type ConnectionStats struct {
+	Active   int64  // Open game, spectator and replay connections and gRPC streams
+	Limit    int    // WS_MAX_CONNECTIONS; 0 is unlimited
+	Rejected uint64 // Upgrades and streams refused by the instance's or a room's limit
+}
+
+// Stats returns the handler's connection counts
+func (h *Handler) Stats() ConnectionStats {
+	return ConnectionStats{
+		Active:   h.active.Load(),
+		Limit:    h.config.MaxConnections,
+		Rejected: h.rejected.Load(),
+	}
+}
+
+// acquire takes one of the instance's connections, returning false if they
+// are all in use. Call release when the connection closes.
+func (h *Handler) acquire() bool {
+	n := h.active.Add(1)
+	if h.config.MaxConnections > 0 && n > int64(h.config.MaxConnections) {
+		h.active.Add(-1)
+		return false
+	}
+	return true
+}
+
+// release gives back a connection taken by acquire
+func (h *Handler) release() {
+	h.active.Add(-1)
+}
+
+// Acquire takes one of the instance's connections for a stream served by
+// another transport, such as gRPC, so both share WS_MAX_CONNECTIONS. It
+// returns false, counting the refusal, if they are all in use. Call Release
+// when the stream ends.
+func (h *Handler) Acquire() bool {
+	if h.acquire() {
+		return true
+	}
+	h.rejected.Add(1)
+	return false
+}
+
+// Release gives back a connection taken by Acquire
+func (h *Handler) Release() {
+	h.release()
+}
+
+// Refused counts a stream another transport refused for a room's limit
+func (h *Handler) Refused() {
+	h.rejected.Add(1)
+}
+
+// rejection is the body of an upgrade refused for load, shaped like a REST
+// error response so clients can handle both alike
+type rejection struct {
+	Success bool           `json:"success"`
+	Error   rejectionError `json:"error"`
+}
+
+type rejectionError struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details"`
+	RequestID string                 `json:"requestId,omitempty"`
+}
+
+// rejectOverloaded refuses an upgrade with a 503 telling the client when to
+// try again, rather than letting a surge of connections knock the instance over
+func (h *Handler) rejectOverloaded(w http.ResponseWriter, r *http.Request, code, message string) {
+	h.rejected.Add(1)
+	h.logger.Warn("websocket connection refused", "code", code, "active", h.active.Load())
+
+	locale := i18n.FromRequest(r)
+	w.Header().Set("Content-Language", locale)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(overloadRetryAfter))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(rejection{
+		Error: rejectionError{
+			Code:      code,
+			Message:   i18n.Translate(locale, message),
+			Retryable: apierror.IsRetryable(code),
+			Details:   map[string]interface{}{"retryAfterSeconds": overloadRetryAfter},
+			RequestID: requestid.FromContext(r.Context()),
+		},
+	})
+}
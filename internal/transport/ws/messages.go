@@ -1,6 +1,9 @@
 package ws
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // MessageType represents the type of WebSocket message
 type MessageType string
@@ -11,16 +14,25 @@ const (
 	MsgStartGame       MessageType = "start_game"
 	MsgSubmitWord      MessageType = "submit_word"
 	MsgCastVote        MessageType = "cast_vote"
+	MsgAudienceVote    MessageType = "audience_vote"
 	MsgRequestNewRound MessageType = "request_new_round"
+	MsgCreateInvite    MessageType = "create_invite"
+	MsgAddBot          MessageType = "add_bot"
 	MsgPing            MessageType = "ping"
+	MsgHello           MessageType = "hello"
+	MsgAck             MessageType = "ack"
 )
 
 // Server → Client message types
 const (
 	MsgConnected          MessageType = "connected"
+	MsgResumed            MessageType = "resumed"
+	MsgSpectating         MessageType = "spectating"
+	MsgReplaying          MessageType = "replaying"
 	MsgError              MessageType = "error"
 	MsgLobbyUpdate        MessageType = "lobby_update"
 	MsgGameStarted        MessageType = "game_started"
+	MsgPhaseChanged       MessageType = "phase_changed"
 	MsgRoleAssigned       MessageType = "role_assigned"
 	MsgSubmissionPhase    MessageType = "submission_phase"
 	MsgSubmissionUpdate   MessageType = "submission_update"
@@ -31,12 +43,16 @@ const (
 	MsgPlayerDisconnected MessageType = "player_disconnected"
 	MsgPlayerReconnected  MessageType = "player_reconnected"
 	MsgPong               MessageType = "pong"
+	MsgProtocol           MessageType = "protocol"
+	MsgInviteCreated      MessageType = "invite_created"
+	MsgSystemNotice       MessageType = "system_notice"
 )
 
-// ClientMessage represents a message from client to server
+// ClientMessage represents a message from client to server. The payload is
+// decoded into the message type's payload struct once the type is known.
 type ClientMessage struct {
-	Type    MessageType `json:"type"`
-	Payload interface{} `json:"payload,omitempty"`
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
 }
 
 // ServerMessage represents a message from server to client
@@ -60,6 +76,8 @@ func NewServerMessage(msgType MessageType, payload interface{}) *ServerMessage {
 // JoinLobbyPayload is the payload for join_lobby message
 type JoinLobbyPayload struct {
 	Nickname string `json:"nickname"`
+	Password string `json:"password,omitempty"` // Required for locked rooms
+	Invite   string `json:"invite,omitempty"`   // Admits the player to a locked room instead of the password
 }
 
 // SubmitWordPayload is the payload for submit_word message
@@ -72,31 +90,142 @@ type CastVotePayload struct {
 	TargetPlayerID string `json:"targetPlayerId"`
 }
 
+// AudienceVotePayload is the payload for audience_vote message (spectators only)
+type AudienceVotePayload struct {
+	TargetPlayerID string `json:"targetPlayerId"`
+}
+
+// CreateInvitePayload is the payload for create_invite message (host only)
+type CreateInvitePayload struct {
+	TTLSeconds int `json:"ttlSeconds,omitempty"` // Defaults to one hour, at most 24 hours
+	MaxUses    int `json:"maxUses,omitempty"`    // 0 allows any number of joins until expiry
+}
+
+// HelloPayload is the payload for hello message
+type HelloPayload struct {
+	ProtocolVersion ProtocolVersion `json:"protocolVersion"`
+	Locale          string          `json:"locale,omitempty"` // e.g. "es"; error messages are sent in it if supported
+	Acks            bool            `json:"acks,omitempty"`   // The client acknowledges critical events with ack; unacknowledged ones are resent
+}
+
+// AckPayload is the payload for ack message. It acknowledges every event up
+// to and including Seq.
+type AckPayload struct {
+	Seq uint64 `json:"seq"`
+}
+
 // Server message payloads
 
 // ConnectedPayload is the payload for connected message
 type ConnectedPayload struct {
-	PlayerID  string                 `json:"playerId"`
-	GameID    string                 `json:"gameId"`
-	GameState map[string]interface{} `json:"gameState"`
+	PlayerID           string                 `json:"playerId"`
+	GameID             string                 `json:"gameId"`
+	ReconnectToken     string                 `json:"reconnectToken"` // Pass as ?token= to reconnect
+	GameState          map[string]interface{} `json:"gameState"`
+	LastSeq            uint64                 `json:"lastSeq"` // Pass as ?lastSeq= with the token to resume
+	ProtocolVersion    ProtocolVersion        `json:"protocolVersion"`
+	SupportedVersions  []ProtocolVersion      `json:"supportedVersions"`
+	Encoding           Encoding               `json:"encoding"`
+	SupportedEncodings []Encoding             `json:"supportedEncodings"`
+	ServerVersion      string                 `json:"serverVersion"`
+}
+
+// ResumedPayload is the payload for resumed message, sent instead of connected
+// when the events missed while disconnected were replayed
+type ResumedPayload struct {
+	PlayerID       string `json:"playerId"`
+	GameID         string `json:"gameId"`
+	ReconnectToken string `json:"reconnectToken"`
+}
+
+// SpectatingPayload is the payload for spectating message, sent to read-only
+// connections instead of connected
+type SpectatingPayload struct {
+	GameID             string                 `json:"gameId"`
+	GameState          map[string]interface{} `json:"gameState"` // Public state only, no roles or secret word
+	ProtocolVersion    ProtocolVersion        `json:"protocolVersion"`
+	SupportedVersions  []ProtocolVersion      `json:"supportedVersions"`
+	Encoding           Encoding               `json:"encoding"`
+	SupportedEncodings []Encoding             `json:"supportedEncodings"`
+}
+
+// ReplayingPayload is the payload for replaying message, sent to replay
+// connections before the recorded events
+type ReplayingPayload struct {
+	ReplayID   string  `json:"replayId"`
+	RoomCode   string  `json:"roomCode"`
+	Rounds     int     `json:"rounds"`
+	Events     int     `json:"events"`
+	DurationMs int64   `json:"durationMs"` // As recorded, before idle stretches are shortened
+	Speed      float64 `json:"speed"`
+}
+
+// ProtocolPayload is the payload for protocol message (reply to hello)
+type ProtocolPayload struct {
+	ProtocolVersion    ProtocolVersion   `json:"protocolVersion"`
+	SupportedVersions  []ProtocolVersion `json:"supportedVersions"`
+	Encoding           Encoding          `json:"encoding"`
+	SupportedEncodings []Encoding        `json:"supportedEncodings"`
+}
+
+// InviteCreatedPayload is the payload for invite_created message. Share the
+// token as /join/{gameId}?invite={token}.
+type InviteCreatedPayload struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxUses   int       `json:"maxUses"`
+}
+
+// NoticeSeverity is how urgent a system notice is
+type NoticeSeverity string
+
+// Notice severities
+const (
+	SeverityInfo     NoticeSeverity = "info"
+	SeverityWarning  NoticeSeverity = "warning"  // e.g. a restart is coming
+	SeverityCritical NoticeSeverity = "critical" // e.g. the server is going down now
+)
+
+// IsValid reports whether s is a known severity
+func (s NoticeSeverity) IsValid() bool {
+	return s == SeverityInfo || s == SeverityWarning || s == SeverityCritical
+}
+
+// SystemNoticePayload is the payload for system_notice message, sent by the
+// operators to everyone connected, whatever room they are in
+type SystemNoticePayload struct {
+	Message  string         `json:"message"`
+	Severity NoticeSeverity `json:"severity"`
 }
 
 // ErrorPayload is the payload for error message
 type ErrorPayload struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Field     string                 `json:"field,omitempty"`     // The payload field an INVALID_MESSAGE error is about, if any
+	Retryable bool                   `json:"retryable"`           // Sending the same message later may succeed
+	Details   map[string]interface{} `json:"details,omitempty"`   // Facts about the error specific to its code
+	RequestID string                 `json:"requestId,omitempty"` // Connection ID, quote when reporting a problem
 }
 
 // Error codes
 const (
-	ErrCodeInvalidMessage  = "INVALID_MESSAGE"
-	ErrCodeGameNotFound    = "GAME_NOT_FOUND"
-	ErrCodeGameFull        = "GAME_FULL"
-	ErrCodeNotYourTurn     = "NOT_YOUR_TURN"
-	ErrCodeInvalidAction   = "INVALID_ACTION"
-	ErrCodeNotHost         = "NOT_HOST"
-	ErrCodeAlreadyVoted    = "ALREADY_VOTED"
-	ErrCodeCannotVoteSelf  = "CANNOT_VOTE_SELF"
-	ErrCodeInternalError   = "INTERNAL_ERROR"
+	ErrCodeInvalidMessage      = "INVALID_MESSAGE"
+	ErrCodeGameNotFound        = "GAME_NOT_FOUND"
+	ErrCodeGameFull            = "GAME_FULL"
+	ErrCodeNotYourTurn         = "NOT_YOUR_TURN"
+	ErrCodeInvalidAction       = "INVALID_ACTION"
+	ErrCodeNotHost             = "NOT_HOST"
+	ErrCodeAlreadyVoted        = "ALREADY_VOTED"
+	ErrCodeCannotVoteSelf      = "CANNOT_VOTE_SELF"
+	ErrCodeInternalError       = "INTERNAL_ERROR"
+	ErrCodeUnsupportedProtocol = "UNSUPPORTED_PROTOCOL"
+	ErrCodeInvalidToken        = "INVALID_TOKEN"
+	ErrCodeWrongPassword       = "WRONG_PASSWORD"
+	ErrCodeReadOnly            = "READ_ONLY"
+	ErrCodeInvalidInvite       = "INVALID_INVITE"
+	ErrCodeInviteExpired       = "INVITE_EXPIRED"
+	ErrCodeQuotaExceeded       = "QUOTA_EXCEEDED"
+	ErrCodeTooManyBots         = "TOO_MANY_BOTS"
+	ErrCodePracticeRoom        = "PRACTICE_ROOM"
 )
-
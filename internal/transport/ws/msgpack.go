@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// msgpackCodecV1 is the binary MessagePack codec for protocol version 1
+type msgpackCodecV1 struct{}
+
+func (msgpackCodecV1) Version() ProtocolVersion {
+	return ProtocolV1
+}
+
+func (msgpackCodecV1) Encoding() Encoding {
+	return EncodingMessagePack
+}
+
+// Encode serializes a message using its json struct tags so field names
+// match the JSON protocol exactly
+func (msgpackCodecV1) Encode(message interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := getMsgpackEncoder(&buf)
+	defer msgpack.PutEncoder(enc)
+
+	if err := enc.Encode(message); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AppendSeq adds the seq field, which Encode writes last, to an encoded
+// event. Events are small enough to be encoded as fixmaps, whose header
+// holds the field count.
+func (msgpackCodecV1) AppendSeq(dst, data []byte, seq uint64) ([]byte, error) {
+	if len(data) == 0 || data[0]&0xf0 != msgpcode.FixedMapLow || data[0] == msgpcode.FixedMapHigh {
+		return nil, errUnsequenceable
+	}
+
+	buf := bytes.NewBuffer(slices.Grow(dst, len(data)+16))
+	buf.WriteByte(data[0] + 1)
+	buf.Write(data[1:])
+
+	enc := getMsgpackEncoder(buf)
+	defer msgpack.PutEncoder(enc)
+
+	if err := enc.EncodeString("seq"); err != nil {
+		return nil, err
+	}
+	if err := enc.EncodeUint(seq); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// getMsgpackEncoder returns a pooled encoder writing to w with json struct
+// tags and compact integers. Release it with msgpack.PutEncoder.
+func getMsgpackEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.GetEncoder()
+	enc.Reset(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	return enc
+}
+
+// Decode parses a client message. The generic value is normalized through
+// JSON so payloads decode into the same structs as with the JSON codec.
+func (msgpackCodecV1) Decode(data []byte) (*ClientMessage, error) {
+	var raw interface{}
+	if err := msgpack.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	normalized, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg ClientMessage
+	if err := json.Unmarshal(normalized, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
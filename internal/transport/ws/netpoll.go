@@ -0,0 +1,601 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+)
+
+const (
+	// Workers reading and writing epoll backend connections, per GOMAXPROCS
+	workersPerCPU = 16
+
+	// Tasks waiting for a worker before another goroutine is started for one
+	taskQueueSize = 1024
+
+	// Time allowed to read the rest of a frame once it starts arriving
+	frameReadWait = 10 * time.Second
+)
+
+// netpoller serves the epoll backend's connections. One goroutine waits for
+// any of them to become readable and a shared pool of workers reads and
+// writes them, so an idle connection holds no goroutine, stack or read buffer.
+type netpoller struct {
+	epoll  *epoll
+	tasks  chan func()
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	conns map[*netpollClient]struct{}
+	fds   map[int]*netpollClient // Connections watched by epoll
+}
+
+// newNetpoller starts the epoll backend's waiter, workers and pings
+func newNetpoller(logger *slog.Logger) (*netpoller, error) {
+	e, err := newEpoll()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &netpoller{
+		epoll:  e,
+		tasks:  make(chan func(), taskQueueSize),
+		logger: logger,
+		conns:  make(map[*netpollClient]struct{}),
+		fds:    make(map[int]*netpollClient),
+	}
+	for i := 0; i < workersPerCPU*runtime.GOMAXPROCS(0); i++ {
+		go p.work()
+	}
+	go p.wait()
+	go p.keepAlive()
+	return p, nil
+}
+
+// schedule runs task on a worker, or on its own goroutine if they are all
+// busy and the queue is full, rather than holding up the caller
+func (p *netpoller) schedule(task func()) {
+	select {
+	case p.tasks <- task:
+	default:
+		go task()
+	}
+}
+
+// work runs scheduled tasks
+func (p *netpoller) work() {
+	for task := range p.tasks {
+		task()
+	}
+}
+
+// wait schedules a read for each connection epoll reports readable
+func (p *netpoller) wait() {
+	var ready []int
+	for {
+		var err error
+		ready, err = p.epoll.wait(ready[:0])
+		if err != nil {
+			p.logger.Error("epoll wait failed, websocket connections are no longer read", "error", err)
+			return
+		}
+
+		p.mu.Lock()
+		for _, fd := range ready {
+			if c, ok := p.fds[fd]; ok {
+				p.schedule(c.read)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+// keepAlive pings every connection each pingPeriod, and closes those that
+// have sent nothing, pongs included, for pongWait
+func (p *netpoller) keepAlive() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	var conns []*netpollClient
+	for range ticker.C {
+		p.mu.Lock()
+		for c := range p.conns {
+			conns = append(conns, c)
+		}
+		p.mu.Unlock()
+
+		for _, c := range conns {
+			p.schedule(c.ping)
+		}
+		clear(conns)
+		conns = conns[:0]
+	}
+}
+
+// add starts serving c (caller must hold c.mu). Connections without a file
+// descriptor, such as TLS ones, are read by a goroutine of their own instead
+// of through epoll.
+func (p *netpoller) add(c *netpollClient) {
+	fd, ok := connFD(c.conn)
+	p.mu.Lock()
+	p.conns[c] = struct{}{}
+	if ok {
+		c.fd = fd
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		go c.readBlocking()
+		return
+	}
+	if c.ahead != nil {
+		// Messages read ahead during the upgrade will not wake epoll
+		p.schedule(c.read)
+		return
+	}
+	p.watch(c)
+}
+
+// watch has epoll report c when it is next readable. Only the worker that
+// last read c may call it.
+func (p *netpoller) watch(c *netpollClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.conns[c]; !ok {
+		return // Closed
+	}
+
+	var err error
+	if _, watched := p.fds[c.fd]; watched {
+		err = p.epoll.rearm(c.fd)
+	} else {
+		p.fds[c.fd] = c
+		err = p.epoll.add(c.fd)
+	}
+	if err != nil {
+		c.logger.Warn("failed to watch websocket connection", "error", err)
+		delete(p.fds, c.fd)
+		go c.Close()
+	}
+}
+
+// remove stops serving c, before its connection and file descriptor are closed
+func (p *netpoller) remove(c *netpollClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.conns, c)
+	if p.fds[c.fd] == c {
+		delete(p.fds, c.fd)
+		p.epoll.remove(c.fd)
+	}
+}
+
+// connFD returns the file descriptor of conn, if it has one to watch
+func connFD(conn net.Conn) (int, bool) {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return 0, false
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+
+	fd := -1
+	raw.Control(func(f uintptr) {
+		fd = int(f)
+	})
+	return fd, fd >= 0
+}
+
+// netpollClient is a WebSocket connection served by the epoll backend. It
+// behaves like Client, but is read by a pool worker when epoll reports data
+// and written by one when messages are queued. Messages are not compressed.
+type netpollClient struct {
+	conn     net.Conn
+	fd       int
+	poller   *netpoller
+	session  *app.GameSession
+	playerID string
+	connID   string
+	codec    Codec
+	locale   string
+	acks     bool
+	outbox   *Outbox
+
+	dispatcher *Dispatcher
+
+	// Read-only connection that is not a player
+	spectator bool
+
+	// Used by one worker at a time, as epoll reports a connection once until rearmed
+	reader  *wsutil.Reader
+	control wsutil.FrameHandlerFunc
+	ahead   *io.LimitedReader // Bytes read ahead during the upgrade, nil once consumed
+
+	// When anything, pongs included, was last read (Unix nanoseconds)
+	lastRead atomic.Int64
+
+	// Set while a flush is scheduled or running
+	flushing atomic.Bool
+
+	// Serializes frames written by flushes, pings and control replies
+	writeMu sync.Mutex
+
+	logger *slog.Logger
+	mu     sync.Mutex
+	closed bool
+	done   func() // Called once the connection has left the session
+}
+
+// newNetpollClient creates an epoll backend connection. br holds anything the
+// client sent right after the upgrade request.
+func newNetpollClient(conn net.Conn, br *bufio.Reader, poller *netpoller, session *app.GameSession, playerID, connID string, codec Codec, cfg config.WebSocketConfig, logger *slog.Logger) *netpollClient {
+	c := &netpollClient{
+		conn:     conn,
+		fd:       -1,
+		poller:   poller,
+		session:  session,
+		playerID: playerID,
+		connID:   connID,
+		codec:    codec,
+		outbox:   NewOutbox(cfg),
+		logger:   logger,
+	}
+	c.dispatcher = NewDispatcher(session, c)
+	c.lastRead.Store(time.Now().UnixNano())
+
+	source := io.Reader(conn)
+	if n := br.Buffered(); n > 0 {
+		c.ahead = &io.LimitedReader{R: br, N: int64(n)}
+		source = io.MultiReader(c.ahead, conn)
+	}
+	c.control = wsutil.ControlFrameHandler(frameWriter{c}, ws.StateServerSide)
+	c.reader = &wsutil.Reader{
+		Source:         source,
+		State:          ws.StateServerSide,
+		CheckUTF8:      true,
+		MaxFrameSize:   maxMessageSize,
+		OnIntermediate: c.control,
+	}
+	return c
+}
+
+// newNetpollSpectator creates a read-only epoll backend connection that
+// receives public events without joining the game
+func newNetpollSpectator(conn net.Conn, br *bufio.Reader, poller *netpoller, session *app.GameSession, connID string, codec Codec, cfg config.WebSocketConfig, logger *slog.Logger) *netpollClient {
+	c := newNetpollClient(conn, br, poller, session, "", connID, codec, cfg, logger)
+	c.spectator = true
+	c.dispatcher = NewSpectatorDispatcher(session, c)
+	return c
+}
+
+// GetPlayerID returns the player ID for this client
+func (c *netpollClient) GetPlayerID() string {
+	return c.playerID
+}
+
+// GetConnectionID returns the ID correlating this connection's logs and errors
+func (c *netpollClient) GetConnectionID() string {
+	return c.connID
+}
+
+// Send implements app.ClientConnection interface
+func (c *netpollClient) Send(message interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	data, pooled, err := encodePooled(c.codec, message)
+	if err != nil {
+		return err
+	}
+
+	dropped, err := c.outbox.push(outboxEntry{data: data, lowPriority: IsLowPriority(message), pooled: pooled})
+	if (err != nil || dropped) && pooled != nil {
+		putBuffer(pooled)
+	}
+	if err != nil {
+		c.logger.Warn("send buffer full, disconnecting slow client")
+		c.closeLocked()
+		return err
+	}
+	if dropped {
+		c.logger.Debug("send buffer full, low-priority message dropped")
+		return nil
+	}
+
+	if c.flushing.CompareAndSwap(false, true) {
+		c.poller.schedule(c.flush)
+	}
+	return nil
+}
+
+// Close implements app.ClientConnection interface
+func (c *netpollClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeLocked()
+}
+
+// closeLocked closes the connection and has it leave the session (caller
+// must hold mu). Leaving is asynchronous, as the session itself may be
+// closing the connection.
+func (c *netpollClient) closeLocked() error {
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	c.poller.remove(c)
+	err := c.conn.Close()
+	if c.done != nil {
+		go c.leave(c.done)
+	}
+	return err
+}
+
+// leave removes the closed connection from the session, like Client's read
+// pump does when it stops
+func (c *netpollClient) leave(done func()) {
+	if c.spectator {
+		c.session.RemoveSpectator(c.connID)
+	} else {
+		c.session.UnregisterClient(c.playerID)
+		c.session.DisconnectPlayer(c.playerID)
+	}
+	done()
+}
+
+// serve hands the connection to the poller, calling done once it has closed
+// and left the session. It returns without waiting.
+func (c *netpollClient) serve(done func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		go c.leave(done)
+		return
+	}
+	c.done = done
+	c.poller.add(c)
+}
+
+// refuse closes a connection that is not served, telling the client why
+func (c *netpollClient) refuse(code int, reason string) {
+	c.writeFrame(ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusCode(code), reason)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.conn.Close()
+}
+
+// getDispatcher returns the dispatcher handling the connection's messages
+func (c *netpollClient) getDispatcher() *Dispatcher {
+	return c.dispatcher
+}
+
+// read handles the messages epoll reported, then has it watch for more
+func (c *netpollClient) read() {
+	c.conn.SetReadDeadline(time.Now().Add(frameReadWait))
+	for {
+		if err := c.readFrame(); err != nil {
+			c.readFailed(err)
+			return
+		}
+		if c.ahead == nil || c.ahead.N == 0 {
+			c.ahead = nil
+			break
+		}
+	}
+	c.poller.watch(c)
+}
+
+// readBlocking reads a connection epoll cannot watch until it closes
+func (c *netpollClient) readBlocking() {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		if err := c.readFrame(); err != nil {
+			c.readFailed(err)
+			return
+		}
+	}
+}
+
+// readFrame reads one frame, replying to control frames and dispatching
+// the message a data frame starts
+func (c *netpollClient) readFrame() error {
+	hdr, err := c.reader.NextFrame()
+	if err != nil {
+		return err
+	}
+	c.lastRead.Store(time.Now().UnixNano())
+
+	if hdr.OpCode.IsControl() {
+		return c.control(hdr, c.reader)
+	}
+	if hdr.OpCode != ws.OpText && hdr.OpCode != ws.OpBinary {
+		return c.reader.Discard()
+	}
+
+	// A fragmented message continues in the frames that follow
+	data, err := io.ReadAll(io.LimitReader(c.reader, maxMessageSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxMessageSize {
+		return wsutil.ErrFrameTooLarge
+	}
+
+	c.dispatcher.Receive(data)
+	return nil
+}
+
+// readFailed closes the connection after a read error, telling the client
+// if its message was too big
+func (c *netpollClient) readFailed(err error) {
+	var closed wsutil.ClosedError
+	switch {
+	case errors.Is(err, wsutil.ErrFrameTooLarge):
+		c.writeFrame(ws.NewCloseFrame(ws.NewCloseFrameBody(ws.StatusMessageTooBig, "")))
+	case errors.As(err, &closed):
+		if closed.Code != ws.StatusNormalClosure && closed.Code != ws.StatusGoingAway {
+			c.logger.Debug("websocket closed", "code", closed.Code, "reason", closed.Reason)
+		}
+	case !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed):
+		c.logger.Debug("websocket read error", "error", err)
+	}
+	c.Close()
+}
+
+// flush writes queued messages until none are left
+func (c *netpollClient) flush() {
+	for {
+		batch := c.outbox.drain()
+		if len(batch) > 0 {
+			err := c.writeBatch(batch)
+			c.outbox.recycle(batch)
+			if err != nil {
+				c.Close()
+				return
+			}
+		}
+
+		// A message queued after the drain may have seen the flag still set
+		c.flushing.Store(false)
+		if c.outbox.len() == 0 || !c.flushing.CompareAndSwap(false, true) {
+			return
+		}
+	}
+}
+
+// writeBatch writes queued messages to the connection, one frame for a JSON
+// batch like Client, with a single write where the connection supports it
+func (c *netpollClient) writeBatch(batch []outboxEntry) error {
+	var bufs net.Buffers
+	if c.GetCodec().Encoding().IsBinary() {
+		for _, e := range batch {
+			bufs = append(bufs, frameHeader(ws.OpBinary, len(e.data)), e.data)
+		}
+	} else {
+		size := len(batch) - 1
+		for _, e := range batch {
+			size += len(e.data)
+		}
+		bufs = append(bufs, frameHeader(ws.OpText, size))
+		for i, e := range batch {
+			if i > 0 {
+				bufs = append(bufs, newline)
+			}
+			bufs = append(bufs, e.data)
+		}
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := bufs.WriteTo(c.conn)
+	return err
+}
+
+// ping closes the connection if it has gone quiet for pongWait, and pings it otherwise
+func (c *netpollClient) ping() {
+	if time.Since(time.Unix(0, c.lastRead.Load())) > pongWait {
+		c.logger.Debug("websocket timed out")
+		c.Close()
+		return
+	}
+	if err := c.writeFrame(ws.NewPingFrame(nil)); err != nil {
+		c.Close()
+	}
+}
+
+// writeFrame writes a single frame, such as a ping or close
+func (c *netpollClient) writeFrame(f ws.Frame) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return ws.WriteFrame(c.conn, f)
+}
+
+// frameWriter writes the replies to control frames between other frames
+type frameWriter struct {
+	c *netpollClient
+}
+
+func (w frameWriter) Write(p []byte) (int, error) {
+	w.c.writeMu.Lock()
+	defer w.c.writeMu.Unlock()
+	w.c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return w.c.conn.Write(p)
+}
+
+// frameHeader returns the header of an unfragmented server frame
+func frameHeader(op ws.OpCode, length int) []byte {
+	var b bytes.Buffer
+	b.Grow(ws.MaxHeaderSize)
+	ws.WriteHeader(&b, ws.Header{Fin: true, OpCode: op, Length: int64(length)})
+	return b.Bytes()
+}
+
+// GetCodec returns the codec negotiated for this connection
+func (c *netpollClient) GetCodec() Codec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.codec
+}
+
+// SetCodec switches the codec used for subsequent messages
+func (c *netpollClient) SetCodec(codec Codec) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.codec = codec
+}
+
+// GetLocale returns the language error messages are sent in
+func (c *netpollClient) GetLocale() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.locale
+}
+
+// SetLocale switches the language of subsequent error messages
+func (c *netpollClient) SetLocale(locale string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.locale = locale
+}
+
+// AcksEvents returns true if the client acknowledges critical events
+func (c *netpollClient) AcksEvents() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.acks
+}
+
+// SetAcks sets whether the client acknowledges critical events
+func (c *netpollClient) SetAcks(acks bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acks = acks
+}
@@ -0,0 +1,185 @@
+package ws
+
+import (
+	"errors"
+	"sync"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+// SlowClientPolicy decides what happens when a connection's send queue is full
+type SlowClientPolicy string
+
+const (
+	SlowClientDisconnect      SlowClientPolicy = "disconnect"        // Close the connection; the client resyncs on reconnect
+	SlowClientDropLowPriority SlowClientPolicy = "drop-low-priority" // Shed superseded vote progress updates, disconnect if that is not enough
+	SlowClientGrow            SlowClientPolicy = "grow"              // Double the queue up to MaxSendBufferSize, then disconnect
+)
+
+// IsValid returns true if p is a known policy
+func (p SlowClientPolicy) IsValid() bool {
+	return p == SlowClientDisconnect || p == SlowClientDropLowPriority || p == SlowClientGrow
+}
+
+// ErrSlowClient is returned by Outbox.Push when the message cannot be queued
+// and the connection must be closed instead of losing it
+var ErrSlowClient = errors.New("client is not keeping up")
+
+// outboxEntry is an encoded message waiting to be written
+type outboxEntry struct {
+	data        []byte
+	lowPriority bool
+
+	// Buffer holding data, returned to the pool once written (nil if not pooled)
+	pooled *[]byte
+}
+
+// Outbox queues encoded messages for a single connection. Critical messages
+// are never dropped: when one cannot be queued, Push fails with ErrSlowClient.
+type Outbox struct {
+	policy  SlowClientPolicy
+	limit   int
+	maxSize int
+
+	mu      sync.Mutex
+	entries []outboxEntry
+	ready   chan struct{}
+
+	// A drained batch kept for reuse once written
+	spare []outboxEntry
+}
+
+// NewOutbox creates an outbox sized and governed by cfg. Unknown policies
+// behave like drop-low-priority.
+func NewOutbox(cfg config.WebSocketConfig) *Outbox {
+	limit := cfg.SendBufferSize
+	if limit <= 0 {
+		limit = 256
+	}
+	maxSize := cfg.MaxSendBufferSize
+	if maxSize < limit {
+		maxSize = limit
+	}
+
+	return &Outbox{
+		policy:  SlowClientPolicy(cfg.SlowClientPolicy),
+		limit:   limit,
+		maxSize: maxSize,
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Push queues data. dropped is true if a low-priority message was shed instead.
+func (o *Outbox) Push(data []byte, lowPriority bool) (dropped bool, err error) {
+	return o.push(outboxEntry{data: data, lowPriority: lowPriority})
+}
+
+// push queues an entry. The caller keeps ownership of a pooled buffer that
+// was not queued.
+func (o *Outbox) push(entry outboxEntry) (dropped bool, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.entries) >= o.limit {
+		switch o.policy {
+		case SlowClientDisconnect:
+			return false, ErrSlowClient
+		case SlowClientGrow:
+			if o.limit >= o.maxSize {
+				return false, ErrSlowClient
+			}
+			o.limit = min(o.limit*2, o.maxSize)
+		default:
+			if entry.lowPriority {
+				return true, nil
+			}
+			o.shedLowPriority()
+			if len(o.entries) >= o.limit {
+				return false, ErrSlowClient
+			}
+		}
+	}
+
+	o.entries = append(o.entries, entry)
+
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+	return false, nil
+}
+
+// shedLowPriority removes queued low-priority messages (caller must hold lock)
+func (o *Outbox) shedLowPriority() {
+	kept := o.entries[:0]
+	for _, e := range o.entries {
+		if !e.lowPriority {
+			kept = append(kept, e)
+		} else if e.pooled != nil {
+			putBuffer(e.pooled)
+		}
+	}
+	clear(o.entries[len(kept):])
+	o.entries = kept
+}
+
+// Ready is signalled when messages are waiting to be written
+func (o *Outbox) Ready() <-chan struct{} {
+	return o.ready
+}
+
+// len returns the number of queued messages
+func (o *Outbox) len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Drain removes and returns all queued messages in order
+func (o *Outbox) Drain() [][]byte {
+	entries := o.drain()
+	batch := make([][]byte, len(entries))
+	for i, e := range entries {
+		batch[i] = e.data
+	}
+	return batch
+}
+
+// drain removes and returns all queued entries in order. Pass them to
+// recycle once written.
+func (o *Outbox) drain() []outboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := o.entries
+	o.entries, o.spare = o.spare, nil
+	return entries
+}
+
+// recycle releases the pooled buffers of written entries and keeps the
+// slice for a later batch
+func (o *Outbox) recycle(entries []outboxEntry) {
+	for _, e := range entries {
+		if e.pooled != nil {
+			putBuffer(e.pooled)
+		}
+	}
+	clear(entries)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.spare = entries[:0]
+}
+
+// IsLowPriority returns true if message may be shed for a slow client
+func IsLowPriority(message interface{}) bool {
+	switch m := message.(type) {
+	case *domain.GameEvent:
+		return m.IsLowPriority()
+	case *app.Frame:
+		return m.Event.IsLowPriority()
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// FieldError reports a client message payload that is malformed or has a
+// missing or invalid field. Field is empty when the payload as a whole is at
+// fault, e.g. it is not an object.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// clientPayload is a client message payload that checks its own fields once decoded
+type clientPayload interface {
+	validate() error
+}
+
+// decodePayload decodes a client message payload into p and validates it.
+// Unknown fields are ignored, so clients may send fields newer servers
+// understand. A missing payload decodes as an empty one, leaving validate to
+// report any required fields. Errors are *FieldError.
+func decodePayload(raw json.RawMessage, p clientPayload) error {
+	if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		if err := json.Unmarshal(raw, p); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) && typeErr.Field != "" {
+				return &FieldError{Field: typeErr.Field, Message: typeErr.Field + " must be " + describeKind(typeErr.Type)}
+			}
+			return &FieldError{Message: "Invalid payload"}
+		}
+	}
+	return p.validate()
+}
+
+// describeKind names the JSON value a field of type t takes, for error messages
+func describeKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func (p *HelloPayload) validate() error {
+	if p.ProtocolVersion == 0 {
+		return &FieldError{Field: "protocolVersion", Message: "Protocol version is required"}
+	}
+	return nil
+}
+
+func (p *JoinLobbyPayload) validate() error {
+	if p.Nickname == "" {
+		return &FieldError{Field: "nickname", Message: "Nickname is required"}
+	}
+	return nil
+}
+
+func (p *SubmitWordPayload) validate() error {
+	if p.Word == "" {
+		return &FieldError{Field: "word", Message: "Word is required"}
+	}
+	return nil
+}
+
+func (p *CastVotePayload) validate() error {
+	if p.TargetPlayerID == "" {
+		return &FieldError{Field: "targetPlayerId", Message: "Target player ID is required"}
+	}
+	return nil
+}
+
+func (p *AudienceVotePayload) validate() error {
+	if p.TargetPlayerID == "" {
+		return &FieldError{Field: "targetPlayerId", Message: "Target player ID is required"}
+	}
+	return nil
+}
+
+func (p *AckPayload) validate() error {
+	if p.Seq == 0 {
+		return &FieldError{Field: "seq", Message: "Sequence number is required"}
+	}
+	return nil
+}
+
+func (p *CreateInvitePayload) validate() error {
+	switch {
+	case p.TTLSeconds < 0:
+		return &FieldError{Field: "ttlSeconds", Message: "ttlSeconds and maxUses cannot be negative"}
+	case p.MaxUses < 0:
+		return &FieldError{Field: "maxUses", Message: "ttlSeconds and maxUses cannot be negative"}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package ws
+
+import (
+	"sync"
+
+	"imposter/internal/domain"
+)
+
+const (
+	// Capacity of new pooled message buffers, enough for most events
+	pooledBufferSize = 1024
+
+	// Larger buffers are not returned to the pool, so one big message does
+	// not keep its memory alive
+	maxPooledBufferSize = 64 * 1024
+)
+
+// bufferPool recycles the buffers frames are encoded into once written
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, pooledBufferSize)
+		return &b
+	},
+}
+
+// eventPool recycles the event copies codecs make to rewrite a message
+var eventPool = sync.Pool{
+	New: func() interface{} {
+		return new(domain.GameEvent)
+	},
+}
+
+// writeBufferPool shares write buffers between connections, so idle ones
+// do not each hold a buffer
+var writeBufferPool = &sync.Pool{}
+
+// getBuffer returns an empty buffer from the pool
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer returns a buffer to the pool once nothing refers to its contents
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBufferSize {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// getEvent returns a copy of event from the pool. Release it with putEvent.
+func getEvent(event *domain.GameEvent) *domain.GameEvent {
+	copied := eventPool.Get().(*domain.GameEvent)
+	*copied = *event
+	return copied
+}
+
+// putEvent clears an event copy and returns it to the pool
+func putEvent(event *domain.GameEvent) {
+	*event = domain.GameEvent{}
+	eventPool.Put(event)
+}
@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"fmt"
+	"testing"
+
+	"imposter/internal/app"
+	"imposter/internal/domain"
+)
+
+// BenchmarkEncodeFrame builds each recipient's copy of a frame in a pooled
+// buffer released once written, against a fresh buffer per recipient
+func BenchmarkEncodeFrame(b *testing.B) {
+	for _, encoding := range []Encoding{EncodingJSON, EncodingProtobuf, EncodingMessagePack} {
+		codec, err := CodecFor(ProtocolV1, encoding)
+		if err != nil {
+			b.Fatal(err)
+		}
+		frame := app.NewFrame(benchmarkEvent(10))
+
+		b.Run(fmt.Sprintf("%s/pooled", encoding), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := range b.N {
+				_, pooled, err := encodePooled(codec, frame.WithSeq(uint64(i+1)))
+				if err != nil {
+					b.Fatal(err)
+				}
+				putBuffer(pooled)
+			}
+		})
+
+		b.Run(fmt.Sprintf("%s/unpooled", encoding), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := range b.N {
+				if _, err := EncodeMessage(codec, frame.WithSeq(uint64(i+1))); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeDelta rewrites an event with its delta payload in a pooled
+// copy, against a new copy per encoding
+func BenchmarkEncodeDelta(b *testing.B) {
+	event := benchmarkEvent(10)
+	lobby := event.Payload.(*domain.LobbyUpdatePayload)
+	event.Delta = &domain.LobbyDeltaPayload{Changed: lobby.Players[:1], HostID: lobby.HostID, CanStart: lobby.CanStart}
+	codec := jsonCodecV1{}
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := (deltaCodec{codec}).Encode(event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			compact := *event
+			compact.Payload = event.Delta
+			if _, err := codec.Encode(&compact); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
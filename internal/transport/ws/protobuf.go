@@ -0,0 +1,148 @@
+package ws
+
+//go:generate protoc --go_out=../../.. --go_opt=module=imposter messages.proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"imposter/internal/transport/ws/wspb"
+)
+
+// envelopeSeq is the Envelope's seq field number (see messages.proto)
+const envelopeSeq protowire.Number = 6
+
+// envelopeMessages is the Envelope's oneof, with a field for each message
+// type named after it
+var envelopeMessages = (&wspb.Envelope{}).ProtoReflect().Descriptor().Oneofs().ByName("message")
+
+// payloadUnmarshal reads JSON payloads into their protobuf messages. Fields
+// the schema lacks are dropped rather than failing the message.
+var payloadUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// protobufCodecV1 is the binary protobuf codec for protocol version 1
+type protobufCodecV1 struct{}
+
+func (protobufCodecV1) Version() ProtocolVersion {
+	return ProtocolV1
+}
+
+func (protobufCodecV1) Encoding() Encoding {
+	return EncodingProtobuf
+}
+
+// Encode converts a server message or game event into an Envelope. The
+// message is first normalized through its JSON form and its payload read into
+// the message for its type, so fields keep exactly the same names and values
+// as in the JSON protocol.
+func (c protobufCodecV1) Encode(message interface{}) ([]byte, error) {
+	raw, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields struct {
+		Type      string          `json:"type"`
+		Payload   json.RawMessage `json:"payload"`
+		Timestamp string          `json:"timestamp"`
+		GameID    string          `json:"gameId"`
+		PlayerID  string          `json:"playerId"`
+		Seq       uint64          `json:"seq"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	field := envelopeMessages.Fields().ByName(protoreflect.Name(strings.ToLower(fields.Type)))
+	if field == nil {
+		return nil, fmt.Errorf("no protobuf message for %q", fields.Type)
+	}
+
+	envelope := &wspb.Envelope{
+		Timestamp: fields.Timestamp,
+		GameId:    fields.GameID,
+		PlayerId:  fields.PlayerID,
+	}
+	payload := envelope.ProtoReflect().NewField(field)
+	if len(fields.Payload) > 0 && string(fields.Payload) != "null" {
+		if err := payloadUnmarshal.Unmarshal(fields.Payload, payload.Message().Interface()); err != nil {
+			return nil, fmt.Errorf("%s payload: %w", fields.Type, err)
+		}
+	}
+	envelope.ProtoReflect().Set(field, payload)
+
+	data, err := proto.Marshal(envelope)
+	if err != nil || fields.Seq == 0 {
+		return data, err
+	}
+	return c.AppendSeq(nil, data, fields.Seq)
+}
+
+// AppendSeq adds the seq field, which Encode writes last, to an encoded Envelope
+func (protobufCodecV1) AppendSeq(dst, data []byte, seq uint64) ([]byte, error) {
+	dst = slices.Grow(dst, len(data)+protowire.SizeTag(envelopeSeq)+protowire.SizeVarint(seq))
+	dst = append(dst, data...)
+	dst = protowire.AppendTag(dst, envelopeSeq, protowire.VarintType)
+	return protowire.AppendVarint(dst, seq), nil
+}
+
+// Decode parses a client Envelope into the message with the payload the JSON
+// protocol would carry, ignoring unknown fields. Server messages decode as
+// their type alone, which the dispatcher rejects.
+func (protobufCodecV1) Decode(data []byte) (*ClientMessage, error) {
+	var envelope wspb.Envelope
+	if err := proto.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	var payload interface{}
+	switch m := envelope.Message.(type) {
+	case nil:
+		return nil, errors.New("envelope carries no message")
+	case *wspb.Envelope_Hello:
+		payload = &HelloPayload{
+			ProtocolVersion: ProtocolVersion(m.Hello.GetProtocolVersion()),
+			Locale:          m.Hello.GetLocale(),
+			Acks:            m.Hello.GetAcks(),
+		}
+	case *wspb.Envelope_JoinLobby:
+		payload = &JoinLobbyPayload{
+			Nickname: m.JoinLobby.GetNickname(),
+			Password: m.JoinLobby.GetPassword(),
+			Invite:   m.JoinLobby.GetInvite(),
+		}
+	case *wspb.Envelope_SubmitWord:
+		payload = &SubmitWordPayload{Word: m.SubmitWord.GetWord()}
+	case *wspb.Envelope_CastVote:
+		payload = &CastVotePayload{TargetPlayerID: m.CastVote.GetTargetPlayerId()}
+	case *wspb.Envelope_AudienceVote:
+		payload = &AudienceVotePayload{TargetPlayerID: m.AudienceVote.GetTargetPlayerId()}
+	case *wspb.Envelope_CreateInvite:
+		payload = &CreateInvitePayload{
+			TTLSeconds: int(m.CreateInvite.GetTtlSeconds()),
+			MaxUses:    int(m.CreateInvite.GetMaxUses()),
+		}
+	case *wspb.Envelope_Ack:
+		payload = &AckPayload{Seq: m.Ack.GetSeq()}
+	}
+
+	msg := &ClientMessage{
+		Type: MessageType(envelope.ProtoReflect().WhichOneof(envelopeMessages).Name()),
+	}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payload: %w", err)
+		}
+		msg.Payload = raw
+	}
+	return msg, nil
+}
@@ -0,0 +1,332 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"imposter/internal/domain"
+	"imposter/internal/transport/ws/wspb"
+)
+
+// serverMessages returns a server message or game event for every message in
+// the Envelope's oneof a server sends, with every payload field set
+func serverMessages() []interface{} {
+	at := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	players := []domain.PlayerInfo{
+		{ID: "p1", Nickname: "Ana", HasVoted: true, HasSubmitted: true, Status: domain.StatusConnected},
+		{ID: "p2", Nickname: "Bot", Status: domain.StatusDisconnected, IsBot: true},
+	}
+	submission := &domain.Submission{PlayerID: "p1", Nickname: "Ana", Word: "fruit", Order: 1, Timestamp: at}
+	lobby := &domain.LobbyUpdatePayload{Players: players, HostID: "p1", CanStart: true}
+	event := func(eventType domain.EventType, payload interface{}) *domain.GameEvent {
+		e := domain.NewPlayerEvent(eventType, "ABC123", "p1", payload)
+		e.Timestamp = at
+		return e
+	}
+	gameState := map[string]interface{}{
+		"phase":   "LOBBY",
+		"players": []interface{}{map[string]interface{}{"id": "p1", "nickname": "Ana"}},
+	}
+
+	return []interface{}{
+		NewServerMessage(MsgConnected, &ConnectedPayload{
+			PlayerID: "p1", GameID: "ABC123", ReconnectToken: "token", GameState: gameState, LastSeq: 7,
+			ProtocolVersion: ProtocolV2, SupportedVersions: []ProtocolVersion{ProtocolV1, ProtocolV2},
+			Encoding: EncodingProtobuf, SupportedEncodings: []Encoding{EncodingJSON, EncodingProtobuf},
+			ServerVersion: "v1.2.3",
+		}),
+		NewServerMessage(MsgResumed, &ResumedPayload{PlayerID: "p1", GameID: "ABC123", ReconnectToken: "token"}),
+		NewServerMessage(MsgSpectating, &SpectatingPayload{
+			GameID: "ABC123", GameState: gameState,
+			ProtocolVersion: ProtocolV1, SupportedVersions: []ProtocolVersion{ProtocolV1},
+			Encoding: EncodingProtobuf, SupportedEncodings: []Encoding{EncodingProtobuf},
+		}),
+		NewServerMessage(MsgReplaying, &ReplayingPayload{ReplayID: "r1", RoomCode: "ABC123", Rounds: 2, Events: 40, DurationMs: 90000, Speed: 1.5}),
+		NewServerMessage(MsgError, &ErrorPayload{
+			Code: ErrCodeInvalidMessage, Message: "Word is required", Field: "word", Retryable: true,
+			Details: map[string]interface{}{"limit": 3.0}, RequestID: "conn-1",
+		}),
+		NewServerMessage(MsgPong, nil),
+		NewServerMessage(MsgProtocol, &ProtocolPayload{
+			ProtocolVersion: ProtocolV2, SupportedVersions: []ProtocolVersion{ProtocolV1, ProtocolV2},
+			Encoding: EncodingProtobuf, SupportedEncodings: []Encoding{EncodingProtobuf},
+		}),
+		NewServerMessage(MsgInviteCreated, &InviteCreatedPayload{Token: "invite", ExpiresAt: at, MaxUses: 5}),
+		NewServerMessage(MsgSystemNotice, &SystemNoticePayload{Message: "Restarting soon", Severity: SeverityWarning}),
+
+		event(domain.EventPlayerJoined, lobby),
+		event(domain.EventPlayerLeft, &domain.LobbyDeltaPayload{Changed: players[:1], Removed: []string{"p3"}, HostID: "p1", CanStart: true}),
+		event(domain.EventPlayerReconnected, lobby),
+		event(domain.EventGameEnded, lobby),
+		event(domain.EventGameStarted, &domain.GameStartedPayload{RoundNumber: 1, PlayerCount: 4}),
+		event(domain.EventPhaseChanged, &domain.PhaseChangedPayload{From: domain.PhaseLobby, To: domain.PhaseRoleAssignment, Round: 1}),
+		event(domain.EventRolesAssigned, &domain.RoleAssignedPayload{Role: domain.RoleVilek, SecretWord: "apple"}),
+		event(domain.EventSubmissionPhaseStarted, &domain.SubmissionPhasePayload{CurrentPlayerID: "p1", PlayerOrder: players, Submissions: []*domain.Submission{submission}}),
+		event(domain.EventSubmissionMade, &domain.SubmissionUpdatePayload{Submissions: []*domain.Submission{submission}, CurrentPlayerID: "p2", IsComplete: true}),
+		event(domain.EventSubmissionMade, &domain.SubmissionDeltaPayload{Added: submission, CurrentPlayerID: "p2", IsComplete: true}),
+		event(domain.EventVotingStarted, &domain.VotingPhasePayload{RemainingSeconds: 20, Deadline: at, Players: players, Candidates: []string{"p1", "p2"}}),
+		event(domain.EventVotingCountdown, &domain.VotingCountdownPayload{RemainingSeconds: 10, Deadline: at}),
+		event(domain.EventVoteCast, &domain.VoteUpdatePayload{VotedCount: 1, TotalPlayers: 2}),
+		event(domain.EventRoundEnded, &domain.RoundResultsPayload{
+			Votes:      []domain.VoteResult{{PlayerID: "p2", Nickname: "Bot", VoteCount: 1, VotedBy: []string{"Ana"}, IsImposter: true}},
+			ImposterID: "p2", ImposterIDs: []string{"p2", "p3"}, Winner: domain.RoleVilek, SecretWord: "apple",
+			AccusedID: "p2", Tied: []string{"p1", "p2"},
+			Audience: &domain.AudienceSuspicion{Voters: 4, Players: []domain.AudienceSuspect{{PlayerID: "p2", Votes: 3, Share: 0.75}}},
+		}),
+		event(domain.EventRoomExpiring, &domain.RoomExpiringPayload{ExpiresAt: at}),
+		event(domain.EventCheatSuspected, &domain.CheatSuspectedPayload{
+			Kind: domain.SuspicionSharedClue, Round: 2, PlayerIDs: []string{"p1", "p2"}, Nicknames: []string{"Ana", "Bot"}, Detail: "same clue",
+		}),
+		event(domain.EventTournamentUpdate, &domain.TournamentUpdatePayload{
+			TournamentID: "t1", Name: "Cup", Change: domain.TournamentMatchEnded, Stage: 2, RoomCode: "ABC123", WinnerID: "p1",
+			Standings: []domain.TournamentStanding{{Rank: 1, PlayerID: "p1", Nickname: "Ana", Points: 9, Eliminated: true}},
+		}),
+	}
+}
+
+// normalize drops the zero values proto3 leaves out and renders numbers as
+// strings, since protojson writes 64-bit integers as strings
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{})
+		for key, value := range v {
+			if value = normalize(value); value != nil {
+				out[key] = value
+			}
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return out
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = normalize(value)
+		}
+		return out
+	case float64:
+		if v == 0 {
+			return nil
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+	case bool:
+		if !v {
+			return nil
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// decodeJSON decodes data into a generic value
+func decodeJSON(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("json.Unmarshal(%s): %v", data, err)
+	}
+	return v
+}
+
+func TestProtobufServerMessagesRoundTrip(t *testing.T) {
+	codec := protobufCodecV1{}
+	sent := make(map[string]bool)
+
+	for _, message := range serverMessages() {
+		raw, err := json.Marshal(message)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		var want struct {
+			Type      string          `json:"type"`
+			Payload   json.RawMessage `json:"payload"`
+			Timestamp string          `json:"timestamp"`
+			GameID    string          `json:"gameId"`
+			PlayerID  string          `json:"playerId"`
+		}
+		if err := json.Unmarshal(raw, &want); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+
+		t.Run(want.Type, func(t *testing.T) {
+			data, err := codec.Encode(message)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			var envelope wspb.Envelope
+			if err := proto.Unmarshal(data, &envelope); err != nil {
+				t.Fatalf("proto.Unmarshal: %v", err)
+			}
+
+			field := envelope.ProtoReflect().WhichOneof(envelopeMessages)
+			if field == nil {
+				t.Fatalf("envelope carries no message")
+			}
+			sent[string(field.Name())] = true
+			if got := string(field.Name()); got != strings.ToLower(want.Type) {
+				t.Errorf("envelope carries %s, want %s", got, strings.ToLower(want.Type))
+			}
+			if envelope.Timestamp != want.Timestamp || envelope.GameId != want.GameID || envelope.PlayerId != want.PlayerID {
+				t.Errorf("envelope = %q %q %q, want %q %q %q",
+					envelope.Timestamp, envelope.GameId, envelope.PlayerId, want.Timestamp, want.GameID, want.PlayerID)
+			}
+
+			payload, err := protojson.Marshal(envelope.ProtoReflect().Get(field).Message().Interface())
+			if err != nil {
+				t.Fatalf("protojson.Marshal: %v", err)
+			}
+			got := normalize(decodeJSON(t, payload))
+			var expected interface{}
+			if len(want.Payload) > 0 {
+				expected = normalize(decodeJSON(t, want.Payload))
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("payload = %s\nwant %s", payload, want.Payload)
+			}
+		})
+	}
+
+	// Every server message in the schema is covered
+	fields := envelopeMessages.Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if field.Number() >= 30 && !sent[string(field.Name())] {
+			t.Errorf("no test message for %s", field.Name())
+		}
+	}
+}
+
+func TestProtobufClientMessagesRoundTrip(t *testing.T) {
+	tests := []struct {
+		envelope *wspb.Envelope
+		want     MessageType
+		payload  clientPayload
+		expected clientPayload
+	}{
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_Hello{Hello: &wspb.Hello{ProtocolVersion: 2, Locale: "es", Acks: true}}},
+			MsgHello, &HelloPayload{}, &HelloPayload{ProtocolVersion: ProtocolV2, Locale: "es", Acks: true},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_JoinLobby{JoinLobby: &wspb.JoinLobby{Nickname: "Ana <3", Password: "secret", Invite: "inv"}}},
+			MsgJoinLobby, &JoinLobbyPayload{}, &JoinLobbyPayload{Nickname: "Ana <3", Password: "secret", Invite: "inv"},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_SubmitWord{SubmitWord: &wspb.SubmitWord{Word: "fruit"}}},
+			MsgSubmitWord, &SubmitWordPayload{}, &SubmitWordPayload{Word: "fruit"},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_CastVote{CastVote: &wspb.CastVote{TargetPlayerId: "p2"}}},
+			MsgCastVote, &CastVotePayload{}, &CastVotePayload{TargetPlayerID: "p2"},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_AudienceVote{AudienceVote: &wspb.AudienceVote{TargetPlayerId: "p2"}}},
+			MsgAudienceVote, &AudienceVotePayload{}, &AudienceVotePayload{TargetPlayerID: "p2"},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_CreateInvite{CreateInvite: &wspb.CreateInvite{TtlSeconds: 600, MaxUses: 3}}},
+			MsgCreateInvite, &CreateInvitePayload{}, &CreateInvitePayload{TTLSeconds: 600, MaxUses: 3},
+		},
+		{
+			&wspb.Envelope{Message: &wspb.Envelope_Ack{Ack: &wspb.Ack{Seq: 1 << 40}}},
+			MsgAck, &AckPayload{}, &AckPayload{Seq: 1 << 40},
+		},
+		{&wspb.Envelope{Message: &wspb.Envelope_StartGame{StartGame: &wspb.StartGame{}}}, MsgStartGame, nil, nil},
+		{&wspb.Envelope{Message: &wspb.Envelope_RequestNewRound{RequestNewRound: &wspb.RequestNewRound{}}}, MsgRequestNewRound, nil, nil},
+		{&wspb.Envelope{Message: &wspb.Envelope_AddBot{AddBot: &wspb.AddBot{}}}, MsgAddBot, nil, nil},
+		{&wspb.Envelope{Message: &wspb.Envelope_Ping{Ping: &wspb.Ping{}}}, MsgPing, nil, nil},
+	}
+
+	codec := protobufCodecV1{}
+	received := make(map[MessageType]bool)
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			data, err := proto.Marshal(tt.envelope)
+			if err != nil {
+				t.Fatalf("proto.Marshal: %v", err)
+			}
+			msg, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			received[msg.Type] = true
+			if msg.Type != tt.want {
+				t.Errorf("type = %s, want %s", msg.Type, tt.want)
+			}
+			if tt.payload == nil {
+				if msg.Payload != nil {
+					t.Errorf("payload = %s, want none", msg.Payload)
+				}
+				return
+			}
+			if err := decodePayload(msg.Payload, tt.payload); err != nil {
+				t.Fatalf("decodePayload: %v", err)
+			}
+			if !reflect.DeepEqual(tt.payload, tt.expected) {
+				t.Errorf("payload = %+v, want %+v", tt.payload, tt.expected)
+			}
+		})
+	}
+
+	// Every client message in the schema is covered
+	fields := envelopeMessages.Fields()
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if field.Number() < 30 && !received[MessageType(field.Name())] {
+			t.Errorf("no test message for %s", field.Name())
+		}
+	}
+}
+
+func TestProtobufDecodeRejectsEmptyEnvelope(t *testing.T) {
+	if _, err := (protobufCodecV1{}).Decode(nil); err == nil {
+		t.Errorf("Decode of an empty envelope succeeded")
+	}
+}
+
+func TestProtobufAppendSeqMatchesEncode(t *testing.T) {
+	codec := protobufCodecV1{}
+	event := domain.NewEvent(domain.EventVoteCast, "ABC123", &domain.VoteUpdatePayload{VotedCount: 1, TotalPlayers: 3})
+
+	unsequenced, err := codec.Encode(event)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	appended, err := codec.AppendSeq(nil, unsequenced, 42)
+	if err != nil {
+		t.Fatalf("AppendSeq: %v", err)
+	}
+	sequenced, err := codec.Encode(event.WithSeq(42))
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.Equal(appended, sequenced) {
+		t.Errorf("AppendSeq = %x, want %x", appended, sequenced)
+	}
+
+	var envelope wspb.Envelope
+	if err := proto.Unmarshal(appended, &envelope); err != nil {
+		t.Fatalf("proto.Unmarshal: %v", err)
+	}
+	if envelope.Seq != 42 || envelope.GetVoteCast().GetTotalPlayers() != 3 {
+		t.Errorf("envelope seq %d, total players %d, want 42 and 3", envelope.Seq, envelope.GetVoteCast().GetTotalPlayers())
+	}
+}
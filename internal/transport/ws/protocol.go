@@ -0,0 +1,190 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersion identifies a revision of the WebSocket message protocol
+type ProtocolVersion int
+
+const (
+	// ProtocolV1 is the original JSON message protocol
+	ProtocolV1 ProtocolVersion = 1
+
+	// ProtocolV2 sends lobby and submission updates as deltas after the
+	// initial snapshot instead of repeating the full lists
+	ProtocolV2 ProtocolVersion = 2
+
+	// DefaultProtocolVersion is used when the client does not request a version
+	DefaultProtocolVersion = ProtocolV1
+)
+
+// Encoding identifies the wire format used for WebSocket frames
+type Encoding string
+
+const (
+	EncodingJSON        Encoding = "json"     // Text frames, newline-batched
+	EncodingProtobuf    Encoding = "protobuf" // Binary frames, one message per frame
+	EncodingMessagePack Encoding = "msgpack"  // Binary frames, one message per frame
+
+	// DefaultEncoding is used when the client does not request an encoding
+	DefaultEncoding = EncodingJSON
+)
+
+// IsBinary returns true if messages in this encoding are sent as binary frames
+func (e Encoding) IsBinary() bool {
+	return e != EncodingJSON
+}
+
+// Protocol errors
+var (
+	ErrUnsupportedProtocol = errors.New("unsupported protocol version")
+	ErrUnsupportedEncoding = errors.New("unsupported encoding")
+)
+
+// Codec encodes server messages and decodes client messages for one protocol version
+type Codec interface {
+	Version() ProtocolVersion
+	Encoding() Encoding
+	Encode(message interface{}) ([]byte, error)
+	Decode(data []byte) (*ClientMessage, error)
+}
+
+// codecKey identifies a codec by protocol version and wire encoding
+type codecKey struct {
+	version  ProtocolVersion
+	encoding Encoding
+}
+
+// codecs maps each supported protocol version and encoding to its codec
+var codecs = map[codecKey]Codec{
+	{ProtocolV1, EncodingJSON}:        jsonCodecV1{},
+	{ProtocolV1, EncodingProtobuf}:    protobufCodecV1{},
+	{ProtocolV1, EncodingMessagePack}: msgpackCodecV1{},
+	{ProtocolV2, EncodingJSON}:        deltaCodec{jsonCodecV1{}},
+	{ProtocolV2, EncodingProtobuf}:    deltaCodec{protobufCodecV1{}},
+	{ProtocolV2, EncodingMessagePack}: deltaCodec{msgpackCodecV1{}},
+}
+
+// SupportedProtocolVersions returns all protocol versions the server speaks, oldest first
+func SupportedProtocolVersions() []ProtocolVersion {
+	seen := make(map[ProtocolVersion]bool)
+	versions := make([]ProtocolVersion, 0, len(codecs))
+	for key := range codecs {
+		if !seen[key.version] {
+			seen[key.version] = true
+			versions = append(versions, key.version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// SupportedEncodings returns all wire encodings the server speaks, sorted by name
+func SupportedEncodings() []Encoding {
+	seen := make(map[Encoding]bool)
+	encodings := make([]Encoding, 0, len(codecs))
+	for key := range codecs {
+		if !seen[key.encoding] {
+			seen[key.encoding] = true
+			encodings = append(encodings, key.encoding)
+		}
+	}
+	sort.Slice(encodings, func(i, j int) bool { return encodings[i] < encodings[j] })
+	return encodings
+}
+
+// CodecFor returns the codec for the given protocol version and encoding
+func CodecFor(version ProtocolVersion, encoding Encoding) (Codec, error) {
+	codec, ok := codecs[codecKey{version, encoding}]
+	if ok {
+		return codec, nil
+	}
+
+	// Distinguish an unknown version from an unknown encoding
+	for key := range codecs {
+		if key.version == version {
+			return nil, ErrUnsupportedEncoding
+		}
+	}
+	return nil, ErrUnsupportedProtocol
+}
+
+// NegotiateCodec resolves the codec for raw protocolVersion and encoding values.
+// Empty values select the defaults for backwards compatibility.
+func NegotiateCodec(rawVersion, rawEncoding string) (Codec, error) {
+	version := DefaultProtocolVersion
+	if rawVersion != "" {
+		v, err := strconv.Atoi(rawVersion)
+		if err != nil {
+			return nil, ErrUnsupportedProtocol
+		}
+		version = ProtocolVersion(v)
+	}
+
+	encoding := DefaultEncoding
+	if rawEncoding != "" {
+		encoding = Encoding(strings.ToLower(rawEncoding))
+	}
+
+	return CodecFor(version, encoding)
+}
+
+// jsonCodecV1 is the JSON text codec for protocol version 1
+type jsonCodecV1 struct{}
+
+func (jsonCodecV1) Version() ProtocolVersion {
+	return ProtocolV1
+}
+
+func (jsonCodecV1) Encoding() Encoding {
+	return EncodingJSON
+}
+
+func (jsonCodecV1) Encode(message interface{}) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+// AppendSeq adds the seq field, which json.Marshal writes last, to an encoded event
+func (jsonCodecV1) AppendSeq(dst, data []byte, seq uint64) ([]byte, error) {
+	if len(data) < 2 || data[len(data)-1] != '}' {
+		return nil, errUnsequenceable
+	}
+
+	dst = slices.Grow(dst, len(data)+len(`,"seq":}`)+20)
+	dst = append(dst, data[:len(data)-1]...)
+	dst = append(dst, `,"seq":`...)
+	dst = strconv.AppendUint(dst, seq, 10)
+	return append(dst, '}'), nil
+}
+
+func (jsonCodecV1) Decode(data []byte) (*ClientMessage, error) {
+	var msg ClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// formatVersions renders protocol versions as a comma-separated list
+func formatVersions(versions []ProtocolVersion) string {
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatEncodings renders encodings as a comma-separated list
+func formatEncodings(encodings []Encoding) string {
+	parts := make([]string, len(encodings))
+	for i, e := range encodings {
+		parts[i] = string(e)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,116 @@
+package ws
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"imposter/internal/app"
+)
+
+// Playback speeds a replay may be watched at
+const (
+	minReplaySpeed = 0.25
+	maxReplaySpeed = 16
+)
+
+// maxReplayGap caps the pause between two replayed events, so stretches where
+// nothing happened, like a lobby waiting for players, are skipped over
+const maxReplayGap = 5 * time.Second
+
+// Replay handles read-only WebSocket connections that play a recorded game
+// back: a replaying message, then the game's public events with their
+// original timing, then a normal close. ?speed= plays it faster or slower.
+// Replays are always sent as protocol 1 JSON, as they were recorded.
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	if !h.acquire() {
+		h.rejectOverloaded(w, r, "SERVER_AT_CAPACITY", "Too many connections right now, please try again shortly")
+		return
+	}
+	defer h.release()
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		httpError(w, r, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	speed := 1.0
+	if raw := r.URL.Query().Get("speed"); raw != "" {
+		var err error
+		speed, err = strconv.ParseFloat(raw, 64)
+		if err != nil || speed < minReplaySpeed || speed > maxReplaySpeed {
+			httpError(w, r, "speed must be between 0.25 and 16", http.StatusBadRequest)
+			return
+		}
+	}
+
+	replay, err := h.hub.LoadReplay(r.Context(), id)
+	if err == app.ErrReplayNotFound {
+		httpError(w, r, "Replay not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to load replay", "replayID", id, "error", err)
+		httpError(w, r, "Failed to load replay", http.StatusInternalServerError)
+		return
+	}
+
+	conn, ok := h.upgrade(w, r)
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	logger := h.logger.With("requestID", connectionID(r), "replayID", id, "roomCode", replay.RoomCode)
+	logger.Info("replay connected", "events", len(replay.Events), "speed", speed)
+
+	// Nothing is read, but reading notices the viewer leaving
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		conn.SetReadLimit(maxMessageSize)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(message interface{}) bool {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		return conn.WriteJSON(message) == nil
+	}
+
+	if !write(NewServerMessage(MsgReplaying, &ReplayingPayload{
+		ReplayID:   replay.ID,
+		RoomCode:   replay.RoomCode,
+		Rounds:     replay.Rounds,
+		Events:     len(replay.Events),
+		DurationMs: replay.Duration().Milliseconds(),
+		Speed:      speed,
+	})) {
+		return
+	}
+
+	for i, event := range replay.Events {
+		if i > 0 {
+			gap := min(event.Timestamp.Sub(replay.Events[i-1].Timestamp), maxReplayGap)
+			select {
+			case <-stopped:
+				return
+			case <-time.After(time.Duration(float64(gap) / speed)):
+			}
+		}
+
+		if !write(event) {
+			return
+		}
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "replay finished"))
+	logger.Debug("replay finished")
+}
@@ -0,0 +1,225 @@
+// Package tsgen writes TypeScript declarations for Go types, following their
+// json struct tags, so the web frontend and other clients can check
+// themselves against the structs the server encodes and decodes.
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Header starts every generated file
+const Header = "// Code generated by \"imposter typescript\". DO NOT EDIT.\n"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generator collects Go types and declares them in TypeScript. Types a
+// declared type refers to are declared too. Named types keep their Go name,
+// prefixed with their package's when two packages use the same one.
+type Generator struct {
+	module string // Import path prefix of the packages whose named types are declared
+	names  map[reflect.Type]string
+	taken  map[string]bool
+	order  []reflect.Type
+}
+
+// New creates an empty generator for the types of a module. Named types of
+// other modules and the standard library, such as time.Duration, are written
+// as what they encode to instead of being declared.
+func New(module string) *Generator {
+	return &Generator{
+		module: module,
+		names:  make(map[reflect.Type]string),
+		taken:  make(map[string]bool),
+	}
+}
+
+// Add declares the types of values, which are structs, pointers to structs
+// or named types such as domain.Role
+func (g *Generator) Add(values ...interface{}) {
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		g.typeName(t)
+	}
+}
+
+// WriteTo writes the declarations in the order their types were added
+func (g *Generator) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	b.WriteString(Header)
+
+	// Declaring a type may reach new ones, which are appended to order
+	for i := 0; i < len(g.order); i++ {
+		t := g.order[i]
+		b.WriteByte('\n')
+		if t.Kind() != reflect.Struct {
+			fmt.Fprintf(&b, "export type %s = %s;\n", g.names[t], g.basicType(t))
+			continue
+		}
+
+		fmt.Fprintf(&b, "export interface %s {\n", g.names[t])
+		g.writeFields(&b, t)
+		b.WriteString("}\n")
+	}
+
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// writeFields writes the fields of struct t as encoding/json would encode them
+func (g *Generator) writeFields(b *bytes.Buffer, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Untagged embedded structs have their fields promoted
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.writeFields(b, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		optional := strings.Contains(","+opts+",", ",omitempty,")
+		tsType := g.tsType(fieldType)
+		if strings.Contains(","+opts+",", ",string,") {
+			tsType = "string"
+		} else if fieldType.Kind() == reflect.Pointer && !optional {
+			tsType += " | null"
+		}
+
+		b.WriteString("    ")
+		b.WriteString(quoteName(name))
+		if optional {
+			b.WriteByte('?')
+		}
+		fmt.Fprintf(b, ": %s;\n", tsType)
+	}
+}
+
+// tsType returns the TypeScript type for values of t
+func (g *Generator) tsType(t reflect.Type) string {
+	switch {
+	case t == timeType:
+		return "string" // RFC 3339
+	case t == rawMessageType:
+		return "unknown"
+	case t.Kind() == reflect.Pointer:
+		return g.tsType(t.Elem())
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return "unknown" // Encodes itself in a way reflection cannot see
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			var b bytes.Buffer
+			b.WriteString("{\n")
+			g.writeFields(&b, t)
+			b.WriteString("}")
+			return b.String()
+		}
+		return g.typeName(t)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // Base64
+		}
+		return arrayOf(g.tsType(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("Record<string, %s>", g.tsType(t.Elem()))
+	case reflect.Interface:
+		return "unknown"
+	}
+
+	// Named basic types of the module, such as domain.Role, are declared as
+	// aliases; others, such as time.Duration, are written as their kind
+	if t.Name() != "" && strings.HasPrefix(t.PkgPath()+"/", g.module+"/") {
+		return g.typeName(t)
+	}
+	return g.basicType(t)
+}
+
+// basicType returns the TypeScript type for a type that is not a struct,
+// array, slice or map
+func (g *Generator) basicType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Pointer:
+		return g.tsType(t)
+	default:
+		return "unknown"
+	}
+}
+
+// typeName returns the declared name of t, a struct or named type, queueing
+// its declaration the first time it is seen
+func (g *Generator) typeName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	if t.Name() == "" {
+		return g.basicType(t)
+	}
+
+	name := t.Name()
+	if g.taken[name] {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = string(unicode.ToUpper(rune(pkg[0]))) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.taken[name] = true
+	g.order = append(g.order, t)
+	return name
+}
+
+// arrayOf returns the TypeScript array type of elem
+func arrayOf(elem string) string {
+	if strings.ContainsAny(elem, " |<") {
+		return "Array<" + elem + ">"
+	}
+	return elem + "[]"
+}
+
+// quoteName quotes a property name that is not a valid identifier
+func quoteName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
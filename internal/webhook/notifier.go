@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+const (
+	// queueSize is how many deliveries to a URL may be pending before new ones
+	// to it are dropped
+	queueSize = 256
+
+	// requestTimeout bounds a single delivery attempt
+	requestTimeout = 10 * time.Second
+
+	// initialBackoff is the delay before the first retry; it doubles on each attempt
+	initialBackoff = 1 * time.Second
+
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body
+	SignatureHeader = "X-Imposter-Signature"
+)
+
+// lifecycleEvents are the event types forwarded to webhooks
+var lifecycleEvents = map[domain.EventType]bool{
+	domain.EventGameCreated: true,
+	domain.EventGameStarted: true,
+	domain.EventRoundEnded:  true,
+	domain.EventGameDeleted: true,
+}
+
+// delivery is a single event to be POSTed to a single URL
+type delivery struct {
+	id    string
+	url   string
+	event *domain.GameEvent
+	body  []byte
+}
+
+// Notifier POSTs game lifecycle events to the configured webhook URLs.
+// Deliveries are signed with HMAC-SHA256 and retried with exponential backoff.
+// Each URL has its own queue and worker, so a slow or dead endpoint only
+// holds up its own deliveries.
+type Notifier struct {
+	endpoints  []*endpoint
+	secret     []byte
+	maxRetries int
+	client     *http.Client
+	logger     *slog.Logger
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// endpoint is a webhook URL and its pending deliveries
+type endpoint struct {
+	url   string
+	queue chan delivery
+}
+
+// NewNotifier creates a notifier for the configured URLs and starts a
+// delivery worker for each
+func NewNotifier(cfg config.WebhookConfig, logger *slog.Logger) *Notifier {
+	n := &Notifier{
+		secret:     []byte(cfg.Secret),
+		maxRetries: cfg.MaxRetries,
+		client:     &http.Client{Timeout: requestTimeout},
+		logger:     logger,
+		done:       make(chan struct{}),
+	}
+
+	for _, url := range cfg.URLs {
+		e := &endpoint{url: url, queue: make(chan delivery, queueSize)}
+		n.endpoints = append(n.endpoints, e)
+
+		n.wg.Add(1)
+		go n.worker(e)
+	}
+
+	return n
+}
+
+// URLs returns the webhook URLs
+func (n *Notifier) URLs() []string {
+	urls := make([]string, len(n.endpoints))
+	for i, e := range n.endpoints {
+		urls[i] = e.url
+	}
+	return urls
+}
+
+// Handle queues deliveries for lifecycle events. It is an app.EventHandler.
+func (n *Notifier) Handle(event *domain.GameEvent) {
+	if !lifecycleEvents[event.Type] {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error("failed to encode webhook event", "type", event.Type, "error", err)
+		return
+	}
+
+	for _, e := range n.endpoints {
+		d := delivery{
+			id:    uuid.New().String(),
+			url:   e.url,
+			event: event,
+			body:  body,
+		}
+
+		select {
+		case e.queue <- d:
+		default:
+			n.logger.Warn("webhook queue full, dropping delivery", "url", e.url, "type", event.Type)
+		}
+	}
+}
+
+// Close stops the delivery workers, abandoning pending deliveries
+func (n *Notifier) Close() {
+	close(n.done)
+	n.wg.Wait()
+}
+
+// worker delivers an endpoint's queued events one at a time
+func (n *Notifier) worker(e *endpoint) {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.done:
+			return
+		case d := <-e.queue:
+			n.deliver(d)
+		}
+	}
+}
+
+// deliver POSTs a delivery, retrying on network errors, 429 and 5xx responses
+func (n *Notifier) deliver(d delivery) {
+	backoff := initialBackoff
+
+	for attempt := 0; ; attempt++ {
+		retryable, err := n.post(d)
+		if err == nil {
+			n.logger.Debug("webhook delivered", "url", d.url, "type", d.event.Type, "deliveryID", d.id)
+			return
+		}
+
+		if !retryable || attempt >= n.maxRetries {
+			n.logger.Warn("webhook delivery failed",
+				"url", d.url,
+				"type", d.event.Type,
+				"deliveryID", d.id,
+				"attempts", attempt+1,
+				"error", err,
+			)
+			return
+		}
+
+		select {
+		case <-n.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt
+func (n *Notifier) post(d delivery) (retryable bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Imposter-Event", string(d.event.Type))
+	req.Header.Set("X-Imposter-Delivery", d.id)
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.secret, d.body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, &StatusError{StatusCode: resp.StatusCode}
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body using secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// StatusError is returned when a webhook endpoint responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.StatusCode)
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+func TestNotifierSlowEndpointDoesNotHoldUpOthers(t *testing.T) {
+	stuck := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stuck
+	}))
+	defer slow.Close()
+
+	delivered := make(chan string, 4)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- r.Header.Get("X-Imposter-Event")
+	}))
+	defer fast.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	n := NewNotifier(config.WebhookConfig{URLs: []string{slow.URL, fast.URL}}, logger)
+	defer n.Close()
+	defer close(stuck)
+
+	n.Handle(domain.NewEvent(domain.EventGameCreated, "ABC123", nil))
+	n.Handle(domain.NewEvent(domain.EventGameDeleted, "ABC123", nil))
+
+	for _, want := range []domain.EventType{domain.EventGameCreated, domain.EventGameDeleted} {
+		select {
+		case got := <-delivered:
+			if got != string(want) {
+				t.Errorf("delivered %s, want %s", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s was not delivered while another endpoint hung", want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package wordgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"imposter/internal/app"
+	"imposter/internal/config"
+	"imposter/internal/domain"
+)
+
+// seenLimit is how many generated words are remembered, as a multiple of the
+// cache size, so later batches do not repeat them
+const seenLimit = 10
+
+// validWord matches words fit to be secret words: one plain lowercase word
+var validWord = regexp.MustCompile(`^[a-z]{3,16}$`)
+
+// Generator is an app.WordProvider that asks an OpenAI-compatible chat
+// completions API for secret words. Words are fetched in batches ahead of
+// time and screened before use, so rounds never wait on the API; while none
+// are ready, words come from the fallback provider.
+type Generator struct {
+	cfg      config.LLMWordsConfig
+	client   *http.Client
+	fallback app.WordProvider
+	blocked  map[string]bool
+	logger   *slog.Logger
+
+	mu        sync.Mutex
+	cache     []string        // Screened words not yet used
+	seen      map[string]bool // Words generated before, not to be cached again
+	refilling bool
+
+	ctx    context.Context // Canceled by Close to abandon requests
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewGenerator creates a generator and starts filling its cache. Words in
+// blocklist are never used.
+func NewGenerator(cfg config.LLMWordsConfig, blocklist []string, fallback app.WordProvider, logger *slog.Logger) *Generator {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Generator{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		fallback: fallback,
+		blocked:  make(map[string]bool, len(blocklist)),
+		logger:   logger,
+		seen:     make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, word := range blocklist {
+		g.blocked[strings.ToLower(word)] = true
+	}
+
+	g.mu.Lock()
+	g.refill()
+	g.mu.Unlock()
+
+	return g
+}
+
+// NextWord returns a cached word not in used, or one from the fallback
+// provider if none is ready. It never waits for the API. Generated words come
+// in the order the API gave them, so only fallback words follow rng.
+func (g *Generator) NextWord(rng domain.Rand, used []string) (string, error) {
+	excluded := make(map[string]bool, len(used))
+	for _, w := range used {
+		excluded[w] = true
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	word := ""
+	for i, cached := range g.cache {
+		if !excluded[cached] {
+			word = cached
+			g.cache = append(g.cache[:i], g.cache[i+1:]...)
+			break
+		}
+	}
+
+	if len(g.cache) < g.cfg.CacheSize/2 || word == "" {
+		g.refill()
+	}
+
+	if word == "" {
+		g.logger.Debug("no generated words ready, using fallback")
+		return g.fallback.NextWord(rng, used)
+	}
+	return word, nil
+}
+
+// Close abandons any request in flight and waits for it to return
+func (g *Generator) Close() {
+	g.cancel()
+	g.wg.Wait()
+}
+
+// refill fetches and screens a batch of words in the background, unless a
+// batch is already on its way (caller must hold mu)
+func (g *Generator) refill() {
+	if g.refilling || g.ctx.Err() != nil {
+		return
+	}
+	g.refilling = true
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		words, err := g.generate(g.ctx, g.cfg.CacheSize)
+		if err == nil {
+			words, err = g.screen(g.ctx, words)
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.refilling = false
+
+		if err != nil {
+			g.logger.Warn("failed to generate secret words", "error", err)
+			return
+		}
+
+		if len(g.seen) > seenLimit*g.cfg.CacheSize {
+			g.seen = make(map[string]bool)
+		}
+		added := 0
+		for _, word := range words {
+			if len(g.cache) >= g.cfg.CacheSize {
+				break
+			}
+			if !g.seen[word] {
+				g.seen[word] = true
+				g.cache = append(g.cache, word)
+				added++
+			}
+		}
+		g.logger.Debug("generated secret words", "added", added, "cached", len(g.cache))
+	}()
+}
+
+// chatRequest is an OpenAI-compatible chat completions request
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatResponse is the part of a chat completions response the generator reads
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// generate asks the API for count secret words
+func (g *Generator) generate(ctx context.Context, count int) ([]string, error) {
+	prompt := fmt.Sprintf("Suggest %d secret words for a party game where players each give a one-word clue "+
+		"about the secret word while one player, the imposter, does not know it and must bluff. "+
+		"Choose common, concrete, family-friendly English nouns that invite many different clues. "+
+		"Use single lowercase words without proper nouns. Reply with only a JSON array of strings.", count)
+	if g.cfg.Theme != "" {
+		prompt += " Theme: " + g.cfg.Theme + "."
+	}
+
+	var resp chatResponse
+	err := g.post(ctx, "/chat/completions", &chatRequest{
+		Model:       g.cfg.Model,
+		Messages:    []chatMessage{{Role: "user", Content: prompt}},
+		Temperature: 1,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("response has no choices")
+	}
+
+	return parseWords(resp.Choices[0].Message.Content), nil
+}
+
+// parseWords reads the words from a reply, which should be a JSON array but
+// may be wrapped in prose or a code block, or be a plain list
+func parseWords(content string) []string {
+	var words []string
+	if start, end := strings.Index(content, "["), strings.LastIndex(content, "]"); start >= 0 && end > start {
+		if json.Unmarshal([]byte(content[start:end+1]), &words) == nil {
+			return words
+		}
+	}
+
+	return strings.FieldsFunc(content, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '"'
+	})
+}
+
+// moderationRequest is an OpenAI-compatible moderations request
+type moderationRequest struct {
+	Input []string `json:"input"`
+}
+
+// moderationResponse has one result per input
+type moderationResponse struct {
+	Results []struct {
+		Flagged bool `json:"flagged"`
+	} `json:"results"`
+}
+
+// screen keeps the words fit to be secret words: well formed, not blocked
+// and, if moderation is on, not flagged by the API. If moderation fails, no
+// words are kept.
+func (g *Generator) screen(ctx context.Context, words []string) ([]string, error) {
+	kept := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if validWord.MatchString(word) && !g.blocked[word] {
+			kept = append(kept, word)
+		}
+	}
+
+	if !g.cfg.Moderation || len(kept) == 0 {
+		return kept, nil
+	}
+
+	var resp moderationResponse
+	if err := g.post(ctx, "/moderations", &moderationRequest{Input: kept}, &resp); err != nil {
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	if len(resp.Results) != len(kept) {
+		return nil, fmt.Errorf("moderation returned %d results for %d words", len(resp.Results), len(kept))
+	}
+
+	allowed := kept[:0]
+	for i, word := range kept {
+		if !resp.Results[i].Flagged {
+			allowed = append(allowed, word)
+		}
+	}
+	return allowed, nil
+}
+
+// post sends body as JSON to path under the API URL and decodes the reply into out
+func (g *Generator) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(g.cfg.URL, "/")+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.cfg.APIKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
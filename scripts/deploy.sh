@@ -41,7 +41,12 @@ echo ""
 
 # Step 1: Build
 echo -e "${YELLOW}[1/4]${NC} Building for Linux..."
-GOOS=linux GOARCH=amd64 go build -ldflags="-s -w" -o bin/server-linux ./cmd/server
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT=$(git rev-parse HEAD 2>/dev/null || true)
+BUILD_DATE=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+GOOS=linux GOARCH=amd64 go build \
+    -ldflags="-s -w -X imposter/internal/buildinfo.Version=${VERSION} -X imposter/internal/buildinfo.Commit=${COMMIT} -X imposter/internal/buildinfo.Date=${BUILD_DATE}" \
+    -o bin/server-linux ./cmd/server
 echo -e "${GREEN}✓${NC} Build complete"
 
 # Step 2: Upload binary